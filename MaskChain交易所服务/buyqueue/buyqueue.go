@@ -0,0 +1,243 @@
+// Package buyqueue 是交易所的购币任务队列。每个购币请求带有客户端给出的幂等键，
+// 作为一个任务持久化到本地JSON文件，由后台的Worker逐个构造、签名并发送购币交易，
+// 再跟踪交易直到上链或失败。重复提交同一幂等键只返回已有任务，不会重复发行。
+package buyqueue
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnknownJob 队列中没有该幂等键的任务
+	ErrUnknownJob = errors.New("unknown purchase")
+	// ErrConflict 幂等键已被参数不同的请求使用
+	ErrConflict = errors.New("purchase id already used by another request")
+)
+
+// 任务的状态
+const (
+	StatusPending = "pending" // 排队中或交易已发送尚未上链
+	StatusMined   = "mined"   // 交易已上链
+	StatusFailed  = "failed"  // 未发行，可用新的幂等键重新购买
+)
+
+// Request 一个购币请求，ID为客户端给出的幂等键
+type Request struct {
+	ID     string `json:"id"`
+	G1     string `json:"g1"`
+	G2     string `json:"g2"`
+	P      string `json:"p"`
+	H      string `json:"h"`
+	Amount uint64 `json:"amount"`
+}
+
+// Receipt 返回给用户的购币回执，用户用私钥解密r后即可打开承诺，
+// 并由r的密文和花费公钥求得隐匿花费所需的花费私钥
+type Receipt struct {
+	Cmv      string `json:"cmv"`
+	Epkrc1   string `json:"epkrc1"`
+	Epkrc2   string `json:"epkrc2"`
+	Spendkey string `json:"spendkey"`
+}
+
+// Job 一个购币任务及其处理状态
+type Job struct {
+	Request
+	Status   string   `json:"status"`
+	Receipt  *Receipt `json:"receipt,omitempty"`  // 交易签名后给出
+	Hash     string   `json:"hash,omitempty"`     // 购币交易哈希
+	Nonce    uint64   `json:"nonce"`              // 交易的nonce，重发时保持不变
+	Raw      string   `json:"raw,omitempty"`      // 已签名的交易，为空表示尚未签名
+	Block    uint64   `json:"block,omitempty"`    // 上链的区块号
+	Error    string   `json:"error,omitempty"`    // 最近一次失败的原因
+	Attempts int      `json:"attempts"`           // 签名的尝试次数，签名后为广播连续被节点拒绝的次数
+	Released bool     `json:"released,omitempty"` // nonce已释放给下一笔交易，不再重发，只等待该nonce被占用
+	Created  int64    `json:"created"`            // 提交的Unix时间
+}
+
+// signed 任务的交易是否已签名。已签名的任务只会重发同一笔交易
+func (j *Job) signed() bool { return j.Raw != "" }
+
+// Queue 持久化的购币任务队列
+type Queue struct {
+	path string
+	lock sync.Mutex
+	wake chan struct{}
+
+	Jobs      map[string]*Job `json:"jobs"`           // 以幂等键为索引
+	NextNonce uint64          `json:"nextNonce"`      // 下一笔交易至少使用的nonce
+	Free      []uint64        `json:"free,omitempty"` // 交易被拒绝而释放的nonce，按升序排列
+}
+
+// Open 打开（不存在时新建）指定路径的任务队列
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path, wake: make(chan struct{}, 1), Jobs: make(map[string]*Job)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, err
+	}
+	if q.Jobs == nil {
+		q.Jobs = make(map[string]*Job)
+	}
+	return q, nil
+}
+
+// Save 将队列写回文件，先写临时文件再替换，避免写入中断损坏队列
+func (q *Queue) Save() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.save()
+}
+
+func (q *Queue) save() error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0700); err != nil {
+		return err
+	}
+	tmp := q.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+// Submit 提交购币请求并持久化。幂等键已存在时返回已有任务和false，
+// 参数不同则返回ErrConflict
+func (q *Queue) Submit(req Request) (Job, bool, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if job, ok := q.Jobs[req.ID]; ok {
+		if job.Request != req {
+			return Job{}, false, ErrConflict
+		}
+		return *job, false, nil
+	}
+	job := &Job{Request: req, Status: StatusPending, Created: time.Now().Unix()}
+	q.Jobs[req.ID] = job
+	if err := q.save(); err != nil {
+		delete(q.Jobs, req.ID)
+		return Job{}, false, err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return *job, true, nil
+}
+
+// Get 返回幂等键id的任务
+func (q *Queue) Get(id string) (Job, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	job, ok := q.Jobs[id]
+	if !ok {
+		return Job{}, ErrUnknownJob
+	}
+	return *job, nil
+}
+
+// pending 按提交顺序返回尚未完成的任务
+func (q *Queue) pending() []Job {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var jobs []Job
+	for _, job := range q.Jobs {
+		if job.Status == StatusPending {
+			jobs = append(jobs, *job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Created != jobs[j].Created {
+			return jobs[i].Created < jobs[j].Created
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs
+}
+
+// update 以job替换队列中的任务并持久化
+func (q *Queue) update(job Job) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.Jobs[job.ID] = &job
+	if job.signed() && !job.Released {
+		if job.Nonce >= q.NextNonce {
+			q.NextNonce = job.Nonce + 1
+		}
+		for i, nonce := range q.Free {
+			if nonce == job.Nonce {
+				q.Free = append(q.Free[:i], q.Free[i+1:]...)
+				break
+			}
+		}
+	}
+	return q.save()
+}
+
+// release 以job替换队列中的任务并释放它的nonce。交易被节点拒绝后该nonce空缺，
+// 之后nonce更大的交易都无法上链，因此下一笔交易重新使用它。任务仍为pending，
+// 直到该nonce被这笔或下一笔交易占用
+func (q *Queue) release(job Job) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	job.Released = true
+	q.Jobs[job.ID] = &job
+	q.Free = append(q.Free, job.Nonce)
+	sort.Slice(q.Free, func(i, j int) bool { return q.Free[i] < q.Free[j] })
+	return q.save()
+}
+
+// nonce 返回下一笔交易使用的nonce：优先使用最小的已释放nonce，否则取节点pending nonce
+// 与NextNonce中的较大者。小于pending nonce的已释放nonce已被其他交易占用，不再使用
+func (q *Queue) nonce(pending uint64) uint64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for len(q.Free) > 0 && q.Free[0] < pending {
+		q.Free = q.Free[1:]
+	}
+	if len(q.Free) > 0 {
+		return q.Free[0]
+	}
+	if q.NextNonce > pending {
+		return q.NextNonce
+	}
+	return pending
+}
+
+// View 任务对用户可见的状态。交易签名后包含购币回执的承诺与r的密文
+type View struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	*Receipt
+	Hash   string `json:"hash,omitempty"`
+	Block  uint64 `json:"block,omitempty"`
+	Amount uint64 `json:"amount"`
+	Error  string `json:"error,omitempty"`
+}
+
+// View 返回任务对用户可见的状态
+func (j Job) View() View {
+	return View{ID: j.ID, Status: j.Status, Receipt: j.Receipt, Hash: j.Hash, Block: j.Block, Amount: j.Amount, Error: j.Error}
+}
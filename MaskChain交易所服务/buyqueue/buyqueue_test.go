@@ -0,0 +1,284 @@
+package buyqueue
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"exchange/issuedb"
+	"exchange/utils"
+)
+
+// fakeNode 模拟节点：签名的交易以nonce区分，mine使交易上链
+type fakeNode struct {
+	pending  uint64            // 交易池中的nonce
+	latest   uint64            // 已上链的nonce
+	signed   map[string]string // 交易哈希 -> 签名的交易
+	sent     map[string]int    // 交易哈希 -> 广播次数
+	receipts map[string]*utils.RPCReceipt
+	known    map[string]bool // 在其他节点广播、本节点也已知道的交易
+	fail     error           // SignTransaction返回的错误
+	reject   error           // SendRawTransaction返回的错误
+}
+
+func newFakeNode() *fakeNode {
+	return &fakeNode{signed: make(map[string]string), sent: make(map[string]int), receipts: make(map[string]*utils.RPCReceipt), known: make(map[string]bool)}
+}
+
+func (n *fakeNode) Nonce(account, tag string) (uint64, error) {
+	if tag == "pending" {
+		return n.pending, nil
+	}
+	return n.latest, nil
+}
+
+func (n *fakeNode) SignTransaction(tx utils.SendTx) (string, string, error) {
+	if n.fail != nil {
+		return "", "", n.fail
+	}
+	hash := fmt.Sprintf("0x%x%s", len(n.signed), tx.Nonce[2:])
+	n.signed[hash] = tx.Nonce
+	return "0xraw" + hash[2:], hash, nil
+}
+
+func (n *fakeNode) SendRawTransaction(raw string) (string, error) {
+	if n.reject != nil {
+		return "", n.reject
+	}
+	hash := "0x" + raw[5:]
+	if n.sent[hash]++; n.sent[hash] > 1 {
+		return "", errors.New("known transaction: " + hash)
+	}
+	n.pending++
+	return hash, nil
+}
+
+func (n *fakeNode) TransactionReceipt(hash string) (*utils.RPCReceipt, error) {
+	if r, ok := n.receipts[hash]; ok {
+		return r, nil
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (n *fakeNode) TransactionByHash(hash string) (*utils.RPCTransaction, error) {
+	if n.sent[hash] > 0 || n.known[hash] {
+		return &utils.RPCTransaction{Hash: hash}, nil
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (n *fakeNode) mine(hash string, block uint64) {
+	n.receipts[hash] = &utils.RPCReceipt{TransactionHash: hash, BlockNumber: fmt.Sprintf("0x%x", block), Status: "0x1"}
+	n.latest++
+}
+
+func newTestWorker(t *testing.T, node *fakeNode) *Worker {
+	dir := t.TempDir()
+	q, err := Open(filepath.Join(dir, "purchases.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger, _ := issuedb.Open(filepath.Join(dir, "issuance.json"))
+	built := 0
+	build := func(req Request) (utils.SendTx, Receipt, error) {
+		built++
+		cmv := fmt.Sprintf("0x%02x", built)
+		return utils.SendTx{CmV: cmv}, Receipt{Cmv: cmv}, nil
+	}
+	return &Worker{Queue: q, Ledger: ledger, Node: node, Build: build, Account: "0x01"}
+}
+
+func TestIdempotentPurchase(t *testing.T) {
+	node := newFakeNode()
+	w := newTestWorker(t, node)
+
+	req := Request{ID: "a", H: "0x11", Amount: 100}
+	if _, created, err := w.Queue.Submit(req); err != nil || !created {
+		t.Fatalf("submit: created %v, err %v", created, err)
+	}
+	w.Process()
+	job, _ := w.Queue.Get("a")
+	if job.Status != StatusPending || job.Hash == "" || job.Receipt == nil {
+		t.Fatalf("purchase not sent: %+v", job)
+	}
+	// 重复提交与重试不会再次签名
+	if again, created, err := w.Queue.Submit(req); err != nil || created || again.Hash != job.Hash {
+		t.Errorf("resubmitted purchase: %+v, created %v, err %v", again, created, err)
+	}
+	if _, _, err := w.Queue.Submit(Request{ID: "a", H: "0x11", Amount: 200}); err != ErrConflict {
+		t.Errorf("conflicting purchase: have %v, want %v", err, ErrConflict)
+	}
+	w.Process()
+	if len(node.signed) != 1 || node.sent[job.Hash] != 2 {
+		t.Errorf("retry did not rebroadcast the signed transaction: signed %v, sent %v", node.signed, node.sent)
+	}
+	node.mine(job.Hash, 7)
+	w.Process()
+	if job, _ = w.Queue.Get("a"); job.Status != StatusMined || job.Block != 7 {
+		t.Errorf("purchase not mined: %+v", job)
+	}
+	if is, _ := w.Ledger.Get(job.Hash); is.Status != issuedb.StatusMined || is.Amount != 100 || is.Block != 7 {
+		t.Errorf("issuance not recorded: %+v", is)
+	}
+
+	// 队列重新打开后保留任务与nonce
+	q, err := Open(w.Queue.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, err := q.Get("a"); err != nil || job.Status != StatusMined || q.NextNonce != 1 {
+		t.Errorf("queue not restored: %+v, next nonce %d, err %v", job, q.NextNonce, err)
+	}
+}
+
+func TestPurchaseNonceTaken(t *testing.T) {
+	node := newFakeNode()
+	w := newTestWorker(t, node)
+
+	w.Queue.Submit(Request{ID: "a", Amount: 1})
+	w.Process()
+	job, _ := w.Queue.Get("a")
+
+	// 另一笔交易占用了该nonce，这笔交易永远无法上链
+	node.latest = job.Nonce + 1
+	w.Process()
+	if job, _ = w.Queue.Get("a"); job.Status != StatusFailed || job.Error == "" {
+		t.Errorf("purchase with taken nonce not failed: %+v", job)
+	}
+	if is, _ := w.Ledger.Get(job.Hash); is.Status != issuedb.StatusFailed {
+		t.Errorf("failed issuance not recorded: %+v", is)
+	}
+
+	// 后续任务使用新的nonce
+	w.Queue.Submit(Request{ID: "b", Amount: 2})
+	w.Process()
+	if next, _ := w.Queue.Get("b"); next.Nonce <= job.Nonce {
+		t.Errorf("nonce %d reused after %d", next.Nonce, job.Nonce)
+	}
+}
+
+func TestPurchaseSignRetry(t *testing.T) {
+	node := newFakeNode()
+	node.fail = errors.New("account locked")
+	w := newTestWorker(t, node)
+
+	w.Queue.Submit(Request{ID: "a", Amount: 1})
+	for i := 0; i < MaxAttempts-1; i++ {
+		w.Process()
+	}
+	if job, _ := w.Queue.Get("a"); job.Status != StatusPending || job.Attempts != MaxAttempts-1 {
+		t.Fatalf("unsigned purchase not retried: %+v", job)
+	}
+	w.Process()
+	if job, _ := w.Queue.Get("a"); job.Status != StatusFailed || job.Error != "account locked" {
+		t.Errorf("purchase not failed after %d attempts: %+v", MaxAttempts, job)
+	}
+}
+
+func TestPurchaseBroadcastRejected(t *testing.T) {
+	node := newFakeNode()
+	node.reject = errors.New("connection refused")
+	w := newTestWorker(t, node)
+
+	w.Queue.Submit(Request{ID: "a", Amount: 1})
+	for i := 0; i < MaxAttempts; i++ {
+		w.Process()
+	}
+	// 连接节点失败不算交易被拒绝
+	job, _ := w.Queue.Get("a")
+	if job.Status != StatusPending || job.Attempts != 0 || job.Error == "" {
+		t.Fatalf("purchase failed without rejection: %+v", job)
+	}
+	// 交易池一直拒绝的交易在MaxAttempts次后失败
+	node.reject = &utils.RPCError{Method: "eth_sendRawTransaction", Message: "insufficient funds for gas * price + value"}
+	for i := 0; i < MaxAttempts-1; i++ {
+		w.Process()
+	}
+	if job, _ = w.Queue.Get("a"); job.Status != StatusPending || job.Attempts != MaxAttempts-1 {
+		t.Fatalf("rejected purchase not retried: %+v", job)
+	}
+	w.Process()
+	// 连续被拒绝MaxAttempts次后释放nonce，任务仍等待该nonce被占用
+	if job, _ = w.Queue.Get("a"); job.Status != StatusPending || !job.Released || job.Error != node.reject.Error() {
+		t.Fatalf("nonce not released after %d rejections: %+v", MaxAttempts, job)
+	}
+	if is, _ := w.Ledger.Get(job.Hash); is.Status != issuedb.StatusPending {
+		t.Errorf("released issuance settled early: %+v", is)
+	}
+
+	// 后续任务重新使用空缺的nonce，不被阻塞；释放的交易不再重发
+	node.reject = nil
+	w.Queue.Submit(Request{ID: "b", Amount: 2})
+	w.Queue.Submit(Request{ID: "c", Amount: 3})
+	w.Process()
+	b, _ := w.Queue.Get("b")
+	c, _ := w.Queue.Get("c")
+	if b.Nonce != job.Nonce || c.Nonce != job.Nonce+1 || len(w.Queue.Free) != 0 {
+		t.Errorf("nonce %d not reused: b %d, c %d, free %v", job.Nonce, b.Nonce, c.Nonce, w.Queue.Free)
+	}
+	if node.sent[job.Hash] != 0 {
+		t.Errorf("released purchase rebroadcast: sent %v", node.sent)
+	}
+	// 该nonce被下一笔交易占用后任务才失败
+	node.mine(b.Hash, 4)
+	w.Process()
+	if job, _ = w.Queue.Get("a"); job.Status != StatusFailed {
+		t.Errorf("released purchase not failed after its nonce was used: %+v", job)
+	}
+	if is, _ := w.Ledger.Get(job.Hash); is.Status != issuedb.StatusFailed {
+		t.Errorf("rejected issuance not recorded: %+v", is)
+	}
+}
+
+func TestPurchaseRejectedStillKnown(t *testing.T) {
+	node := newFakeNode()
+	node.reject = &utils.RPCError{Method: "eth_sendRawTransaction", Message: "replacement transaction underpriced"}
+	w := newTestWorker(t, node)
+
+	w.Queue.Submit(Request{ID: "a", Amount: 1})
+	w.Process()
+	job, _ := w.Queue.Get("a")
+
+	// 节点仍知道这笔交易时它可能上链，nonce不释放
+	node.known[job.Hash] = true
+	for i := 0; i < MaxAttempts; i++ {
+		w.Process()
+	}
+	if job, _ = w.Queue.Get("a"); job.Status != StatusPending || job.Released || len(w.Queue.Free) != 0 {
+		t.Fatalf("nonce of a known transaction released: %+v, free %v", job, w.Queue.Free)
+	}
+	// 交易最终上链，任务照常完成
+	node.reject = nil
+	node.mine(job.Hash, 5)
+	w.Process()
+	if job, _ = w.Queue.Get("a"); job.Status != StatusMined || job.Block != 5 {
+		t.Errorf("known purchase not mined: %+v", job)
+	}
+}
+
+func TestPurchaseLedgerReconcile(t *testing.T) {
+	node := newFakeNode()
+	w := newTestWorker(t, node)
+
+	// 任务已持久化为已签名，记账前中断
+	job, _, _ := w.Queue.Submit(Request{ID: "a", H: "0x11", Amount: 100})
+	if err := w.sign(&job); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Queue.update(job); err != nil {
+		t.Fatal(err)
+	}
+	w.Process()
+	if is, err := w.Ledger.Get(job.Hash); err != nil || is.Status != issuedb.StatusPending || is.Amount != 100 {
+		t.Fatalf("signed purchase not recorded: %+v, err %v", is, err)
+	}
+	if node.sent[job.Hash] != 1 {
+		t.Errorf("signed purchase not broadcast: sent %v", node.sent)
+	}
+	node.mine(job.Hash, 3)
+	w.Process()
+	if is, _ := w.Ledger.Get(job.Hash); is.Status != issuedb.StatusMined || is.Block != 3 {
+		t.Errorf("issuance not mined: %+v", is)
+	}
+}
@@ -0,0 +1,253 @@
+package buyqueue
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"exchange/issuedb"
+	"exchange/utils"
+)
+
+// MaxAttempts 尚未签名的任务最多尝试签名的次数，已签名的任务交易最多连续被节点拒绝的次数，
+// 之后任务失败
+const MaxAttempts = 5
+
+// Node 处理购币任务所需的节点接口，由utils.NodeClient实现
+type Node interface {
+	Nonce(account, tag string) (uint64, error)
+	SignTransaction(tx utils.SendTx) (raw string, hash string, err error)
+	SendRawTransaction(raw string) (string, error)
+	TransactionReceipt(hash string) (*utils.RPCReceipt, error)
+	TransactionByHash(hash string) (*utils.RPCTransaction, error)
+}
+
+// Builder 为购币请求构造购币交易（不含nonce）及给用户的回执。每次调用使用新的随机数，
+// 各请求的承诺与密文互不共享
+type Builder func(req Request) (utils.SendTx, Receipt, error)
+
+// Worker 逐个处理队列中的购币任务：构造并签名交易、广播，再跟踪交易直到上链或失败。
+//
+// 交易先由节点以确定的nonce签名并持久化，之后只广播这同一笔交易，重试也不重新构造。
+// 因此一个任务至多发行一次：要么这笔交易上链，要么它的nonce被其他交易占用而永远无法上链。
+// 节点一直拒绝且不知道的交易，在它的nonce尚未被占用时释放该nonce，由下一笔交易重新使用，
+// 使之后的交易不被阻塞。这笔交易可能仍在其他节点的交易池中，任务因此保持pending，
+// 直到该nonce被占用后才按这笔交易是否上链确定结果。
+type Worker struct {
+	Queue   *Queue
+	Ledger  *issuedb.DB // 发行账本，交易签名后记录
+	Node    Node
+	Build   Builder
+	Account string      // 发行者的链上账户
+	Unlock  func() bool // 签名前解锁账户，可为nil
+}
+
+// Process 签名并发送所有尚未签名的任务，跟踪已发送的任务
+func (w *Worker) Process() {
+	for _, job := range w.Queue.pending() {
+		var err error
+		if job.signed() {
+			err = w.track(job)
+		} else {
+			err = w.send(job)
+		}
+		if err != nil {
+			log.Printf("purchase %s: %v\n", job.ID, err)
+		}
+	}
+}
+
+// send 构造、签名并广播任务的交易。签名前出错的任务留待重试，超过MaxAttempts次后失败
+func (w *Worker) send(job Job) error {
+	job.Attempts++
+	if err := w.sign(&job); err != nil {
+		job.Error = err.Error()
+		if job.Attempts >= MaxAttempts {
+			job.Status = StatusFailed
+		}
+		if serr := w.Queue.update(job); serr != nil {
+			return serr
+		}
+		return err
+	}
+	job.Attempts, job.Error = 0, ""
+	if err := w.Queue.update(job); err != nil {
+		return err
+	}
+	if err := w.record(job); err != nil {
+		return err
+	}
+	return w.broadcast(job)
+}
+
+// sign 构造任务的交易并以Queue.nonce分配的nonce签名
+func (w *Worker) sign(job *Job) error {
+	tx, receipt, err := w.Build(job.Request)
+	if err != nil {
+		return err
+	}
+	if w.Unlock != nil && !w.Unlock() {
+		return errors.New("unlock exchange account failed")
+	}
+	pending, err := w.Node.Nonce(w.Account, "pending")
+	if err != nil {
+		return err
+	}
+	nonce := w.Queue.nonce(pending)
+	tx.Nonce = fmt.Sprintf("0x%x", nonce)
+	raw, hash, err := w.Node.SignTransaction(tx)
+	if err != nil {
+		return err
+	}
+	job.Nonce, job.Raw, job.Hash, job.Receipt = nonce, raw, strings.ToLower(hash), &receipt
+	return nil
+}
+
+// record 将已签名任务的交易记入发行账本。任务先持久化为已签名再记账，
+// 两者之间中断的任务由track再次调用record补记
+func (w *Worker) record(job Job) error {
+	added := w.Ledger.Add(issuedb.Issuance{
+		Hash:   job.Hash,
+		CmV:    job.Receipt.Cmv,
+		Amount: job.Amount,
+		User:   job.H,
+		Time:   job.Created,
+	})
+	if !added {
+		return nil
+	}
+	return w.Ledger.Save()
+}
+
+// broadcast 广播任务已签名的交易，节点已有该交易不算错误。节点拒绝交易（返回RPCError）时
+// 计入Attempts，连续被拒绝MaxAttempts次后由release决定是否释放nonce；
+// 连接节点失败等其他错误只记录
+func (w *Worker) broadcast(job Job) error {
+	_, err := w.Node.SendRawTransaction(job.Raw)
+	if err == nil || isKnown(err) {
+		if job.Attempts == 0 && job.Error == "" {
+			return nil
+		}
+		job.Attempts, job.Error = 0, ""
+		return w.Queue.update(job)
+	}
+	job.Error = err.Error()
+	var rpcErr *utils.RPCError
+	if errors.As(err, &rpcErr) {
+		job.Attempts++
+	}
+	if job.Attempts < MaxAttempts {
+		if serr := w.Queue.update(job); serr != nil {
+			return serr
+		}
+		return err
+	}
+	if serr := w.release(job); serr != nil {
+		return serr
+	}
+	return err
+}
+
+// release 释放连续被拒绝的任务的nonce，交给下一笔交易使用。只有账户已上链的nonce仍不超过
+// 交易的nonce、且节点不知道这笔交易时才释放，否则这笔交易仍可能上链，任务保持原样，
+// 由track继续跟踪直到该nonce被占用
+func (w *Worker) release(job Job) error {
+	latest, err := w.Node.Nonce(w.Account, "latest")
+	if err != nil {
+		return err
+	}
+	if latest > job.Nonce {
+		return w.Queue.update(job)
+	}
+	if _, err := w.Node.TransactionByHash(job.Hash); !errors.Is(err, utils.ErrNotFound) {
+		if err != nil {
+			return err
+		}
+		return w.Queue.update(job)
+	}
+	return w.Queue.release(job)
+}
+
+// track 查询已发送任务的交易。有回执即上链（或执行失败）；没有回执时，若账户已上链的
+// nonce超过交易的nonce，则该nonce已被其他交易占用，任务失败，否则重发交易。
+// nonce已释放的任务不再重发，只等待该nonce被占用
+func (w *Worker) track(job Job) error {
+	if err := w.record(job); err != nil {
+		return err
+	}
+	receipt, err := w.Node.TransactionReceipt(job.Hash)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return err
+	}
+	if receipt == nil {
+		nonce, err := w.Node.Nonce(w.Account, "latest")
+		if err != nil {
+			return err
+		}
+		if nonce <= job.Nonce {
+			if job.Released {
+				return nil
+			}
+			return w.broadcast(job)
+		}
+		// 读取nonce之前交易可能刚刚上链，再查询一次回执
+		if receipt, err = w.Node.TransactionReceipt(job.Hash); err != nil && !errors.Is(err, utils.ErrNotFound) {
+			return err
+		}
+	}
+	switch {
+	case receipt == nil:
+		job.Status, job.Error = StatusFailed, fmt.Sprintf("nonce %d used by another transaction", job.Nonce)
+	case receipt.Status == "0x0":
+		job.Status, job.Error = StatusFailed, "transaction failed"
+	default:
+		job.Status, job.Error = StatusMined, ""
+	}
+	if receipt != nil {
+		if job.Block, err = utils.ParseHexUint(receipt.BlockNumber); err != nil {
+			return err
+		}
+	}
+	if err := w.Queue.update(job); err != nil {
+		return err
+	}
+	return w.settle(job)
+}
+
+// settle 将已完成任务的状态写入发行账本
+func (w *Worker) settle(job Job) error {
+	if err := w.Ledger.SetStatus(job.Hash, ledgerStatus(job.Status), job.Block); err != nil {
+		return err
+	}
+	return w.Ledger.Save()
+}
+
+func ledgerStatus(status string) string {
+	if status == StatusMined {
+		return issuedb.StatusMined
+	}
+	return issuedb.StatusFailed
+}
+
+// isKnown 判断广播错误是否表示节点已有该交易
+func isKnown(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "known transaction") || strings.Contains(msg, "already known")
+}
+
+// Run 处理队列直到quit关闭：有新任务提交时立即处理，否则每隔interval处理一次
+func (w *Worker) Run(interval time.Duration, quit <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.Process()
+		select {
+		case <-w.Queue.wake:
+		case <-ticker.C:
+		case <-quit:
+			return
+		}
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/labstack/echo v3.3.10+incompatible
 	github.com/labstack/gommon v0.3.0 // indirect
 	github.com/urfave/cli v1.22.4
+	maskchain/privacy v0.0.0
 )
 
 replace (
@@ -18,4 +19,5 @@ replace (
 	golang.org/x/sys => github.com/golang/sys v0.0.0-20190830142957-1e83adbbebd0
 	golang.org/x/text v0.3.0 => github.com/golang/text v0.3.0
 	golang.org/x/tools v0.0.0-20181221001348-537d06c36207 => github.com/golang/tools v0.0.0-20181221001348-537d06c36207
+	maskchain/privacy => ../MaskChain隐私计算
 )
@@ -0,0 +1,168 @@
+// Package issuedb 是交易所本地的发行账本。它以交易哈希为索引记录每笔购币交易发行的
+// 承诺、金额、购币用户以及交易是否已上链（由buyqueue在跟踪交易时更新），审计者可据此
+// 核对链上发行总额与交易所的法币储备。
+//
+// 账本以JSON文件的形式保存在本地。
+package issuedb
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownIssuance 账本中没有该交易
+var ErrUnknownIssuance = errors.New("unknown issuance")
+
+// 购币交易的状态
+const (
+	StatusPending = "pending" // 已发送，尚未上链
+	StatusMined   = "mined"   // 已上链
+	StatusFailed  = "failed"  // 执行失败或nonce已被其他交易占用，未发行
+)
+
+// Issuance 一笔购币交易的发行记录
+type Issuance struct {
+	Hash   string `json:"hash"`            // 购币交易哈希
+	CmV    string `json:"cmv"`             // 发行的承诺
+	Amount uint64 `json:"amount"`          // 发行金额
+	User   string `json:"user"`            // 购币用户公钥的H
+	Status string `json:"status"`          // pending, mined或failed
+	Block  uint64 `json:"block,omitempty"` // 上链的区块号
+	Time   int64  `json:"time"`            // 提交购币请求的Unix时间
+}
+
+// Totals 已上链的发行总额与笔数
+type Totals struct {
+	Amount    uint64 `json:"amount"`
+	Purchases uint64 `json:"purchases"`
+	Pending   uint64 `json:"pending"` // 尚未上链的笔数
+}
+
+// DB 交易所的发行账本
+type DB struct {
+	path string
+	lock sync.Mutex
+
+	Issuances map[string]*Issuance `json:"issuances"` // 以交易哈希为索引
+}
+
+// Open 打开（不存在时新建）指定路径的发行账本
+func Open(path string) (*DB, error) {
+	db := &DB{path: path, Issuances: make(map[string]*Issuance)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, db); err != nil {
+		return nil, err
+	}
+	if db.Issuances == nil {
+		db.Issuances = make(map[string]*Issuance)
+	}
+	return db, nil
+}
+
+// Save 将账本写回文件，先写临时文件再替换，避免写入中断损坏账本
+func (db *DB) Save() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(db.path), 0700); err != nil {
+		return err
+	}
+	tmp := db.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, db.path)
+}
+
+// Add 记录一笔已发送的购币交易，已记录过的交易返回false
+func (db *DB) Add(is Issuance) bool {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	is.Hash = strings.ToLower(is.Hash)
+	if _, ok := db.Issuances[is.Hash]; ok {
+		return false
+	}
+	if is.Status == "" {
+		is.Status = StatusPending
+	}
+	db.Issuances[is.Hash] = &is
+	return true
+}
+
+// Get 返回交易hash的发行记录
+func (db *DB) Get(hash string) (Issuance, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	is, ok := db.Issuances[strings.ToLower(hash)]
+	if !ok {
+		return Issuance{}, ErrUnknownIssuance
+	}
+	return *is, nil
+}
+
+// List 按发送时间顺序返回发行记录
+func (db *DB) List() []Issuance {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	list := make([]Issuance, 0, len(db.Issuances))
+	for _, is := range db.Issuances {
+		list = append(list, *is)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Time != list[j].Time {
+			return list[i].Time < list[j].Time
+		}
+		return list[i].Hash < list[j].Hash
+	})
+	return list
+}
+
+// SetStatus 更新交易hash的状态及上链的区块号
+func (db *DB) SetStatus(hash, status string, block uint64) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	is, ok := db.Issuances[strings.ToLower(hash)]
+	if !ok {
+		return ErrUnknownIssuance
+	}
+	is.Status, is.Block = status, block
+	return nil
+}
+
+// Totals 返回已上链的发行总额，应与节点maskchain_totalIssued的结果一致
+func (db *DB) Totals() Totals {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	var totals Totals
+	for _, is := range db.Issuances {
+		switch is.Status {
+		case StatusMined:
+			totals.Amount += is.Amount
+			totals.Purchases++
+		case StatusPending:
+			totals.Pending++
+		}
+	}
+	return totals
+}
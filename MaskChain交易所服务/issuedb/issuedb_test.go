@@ -0,0 +1,53 @@
+package issuedb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "issuance.json")
+	db, _ := Open(path)
+	for i, is := range []Issuance{
+		{Hash: "0xAA", Amount: 100, Time: 1},
+		{Hash: "0xbb", Amount: 20, Time: 2},
+		{Hash: "0xcc", Amount: 5, Time: 3},
+	} {
+		if !db.Add(is) {
+			t.Fatalf("issuance %d not added", i)
+		}
+	}
+	if db.Add(Issuance{Hash: "0xaa"}) {
+		t.Errorf("issuance added twice")
+	}
+	if err := db.SetStatus("0xaa", StatusMined, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetStatus("0xBB", StatusFailed, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetStatus("0xee", StatusMined, 1); err != ErrUnknownIssuance {
+		t.Errorf("set status of unknown issuance: have %v, want %v", err, ErrUnknownIssuance)
+	}
+	if err := db.Save(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for hash, want := range map[string]string{"0xaa": StatusMined, "0xbb": StatusFailed, "0xcc": StatusPending} {
+		if is, _ := db.Get(hash); is.Status != want {
+			t.Errorf("%s: have status %q, want %q", hash, is.Status, want)
+		}
+	}
+	if is, _ := db.Get("0xAA"); is.Block != 3 {
+		t.Errorf("mined block not recorded: %+v", is)
+	}
+	if list := db.List(); len(list) != 3 || list[0].Hash != "0xaa" || list[2].Hash != "0xcc" {
+		t.Errorf("issuances not listed in order: %+v", list)
+	}
+	if totals := db.Totals(); totals != (Totals{Amount: 100, Purchases: 1, Pending: 1}) {
+		t.Errorf("unexpected totals: %+v", totals)
+	}
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
-	ecc "exchange/crypto/ECC"
+	"crypto/subtle"
+	"errors"
+	"exchange/buyqueue"
+	"exchange/issuedb"
+	"exchange/params"
+	"exchange/redeemdb"
 	"exchange/utils"
 	"fmt"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 	"github.com/urfave/cli"
+	"maskchain/privacy/ecc"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -20,19 +27,22 @@ var (
 		utils.KeyFlag,
 		utils.EthAccountFlag,
 		utils.EthKeyFlag,
+		utils.BuyDBFlag,
+		utils.IssueDBFlag,
+		utils.RedeemDBFlag,
+		utils.OperatorTokenFlag,
 	}
 	ethaccount    string
-	usrpub        = ecc.PublicKey{}
 	publisherpub  = ecc.PublicKey{}
 	publisherpriv = ecc.PrivateKey{}
 	regulatorpub  = ecc.PublicKey{}
-	cm_and_r      = ecc.Commitment{}
-	elgamal_info  = ecc.CypherText{}
-	elgamal_r     = ecc.CypherText{}
-	signature     = ecc.Signature{}
+	purchases     *buyqueue.Queue
+	issuances     *issuedb.DB
+	redemptions   *redeemdb.DB
 	ea            string
 	ek            string
 	gk            string
+	operatortoken string
 )
 
 func init() {
@@ -55,8 +65,39 @@ func exchange(ctx *cli.Context) {
 	ea = ctx.String("ethaccount")
 	ek = ctx.String("ethkey")
 	ethaccount = ctx.String("ethaccount")
+	operatortoken = ctx.String("operatortoken")
 	publisherpub, publisherpriv, _ = utils.GenerateKey(gk)
 	regulatorpub = utils.SetRegulator()
+	node := &utils.NodeClient{URL: params.Ethurl}
+	issued, err := issuedb.Open(ctx.String("issuedb"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open issuance database failed:", err)
+		os.Exit(1)
+	}
+	issuances = issued
+	queue, err := buyqueue.Open(ctx.String("buydb"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open purchase queue failed:", err)
+		os.Exit(1)
+	}
+	purchases = queue
+	worker := &buyqueue.Worker{
+		Queue:   purchases,
+		Ledger:  issuances,
+		Node:    node,
+		Build:   buildPurchase,
+		Account: ethaccount,
+		Unlock:  func() bool { return utils.UnlockAccount(ea, ek) },
+	}
+	go worker.Run(5*time.Second, nil)
+	db, err := redeemdb.Open(ctx.String("redeemdb"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open redeem database failed:", err)
+		os.Exit(1)
+	}
+	redemptions = db
+	watcher := redeemdb.NewWatcher(node, publisherpriv)
+	go watcher.Run(redemptions, 15*time.Second, nil)
 	//if utils.UnlockAccount(ea, ek) == true {
 	startNetwork(ctx)
 	//} else {
@@ -74,39 +115,141 @@ func startNetwork(ctx *cli.Context) error {
 	e.Use(middleware.Recover())
 
 	e.POST("/buy", buy)
+	e.GET("/buy/:id", buyStatus)
 	e.GET("/pubpub", pubpub)
 
+	// 发行账本、兑付义务及兑付登记含金额与用户公钥，只对交易所运营者开放
+	issued := e.Group("/issued", operatorAuth())
+	issued.GET("", issuedList)
+	issued.GET("/:hash", issuedGet)
+	redeem := e.Group("/redeem", operatorAuth())
+	redeem.GET("", redeemList)
+	redeem.GET("/:hash", redeemGet)
+	redeem.POST("/:hash", redeemPaid)
+
 	e.Logger.Fatal(e.Start(":" + port))
 	return nil
 }
 
+// buy 接收购币请求。请求以客户端给出的幂等键id（或请求头Idempotency-Key）标识，
+// 入队后立即返回，由后台处理，通过/buy/:id查询结果。同一id重复提交返回已有任务
 func buy(c echo.Context) error {
 	u := new(utils.Purchase)
 	if err := c.Bind(u); err != nil {
 		return err
 	}
-	if u.G1 == "" || u.G2 == "" || u.P == "" || u.H == "" || u.Amount == "" {
+	if u.ID == "" {
+		u.ID = c.Request().Header.Get("Idempotency-Key")
+	}
+	if u.ID == "" || u.G1 == "" || u.G2 == "" || u.P == "" || u.H == "" || u.Amount == "" {
 		return c.JSON(http.StatusCreated, "err params lack")
 	}
+	// 签名的金额即链上统计的发行金额，须为规范的十进制数
+	amount, err := strconv.ParseUint(u.Amount, 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusCreated, "err amount")
+	}
+	req := buyqueue.Request{ID: u.ID, G1: u.G1, G2: u.G2, P: u.P, H: u.H, Amount: amount}
+	if job, err := purchases.Get(req.ID); err == nil {
+		if job.Request != req {
+			return c.JSON(http.StatusConflict, buyqueue.ErrConflict.Error())
+		}
+		return c.JSON(http.StatusOK, job.View())
+	}
 	if utils.Verify(u.H) == false {
 		return c.JSON(http.StatusCreated, "error publickey, please check again or registe now")
-	} else {
-		utils.UnlockAccount(ea, ek)
-		usrpub = utils.CreateUsrPub(u.G1, u.G2, u.P, u.H)
-		//cm_and_r = utils.CreateCM_v(regulatorpub, u.Amount)
-		//elgamal_info = utils.CreateElgamalInfo(regulatorpub, u.Amount, u.H)
-		elgamal_info, cm_and_r = utils.CreateDE_CM(regulatorpub, u.Amount)
-		elgamal_r = utils.CreateElgamalR(usrpub, cm_and_r.R)
-		fmt.Println("you want this one",utils.Byteto0xstring(cm_and_r.R))
-		signature = utils.CreateSign(publisherpriv, u.Amount)
-		//sendTranscation
-		if succ, hash := utils.SendTransaction(elgamal_info, elgamal_r, signature, cm_and_r, ethaccount); succ == true {
-			result := utils.Toreceipt(cm_and_r.Commitment, elgamal_r.C1, elgamal_r.C2, hash)
-			return c.JSON(http.StatusOK, result)
-		} else {
-			return c.JSON(http.StatusCreated, "err send transaction")
-		}
 	}
+	job, created, err := purchases.Submit(req)
+	switch {
+	case err == buyqueue.ErrConflict:
+		return c.JSON(http.StatusConflict, err.Error())
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	case created:
+		return c.JSON(http.StatusAccepted, job.View())
+	}
+	return c.JSON(http.StatusOK, job.View())
+}
+
+// buyStatus 返回购币任务的状态：pending、mined（及区块号）或failed
+func buyStatus(c echo.Context) error {
+	job, err := purchases.Get(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, job.View())
+}
+
+// buildPurchase 为购币请求生成承诺、监管者公钥下的购币信息密文、用户公钥下r的密文和
+// 发行者签名，构造购币交易。所有中间值都是本次请求的局部变量
+func buildPurchase(req buyqueue.Request) (utils.SendTx, buyqueue.Receipt, error) {
+	amount := strconv.FormatUint(req.Amount, 10)
+	usrpub := utils.CreateUsrPub(req.G1, req.G2, req.P, req.H)
+	if usrpub.G1 == nil || usrpub.G2 == nil || usrpub.P == nil || usrpub.H == nil {
+		return utils.SendTx{}, buyqueue.Receipt{}, errors.New("invalid user public key")
+	}
+	info, cm := utils.CreateDE_CM(regulatorpub, amount)
+	encR, spendkey := utils.CreateElgamalR(usrpub, cm.R)
+	if len(cm.Commitment) == 0 || len(encR.C1) == 0 || len(spendkey) == 0 {
+		return utils.SendTx{}, buyqueue.Receipt{}, errors.New("create commitment failed")
+	}
+	sig := utils.CreateSign(publisherpriv, amount)
+	receipt := buyqueue.Receipt{
+		Cmv:      utils.Byteto0xstring(cm.Commitment),
+		Epkrc1:   utils.Byteto0xstring(encR.C1),
+		Epkrc2:   utils.Byteto0xstring(encR.C2),
+		Spendkey: utils.Byteto0xstring(spendkey),
+	}
+	return utils.NewPurchaseTx(info, encR, sig, cm, spendkey, ethaccount), receipt, nil
+}
+
+// issuedList 返回发行账本中的全部购币交易及已上链的发行总额
+func issuedList(c echo.Context) error {
+	return c.JSON(http.StatusOK, struct {
+		Totals    issuedb.Totals     `json:"totals"`
+		Issuances []issuedb.Issuance `json:"issuances"`
+	}{issuances.Totals(), issuances.List()})
+}
+
+func issuedGet(c echo.Context) error {
+	is, err := issuances.Get(c.Param("hash"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, is)
+}
+
+// operatorAuth 校验请求头Authorization: Bearer <operatortoken>，未配置operatortoken时
+// 拒绝全部请求
+func operatorAuth() echo.MiddlewareFunc {
+	return middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+		return operatortoken != "" && subtle.ConstantTimeCompare([]byte(key), []byte(operatortoken)) == 1, nil
+	})
+}
+
+// redeemList 返回链上赎回交易的兑付义务，unpaid=true时只返回尚未兑付的
+func redeemList(c echo.Context) error {
+	return c.JSON(http.StatusOK, redemptions.List(c.QueryParam("unpaid") == "true"))
+}
+
+func redeemGet(c echo.Context) error {
+	o, err := redemptions.Get(c.Param("hash"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, o)
+}
+
+// redeemPaid 交易所完成法币兑付后登记兑付凭证ref
+func redeemPaid(c echo.Context) error {
+	if err := redemptions.MarkPaid(c.Param("hash"), c.FormValue("ref")); err != nil {
+		return c.JSON(http.StatusNotFound, err.Error())
+	}
+	if err := redemptions.Save(); err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	o, _ := redemptions.Get(c.Param("hash"))
+	return c.JSON(http.StatusOK, o)
 }
 
 func pubpub(c echo.Context) error {
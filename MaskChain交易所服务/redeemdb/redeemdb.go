@@ -0,0 +1,150 @@
+// Package redeemdb 是交易所本地的赎回兑付数据库。它记录链上每笔赎回交易对应的
+// 法币兑付义务：赎回用户、金额以及交易所是否已经兑付。
+//
+// 数据库以JSON文件的形式保存在本地。
+package redeemdb
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownObligation 数据库中没有该交易的兑付义务
+var ErrUnknownObligation = errors.New("unknown redemption")
+
+// Obligation 一笔赎回交易产生的兑付义务
+type Obligation struct {
+	Hash   string `json:"hash"`             // 赎回交易哈希
+	From   string `json:"from"`             // 赎回用户的链上账户
+	Amount uint64 `json:"amount"`           // 应兑付的金额
+	Block  uint64 `json:"block"`            // 赎回交易所在区块号
+	Paid   bool   `json:"paid"`             // 是否已兑付
+	Ref    string `json:"ref,omitempty"`    // 兑付凭证，如银行流水号
+	Failed string `json:"failed,omitempty"` // 无法解密金额时的原因，需人工处理
+}
+
+// DB 交易所的兑付义务数据库
+type DB struct {
+	path string
+	lock sync.Mutex
+
+	Head        uint64                 `json:"head"`        // 已扫描到的区块号
+	Obligations map[string]*Obligation `json:"obligations"` // 以交易哈希为索引
+}
+
+// Open 打开（不存在时新建）指定路径的兑付义务数据库
+func Open(path string) (*DB, error) {
+	db := &DB{path: path, Obligations: make(map[string]*Obligation)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, db); err != nil {
+		return nil, err
+	}
+	if db.Obligations == nil {
+		db.Obligations = make(map[string]*Obligation)
+	}
+	return db, nil
+}
+
+// Save 将数据库写回文件，先写临时文件再替换，避免写入中断损坏数据库
+func (db *DB) Save() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(db.path), 0700); err != nil {
+		return err
+	}
+	tmp := db.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, db.path)
+}
+
+// Add 记录一笔兑付义务，已记录过的交易返回false
+func (db *DB) Add(o Obligation) bool {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	o.Hash = strings.ToLower(o.Hash)
+	if _, ok := db.Obligations[o.Hash]; ok {
+		return false
+	}
+	db.Obligations[o.Hash] = &o
+	return true
+}
+
+// Get 返回交易hash的兑付义务
+func (db *DB) Get(hash string) (Obligation, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	o, ok := db.Obligations[strings.ToLower(hash)]
+	if !ok {
+		return Obligation{}, ErrUnknownObligation
+	}
+	return *o, nil
+}
+
+// List 按区块顺序返回兑付义务，unpaid为true时只返回尚未兑付的
+func (db *DB) List(unpaid bool) []Obligation {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	list := make([]Obligation, 0, len(db.Obligations))
+	for _, o := range db.Obligations {
+		if unpaid && o.Paid {
+			continue
+		}
+		list = append(list, *o)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Block != list[j].Block {
+			return list[i].Block < list[j].Block
+		}
+		return list[i].Hash < list[j].Hash
+	})
+	return list
+}
+
+// MarkPaid 将交易hash的兑付义务标记为已兑付，ref为兑付凭证
+func (db *DB) MarkPaid(hash, ref string) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	o, ok := db.Obligations[strings.ToLower(hash)]
+	if !ok {
+		return ErrUnknownObligation
+	}
+	o.Paid, o.Ref = true, ref
+	return nil
+}
+
+func (db *DB) head() uint64 {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	return db.Head
+}
+
+func (db *DB) setHead(number uint64) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	if number > db.Head {
+		db.Head = number
+	}
+}
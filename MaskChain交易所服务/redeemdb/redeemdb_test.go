@@ -0,0 +1,72 @@
+package redeemdb
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"exchange/utils"
+	"maskchain/privacy/ecc"
+)
+
+type fakeChain []*utils.RPCBlock
+
+func (c fakeChain) BlockNumber() (uint64, error) { return uint64(len(c)), nil }
+
+func (c fakeChain) BlockByNumber(number uint64) (*utils.RPCBlock, error) {
+	return c[number-1], nil
+}
+
+func TestWatcher(t *testing.T) {
+	pub, priv, err := ecc.GenerateKeys("exchange")
+	if err != nil {
+		t.Fatal(err)
+	}
+	redeem := func(hash string, amount uint64) utils.RPCTransaction {
+		ct, _, err := ecc.EncryptValue(pub, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return utils.RPCTransaction{Hash: hash, From: "0x01", ID: "0x6", ExC1: fmt.Sprintf("0x%x", ct.C1), ExC2: fmt.Sprintf("0x%x", ct.C2)}
+	}
+	chain := fakeChain{
+		{Transactions: []utils.RPCTransaction{redeem("0xAA", 100), {Hash: "0xbb", ID: "0x1"}}},
+		{Transactions: []utils.RPCTransaction{{Hash: "0xcc", ID: "0x6"}}},
+		{Transactions: []utils.RPCTransaction{redeem("0xdd", 7)}}, // 确认数不足
+	}
+	path := filepath.Join(t.TempDir(), "redeem.json")
+	db, _ := Open(path)
+	w := NewWatcher(chain, priv)
+	w.Confirmations = 1
+
+	found, err := w.Scan(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].Amount != 100 || found[0].Hash != "0xaa" || found[1].Failed == "" {
+		t.Fatalf("unexpected obligations: %+v", found)
+	}
+	// 重复扫描不会重复记录
+	if found, _ := w.Scan(db); len(found) != 0 {
+		t.Errorf("rescanned obligations: %+v", found)
+	}
+	if err := db.MarkPaid("0xAA", "bank-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.MarkPaid("0xee", ""); err != ErrUnknownObligation {
+		t.Errorf("mark unknown redemption: have %v, want %v", err, ErrUnknownObligation)
+	}
+	if err := db.Save(); err != nil {
+		t.Fatal(err)
+	}
+	db, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpaid := db.List(true); db.Head != 2 || len(unpaid) != 1 || unpaid[0].Hash != "0xcc" {
+		t.Errorf("database not restored: head %d, unpaid %+v", db.Head, unpaid)
+	}
+	if o, err := db.Get("0xaa"); err != nil || !o.Paid || o.Ref != "bank-1" {
+		t.Errorf("paid redemption not restored: %+v, %v", o, err)
+	}
+}
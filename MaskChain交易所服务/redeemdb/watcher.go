@@ -0,0 +1,112 @@
+package redeemdb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"exchange/utils"
+	"maskchain/privacy/ecc"
+)
+
+// Confirmations 默认只扫描已有足够确认数的区块，避免分叉回滚后兑付不存在的赎回
+const Confirmations = 6
+
+// redeemTx 赎回交易的ID
+const redeemTx = 6
+
+// Chain 扫描所需的节点接口，由utils.NodeClient实现
+type Chain interface {
+	BlockNumber() (uint64, error)
+	BlockByNumber(number uint64) (*utils.RPCBlock, error)
+}
+
+// Watcher 扫描新区块中的赎回交易，用交易所私钥解密赎回金额并记录兑付义务。
+// 节点已校验赎回金额的密文与被销毁承诺的金额相等，因此解密结果即应兑付的金额。
+type Watcher struct {
+	Chain         Chain
+	Key           ecc.PrivateKey // 交易所私钥
+	Confirmations uint64         // 扫描到最新区块之前的确认数
+}
+
+// NewWatcher 创建使用默认确认数的扫描器
+func NewWatcher(chain Chain, key ecc.PrivateKey) *Watcher {
+	return &Watcher{Chain: chain, Key: key, Confirmations: Confirmations}
+}
+
+// Scan 扫描db中记录的区块之后所有已确认的区块，返回新记录的兑付义务，并保存数据库
+func (w *Watcher) Scan(db *DB) ([]Obligation, error) {
+	latest, err := w.Chain.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	if latest < w.Confirmations {
+		return nil, nil
+	}
+	var found []Obligation
+	for number := db.head() + 1; number <= latest-w.Confirmations; number++ {
+		block, err := w.Chain.BlockByNumber(number)
+		if err != nil {
+			return found, err
+		}
+		for _, tx := range block.Transactions {
+			id, err := utils.ParseHexUint(tx.ID)
+			if err != nil {
+				return found, fmt.Errorf("block %d tx %s: %v", number, tx.Hash, err)
+			}
+			if id != redeemTx {
+				continue
+			}
+			o := w.obligation(number, tx)
+			if db.Add(o) {
+				found = append(found, o)
+			}
+		}
+		db.setHead(number)
+	}
+	return found, db.Save()
+}
+
+// obligation 解密赎回交易的金额。无法解密时仍记录该交易并注明原因，由人工处理
+func (w *Watcher) obligation(number uint64, tx utils.RPCTransaction) Obligation {
+	o := Obligation{Hash: strings.ToLower(tx.Hash), From: tx.From, Block: number}
+	C1, err1 := decodeHex(tx.ExC1)
+	C2, err2 := decodeHex(tx.ExC2)
+	if err1 != nil || err2 != nil || len(C1) == 0 {
+		o.Failed = "missing exchange ciphertext"
+		return o
+	}
+	amount, err := ecc.DecryptValue(w.Key, ecc.CypherText{C1: C1, C2: C2})
+	if err != nil {
+		o.Failed = err.Error()
+		return o
+	}
+	o.Amount = amount
+	return o
+}
+
+// Run 每隔interval扫描一次新区块，直到quit关闭
+func (w *Watcher) Run(db *DB, interval time.Duration, quit <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		found, err := w.Scan(db)
+		if err != nil {
+			log.Println("scan redemptions failed:", err)
+		}
+		for _, o := range found {
+			log.Printf("redemption %s of %d from %s in block %d\n", o.Hash, o.Amount, o.From, o.Block)
+		}
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return
+		}
+	}
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// ErrNotFound 节点没有所查询的区块或交易
+var ErrNotFound = errors.New("not found")
+
+// RPCError 节点处理请求后返回的错误，如交易被交易池拒绝。连接节点失败等错误不是RPCError
+type RPCError struct {
+	Method  string
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Message)
+}
+
+// NodeClient 通过RPC读取区块链节点，出错时返回错误而不是退出服务
+type NodeClient struct {
+	URL string // 节点RPC地址，如http://127.0.0.1:8545
+}
+
+// RPCBlock 节点返回的区块，只包含交易所关心的字段
+type RPCBlock struct {
+	Number       string           `json:"number"`
+	Hash         string           `json:"hash"`
+	Transactions []RPCTransaction `json:"transactions"`
+}
+
+// RPCTransaction 节点返回的交易，只包含交易所关心的字段
+type RPCTransaction struct {
+	Hash        string `json:"hash"`
+	BlockNumber string `json:"blockNumber"`
+	From        string `json:"from"`
+	ID          string `json:"ID"`
+	ExC1        string `json:"exc1"` // 赎回交易中交易所公钥下的金额密文
+	ExC2        string `json:"exc2"`
+}
+
+// RPCReceipt 节点返回的交易回执，只包含交易所关心的字段
+type RPCReceipt struct {
+	TransactionHash string `json:"transactionHash"`
+	BlockNumber     string `json:"blockNumber"`
+	Status          string `json:"status"` // 0x1成功，0x0失败
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call 调用节点RPC方法并将结果解析到result
+func (c *NodeClient) call(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, err := json.Marshal(toETH{"2.0", method, params, 67})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(c.URL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var res rpcResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return &RPCError{Method: method, Code: res.Error.Code, Message: res.Error.Message}
+	}
+	if len(res.Result) == 0 || string(res.Result) == "null" {
+		return fmt.Errorf("%s: %w", method, ErrNotFound)
+	}
+	return json.Unmarshal(res.Result, result)
+}
+
+// BlockNumber 返回节点当前的区块高度
+func (c *NodeClient) BlockNumber() (uint64, error) {
+	var hex string
+	if err := c.call(&hex, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return ParseHexUint(hex)
+}
+
+// BlockByNumber 返回指定高度的区块及其完整交易
+func (c *NodeClient) BlockByNumber(number uint64) (*RPCBlock, error) {
+	block := new(RPCBlock)
+	if err := c.call(block, "eth_getBlockByNumber", fmt.Sprintf("0x%x", number), true); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// TransactionReceipt 返回已上链交易的回执，交易尚未上链时返回ErrNotFound
+func (c *NodeClient) TransactionReceipt(hash string) (*RPCReceipt, error) {
+	receipt := new(RPCReceipt)
+	if err := c.call(receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// TransactionByHash 返回节点已知（在交易池中或已上链）的交易，未知时返回ErrNotFound
+func (c *NodeClient) TransactionByHash(hash string) (*RPCTransaction, error) {
+	tx := new(RPCTransaction)
+	if err := c.call(tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Nonce 返回账户在区块tag（latest或pending）时的nonce
+func (c *NodeClient) Nonce(account, tag string) (uint64, error) {
+	var hex string
+	if err := c.call(&hex, "eth_getTransactionCount", account, tag); err != nil {
+		return 0, err
+	}
+	return ParseHexUint(hex)
+}
+
+// SignTransaction 由节点用已解锁的账户签名交易，返回RLP编码的交易及其哈希。
+// 交易须给出nonce，同一nonce重新签名得到的交易只可能有一笔上链
+func (c *NodeClient) SignTransaction(tx SendTx) (raw string, hash string, err error) {
+	var res struct {
+		Raw string `json:"raw"`
+		Tx  struct {
+			Hash string `json:"hash"`
+		} `json:"tx"`
+	}
+	if err := c.call(&res, "eth_signTransaction", tx); err != nil {
+		return "", "", err
+	}
+	return res.Raw, res.Tx.Hash, nil
+}
+
+// SendRawTransaction 广播已签名的交易，返回交易哈希
+func (c *NodeClient) SendRawTransaction(raw string) (string, error) {
+	var hash string
+	if err := c.call(&hash, "eth_sendRawTransaction", raw); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// ParseHexUint 解析0x开头的十六进制整数
+func ParseHexUint(s string) (uint64, error) {
+	if len(s) < 3 || s[:2] != "0x" {
+		return 0, fmt.Errorf("invalid hex number %q", s)
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
@@ -25,4 +25,24 @@ var (
 		Usage: "the key that you unlock your eth_account",
 		Value: "",
 	}
+	BuyDBFlag = cli.StringFlag{
+		Name:  "buydb",
+		Usage: "the file that keeps the queue of purchases",
+		Value: "purchases.json",
+	}
+	IssueDBFlag = cli.StringFlag{
+		Name:  "issuedb",
+		Usage: "the file that records the issuance of purchases",
+		Value: "issuance.json",
+	}
+	RedeemDBFlag = cli.StringFlag{
+		Name:  "redeemdb",
+		Usage: "the file that records the payout obligations of redemptions",
+		Value: "redeem.json",
+	}
+	OperatorTokenFlag = cli.StringFlag{
+		Name:  "operatortoken, ot",
+		Usage: "the shared secret operators send as Authorization: Bearer <token> to the issuance and redeem routes, which are closed if empty",
+		Value: "",
+	}
 )
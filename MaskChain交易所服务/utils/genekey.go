@@ -2,8 +2,8 @@ package utils
 
 import (
 	"encoding/json"
-	ecc "exchange/crypto/ECC"
 	"fmt"
+	"maskchain/privacy/ecc"
 	"math/big"
 	"os"
 )
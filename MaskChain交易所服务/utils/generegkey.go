@@ -2,10 +2,10 @@ package utils
 
 import (
 	"encoding/json"
-	ecc "exchange/crypto/ECC"
 	"exchange/params"
 	"fmt"
 	"io/ioutil"
+	"maskchain/privacy/ecc"
 	"math/big"
 	"net/http"
 	"os"
@@ -15,6 +15,7 @@ type Receipt struct {
 
 // the struct from user post
 type Purchase struct {
+	ID     string `json:"id"        xml:"id"        form:"id"        query:"id"` // 幂等键
 	G1     string `json:"g1"        xml:"g1"        form:"g1"        query:"g1"`
 	G2     string `json:"g2"        xml:"g2"        form:"g2"        query:"g2"`
 	P      string `json:"p"         xml:"p"         form:"p"         query:"p"`
@@ -1,8 +1,7 @@
 package utils
 
 import (
-	ecc "exchange/crypto/ECC"
-	"math/rand"
+	"maskchain/privacy/ecc"
 	"strconv"
 )
 
@@ -15,9 +14,9 @@ func CreateDE_CM(regpub ecc.PublicKey, amount string) (C ecc.CypherText,CM ecc.C
 // create commit_v
 func CreateCM_v(regpub ecc.PublicKey, amount string) (CM ecc.Commitment) {
 	amounts, _ := strconv.Atoi(amount)
-	rF := rand.Uint64()
-	r1 := strconv.FormatUint(rF, 16)
-	CM = regpub.CommitByUint64(uint64(amounts), []byte(r1))
+	r := ecc.RandScalar()
+	CM = regpub.CommitByUint64(uint64(amounts), r.Bytes())
+	ecc.ZeroizeInt(r)
 	return
 }
 
@@ -28,8 +27,10 @@ func CreateElgamalInfo(regpub ecc.PublicKey, amount string, publickey string) (C
 	return
 }
 
-func CreateElgamalR(regpub ecc.PublicKey, r []byte) (C ecc.CypherText) {
-	C = ecc.Encrypt(regpub, r)
+// create the cyphertext of r, which only the user can decrypt, and the spend
+// key of the commitment, whose secret only the user can derive
+func CreateElgamalR(usrpub ecc.PublicKey, r []byte) (C ecc.CypherText, key []byte) {
+	C, key, _ = ecc.EncryptBlindKey(usrpub, r)
 	return
 }
 
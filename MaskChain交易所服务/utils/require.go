@@ -3,11 +3,11 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
-	ecc "exchange/crypto/ECC"
 	"exchange/params"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"maskchain/privacy/ecc"
 	"net/http"
 	"net/url"
 )
@@ -43,14 +43,8 @@ type SendTx struct {
 	SigR     string `json:"sigr"`
 	SigS     string `json:"sigs"`
 	CmV      string `json:"cmv"`
-}
-
-// get result from send exchangetx to ethereum
-type SendTxget struct {
-	Jsonrpc string `json:"jsonrpc"`
-	Id      int    `json:"id"`
-	Result  string `json:"result"`
-	Error   string `json:"error"`
+	SpendKey string `json:"spendkey,omitempty"` // 购币承诺的花费公钥
+	Nonce    string `json:"nonce,omitempty"`    // 为空时由节点选择
 }
 
 // verify the publickey of usr to regulator
@@ -124,44 +118,24 @@ func UnlockAccount(ethaccount string, ethkey string) bool {
 	}
 }
 
-// send exchange tx to eth
-func SendTransaction(elgamalinfo ecc.CypherText, elgamalr ecc.CypherText, sig ecc.Signature, cm ecc.Commitment, ethaccount string) (bool, string) {
-	paramstx := make([]interface{}, 1)
-	epkrc1 := Byteto0xstring(elgamalr.C1)
-	epkrc2 := Byteto0xstring(elgamalr.C2)
-	epkpc1 := Byteto0xstring(elgamalinfo.C1)
-	epkpc2 := Byteto0xstring(elgamalinfo.C2)
-	sigm := Byteto0xstring(sig.M)
-	sigmhash := Byteto0xstring(sig.M_hash)
-	sigr := Byteto0xstring(sig.R)
-	sigs := Byteto0xstring(sig.S)
-	cmv := Byteto0xstring(cm.Commitment)
-	//epkrc1 = strings.TrimLeft(epkrc1, "0x")
-	//fmt.Println(hex.DecodeString(epkrc1))
-	paramstx[0] = SendTx{ethaccount, params.Ethto, "0x0", "0x0", "0x0", "0x1", epkrc1, epkrc2, epkpc1, epkpc2, sigm, sigmhash, sigr, sigs, cmv}
-	data := toETH{"2.0", "eth_sendTransaction", paramstx, 67}
-	datapost, err := json.Marshal(data)
-	if err != nil {
-		fmt.Println(err)
-		return false, ""
-	}
-	req, err := http.NewRequest("POST", params.Ethurl, bytes.NewBuffer(datapost))
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println(err)
-		return false, ""
-	}
-	defer resp.Body.Close()
-	bodyC, _ := ioutil.ReadAll(resp.Body)
-	var s SendTxget
-	json.Unmarshal(bodyC, &s)
-	if s.Result != "" {
-		log.Println(string(bodyC), "Succeed to send exchangetx", s.Result)
-		return true, s.Result
-	} else {
-		log.Println(string(bodyC), "Failed to send exchangetx", ethaccount)
-		return false, ""
+// NewPurchaseTx 由购币信息密文、r的密文、发行者签名和承诺构造购币交易
+func NewPurchaseTx(elgamalinfo ecc.CypherText, elgamalr ecc.CypherText, sig ecc.Signature, cm ecc.Commitment, spendkey []byte, ethaccount string) SendTx {
+	return SendTx{
+		From:     ethaccount,
+		To:       params.Ethto,
+		Gas:      "0x0",
+		GasPrice: "0x0",
+		Value:    "0x0",
+		ID:       "0x1",
+		EpkrC1:   Byteto0xstring(elgamalr.C1),
+		EpkrC2:   Byteto0xstring(elgamalr.C2),
+		EpkpC1:   Byteto0xstring(elgamalinfo.C1),
+		EpkpC2:   Byteto0xstring(elgamalinfo.C2),
+		SigM:     Byteto0xstring(sig.M),
+		SigMHash: Byteto0xstring(sig.M_hash),
+		SigR:     Byteto0xstring(sig.R),
+		SigS:     Byteto0xstring(sig.S),
+		CmV:      Byteto0xstring(cm.Commitment),
+		SpendKey: Byteto0xstring(spendkey),
 	}
 }
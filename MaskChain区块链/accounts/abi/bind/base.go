@@ -20,7 +20,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
@@ -56,90 +55,7 @@ type TransactOpts struct {
 
 	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
 
-	//新增交易字段
-	ID       uint64
-	ErpkC1   *hexutil.Bytes
-	ErpkC2   *hexutil.Bytes
-	EspkC1   *hexutil.Bytes
-	EspkC2   *hexutil.Bytes
-	CMRpk    *hexutil.Bytes
-	CMSpk    *hexutil.Bytes
-
-	RpkEPg1          *hexutil.Bytes  `json:"rpkepg1"` //接收方地址公钥相等证明字段g1
-	RpkEPg2      	 *hexutil.Bytes  `json:"rpkepg2"` //接收方地址公钥相等证明字段g2
-	RpkEPy1     	 *hexutil.Bytes  `json:"rpkepy1"` //接收方地址公钥相等证明字段y1
-	RpkEPy2    	     *hexutil.Bytes  `json:"rpkepy2"` //接收方地址公钥相等证明字段y2
-	RpkEPt1      	 *hexutil.Bytes  `json:"rpkept1"` //接收方地址公钥相等证明字段t1
-	RpkEPt2      	 *hexutil.Bytes  `json:"rpkept2"` //接收方地址公钥相等证明字段t2
-	RpkEPs       	 *hexutil.Bytes  `json:"rpkeps"` //接收方地址公钥相等证明字段s
-	RpkEPc       	 *hexutil.Bytes  `json:"rpkepc"` //接收方地址公钥相等证明字段c
-	SpkEPg1      	 *hexutil.Bytes  `json:"spkepg1"` //发送方地址公钥相等证明字段g1
-	SpkEPg2      	 *hexutil.Bytes  `json:"spkepg2"` //发送方地址公钥相等证明字段g2
-	SpkEPy1      	 *hexutil.Bytes  `json:"spkepy1"` //发送方地址公钥相等证明字段y1
-	SpkEPy2      	 *hexutil.Bytes  `json:"spkepy2"` //发送方地址公钥相等证明字段y2
-	SpkEPt1      	 *hexutil.Bytes  `json:"spkept1"` //发送方地址公钥相等证明字段t1
-	SpkEPt2      	 *hexutil.Bytes  `json:"spkept2"` //发送方地址公钥相等证明字段t2
-	SpkEPs       	 *hexutil.Bytes  `json:"spkeps"` //发送方地址公钥相等证明字段s
-	SpkEPc       	 *hexutil.Bytes  `json:"spkepc"` //发送方地址公钥相等证明字段c
-
-	EvSC1    *hexutil.Bytes
-	EvSC2    *hexutil.Bytes
-	EvRC1    *hexutil.Bytes
-	EvRC2    *hexutil.Bytes
-	CmS      *hexutil.Bytes
-	CmR      *hexutil.Bytes
-
-	ScmFPg1      	 *hexutil.Bytes  `json:"scmfpg1"` //发送金额承诺格式证明字段g1
-	ScmFPg2      	 *hexutil.Bytes  `json:"scmfpg2"` //发送金额承诺格式证明字段g2
-	ScmFPy1      	 *hexutil.Bytes  `json:"scmfpy1"` //发送金额承诺格式证明字段y1
-	ScmFPy2      	 *hexutil.Bytes  `json:"scmfpy2"` //发送金额承诺格式证明字段y2
-	ScmFPt1      	 *hexutil.Bytes  `json:"scmfpt1"` //发送金额承诺格式证明字段t1
-	ScmFPt2      	 *hexutil.Bytes  `json:"scmfpt2"` //发送金额承诺格式证明字段t2
-	ScmFPs       	 *hexutil.Bytes  `json:"scmfps"` //发送金额承诺格式证明字段s
-	ScmFPc       	 *hexutil.Bytes  `json:"scmfpc"` //发送金额承诺格式证明字段c
-	RcmFPg1      	 *hexutil.Bytes  `json:"rcmfpg1"` //接收金额承诺格式证明字段g1
-	RcmFPg2      	 *hexutil.Bytes  `json:"rcmfpg2"` //接收金额承诺格式证明字段g2
-	RcmFPy1      	 *hexutil.Bytes  `json:"rcmfpy1"` //接收金额承诺格式证明字段y1
-	RcmFPy2      	 *hexutil.Bytes  `json:"rcmfpy2"` //接收金额承诺格式证明字段y2
-	RcmFPt1      	 *hexutil.Bytes  `json:"rcmfpt1"` //接收金额承诺格式证明字段t1
-	RcmFPt2      	 *hexutil.Bytes  `json:"rcmfpt2"` //接收金额承诺格式证明字段t2
-	RcmFPs       	 *hexutil.Bytes  `json:"rcmfps"` //接收金额承诺格式证明字段s
-	RcmFPc       	 *hexutil.Bytes  `json:"rcmfpc"` //接收金额承诺格式证明字段c
-
-	EvsBsC1  *hexutil.Bytes
-	EvsBsC2  *hexutil.Bytes
-	EvOC1    *hexutil.Bytes
-	EvOC2    *hexutil.Bytes
-	CmO      *hexutil.Bytes
-
-	VoEPg1       	 *hexutil.Bytes  `json:"voepg1"` //被花费承诺相等证明字段g1
-	VoEPg2       	 *hexutil.Bytes  `json:"voepg2"` //被花费承诺相等证明字段g2
-	VoEPy1       	 *hexutil.Bytes  `json:"voepy1"` //被花费承诺相等证明字段y1
-	VoEPy2       	 *hexutil.Bytes  `json:"voepy2"` //被花费承诺相等证明字段y2
-	VoEPt1       	 *hexutil.Bytes  `json:"voept1"` //被花费承诺相等证明字段t1
-	VoEPt2       	 *hexutil.Bytes  `json:"voept2"` //被花费承诺相等证明字段t2
-	VoEPs        	 *hexutil.Bytes  `json:"voeps"` //被花费承诺相等证明字段s
-	VoEPc        	 *hexutil.Bytes  `json:"voepc"` //被花费承诺相等证明字段c
-	BPy          	 *hexutil.Bytes  `json:"bpy"` //会计平衡证明字段y
-	BPt          	 *hexutil.Bytes  `json:"bpt"` //会计平衡证明字段t
-	BPsn1        	 *hexutil.Bytes  `json:"bpsn1"` //会计平衡证明字段sn1
-	BPsn2        	 *hexutil.Bytes  `json:"bpsn2"` //会计平衡证明字段sn2
-	BPsn3        	 *hexutil.Bytes  `json:"bpsn3"` //会计平衡证明字段sn3
-	BPc          	 *hexutil.Bytes  `json:"bpc"` //会计平衡证明字段c
-
-	EpkrC1   *hexutil.Bytes
-	EpkrC2   *hexutil.Bytes
-	EpkpC1   *hexutil.Bytes
-	EpkpC2   *hexutil.Bytes
-	SigM     *hexutil.Bytes
-	SigMHash *hexutil.Bytes
-	SigR     *hexutil.Bytes
-	SigS     *hexutil.Bytes
-	CmV      *hexutil.Bytes
-	CmSRC1   *hexutil.Bytes
-	CmSRC2   *hexutil.Bytes
-	CmRRC1   *hexutil.Bytes
-	CmRRC2   *hexutil.Bytes
+	Privacy types.PrivacyPayload // Transfer or purchase payload of the transaction (nil = plain transaction)
 }
 
 // FilterOpts is the collection of options to fine tune filtering for events
@@ -311,12 +227,7 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 		}
 	}
 	// Create the transaction, sign it and schedule it for execution
-	var rawTx *types.Transaction
-	if contract == nil {
-		rawTx = types.NewContractCreation(nonce, value, gasLimit, gasPrice, input, opts.ID, opts.ErpkC1, opts.ErpkC2, opts.EspkC1, opts.EspkC2, opts.CMRpk, opts.CMSpk, opts.RpkEPg1, opts.RpkEPg2, opts.RpkEPy1, opts.RpkEPy2, opts.RpkEPt1, opts.RpkEPt2, opts.RpkEPs, opts.RpkEPc, opts.SpkEPg1, opts.SpkEPg2, opts.SpkEPy1, opts.SpkEPy2, opts.SpkEPt1, opts.SpkEPt2, opts.SpkEPs, opts.SpkEPc, opts.EvSC1, opts.EvSC2, opts.EvRC1, opts.EvRC2, opts.CmS, opts.CmR, opts.ScmFPg1, opts.ScmFPg2, opts.ScmFPy1, opts.ScmFPy2, opts.ScmFPt1, opts.ScmFPt2, opts.ScmFPs, opts.ScmFPc, opts.RcmFPg1, opts.RcmFPg2, opts.RcmFPy1, opts.RcmFPy2, opts.RcmFPt1, opts.RcmFPt2, opts.RcmFPs, opts.RcmFPc, opts.EvsBsC1, opts.EvsBsC2, opts.EvOC1, opts.EvOC2, opts.CmO,  opts.VoEPg1, opts.VoEPg2, opts.VoEPy1, opts.VoEPy2, opts.VoEPt1, opts.VoEPt2, opts.VoEPs, opts.VoEPc, opts.BPy, opts.BPt, opts.BPsn1, opts.BPsn2, opts.BPsn3, opts.BPc, opts.EpkrC1, opts.EpkrC2, opts.EpkpC1, opts.EpkpC2, opts.SigM, opts.SigMHash, opts.SigR, opts.SigS, opts.CmV, opts.CmSRC1, opts.CmSRC2, opts.CmRRC1, opts.CmRRC2)
-	} else {
-		rawTx = types.NewTransaction(nonce, c.address, value, gasLimit, gasPrice, input, opts.ID, opts.ErpkC1, opts.ErpkC2, opts.EspkC1, opts.EspkC2, opts.CMRpk, opts.CMSpk, opts.RpkEPg1, opts.RpkEPg2, opts.RpkEPy1, opts.RpkEPy2, opts.RpkEPt1, opts.RpkEPt2, opts.RpkEPs, opts.RpkEPc, opts.SpkEPg1, opts.SpkEPg2, opts.SpkEPy1, opts.SpkEPy2, opts.SpkEPt1, opts.SpkEPt2, opts.SpkEPs, opts.SpkEPc, opts.EvSC1, opts.EvSC2, opts.EvRC1, opts.EvRC2, opts.CmS, opts.CmR, opts.ScmFPg1, opts.ScmFPg2, opts.ScmFPy1, opts.ScmFPy2, opts.ScmFPt1, opts.ScmFPt2, opts.ScmFPs, opts.ScmFPc, opts.RcmFPg1, opts.RcmFPg2, opts.RcmFPy1, opts.RcmFPy2, opts.RcmFPt1, opts.RcmFPt2, opts.RcmFPs, opts.RcmFPc, opts.EvsBsC1, opts.EvsBsC2, opts.EvOC1, opts.EvOC2, opts.CmO,  opts.VoEPg1, opts.VoEPg2, opts.VoEPy1, opts.VoEPy2, opts.VoEPt1, opts.VoEPt2, opts.VoEPs, opts.VoEPc, opts.BPy, opts.BPt, opts.BPsn1, opts.BPsn2, opts.BPsn3, opts.BPc, opts.EpkrC1, opts.EpkrC2, opts.EpkpC1, opts.EpkpC2, opts.SigM, opts.SigMHash, opts.SigR, opts.SigS, opts.CmV, opts.CmSRC1, opts.CmSRC2, opts.CmRRC1, opts.CmRRC2)
-	}
+	rawTx := types.NewPrivacyTransaction(nonce, contract, value, gasLimit, gasPrice, input, opts.Privacy)
 	if opts.Signer == nil {
 		return nil, errors.New("no signer to authorize the transaction with")
 	}
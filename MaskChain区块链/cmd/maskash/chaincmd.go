@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/crypto"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -37,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -49,6 +51,8 @@ var (
 		ArgsUsage: "<genesisPath>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.RegulatorKeyFlag,
+			utils.ExchangeKeyFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -56,7 +60,9 @@ The init command initializes a new genesis block and definition for the network.
 This is a destructive action and changes the network in which you will be
 participating.
 
-It expects the genesis file as argument.`,
+It expects the genesis file as argument. The public keys of the regulator and
+the exchange given by --regulatorkey and --exchangekey are pinned in the chain
+config from the genesis block on, unless the genesis file pins them already.`,
 	}
 	dumpGenesisCommand = cli.Command{
 		Action:    utils.MigrateFlags(dumpGenesis),
@@ -221,6 +227,13 @@ func initGenesis(ctx *cli.Context) error {
 	if err := json.NewDecoder(file).Decode(genesis); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
+	if genesis.Config != nil {
+		pinPrivacyKey(&genesis.Config.RegulatorKeys, ctx.GlobalString(utils.RegulatorKeyFlag.Name), "regulator")
+		pinPrivacyKey(&genesis.Config.ExchangeKeys, ctx.GlobalString(utils.ExchangeKeyFlag.Name), "exchange")
+		if err := core.CheckPrivacyKeys(genesis.Config); err != nil {
+			utils.Fatalf("invalid genesis file: %v", err)
+		}
+	}
 	// Open an initialise both full and light databases
 	stack := makeFullNode(ctx, true)
 	defer stack.Close()
@@ -250,6 +263,35 @@ func initGenesis(ctx *cli.Context) error {
 	return nil
 }
 
+// pinPrivacyKey pins the public key in the key file at path, as served by the
+// regulator or the exchange, in keys from the genesis block on. If the genesis
+// file pins keys already, the key must be the one of the genesis block.
+func pinPrivacyKey(keys *[]params.PrivacyKey, path string, name string) {
+	if path == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		utils.Fatalf("Failed to read %s key file: %v", name, err)
+	}
+	var pub types.PubKey
+	if err := json.Unmarshal(data, &pub); err != nil {
+		utils.Fatalf("Invalid %s key file: %v", name, err)
+	}
+	if pub.G1 == nil || pub.G2 == nil || pub.H == nil {
+		utils.Fatalf("Invalid %s key file: missing point", name)
+	}
+	key := pub.ConfigKey(common.Big0)
+	if len(*keys) == 0 {
+		*keys = []params.PrivacyKey{key}
+		log.Info("Pinned key in genesis config", "kind", name, "h", key.H)
+		return
+	}
+	if !(*keys)[0].Equal(&key) {
+		utils.Fatalf("Genesis file pins another %s key", name)
+	}
+}
+
 func dumpGenesis(ctx *cli.Context) error {
 	genesis := utils.MakeGenesis(ctx)
 	if genesis == nil {
@@ -189,14 +189,11 @@ var (
 		utils.MetricsInfluxDBPasswordFlag,
 		utils.MetricsInfluxDBTagsFlag,
 	}
-	//监管者服务器相关的flags
-	//监管者IP和端口
-	regulatorFlags = []cli.Flag{
+
+	// 旧版链配置未固定监管者与交易所公钥时，向其服务请求公钥
+	legacyKeyFlags = []cli.Flag{
 		utils.RegulatorIPFlag,
 		utils.RegulatorPortFlag,
-	}
-
-	exchangeFlags = []cli.Flag{
 		utils.ExchangeIPFlag,
 		utils.ExchangePortFlag,
 	}
@@ -244,8 +241,7 @@ func init() {
 	app.Flags = append(app.Flags, debug.Flags...)
 	app.Flags = append(app.Flags, whisperFlags...)
 	app.Flags = append(app.Flags, metricsFlags...)
-	app.Flags = append(app.Flags, regulatorFlags...)
-	app.Flags = append(app.Flags, exchangeFlags...)
+	app.Flags = append(app.Flags, legacyKeyFlags...)
 
 	app.Before = func(ctx *cli.Context) error {
 		return debug.Setup(ctx)
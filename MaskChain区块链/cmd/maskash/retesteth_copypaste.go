@@ -116,6 +116,9 @@ func RPCMarshalBlock(b *types.Block, inclTx bool, fullTx bool) (map[string]inter
 		"transactionsRoot": head.TxHash,
 		"receiptsRoot":     head.ReceiptHash,
 	}
+	if head.CMRoot != nil {
+		fields["commitmentsRoot"] = head.CMRoot
+	}
 
 	if inclTx {
 		formatTx := func(tx *types.Transaction) (interface{}, error) {
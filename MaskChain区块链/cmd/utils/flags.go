@@ -42,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
@@ -755,24 +756,32 @@ var (
 		Usage: "External EVM configuration (default = built-in interpreter)",
 		Value: "",
 	}
+	RegulatorKeyFlag = cli.StringFlag{
+		Name:  "regulatorkey",
+		Usage: "Regulator public key file (JSON, as served by the regulator) to pin in the genesis config",
+	}
+	ExchangeKeyFlag = cli.StringFlag{
+		Name:  "exchangekey",
+		Usage: "Exchange public key file (JSON, as served by the exchange) to pin in the genesis config",
+	}
 	RegulatorIPFlag = cli.StringFlag{
 		Name:  "regulatorip",
-		Usage: "regulator IP address",
+		Usage: "Regulator server IP to fetch the legacy key from, for chains not pinning keys before pinnedKeysBlock",
 		Value: "127.0.0.1",
 	}
 	RegulatorPortFlag = cli.IntFlag{
 		Name:  "regulatorport",
-		Usage: "regulator server port",
+		Usage: "Regulator server port to fetch the legacy key from",
 		Value: 1423,
 	}
 	ExchangeIPFlag = cli.StringFlag{
 		Name:  "exchangeip",
-		Usage: "exchange IP address",
+		Usage: "Exchange server IP to fetch the legacy key from, for chains not pinning keys before pinnedKeysBlock",
 		Value: "127.0.0.1",
 	}
 	ExchangePortFlag = cli.IntFlag{
 		Name:  "exchangeport",
-		Usage: "exchange server port",
+		Usage: "Exchange server port to fetch the legacy key from",
 		Value: 1323,
 	}
 )
@@ -1196,51 +1205,40 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.DiscoveryV5 = false
 	}
 }
-func setRegulator(ctx *cli.Context, cfg *eth.Config) {
-	cfg.Regulator.IP = ctx.GlobalString("regulatorip")
-	cfg.Regulator.Port = ctx.GlobalInt("regulatorport")
-	//做网络请求获取监管者公钥
-	client := &http.Client{}
-	//url := fmt.Sprintf("http://%s:%d/regkey?chainID=%s", cfg.Regulator.IP, cfg.Regulator.Port, cfg.UserIdent)
-	url := fmt.Sprintf("http://%s:%d/regkey?chainID=1", cfg.Regulator.IP, cfg.Regulator.Port)
-	resp, _ := client.Get(url)
-	if resp != nil {
-		defer resp.Body.Close()
-	} else {
-		log.Warn("Failed to connect to regulator server", "ip", cfg.Regulator.IP, "port", cfg.Regulator.Port)
-		return
+
+// setLegacyKeys fetches the legacy keys of the regulator and the exchange from
+// their servers if one of their flags is set. They only stand in for the keys
+// a chain config does not pin before its pinned keys fork.
+func setLegacyKeys(ctx *cli.Context, cfg *eth.Config) {
+	if ctx.GlobalIsSet(RegulatorIPFlag.Name) || ctx.GlobalIsSet(RegulatorPortFlag.Name) {
+		url := fmt.Sprintf("http://%s:%d/regkey?chainID=1", ctx.GlobalString(RegulatorIPFlag.Name), ctx.GlobalInt(RegulatorPortFlag.Name))
+		cfg.Regulator.PubK = fetchLegacyKey(url, "regulator")
 	}
-	body, _ := ioutil.ReadAll(resp.Body)
-	json.Unmarshal(body, &cfg.Regulator.PubK)
-	if cfg.Regulator.PubK.G1 == nil || cfg.Regulator.PubK.G2 == nil || cfg.Regulator.PubK.P == nil || cfg.Regulator.PubK.H == nil {
-		log.Warn("Failed to connect to regulator server", "ip", cfg.Regulator.IP, "port", cfg.Regulator.Port)
-	} else {
-		pubK := strings.Replace(string(body), "\"", "", -1)
-		pubK = strings.Replace(pubK, "\n", "", -1)
-		log.Info("Succeed to connect to regulator server", "publicKey", pubK)
+	if ctx.GlobalIsSet(ExchangeIPFlag.Name) || ctx.GlobalIsSet(ExchangePortFlag.Name) {
+		url := fmt.Sprintf("http://%s:%d/pubpub", ctx.GlobalString(ExchangeIPFlag.Name), ctx.GlobalInt(ExchangePortFlag.Name))
+		cfg.Exchange.PubKey = fetchLegacyKey(url, "exchange")
 	}
 }
 
-//@mzliu 11/14 set echange url
-func setExchange(ctx *cli.Context, cfg *eth.Config) {
-	cfg.Exchange.IP = ctx.GlobalString("exchangeip")
-	cfg.Exchange.Port = ctx.GlobalInt("exchangeport")
-	//做网络请求获取exchange公钥
-	client := &http.Client{}
-	url := fmt.Sprintf("http://%s:%d/pubpub", cfg.Exchange.IP, cfg.Exchange.Port)
-	resp, err := client.Get(url)
-	if resp != nil {
-		defer resp.Body.Close()
-	} else {
-		log.Warn("Failed to connect to exchange server", "ip", cfg.Exchange.IP, "port", cfg.Exchange.Port)
-		return
-	}
-	body, err := ioutil.ReadAll(resp.Body)
+// fetchLegacyKey requests the public key served at url by the regulator or the
+// exchange. A node asked for a legacy key fails to start without it rather
+// than verifying blocks with another key than its peers.
+func fetchLegacyKey(url string, name string) types.PubKey {
+	resp, err := http.Get(url)
 	if err != nil {
-		fmt.Println(err)
+		Fatalf("Failed to fetch %s key: %v", name, err)
+	}
+	defer resp.Body.Close()
+
+	var pub types.PubKey
+	if err := json.NewDecoder(resp.Body).Decode(&pub); err != nil {
+		Fatalf("Invalid %s key from %s: %v", name, url, err)
+	}
+	if pub.G1 == nil || pub.G2 == nil || pub.H == nil {
+		Fatalf("Invalid %s key from %s: missing point", name, url)
 	}
-	json.Unmarshal(body, &cfg.Exchange.PubKey)
-	log.Info("Secceed to obain Exchange PubKey", "publicKey", cfg.Exchange.PubKey)
+	log.Info("Fetched legacy key", "kind", name, "h", pub.H)
+	return pub
 }
 
 // SetNodeConfig applies node-related command line flags to the config.
@@ -1508,8 +1506,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	setMiner(ctx, &cfg.Miner)
 	setWhitelist(ctx, cfg)
 	setLes(ctx, cfg)
-	setRegulator(ctx, cfg)
-	setExchange(ctx, cfg)
+	setLegacyKeys(ctx, cfg)
 	if ctx.GlobalIsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
 	}
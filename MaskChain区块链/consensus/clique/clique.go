@@ -297,6 +297,9 @@ func (c *Clique) verifyHeader(chain consensus.ChainReader, header *types.Header,
 	if err := misc.VerifyForkHashes(chain.Config(), header, false); err != nil {
 		return err
 	}
+	if err := misc.VerifyCMRootHeader(chain.Config(), header); err != nil {
+		return err
+	}
 	// All basic checks passed, verify cascading fields
 	return c.verifyCascadingFields(chain, header, parents)
 }
@@ -555,6 +558,7 @@ func (c *Clique) Prepare(chain consensus.ChainReader, header *types.Header) erro
 func (c *Clique) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
 	// No block rewards in PoA, so the state remains as is and uncles are dropped
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.CMRoot = misc.CommitmentRoot(chain.Config(), header.Number, state)
 	header.UncleHash = types.CalcUncleHash(nil)
 }
 
@@ -563,6 +567,7 @@ func (c *Clique) Finalize(chain consensus.ChainReader, header *types.Header, sta
 func (c *Clique) FinalizeAndAssemble(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
 	// No block rewards in PoA, so the state remains as is and uncles are dropped
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.CMRoot = misc.CommitmentRoot(chain.Config(), header.Number, state)
 	header.UncleHash = types.CalcUncleHash(nil)
 
 	// Assemble and return the final block for sealing
@@ -715,7 +720,7 @@ func CliqueRLP(header *types.Header) []byte {
 }
 
 func encodeSigHeader(w io.Writer, header *types.Header) {
-	err := rlp.Encode(w, []interface{}{
+	enc := []interface{}{
 		header.ParentHash,
 		header.UncleHash,
 		header.Coinbase,
@@ -731,7 +736,12 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 		header.Extra[:len(header.Extra)-crypto.SignatureLength], // Yes, this will panic if extra is too short
 		header.MixDigest,
 		header.Nonce,
-	})
+	}
+	// The commitment root is signed from the commitment pool fork on only
+	if header.CMRoot != nil {
+		enc = append(enc, *header.CMRoot)
+	}
+	err := rlp.Encode(w, enc)
 	if err != nil {
 		panic("can't encode: " + err.Error())
 	}
@@ -17,15 +17,20 @@
 package ethash
 
 import (
+	"bytes"
 	"encoding/json"
+	"io/ioutil"
 	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 type diffTest struct {
@@ -84,3 +89,64 @@ func TestCalcDifficulty(t *testing.T) {
 		}
 	}
 }
+
+// Tests that a header encoded before the commitment pool fork, i.e. without
+// the commitment root, still decodes to the same hash and passes verification.
+func TestLegacyHeaderVerification(t *testing.T) {
+	// A mainnet header in the encoding of the chain before the fork
+	legacy := []interface{}{
+		common.HexToHash("0xd783efa4d392943503f28438ad5830b2d5964696ffc285f338585e9fe0a37a05"),
+		common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
+		common.HexToAddress("0xc0ea08a2d404d3172d2add29a45be56da40e2949"),
+		common.HexToHash("0x77d14e10470b5850332524f8cd6f69ad21f070ce92dca33ab2858300242ef2f1"),
+		common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"),
+		common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"),
+		types.Bloom{},
+		big.NewInt(167925187834220),
+		big.NewInt(3311058),
+		uint64(4015682),
+		uint64(0),
+		uint64(1488928920),
+		[]byte("www.bw.com"),
+		common.HexToHash("0x3e140b0784516af5e5ec6730f2fb20cca22f32be399b9e4ad77d32541f798cd0"),
+		types.EncodeNonce(0xf400cd0006070c49),
+	}
+	enc, err := rlp.EncodeToBytes(legacy)
+	if err != nil {
+		t.Fatalf("failed to encode legacy header: %v", err)
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(enc, header); err != nil {
+		t.Fatalf("failed to decode legacy header: %v", err)
+	}
+	if header.CMRoot != nil {
+		t.Fatalf("legacy header decoded with commitment root %x", *header.CMRoot)
+	}
+	if reenc, _ := rlp.EncodeToBytes(header); !bytes.Equal(reenc, enc) {
+		t.Fatalf("legacy header encoding mismatch: have %x, want %x", reenc, enc)
+	}
+	// The seal and the commitment root rules of the pre-fork chain must hold
+	cachedir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary cache dir: %v", err)
+	}
+	defer os.RemoveAll(cachedir)
+
+	ethash := New(Config{cachedir, 0, 1, "", 0, 0, ModeNormal, nil}, nil, false)
+	defer ethash.Close()
+	if err := ethash.VerifySeal(nil, header); err != nil {
+		t.Fatalf("legacy header seal verification failed: %v", err)
+	}
+	if err := misc.VerifyCMRootHeader(&params.ChainConfig{}, header); err != nil {
+		t.Fatalf("legacy header rejected before the fork: %v", err)
+	}
+	forked := &params.ChainConfig{CMPoolBlock: big.NewInt(3311058)}
+	if err := misc.VerifyCMRootHeader(forked, header); err != misc.ErrMissingCMRoot {
+		t.Fatalf("legacy header after the fork: have %v, want %v", err, misc.ErrMissingCMRoot)
+	}
+	// Adding the commitment root changes the seal hash, so the old seal breaks
+	header.CMRoot = &common.Hash{0x01}
+	if err := ethash.VerifySeal(nil, header); err == nil {
+		t.Fatalf("seal verified with a commitment root added")
+	}
+}
@@ -0,0 +1,47 @@
+package misc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrMissingCMRoot is returned if a header from the commitment pool fork on
+	// does not carry the root of the commitment pool.
+	ErrMissingCMRoot = errors.New("missing commitment root")
+
+	// ErrUnexpectedCMRoot is returned if a header before the commitment pool
+	// fork carries a commitment root.
+	ErrUnexpectedCMRoot = errors.New("commitment root before the commitment pool fork")
+)
+
+// VerifyCMRootHeader verifies that a header carries the root of the commitment
+// pool if and only if the commitment pool fork is active at its block.
+func VerifyCMRootHeader(config *params.ChainConfig, header *types.Header) error {
+	if config.IsCMPool(header.Number) {
+		if header.CMRoot == nil {
+			return ErrMissingCMRoot
+		}
+		return nil
+	}
+	if header.CMRoot != nil {
+		return ErrUnexpectedCMRoot
+	}
+	return nil
+}
+
+// CommitmentRoot returns the root of the commitment pool of the state to be
+// committed to by the header of block num, nil before the commitment pool fork.
+// It is only up to date after the state has been hashed with IntermediateRoot.
+func CommitmentRoot(config *params.ChainConfig, num *big.Int, statedb *state.StateDB) *common.Hash {
+	if !config.IsCMPool(num) {
+		return nil
+	}
+	root := statedb.CommitmentRoot()
+	return &root
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
@@ -71,6 +72,25 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 		}
 		return consensus.ErrPrunedAncestor
 	}
+	// Check the purchase signatures, proofs and commitments of the block.
+	// Chains without a CMdb (e.g. simulated backends) skip this.
+	// Commitments and shielded transfers are verified against the commitment
+	// pool of the parent state, seeded first if the block is the commitment
+	// pool fork, keys rotated by governance are looked up in the same state.
+	if v.bc.CMdb != nil {
+		parent := v.bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+		statedb, err := v.bc.StateAt(parent.Root)
+		if err != nil {
+			return err
+		}
+		if err := ApplyCMPoolFork(v.config, v.bc, statedb, block.Header()); err != nil {
+			return err
+		}
+		privacy := NewPrivacyValidator(v.config).WithLegacyKeys(v.bc.LegacyKeys()).WithState(statedb)
+		if err := privacy.ValidateBlock(block); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -103,6 +123,12 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 	if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number)); header.Root != root {
 		return fmt.Errorf("invalid merkle root (remote: %x local: %x)", header.Root, root)
 	}
+	// Validate the commitment pool root, which is up to date now that the
+	// state root has been computed. Headers before the commitment pool fork
+	// carry no root.
+	if root := misc.CommitmentRoot(v.config, header.Number, statedb); (root == nil) != (header.CMRoot == nil) || root != nil && *root != *header.CMRoot {
+		return fmt.Errorf("invalid commitment root (remote: %x local: %x)", header.CMRoot, root)
+	}
 	return nil
 }
 
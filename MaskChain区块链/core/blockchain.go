@@ -85,6 +85,10 @@ const (
 	badBlockLimit       = 10
 	TriesInMemory       = 128
 
+	// issuanceIndexInterval is the interval the issuance of blocks imported
+	// without it, e.g. by fast sync, is indexed at.
+	issuanceIndexInterval = time.Minute
+
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
 	// Changelog:
@@ -143,6 +147,9 @@ type BlockChain struct {
 
 	db     ethdb.Database // Low level persistent database to store final content in
 	CMdb   ethdb.Database
+
+	legacyKeys atomic.Value // Keys standing in for unpinned ones before the pinned keys fork (*LegacyKeys)
+
 	triegc *prque.Prque  // Priority queue mapping block numbers to tries to gc
 	gcproc time.Duration // Accumulates canonical block processing for trie dumping
 
@@ -237,6 +244,12 @@ func NewBlockChain(db ethdb.Database, CMdb ethdb.Database, cacheConfig *CacheCon
 	if bc.genesisBlock == nil {
 		return nil, ErrNoGenesis
 	}
+	// Move the spent flags of an older CMdb into the nullifier set
+	if CMdb != nil {
+		if err := rawdb.MigrateCMdb(CMdb); err != nil {
+			return nil, err
+		}
+	}
 
 	var nilBlock *types.Block
 	bc.currentBlock.Store(nilBlock)
@@ -303,6 +316,9 @@ func NewBlockChain(db ethdb.Database, CMdb ethdb.Database, cacheConfig *CacheCon
 	}
 	// Take ownership of this particular state
 	go bc.update()
+
+	bc.wg.Add(1)
+	go bc.indexIssuance()
 	return bc, nil
 }
 
@@ -474,10 +490,21 @@ func (bc *BlockChain) SetHead(head uint64) error {
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
 		}
+		// Undo the commitments of the rewound block, highest block first
+		if bc.CMdb != nil {
+			rawdb.RevertAllCM(bc.CMdb, hash)
+		}
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
 	bc.hc.SetHead(head, updateFn, delFn)
 
+	// If the head block fell back below the head header (missing state), the
+	// blocks in between keep their headers but lose their commitments.
+	if bc.CMdb != nil {
+		for number := bc.CurrentHeader().Number.Uint64(); number > bc.CurrentBlock().NumberU64(); number-- {
+			rawdb.RevertAllCM(bc.CMdb, rawdb.ReadCanonicalHash(bc.db, number))
+		}
+	}
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -582,6 +609,7 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 	batch := bc.db.NewBatch()
 	rawdb.WriteTd(batch, genesis.Hash(), genesis.NumberU64(), genesis.Difficulty())
 	rawdb.WriteBlock(batch, genesis)
+	WriteBlockIssuance(bc.db, batch, genesis)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to write genesis block", "err", err)
 	}
@@ -684,6 +712,10 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to update chain indexes and markers", "err", err)
 	}
+	// Apply the commitments of the block now that it is canonical
+	if bc.CMdb != nil {
+		rawdb.WriteAllCM(bc.CMdb, block)
+	}
 	// Update all in-memory chain markers in the last step
 	if updateHeads {
 		bc.hc.SetCurrentHeader(block.Header())
@@ -1325,6 +1357,7 @@ func (bc *BlockChain) writeBlockWithoutState(block *types.Block, td *big.Int) (e
 	batch := bc.db.NewBatch()
 	rawdb.WriteTd(batch, block.Hash(), block.NumberU64(), td)
 	rawdb.WriteBlock(batch, block)
+	WriteBlockIssuance(bc.db, batch, block)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -1382,7 +1415,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
-	rawdb.WriteAllCM(bc.CMdb, block)
+	WriteBlockIssuance(bc.db, blockBatch, block)
 
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
@@ -2079,6 +2112,13 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	} else {
 		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "newnum", newBlock.Number(), "newhash", newBlock.Hash())
 	}
+	// Undo the commitments of the dropped blocks (newest first) so that the
+	// new chain is applied on top of the commitment set of the common block.
+	if bc.CMdb != nil {
+		for _, block := range oldChain {
+			rawdb.RevertAllCM(bc.CMdb, block.Hash())
+		}
+	}
 	// Insert the new chain(except the head block(reverse order)),
 	// taking care of the proper incremental order.
 	for i := len(newChain) - 1; i >= 1; i-- {
@@ -2141,6 +2181,25 @@ func (bc *BlockChain) update() {
 	}
 }
 
+// indexIssuance indexes the issuance of the canonical chain in the background,
+// at startup and then periodically for the blocks imported without it.
+func (bc *BlockChain) indexIssuance() {
+	defer bc.wg.Done()
+
+	ticker := time.NewTicker(issuanceIndexInterval)
+	defer ticker.Stop()
+	for {
+		if err := IndexIssuance(bc.db, bc.CurrentBlock().NumberU64(), bc.quit); err != nil {
+			log.Warn("Failed to index issuance", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
 // BadBlocks returns a list of the last 'bad blocks' that the client has seen on the network
 // BadBlocks 处理客户端从网络上获取的最近的bad block列表
 func (bc *BlockChain) BadBlocks() []*types.Block {
@@ -2346,3 +2405,14 @@ func (bc *BlockChain) SubscribeBlockProcessingEvent(ch chan<- bool) event.Subscr
 }
 
 func (bc *BlockChain) GetCMdb() ethdb.Database { return bc.CMdb }
+
+// SetLegacyKeys sets the keys the privacy transactions of imported blocks are
+// verified against before the pinned keys fork where the chain config pins no
+// key, see LegacyKeys.
+func (bc *BlockChain) SetLegacyKeys(keys *LegacyKeys) { bc.legacyKeys.Store(keys) }
+
+// LegacyKeys returns the legacy keys of the chain, nil if none are set.
+func (bc *BlockChain) LegacyKeys() *LegacyKeys {
+	keys, _ := bc.legacyKeys.Load().(*LegacyKeys)
+	return keys
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -207,6 +208,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
 			misc.ApplyDAOHardFork(statedb)
 		}
+		if err := ApplyCMPoolFork(config, &generatedChain{blocks[:i], db}, statedb, b.header); err != nil {
+			panic(err)
+		}
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
@@ -250,6 +254,7 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 
 	return &types.Header{
 		Root:       state.IntermediateRoot(chain.Config().IsEIP158(parent.Number())),
+		CMRoot:     misc.CommitmentRoot(chain.Config(), new(big.Int).Add(parent.Number(), common.Big1), state),
 		ParentHash: parent.Hash(),
 		Coinbase:   parent.Coinbase(),
 		Difficulty: engine.CalcDifficulty(chain, time, &types.Header{
@@ -282,6 +287,21 @@ func makeBlockChain(parent *types.Block, n int, engine consensus.Engine, db ethd
 	return blocks
 }
 
+// generatedChain reads the blocks generated so far and their ancestors in db.
+type generatedChain struct {
+	blocks []*types.Block
+	db     ethdb.Database
+}
+
+func (c *generatedChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	for _, block := range c.blocks {
+		if block.Hash() == hash {
+			return block
+		}
+	}
+	return rawdb.ReadBlock(c.db, hash, number)
+}
+
 type fakeChainReader struct {
 	config *params.ChainConfig
 }
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"math/big"
 	"reflect"
 	"testing"
@@ -120,7 +121,7 @@ func TestSetupGenesis(t *testing.T) {
 				// Advance to block #4, past the homestead transition block of customg.
 				genesis := oldcustomg.MustCommit(db)
 
-				bc, _ := NewBlockChain(db, nil, oldcustomg.Config, ethash.NewFullFaker(), vm.Config{}, nil)
+				bc, _ := NewBlockChain(db, nil, nil, oldcustomg.Config, ethash.NewFullFaker(), vm.Config{}, nil)
 				defer bc.Stop()
 
 				blocks, _ := GenerateChain(oldcustomg.Config, genesis, ethash.NewFaker(), db, 4, nil)
@@ -138,6 +139,27 @@ func TestSetupGenesis(t *testing.T) {
 				RewindTo:     1,
 			},
 		},
+		{
+			name: "governance changed in DB",
+			fn: func(db ethdb.Database) (*params.ChainConfig, common.Hash, error) {
+				// Advance to block #4 and add governance, which no rewind
+				// past the genesis block can make consistent.
+				genesis := oldcustomg.MustCommit(db)
+
+				bc, _ := NewBlockChain(db, nil, nil, oldcustomg.Config, ethash.NewFullFaker(), vm.Config{}, nil)
+				defer bc.Stop()
+
+				blocks, _ := GenerateChain(oldcustomg.Config, genesis, ethash.NewFaker(), db, 4, nil)
+				bc.InsertChain(blocks)
+
+				governed := oldcustomg
+				governed.Config = &params.ChainConfig{HomesteadBlock: big.NewInt(2), Governance: &params.GovernanceConfig{Authorities: []common.Address{{1}}, Threshold: 1}}
+				return SetupGenesisBlock(db, &governed)
+			},
+			wantHash:   customghash,
+			wantConfig: &params.ChainConfig{HomesteadBlock: big.NewInt(2), Governance: &params.GovernanceConfig{Authorities: []common.Address{{1}}, Threshold: 1}},
+			wantErr:    errors.New("incompatible chain config: mismatching governance in database (in effect from the genesis block on)"),
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxIssuanceWalk is the number of blocks TotalIssued reads at most to reach an
+// ancestor whose issuance is known. The issuance of the blocks further back is
+// left to IndexIssuance, which runs in the background.
+const maxIssuanceWalk = 1024
+
+var (
+	// ErrMissingIssuanceBlock is returned if an ancestor of the block the
+	// issuance is asked for is not available, e.g. on a light client.
+	ErrMissingIssuanceBlock = errors.New("missing block to compute issuance")
+
+	// ErrIssuanceNotIndexed is returned if the issuance of a block is asked for
+	// before the ancestors it is summed from were indexed.
+	ErrIssuanceNotIndexed = errors.New("issuance not indexed yet")
+)
+
+// blockIssuance returns the amount issued by the purchases of a block and
+// their number. From the issuance fork on block validation rejects purchases
+// whose signed message does not hold an amount (ErrPurchaseAmount); the ones
+// of earlier blocks that do not are counted without adding to the amount.
+func blockIssuance(block *types.Block) (*big.Int, uint64) {
+	amount, purchases := new(big.Int), uint64(0)
+	for _, tx := range block.Transactions() {
+		p := tx.Purchase()
+		if p == nil {
+			continue
+		}
+		purchases++
+		if v, ok := p.Amount(); ok {
+			amount.Add(amount, new(big.Int).SetUint64(v))
+		}
+	}
+	return amount, purchases
+}
+
+// addIssuance returns the issuance of a chain up to block given the one up to
+// its parent.
+func addIssuance(parent *rawdb.Issuance, block *types.Block) *rawdb.Issuance {
+	amount, purchases := blockIssuance(block)
+	return &rawdb.Issuance{
+		Amount:    amount.Add(amount, parent.Amount),
+		Purchases: parent.Purchases + purchases,
+	}
+}
+
+// WriteBlockIssuance stores the issuance of the chain up to a block being
+// inserted if the one of its parent is known, and returns it. Otherwise it
+// returns nil and leaves the block to IndexIssuance.
+func WriteBlockIssuance(db ethdb.KeyValueReader, w ethdb.KeyValueWriter, block *types.Block) *rawdb.Issuance {
+	parent := &rawdb.Issuance{Amount: new(big.Int)}
+	if block.NumberU64() > 0 {
+		if parent = rawdb.ReadIssuance(db, block.ParentHash()); parent == nil {
+			return nil
+		}
+	}
+	issuance := addIssuance(parent, block)
+	rawdb.WriteIssuance(w, block.Hash(), issuance)
+	return issuance
+}
+
+// TotalIssued returns the amount issued by all purchases of the chain up to and
+// including the given block. The issuance is stored per block on insertion and
+// by IndexIssuance, so at most maxIssuanceWalk blocks are read back to the
+// closest ancestor whose issuance is known; the issuance of the blocks read is
+// stored as well.
+func TotalIssued(db ethdb.Database, block *types.Block) (*rawdb.Issuance, error) {
+	var (
+		pending []*types.Block
+		total   = &rawdb.Issuance{Amount: new(big.Int)}
+	)
+	for b := block; ; {
+		if cached := rawdb.ReadIssuance(db, b.Hash()); cached != nil {
+			total = cached
+			break
+		}
+		if len(pending) == maxIssuanceWalk {
+			return nil, ErrIssuanceNotIndexed
+		}
+		pending = append(pending, b)
+		if b.NumberU64() == 0 {
+			break
+		}
+		if b = rawdb.ReadBlock(db, b.ParentHash(), b.NumberU64()-1); b == nil {
+			return nil, ErrMissingIssuanceBlock
+		}
+	}
+	batch := db.NewBatch()
+	for i := len(pending) - 1; i >= 0; i-- {
+		total = addIssuance(total, pending[i])
+		rawdb.WriteIssuance(batch, pending[i].Hash(), total)
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// IndexIssuance stores the issuance of the canonical chain up to block head
+// wherever it was not stored on insertion, e.g. for blocks imported by fast
+// sync or before the issuance was tracked. It walks the chain forward from the
+// block it was last indexed up to, which is checkpointed with every batch, and
+// returns early once quit is closed.
+func IndexIssuance(db ethdb.Database, head uint64, quit <-chan struct{}) error {
+	var (
+		number = uint64(0)
+		total  = &rawdb.Issuance{Amount: new(big.Int)}
+	)
+	// Resume from the last checkpoint unless a reorg replaced its block by one
+	// not indexed yet
+	if progress := rawdb.ReadIssuanceProgress(db); progress != nil && *progress <= head {
+		if cached := rawdb.ReadIssuance(db, rawdb.ReadCanonicalHash(db, *progress)); cached != nil {
+			number, total = *progress+1, cached
+		}
+	}
+	var (
+		batch   = db.NewBatch()
+		indexed int
+	)
+	for ; number <= head; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if cached := rawdb.ReadIssuance(db, hash); cached != nil {
+			total = cached
+		} else {
+			block := rawdb.ReadBlock(db, hash, number)
+			if block == nil {
+				return ErrMissingIssuanceBlock
+			}
+			total = addIssuance(total, block)
+			rawdb.WriteIssuance(batch, hash, total)
+			indexed++
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize || number == head {
+			rawdb.WriteIssuanceProgress(batch, number)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+			select {
+			case <-quit:
+				return nil
+			default:
+			}
+		}
+	}
+	if indexed > 0 {
+		log.Info("Indexed issuance", "blocks", indexed, "head", head, "amount", total.Amount)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"maskchain/privacy/ecc"
+)
+
+// Tests that the issuance is stored as blocks are inserted and that blocks
+// with a purchase whose signature does not hold an amount are rejected.
+func TestIssuanceOnInsert(t *testing.T) {
+	exchange, exchangePriv, _ := ecc.GenerateKeys("exchange")
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		db     = rawdb.NewMemoryDatabase()
+		config = *params.TestChainConfig
+	)
+	config.ExchangeKeys = []params.PrivacyKey{types.PubKey(exchange).ConfigKey(common.Big0)}
+	gspec := &Genesis{Config: &config, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(1000000000)}}}
+	genesis := gspec.MustCommit(db)
+	signer := types.NewEIP155Signer(config.ChainID)
+
+	purchase := func(gen *BlockGen, message string, cm byte) {
+		payload := &types.PurchasePayload{Sig: types.NewPurchaseSignature(ecc.Sign(exchangePriv, []byte(message))), CmV: []byte{cm}}
+		tx, _ := types.SignTx(types.NewPrivacyTransaction(gen.TxNonce(addr), &addr, new(big.Int), params.TxGas, nil, nil, payload), signer, key)
+		gen.AddTx(tx)
+	}
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		purchase(gen, "1100", byte(2*i+1))
+		if i == 1 {
+			purchase(gen, "125", byte(2*i+2))
+		}
+	})
+	chain, err := NewBlockChain(db, rawdb.NewMemoryDatabase(), nil, &config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	if issuance := rawdb.ReadIssuance(db, genesis.Hash()); issuance == nil || issuance.Amount.Sign() != 0 {
+		t.Fatalf("genesis issuance mismatch: have %v", issuance)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for i, want := range []int64{100, 225, 325} {
+		issuance := rawdb.ReadIssuance(db, blocks[i].Hash())
+		if issuance == nil {
+			t.Fatalf("block %d: issuance not stored on insertion", i+1)
+		}
+		if issuance.Amount.Int64() != want {
+			t.Errorf("block %d: issuance mismatch: have %v, want %d", i+1, issuance.Amount, want)
+		}
+	}
+	// A purchase the issuance cannot account for is invalid in a block
+	invalid, _ := GenerateChain(&config, blocks[len(blocks)-1], ethash.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+		purchase(gen, "1abc", 0x10)
+	})
+	if _, err := chain.InsertChain(invalid); err == nil || !strings.Contains(err.Error(), ErrPurchaseAmount.Error()) {
+		t.Errorf("purchase without amount: have %v, want %v", err, ErrPurchaseAmount)
+	}
+	// Before the issuance fork such purchases are valid
+	forked := config
+	forked.IssuanceBlock = big.NewInt(5)
+	tx := invalid[0].Transactions()[0]
+	if err := NewPrivacyValidator(&forked).At(big.NewInt(4)).VerifyPurchaseSign(tx); err != nil {
+		t.Errorf("purchase without amount before the issuance fork: %v", err)
+	}
+	if err := NewPrivacyValidator(&forked).At(big.NewInt(5)).VerifyPurchaseSign(tx); err != ErrPurchaseAmount {
+		t.Errorf("purchase without amount at the issuance fork: have %v, want %v", err, ErrPurchaseAmount)
+	}
+}
+
+// Tests that the issuance of blocks stored without it is indexed from the last
+// checkpoint on, and that TotalIssued does not walk back further than
+// maxIssuanceWalk blocks.
+func TestIndexIssuance(t *testing.T) {
+	_, exchangePriv, _ := ecc.GenerateKeys("exchange")
+	db := rawdb.NewMemoryDatabase()
+
+	// A canonical chain with a purchase of 1 in every block, whose issuance
+	// was never stored
+	var (
+		blocks []*types.Block
+		parent common.Hash
+	)
+	for i := 0; i <= maxIssuanceWalk+10; i++ {
+		var txs []*types.Transaction
+		if i > 0 {
+			payload := &types.PurchasePayload{Sig: types.NewPurchaseSignature(ecc.Sign(exchangePriv, []byte("11")))}
+			txs = append(txs, types.NewPrivacyTransaction(uint64(i), &common.Address{}, new(big.Int), 21000, big.NewInt(1), nil, payload))
+		}
+		block := types.NewBlock(&types.Header{Number: big.NewInt(int64(i)), ParentHash: parent}, txs, nil, nil)
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		blocks, parent = append(blocks, block), block.Hash()
+	}
+	head := blocks[len(blocks)-1]
+	if _, err := TotalIssued(db, head); err != ErrIssuanceNotIndexed {
+		t.Fatalf("issuance beyond the walk limit: have %v, want %v", err, ErrIssuanceNotIndexed)
+	}
+	// Index half of the chain, then resume up to the head
+	if err := IndexIssuance(db, 500, nil); err != nil {
+		t.Fatalf("failed to index issuance: %v", err)
+	}
+	if progress := rawdb.ReadIssuanceProgress(db); progress == nil || *progress != 500 {
+		t.Fatalf("progress mismatch: have %v, want 500", progress)
+	}
+	if err := IndexIssuance(db, head.NumberU64(), nil); err != nil {
+		t.Fatalf("failed to index issuance: %v", err)
+	}
+	issuance, err := TotalIssued(db, head)
+	if err != nil {
+		t.Fatalf("failed to get issuance: %v", err)
+	}
+	if want := int64(head.NumberU64()); issuance.Amount.Int64() != want || issuance.Purchases != uint64(want) {
+		t.Errorf("issuance mismatch: have %v in %d purchases, want %d", issuance.Amount, issuance.Purchases, want)
+	}
+	// A block on top of the indexed chain is summed from its parent
+	next := types.NewBlock(&types.Header{Number: new(big.Int).Add(head.Number(), common.Big1), ParentHash: head.Hash()}, nil, nil, nil)
+	if issuance := WriteBlockIssuance(db, db, next); issuance == nil || issuance.Amount.Int64() != int64(head.NumberU64()) {
+		t.Errorf("issuance of the next block mismatch: have %v", issuance)
+	}
+}
+
+// Tests that the issuance sums the amounts signed by the exchange along the
+// chain of the block asked for, also on a fork sharing cached ancestors.
+func TestTotalIssued(t *testing.T) {
+	_, exchangePriv, _ := ecc.GenerateKeys("exchange")
+	db := rawdb.NewMemoryDatabase()
+
+	purchase := func(nonce uint64, amount string) *types.Transaction {
+		payload := &types.PurchasePayload{Sig: types.NewPurchaseSignature(ecc.Sign(exchangePriv, []byte("1"+amount)))}
+		return types.NewPrivacyTransaction(nonce, &common.Address{}, new(big.Int), 21000, big.NewInt(1), nil, payload)
+	}
+	block := func(parent *types.Block, extra byte, txs ...*types.Transaction) *types.Block {
+		header := &types.Header{Number: big.NewInt(0), Extra: []byte{extra}}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+			header.Number = new(big.Int).Add(parent.Number(), common.Big1)
+		}
+		b := types.NewBlock(header, txs, nil, nil)
+		rawdb.WriteBlock(db, b)
+		return b
+	}
+	genesis := block(nil, 0)
+	b1 := block(genesis, 0, purchase(0, "100"), purchase(1, "20"))
+	b2 := block(b1, 0, purchase(2, "5"), purchase(3, "abc"))
+	fork := block(b1, 1, purchase(2, "1000"))
+
+	for _, tt := range []struct {
+		block     *types.Block
+		amount    int64
+		purchases uint64
+	}{
+		{genesis, 0, 0},
+		{b2, 125, 4},
+		{fork, 1120, 3},
+		{b1, 120, 2},
+	} {
+		issuance, err := TotalIssued(db, tt.block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issuance.Amount.Int64() != tt.amount || issuance.Purchases != tt.purchases {
+			t.Errorf("block %d: have %v in %d purchases, want %d in %d", tt.block.NumberU64(), issuance.Amount, issuance.Purchases, tt.amount, tt.purchases)
+		}
+	}
+	if rawdb.ReadIssuance(db, b2.Hash()) == nil {
+		t.Errorf("issuance not cached")
+	}
+	orphan := types.NewBlock(&types.Header{Number: big.NewInt(5), ParentHash: common.Hash{1}}, nil, nil, nil)
+	if _, err := TotalIssued(db, orphan); err != ErrMissingIssuanceBlock {
+		t.Errorf("issuance of block with missing ancestors: have %v, want %v", err, ErrMissingIssuanceBlock)
+	}
+}
@@ -0,0 +1,960 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"maskchain/privacy/ecc"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrMalformedPrivacyTx is returned if a privacy transaction is missing a
+	// proof field or carries bytes that cannot be decoded into curve points.
+	ErrMalformedPrivacyTx = errors.New("malformed privacy transaction")
+
+	// ErrNoExchangeKey is returned if a purchase has to be verified but the
+	// chain config pins no exchange public key for its block.
+	ErrNoExchangeKey = errors.New("exchange public key not configured")
+
+	// ErrNoRegulatorKey is returned if a transfer has to be verified but the
+	// chain config pins no regulator public key, under which the commitments
+	// are made, for its block.
+	ErrNoRegulatorKey = errors.New("regulator public key not configured")
+
+	// ErrRegulatorGenerators is returned if a transfer has to be verified but
+	// the regulator key does not use the generators pinned by the chain config.
+	ErrRegulatorGenerators = errors.New("regulator key does not use the pinned generators")
+
+	// ErrDoubleSpentCM is returned if a block spends a commitment which is
+	// already spent on chain or spent twice within the block.
+	ErrDoubleSpentCM = errors.New("commitment already spent")
+
+	// ErrShieldedFork is returned for a shielded transfer before the shielded
+	// fork block.
+	ErrShieldedFork = errors.New("shielded transfer before the shielded fork")
+
+	// ErrNoShieldedState is returned if a shielded transfer has to be verified
+	// without the state holding the commitment accumulator.
+	ErrNoShieldedState = errors.New("shielded transfer verified without state")
+
+	// ErrNoCMPoolState is returned if a block from the commitment pool fork on
+	// has to be validated without the state holding the commitment pool.
+	ErrNoCMPoolState = errors.New("commitments verified without state")
+
+	// ErrNoCMPoolFork is returned if a transaction creating or spending
+	// commitments is verified under a chain config scheduling no commitment
+	// pool, so the commitments could not be checked against the chain.
+	ErrNoCMPoolFork = errors.New("commitments without a commitment pool fork")
+
+	// ErrUnknownAnchor is returned if a shielded transfer spends from a ring
+	// below a root the accumulator never had, or from a ring beyond its size.
+	ErrUnknownAnchor = errors.New("unknown accumulator anchor or ring")
+
+	// ErrShieldedCM is returned if a transparent transfer spends a commitment
+	// created after the shielded fork, which only a shielded transfer may spend.
+	ErrShieldedCM = errors.New("commitment can only be spent by a shielded transfer")
+
+	// ErrMissingSpendKey is returned if a transaction from the shielded fork on
+	// does not carry exactly one spend key for every commitment it creates.
+	ErrMissingSpendKey = errors.New("missing spend key of a created commitment")
+
+	// ErrNoGovernance is returned for a key rotation on a chain whose config
+	// has no governance authorities.
+	ErrNoGovernance = errors.New("key rotation without governance authorities")
+
+	// ErrKeyRotationBlock is returned if a key rotation activates its key
+	// before the governance delay has passed or not after all keys of its
+	// kind scheduled so far.
+	ErrKeyRotationBlock = errors.New("key rotation activated too early")
+
+	// ErrKeyRotationQuorum is returned if fewer authorities than the
+	// governance threshold signed a key rotation.
+	ErrKeyRotationQuorum = errors.New("key rotation not signed by a quorum of authorities")
+)
+
+// ShieldedState is the part of the state the commitments of a block are
+// verified against, i.e. the commitment pool with its nullifier set and the
+// commitment accumulator. It is implemented by *state.StateDB.
+type ShieldedState interface {
+	AnchorSize(anchor common.Hash) (uint64, bool)
+	AccumulatorLeaf(index uint64) (cm, key []byte)
+	GetCommitment(hash common.Hash) state.CommitmentStatus
+	HasNullifier(hash common.Hash) bool
+}
+
+// KeyState is the part of the state holding the keys scheduled by key
+// rotations. It is implemented by *state.StateDB.
+type KeyState interface {
+	ScheduledKeys(kind uint8) []params.PrivacyKey
+}
+
+// PrivacyState is the state privacy transactions are verified against.
+type PrivacyState interface {
+	ShieldedState
+	KeyState
+}
+
+// privacyKeys returns the keys of the given kind pinned by the chain config
+// and, if the config has governance authorities, the ones scheduled by key
+// rotations in state, ordered by activation block. The state may be nil.
+func privacyKeys(config *params.ChainConfig, state KeyState, kind uint8) []params.PrivacyKey {
+	var keys []params.PrivacyKey
+	switch kind {
+	case types.RegulatorKeyKind:
+		keys = append(keys, config.RegulatorKeys...)
+	case types.ExchangeKeyKind:
+		keys = append(keys, config.ExchangeKeys...)
+	}
+	if config.Governance != nil && state != nil {
+		keys = append(keys, state.ScheduledKeys(kind)...)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Block.Cmp(keys[j].Block) < 0 })
+	return keys
+}
+
+// PrivacyKeyAt returns the key of the given kind in effect at block num, nil
+// if there is none. Keys pinned by the chain config are superseded by the ones
+// key rotations scheduled in the state, which may be nil to ignore them.
+func PrivacyKeyAt(config *params.ChainConfig, state KeyState, kind uint8, num *big.Int) *params.PrivacyKey {
+	var (
+		keys = privacyKeys(config, state, kind)
+		key  *params.PrivacyKey
+	)
+	for i := range keys {
+		if keys[i].Block.Cmp(num) > 0 {
+			break
+		}
+		key = &keys[i]
+	}
+	return key
+}
+
+// LegacyKeys are the keys of the regulator and the exchange a node is
+// configured with out of band, as fetched from their servers before chain
+// configs pinned the keys. Before the pinned keys fork they stand in for keys
+// neither the chain config nor a key rotation provides. Either may be nil.
+type LegacyKeys struct {
+	Regulator *params.PrivacyKey
+	Exchange  *params.PrivacyKey
+}
+
+// NewLegacyKeys returns the legacy keys of the given regulator and exchange,
+// leaving out the ones without a key.
+func NewLegacyKeys(regulator types.Regulator, exchange types.Exchange) *LegacyKeys {
+	keys := new(LegacyKeys)
+	if pub := regulator.PubK; pub.G1 != nil && pub.G2 != nil && pub.H != nil {
+		key := pub.ConfigKey(common.Big0)
+		keys.Regulator = &key
+	}
+	if pub := exchange.PubKey; pub.G1 != nil && pub.G2 != nil && pub.H != nil {
+		key := pub.ConfigKey(common.Big0)
+		keys.Exchange = &key
+	}
+	return keys
+}
+
+// KeyAt returns the key of the given kind in effect at block num like
+// PrivacyKeyAt, falling back to the legacy key before the pinned keys fork.
+// The legacy keys may be nil for none.
+func (l *LegacyKeys) KeyAt(config *params.ChainConfig, state KeyState, kind uint8, num *big.Int) *params.PrivacyKey {
+	if key := PrivacyKeyAt(config, state, kind, num); key != nil || l == nil || config.IsPinnedKeys(num) {
+		return key
+	}
+	switch kind {
+	case types.RegulatorKeyKind:
+		return l.Regulator
+	case types.ExchangeKeyKind:
+		return l.Exchange
+	}
+	return nil
+}
+
+// checkRotationBlock returns ErrKeyRotationBlock if a key rotation included in
+// block num activates its key before the governance delay has passed or not
+// after all keys of its kind scheduled so far.
+func checkRotationBlock(config *params.ChainConfig, state KeyState, num *big.Int, p *types.KeyRotationPayload) error {
+	if p.Block <= num.Uint64()+config.Governance.Delay {
+		return ErrKeyRotationBlock
+	}
+	keys := privacyKeys(config, state, p.Kind)
+	if n := len(keys); n > 0 && keys[n-1].Block.Uint64() >= p.Block {
+		return ErrKeyRotationBlock
+	}
+	return nil
+}
+
+// PrivacyValidator checks the privacy part of transactions, i.e. the purchase
+// signature of the exchange, the zero-knowledge proofs of transfers and the
+// validity of the commitments against the commitment pool in the state of the
+// parent block.
+//
+// It is shared between the transaction pool and the block validator so that
+// a block mined by a peer is held to exactly the same rules as a transaction
+// submitted to the local pool. The keys of the exchange and the regulator are
+// the ones in effect for the block the transactions are in, see At.
+type PrivacyValidator struct {
+	config     *params.ChainConfig // Chain config the forks and keys are scheduled by
+	legacy     *LegacyKeys         // Keys standing in for unpinned ones before the pinned keys fork, nil if none
+	chainID    *big.Int            // Chain ID the transfer proofs are bound to
+	exchange   types.Exchange
+	regulator  types.Regulator
+	num        *big.Int                 // Block the transactions are verified for, see At
+	rangeBits  int                      // Bit width of the range proofs on transfer outputs
+	generators *params.GeneratorsConfig // Generators the regulator key must use, nil if not pinned
+	state      PrivacyState             // State commitments and key rotations are verified against, nil if none
+}
+
+// NewPrivacyValidator returns a privacy validator verifying transactions under
+// the given chain config. It has no keys until At selects the block the
+// transactions are verified for, and no commitment pool until WithState.
+func NewPrivacyValidator(config *params.ChainConfig) *PrivacyValidator {
+	return &PrivacyValidator{
+		config:     config,
+		chainID:    config.ChainID,
+		rangeBits:  config.RangeProofWidth(),
+		generators: config.PrivacyGenerators,
+	}
+}
+
+// At returns a copy of the validator which verifies transactions of block num,
+// i.e. against the keys of the exchange and the regulator in effect at num.
+// Keys scheduled by key rotations are taken from the state of the validator,
+// so WithState has to be called first.
+func (v *PrivacyValidator) At(num *big.Int) *PrivacyValidator {
+	cpy := *v
+	cpy.num = new(big.Int).Set(num)
+	cpy.exchange = types.NewExchange(v.legacy.KeyAt(v.config, v.keyState(), types.ExchangeKeyKind, num))
+	cpy.regulator = types.NewRegulator(v.legacy.KeyAt(v.config, v.keyState(), types.RegulatorKeyKind, num))
+	return &cpy
+}
+
+// WithLegacyKeys returns a copy of the validator which falls back to the given
+// legacy keys before the pinned keys fork, see LegacyKeys. It has to be called
+// before At.
+func (v *PrivacyValidator) WithLegacyKeys(legacy *LegacyKeys) *PrivacyValidator {
+	cpy := *v
+	cpy.legacy = legacy
+	return &cpy
+}
+
+// keyState returns the state of the validator as KeyState, nil if it has none.
+func (v *PrivacyValidator) keyState() KeyState {
+	if v.state == nil {
+		return nil
+	}
+	return v.state
+}
+
+// WithState returns a copy of the validator which verifies commitments,
+// shielded transfers and key rotations against the given state, i.e. the state
+// of the parent of the block they are included in.
+func (v *PrivacyValidator) WithState(state PrivacyState) *PrivacyValidator {
+	cpy := *v
+	cpy.state = state
+	return &cpy
+}
+
+// rangeProofRequired reports whether transfers must carry range proofs in the
+// block the validator is at. Without a block they always must.
+func (v *PrivacyValidator) rangeProofRequired() bool {
+	return v.num == nil || v.config.IsRangeProof(v.num)
+}
+
+// amountRequired reports whether the exchange signature of purchases must hold
+// the issued amount in the block the validator is at. Without a block it must.
+func (v *PrivacyValidator) amountRequired() bool {
+	return v.num == nil || v.config.IsIssuance(v.num)
+}
+
+// legacyProofsAllowed reports whether transfers may still carry proofs of the
+// legacy privacy version in the block the validator is at. Without a block
+// they may not.
+func (v *PrivacyValidator) legacyProofsAllowed() bool {
+	return v.num != nil && !v.config.IsBoundProof(v.num)
+}
+
+// checkRegulatorKey returns an error if the regulator key is not configured
+// or does not use the generators pinned by the chain config.
+func (v *PrivacyValidator) checkRegulatorKey() error {
+	return CheckRegulatorKey(v.regulator, v.generators)
+}
+
+// CheckRegulatorKey returns an error if the key of the regulator is not
+// configured or does not use the pinned generators, if any.
+func CheckRegulatorKey(regulator types.Regulator, generators *params.GeneratorsConfig) error {
+	pub := regulator.PubK
+	if pub.G1 == nil || pub.G2 == nil || pub.P == nil || pub.H == nil {
+		return ErrNoRegulatorKey
+	}
+	if generators != nil && !ecc.PublicKey(pub).HasGenerators(generators.G1, generators.G2) {
+		return ErrRegulatorGenerators
+	}
+	return nil
+}
+
+// CheckPrivacyKeys returns an error if a regulator key pinned by the chain
+// config does not use the pinned generators.
+func CheckPrivacyKeys(config *params.ChainConfig) error {
+	for i := range config.RegulatorKeys {
+		key := &config.RegulatorKeys[i]
+		if err := CheckRegulatorKey(types.NewRegulator(key), config.PrivacyGenerators); err != nil {
+			return fmt.Errorf("regulator key at block %v: %w", key.Block, err)
+		}
+	}
+	return nil
+}
+
+// VerifyKeyRotation verifies a key rotation (ID=5) in the block the validator
+// is at: the key must consist of valid points, a regulator key must use the
+// pinned generators, the key must be activated after the governance delay
+// and after all keys of its kind, and a quorum of distinct authorities must
+// have signed it.
+func (v *PrivacyValidator) VerifyKeyRotation(tx *types.Transaction) error {
+	g := v.config.Governance
+	if g == nil {
+		return ErrNoGovernance
+	}
+	if v.num == nil {
+		return ErrKeyRotationBlock
+	}
+	p := tx.KeyRotation()
+	if p == nil || p.Kind > types.ExchangeKeyKind {
+		return ErrMalformedPrivacyTx
+	}
+	for _, field := range p.Fields() {
+		if len(*field) == 0 {
+			return ErrMalformedPrivacyTx
+		}
+	}
+	for _, point := range []struct {
+		name string
+		enc  []byte
+	}{{"G1", p.G1}, {"G2", p.G2}, {"H", p.H}} {
+		if _, err := ecc.DecodePoint(point.enc); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrMalformedPrivacyTx, point.name, err)
+		}
+	}
+	key := p.Key()
+	if p.Kind == types.RegulatorKeyKind {
+		if err := CheckRegulatorKey(types.NewRegulator(&key), v.generators); err != nil {
+			return err
+		}
+	}
+	if err := checkRotationBlock(v.config, v.keyState(), v.num, p); err != nil {
+		return err
+	}
+	signers, err := p.Signers(v.chainID)
+	if err != nil {
+		return ErrKeyRotationQuorum
+	}
+	signed := make(map[common.Address]bool)
+	for _, signer := range signers {
+		if g.IsAuthority(signer) {
+			signed[signer] = true
+		}
+	}
+	if uint64(len(signed)) < g.Threshold {
+		return ErrKeyRotationQuorum
+	}
+	return nil
+}
+
+// VerifyPurchaseSign verifies the exchange signature of a purchase (ID=1)
+// transaction and, from the issuance fork on, that the signed message holds
+// the issued amount, which the total issuance is summed from.
+func (v *PrivacyValidator) VerifyPurchaseSign(tx *types.Transaction) (err error) {
+	if v.exchange.PubKey.G1 == nil || v.exchange.PubKey.G2 == nil || v.exchange.PubKey.P == nil || v.exchange.PubKey.H == nil {
+		return ErrNoExchangeKey
+	}
+	p := tx.Purchase()
+	if p == nil || len(p.Sig.M) == 0 || len(p.Sig.MHash) == 0 || len(p.Sig.R) == 0 || len(p.Sig.S) == 0 || len(p.CmV) == 0 {
+		return ErrMalformedPrivacyTx
+	}
+	defer recoverMalformed(tx, &err)
+
+	if !ecc.Verify(ecc.PublicKey(v.exchange.PubKey), p.Sig.ECC()) {
+		return ErrVerifySig
+	}
+	if _, ok := p.Amount(); !ok && v.amountRequired() {
+		return ErrPurchaseAmount
+	}
+	return nil
+}
+
+// VerifyTransferProofs verifies the format, balance, equality and range proofs
+// of a transfer (ID=0) transaction.
+func (v *PrivacyValidator) VerifyTransferProofs(tx *types.Transaction) error {
+	return v.verifyTransferProofs(tx, nil)
+}
+
+// verifyTransferProofs verifies the proofs of a transfer, collecting the group
+// equations of its sigma proofs in b unless b is nil.
+func (v *PrivacyValidator) verifyTransferProofs(tx *types.Transaction, b *ecc.BatchVerifier) (err error) {
+	if err := v.checkRegulatorKey(); err != nil {
+		return err
+	}
+	p := tx.Transfer()
+	if p == nil || !p.Complete(v.rangeProofRequired()) {
+		return ErrMalformedPrivacyTx
+	}
+	if tx.Version() == types.LegacyPrivacyVersion && !v.legacyProofsAllowed() {
+		return ErrLegacyProofs
+	}
+	// The verifiers reject undecodable points themselves, a panic within the
+	// ECC library must still reject the transaction instead of taking the
+	// node down.
+	defer recoverMalformed(tx, &err)
+
+	var tr *ecc.Transcript
+	if tx.Version() != types.LegacyPrivacyVersion {
+		tr = p.Transcript(v.chainID)
+	}
+	ok, err := b.VerifyFormatProof(tr.Fork(types.ScmFPLabel), p.EvS.ECC(), p.ScmFP.ECC())
+	if err := proofError(types.ScmFPLabel, ok, err, ErrVerifyEvSFormatProof); err != nil {
+		return err
+	}
+	ok, err = b.VerifyFormatProof(tr.Fork(types.RcmFPLabel), p.EvR.ECC(), p.RcmFP.ECC())
+	if err := proofError(types.RcmFPLabel, ok, err, ErrVerifyEvRFormatProof); err != nil {
+		return err
+	}
+	ok, err = b.VerifyBalanceProof(tr.Fork(types.BPLabel), p.CmR, p.CmS, p.CmO, p.BP.ECC())
+	if err := proofError(types.BPLabel, ok, err, ErrVerifyBalanceProof); err != nil {
+		return err
+	}
+	ok, err = b.VerifyEqualityProof(tr.Fork(types.VoEPLabel), p.VoEP.ECC())
+	if err := proofError(types.VoEPLabel, ok, err, ErrVerifyTotalEqualityProof); err != nil {
+		return err
+	}
+	ok, err = b.VerifyEqualityProof(tr.Fork(types.RpkEPLabel), p.RpkEP.ECC())
+	if err := proofError(types.RpkEPLabel, ok, err, ErrVerifyRpkEqualityProof); err != nil {
+		return err
+	}
+	ok, err = b.VerifyEqualityProof(tr.Fork(types.SpkEPLabel), p.SpkEP.ECC())
+	if err := proofError(types.SpkEPLabel, ok, err, ErrVerifySpkEqualityProof); err != nil {
+		return err
+	}
+	// The balance proof holds modulo the group order only, so both outputs
+	// must be shown to be small non-negative values. Legacy transfers before
+	// the range proof fork may come without.
+	if len(p.RP) == 0 {
+		return nil
+	}
+	comms := [][]byte{p.CmS, p.CmR}
+	ok, err = ecc.VerifyTransferRangeProof(tr.Fork(types.RPLabel), ecc.PublicKey(v.regulator.PubK), comms, p.RP, v.rangeBits)
+	return proofError(types.RPLabel, ok, err, ErrVerifyRangeProof)
+}
+
+// VerifyMultiTransferProofs verifies the proofs of a multi transfer (ID=3)
+// transaction: the sender and recipient address proofs, the equality proof of
+// every input, the format proof of every output, the balance proof over all
+// inputs and outputs and the aggregated range proof over all outputs.
+func (v *PrivacyValidator) VerifyMultiTransferProofs(tx *types.Transaction) error {
+	return v.verifyMultiTransferProofs(tx, nil)
+}
+
+// verifyMultiTransferProofs verifies the proofs of a multi transfer, collecting
+// the group equations of its sigma proofs in b unless b is nil.
+func (v *PrivacyValidator) verifyMultiTransferProofs(tx *types.Transaction, b *ecc.BatchVerifier) (err error) {
+	if err := v.checkRegulatorKey(); err != nil {
+		return err
+	}
+	p := tx.MultiTransfer()
+	if p == nil || !p.Complete() {
+		return ErrMalformedPrivacyTx
+	}
+	// An input listed twice would be counted twice by the balance proof
+	seen := make(map[string]struct{}, len(p.Inputs))
+	for _, in := range p.Inputs {
+		if _, ok := seen[string(in.Cm)]; ok {
+			return ErrDoubleSpentCM
+		}
+		seen[string(in.Cm)] = struct{}{}
+	}
+	defer recoverMalformed(tx, &err)
+
+	var tr *ecc.Transcript
+	if tx.Version() != types.LegacyPrivacyVersion {
+		tr = p.Transcript(v.chainID)
+	}
+	ok, err := b.VerifyEqualityProof(tr.Fork(types.SpkEPLabel), p.SpkEP.ECC())
+	if err := proofError(types.SpkEPLabel, ok, err, ErrVerifySpkEqualityProof); err != nil {
+		return err
+	}
+	for i, in := range p.Inputs {
+		label := types.IndexedLabel(types.EPLabel, i)
+		ok, err := b.VerifyEqualityProof(tr.Fork(label), in.EP.ECC())
+		if err := proofError(label, ok, err, ErrVerifyTotalEqualityProof); err != nil {
+			return err
+		}
+	}
+	for i, out := range p.Outputs {
+		label := types.IndexedLabel(types.RpkEPLabel, i)
+		ok, err := b.VerifyEqualityProof(tr.Fork(label), out.RpkEP.ECC())
+		if err := proofError(label, ok, err, ErrVerifyRpkEqualityProof); err != nil {
+			return err
+		}
+		label = types.IndexedLabel(types.FPLabel, i)
+		ok, err = b.VerifyFormatProof(tr.Fork(label), out.Ev.ECC(), out.FP.ECC())
+		if err := proofError(label, ok, err, ErrVerifyOutputFormatProof); err != nil {
+			return err
+		}
+	}
+	ok, err = b.VerifyMultiBalanceProof(tr.Fork(types.BPLabel), p.Spent(), p.Created(), p.BP.ECC())
+	if err := proofError(types.BPLabel, ok, err, ErrVerifyBalanceProof); err != nil {
+		return err
+	}
+	ok, err = ecc.VerifyTransferRangeProof(tr.Fork(types.RPLabel), ecc.PublicKey(v.regulator.PubK), p.Created(), p.RP, v.rangeBits)
+	return proofError(types.RPLabel, ok, err, ErrVerifyRangeProof)
+}
+
+// VerifyShieldedTransferProofs verifies the proofs of a shielded transfer
+// (ID=4) transaction against the state of the validator: the sender address
+// proof, the format proof of the pseudo commitment and the membership proof of
+// every input within its ring of the accumulator, the address and format
+// proofs of every output, the balance proof of the pseudo commitments against
+// the outputs and the aggregated range proof over all outputs.
+func (v *PrivacyValidator) VerifyShieldedTransferProofs(tx *types.Transaction) error {
+	return v.verifyShieldedTransferProofs(tx, nil)
+}
+
+// verifyShieldedTransferProofs verifies the proofs of a shielded transfer,
+// collecting the group equations of its sigma proofs in b unless b is nil.
+func (v *PrivacyValidator) verifyShieldedTransferProofs(tx *types.Transaction, b *ecc.BatchVerifier) (err error) {
+	if err := v.checkRegulatorKey(); err != nil {
+		return err
+	}
+	if v.state == nil {
+		return ErrNoShieldedState
+	}
+	// Shielded transfers postdate the legacy proofs, which are not bound to
+	// the transaction
+	p := tx.ShieldedTransfer()
+	if p == nil || !p.Complete() || tx.Version() == types.LegacyPrivacyVersion {
+		return ErrMalformedPrivacyTx
+	}
+	// A nullifier listed twice would spend its commitment twice. The rings are
+	// looked up before any proof is checked, an unknown anchor fails early.
+	var (
+		seen  = make(map[string]struct{}, len(p.Inputs))
+		rings = make([][][]byte, len(p.Inputs))
+		keys  = make([][][]byte, len(p.Inputs))
+	)
+	for i, in := range p.Inputs {
+		if _, ok := seen[string(in.Nullifier)]; ok {
+			return ErrDoubleSpentCM
+		}
+		seen[string(in.Nullifier)] = struct{}{}
+		if rings[i], keys[i], err = v.ring(in.Anchor, in.Ring); err != nil {
+			return err
+		}
+	}
+	defer recoverMalformed(tx, &err)
+
+	var (
+		tr        = p.Transcript(v.chainID)
+		regulator = ecc.PublicKey(v.regulator.PubK)
+	)
+	ok, err := b.VerifyEqualityProof(tr.Fork(types.SpkEPLabel), p.SpkEP.ECC())
+	if err := proofError(types.SpkEPLabel, ok, err, ErrVerifySpkEqualityProof); err != nil {
+		return err
+	}
+	for i, in := range p.Inputs {
+		label := types.IndexedLabel(types.PFPLabel, i)
+		ok, err := b.VerifyFormatProof(tr.Fork(label), in.Ev.ECC(), in.FP.ECC())
+		if err := proofError(label, ok, err, ErrVerifyPseudoFormatProof); err != nil {
+			return err
+		}
+		label = types.IndexedLabel(types.MPLabel, i)
+		var mp ecc.MembershipProof
+		if err := mp.UnmarshalBinary(in.MP); err != nil {
+			return proofError(label, false, err, ErrVerifyMembershipProof)
+		}
+		ok, err = b.VerifyMembershipProof(tr.Fork(label), regulator, rings[i], keys[i], in.Ev.C1, in.Nullifier, mp)
+		if err := proofError(label, ok, err, ErrVerifyMembershipProof); err != nil {
+			return err
+		}
+	}
+	for i, out := range p.Outputs {
+		label := types.IndexedLabel(types.RpkEPLabel, i)
+		ok, err := b.VerifyEqualityProof(tr.Fork(label), out.RpkEP.ECC())
+		if err := proofError(label, ok, err, ErrVerifyRpkEqualityProof); err != nil {
+			return err
+		}
+		label = types.IndexedLabel(types.FPLabel, i)
+		ok, err = b.VerifyFormatProof(tr.Fork(label), out.Ev.ECC(), out.FP.ECC())
+		if err := proofError(label, ok, err, ErrVerifyOutputFormatProof); err != nil {
+			return err
+		}
+	}
+	ok, err = b.VerifyMultiBalanceProof(tr.Fork(types.BPLabel), p.Pseudo(), p.Created(), p.BP.ECC())
+	if err := proofError(types.BPLabel, ok, err, ErrVerifyBalanceProof); err != nil {
+		return err
+	}
+	ok, err = ecc.VerifyTransferRangeProof(tr.Fork(types.RPLabel), regulator, p.Created(), p.RP, v.rangeBits)
+	return proofError(types.RPLabel, ok, err, ErrVerifyRangeProof)
+}
+
+// VerifyRedeemProofs verifies the proofs of a redemption (ID=6) transaction:
+// the cipher equality proof of the ciphertexts of the regulator and the
+// exchange and, for shielded redemptions, the membership proof of the pseudo
+// commitment within its ring of the accumulator in the state of the validator.
+func (v *PrivacyValidator) VerifyRedeemProofs(tx *types.Transaction) error {
+	return v.verifyRedeemProofs(tx, nil)
+}
+
+// verifyRedeemProofs verifies the proofs of a redemption, collecting the group
+// equations of its sigma proofs in b unless b is nil.
+func (v *PrivacyValidator) verifyRedeemProofs(tx *types.Transaction, b *ecc.BatchVerifier) (err error) {
+	if err := v.checkRegulatorKey(); err != nil {
+		return err
+	}
+	exchange := v.exchange.PubKey
+	if exchange.G1 == nil || exchange.G2 == nil || exchange.H == nil {
+		return ErrNoExchangeKey
+	}
+	// Redemptions postdate the legacy proofs, which are not bound to the
+	// transaction
+	p := tx.Redeem()
+	if p == nil || !p.Complete() || tx.Version() == types.LegacyPrivacyVersion {
+		return ErrMalformedPrivacyTx
+	}
+	var members, keys [][]byte
+	if p.Shielded() {
+		if v.state == nil {
+			return ErrNoShieldedState
+		}
+		if members, keys, err = v.ring(p.Anchor, p.Ring); err != nil {
+			return err
+		}
+	}
+	defer recoverMalformed(tx, &err)
+
+	var (
+		tr        = p.Transcript(v.chainID)
+		regulator = ecc.PublicKey(v.regulator.PubK)
+	)
+	if p.Shielded() {
+		var mp ecc.MembershipProof
+		if err := mp.UnmarshalBinary(p.MP); err != nil {
+			return proofError(types.MPLabel, false, err, ErrVerifyMembershipProof)
+		}
+		ok, err := b.VerifyMembershipProof(tr.Fork(types.MPLabel), regulator, members, keys, p.Ev.C1, p.Nullifier, mp)
+		if err := proofError(types.MPLabel, ok, err, ErrVerifyMembershipProof); err != nil {
+			return err
+		}
+	}
+	var cep ecc.CipherEqualityProof
+	if err := cep.UnmarshalBinary(p.CEP); err != nil {
+		return proofError(types.CEPLabel, false, err, ErrVerifyRedeemProof)
+	}
+	ok, err := b.VerifyCipherEqualityProof(tr.Fork(types.CEPLabel), regulator, p.Ev.ECC(), ecc.PublicKey(exchange), p.Ex.ECC(), cep)
+	return proofError(types.CEPLabel, ok, err, ErrVerifyRedeemProof)
+}
+
+// ring returns the members of ring number ring of the accumulator when its
+// root was anchor and their spend keys, nil for the positions without a
+// commitment yet.
+func (v *PrivacyValidator) ring(anchor []byte, ring uint64) (members, keys [][]byte, err error) {
+	if len(anchor) != common.HashLength {
+		return nil, nil, ErrUnknownAnchor
+	}
+	size, ok := v.state.AnchorSize(common.BytesToHash(anchor))
+	if !ok {
+		return nil, nil, ErrUnknownAnchor
+	}
+	from, to, ok := ecc.RingBounds(ring, size)
+	if !ok {
+		return nil, nil, ErrUnknownAnchor
+	}
+	members = make([][]byte, ecc.RingSize)
+	keys = make([][]byte, ecc.RingSize)
+	for i := from; i < to; i++ {
+		members[i-from], keys[i-from] = v.state.AccumulatorLeaf(i)
+	}
+	return members, keys, nil
+}
+
+// VerifyTransfer verifies the proofs of a transfer of any type.
+func (v *PrivacyValidator) VerifyTransfer(tx *types.Transaction) error {
+	return v.verifyTransfer(tx, nil)
+}
+
+func (v *PrivacyValidator) verifyTransfer(tx *types.Transaction, b *ecc.BatchVerifier) error {
+	switch tx.ID() {
+	case uint64(types.MultiTransferTxType):
+		return v.verifyMultiTransferProofs(tx, b)
+	case uint64(types.ShieldedTransferTxType):
+		return v.verifyShieldedTransferProofs(tx, b)
+	case uint64(types.RedeemTxType):
+		return v.verifyRedeemProofs(tx, b)
+	}
+	return v.verifyTransferProofs(tx, b)
+}
+
+// VerifyTransfers verifies the proofs of all transfers among txs, e.g. of a
+// block. The format, equality and balance proofs of all transfers are checked
+// together with one multi-scalar multiplication; only if that fails, the
+// transfers are verified one by one to find the offending ones.
+//
+// It returns nil if all transfers are valid, otherwise the error of every
+// transaction, nil for valid transfers and transactions of other types.
+func (v *PrivacyValidator) VerifyTransfers(txs []*types.Transaction) []error {
+	var (
+		errs   = make([]error, len(txs))
+		failed bool
+		batch  = ecc.NewBatchVerifier()
+	)
+	for i, tx := range txs {
+		if tx.IsTransfer() {
+			if errs[i] = v.verifyTransfer(tx, batch); errs[i] != nil {
+				failed = true
+			}
+		}
+	}
+	if batch.Verify() {
+		if failed {
+			return errs
+		}
+		return nil
+	}
+	log.Debug("Batch proof verification failed, verifying one by one", "txs", len(txs))
+	for i, tx := range txs {
+		if tx.IsTransfer() && errs[i] == nil {
+			errs[i] = v.VerifyTransfer(tx)
+		}
+	}
+	return errs
+}
+
+// ValidateBlock checks every transaction of the block: purchases must carry a
+// valid exchange signature over their amount and a fresh CmV, transfers and redemptions must
+// carry valid proofs, spend unspent commitments or reveal fresh nullifiers and
+// create fresh commitments, key rotations must be signed by a quorum of
+// authorities.
+// Commitments and nullifiers are also checked for collisions between the
+// transactions of the block itself.
+//
+// The keys of the exchange and the regulator are the ones in effect at the
+// block, including the ones scheduled by key rotations in the state of the
+// validator, which must be the one of the parent block. From the commitment
+// pool fork on, commitments and nullifiers are verified against the
+// commitment pool of that same state, so the result only depends on the chain
+// the block extends. From the shielded fork on, the commitments created must
+// carry their spend keys and may only be spent by shielded transfers.
+//
+// Chains have to schedule the commitment pool fork, without it blocks creating
+// or spending commitments are rejected. Blocks before the fork are only
+// checked against themselves, as their parent state does not track
+// commitments yet; the fork block rebuilds the pool from their history, see
+// ApplyCMPoolFork.
+func (v *PrivacyValidator) ValidateBlock(block *types.Block) error {
+	v = v.At(block.Number())
+	var (
+		spent     = make(map[common.Hash]struct{})
+		created   = make(map[common.Hash]struct{})
+		scheduled = make(map[uint8]uint64) // Activation block of the last rotation per kind
+		pooled    = v.config.IsCMPool(block.Number())
+		unpooled  = v.config.CMPoolBlock == nil
+		shielded  = v.config.IsShielded(block.Number())
+	)
+	if shielded && v.state == nil {
+		return ErrNoShieldedState
+	}
+	if pooled && v.state == nil {
+		return ErrNoCMPoolState
+	}
+	// fresh checks that a commitment created by the block is not known yet
+	fresh := func(cm *hexutil.Bytes) error {
+		hash := types.NewDefaultCM(cm).Hash()
+		if _, ok := created[hash]; ok {
+			return ErrExistedCM
+		}
+		if pooled && v.state.GetCommitment(hash) != state.CommitmentUnknown {
+			return ErrExistedCM
+		}
+		created[hash] = struct{}{}
+		return nil
+	}
+	// spend checks that a commitment spent in public is unspent, either in
+	// the commitment pool or as an output of an earlier transaction of the
+	// block, unless that output entered the accumulator
+	spend := func(cm *hexutil.Bytes) error {
+		hash := types.NewDefaultCM(cm).Hash()
+		if _, ok := spent[hash]; ok {
+			return ErrDoubleSpentCM
+		}
+		if _, ok := created[hash]; ok {
+			if shielded {
+				return ErrShieldedCM
+			}
+		} else if pooled {
+			switch v.state.GetCommitment(hash) {
+			case state.CommitmentUnspent:
+			case state.CommitmentUnknown:
+				return ErrInvalidCM
+			case state.CommitmentShielded:
+				return ErrShieldedCM
+			default:
+				return ErrDoubleSpentCM
+			}
+		}
+		spent[hash] = struct{}{}
+		return nil
+	}
+	// nullify checks that a nullifier is revealed for the first time
+	nullify := func(nullifier *hexutil.Bytes) error {
+		hash := types.NullifierHash(*nullifier)
+		if _, ok := spent[hash]; ok {
+			return ErrDoubleSpentCM
+		}
+		if pooled && v.state.HasNullifier(hash) {
+			return ErrDoubleSpentCM
+		}
+		spent[hash] = struct{}{}
+		return nil
+	}
+	// The proofs of all transfers in the block are verified in one batch
+	proofErrs := v.VerifyTransfers(block.Transactions())
+	for i, tx := range block.Transactions() {
+		var err error
+		switch tx.ID() {
+		case 1:
+			if unpooled {
+				err = ErrNoCMPoolFork
+				break
+			}
+			if err = v.VerifyPurchaseSign(tx); err == nil {
+				err = v.checkShieldedCmV(tx, shielded)
+			}
+			if err == nil {
+				err = checkSpendKeys(tx, shielded)
+			}
+			if err == nil {
+				err = fresh(tx.CmV())
+			}
+		case 0, 3, uint64(types.ShieldedTransferTxType), uint64(types.RedeemTxType):
+			if unpooled {
+				err = ErrNoCMPoolFork
+				break
+			}
+			// Spending by nullifier is only possible from the shielded fork on
+			if tx.IsShielded() && !shielded {
+				err = ErrShieldedFork
+				break
+			}
+			if proofErrs != nil && proofErrs[i] != nil {
+				err = proofErrs[i]
+				break
+			}
+			if err = checkSpendKeys(tx, shielded); err != nil {
+				break
+			}
+			for _, cm := range tx.SpentCMs() {
+				if err = spend(cm); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+			for _, nullifier := range tx.Nullifiers() {
+				if err = nullify(nullifier); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+			for _, cm := range tx.CreatedCMs() {
+				if err = fresh(cm); err != nil {
+					break
+				}
+			}
+		case uint64(types.KeyRotationTxType):
+			if err = v.VerifyKeyRotation(tx); err != nil {
+				break
+			}
+			// Rotations of the same kind within the block have to be ordered
+			p := tx.KeyRotation()
+			if last, ok := scheduled[p.Kind]; ok && p.Block <= last {
+				err = ErrKeyRotationBlock
+				break
+			}
+			scheduled[p.Kind] = p.Block
+		default:
+			err = ErrIDFormat
+		}
+		if err != nil {
+			return fmt.Errorf("invalid privacy transaction %d [%x]: %v", i, tx.Hash(), err)
+		}
+	}
+	return nil
+}
+
+// checkShieldedCmV returns an error if the CmV of a purchase cannot enter the
+// accumulator after the shielded fork, i.e. is no uncompressed curve point.
+// The commitments created by transfers are decoded by their range proofs.
+func (v *PrivacyValidator) checkShieldedCmV(tx *types.Transaction, shielded bool) error {
+	if !shielded {
+		return nil
+	}
+	if _, err := ecc.DecodePoint(*tx.CmV()); err != nil {
+		return fmt.Errorf("%w: CmV.%v", ErrMalformedPrivacyTx, err)
+	}
+	return nil
+}
+
+// checkSpendKeys returns an error if a transaction creating commitments after
+// the shielded fork does not carry one spend key, a curve point, for each of
+// them. Without it the owner of a commitment could never spend it.
+func checkSpendKeys(tx *types.Transaction, shielded bool) error {
+	if !shielded {
+		return nil
+	}
+	created := tx.CreatedCMs()
+	if tx.ID() == 1 {
+		created = []*hexutil.Bytes{tx.CmV()}
+	}
+	keys := tx.SpendKeys()
+	if len(keys) != len(created) {
+		return ErrMissingSpendKey
+	}
+	for i, key := range keys {
+		if _, err := ecc.DecodePoint(*key); err != nil {
+			return fmt.Errorf("%w: SpendKeys[%d].%v", ErrMalformedPrivacyTx, i, err)
+		}
+	}
+	return nil
+}
+
+// proofError returns the error of a transaction after the proof with the
+// given transcript label was checked: ErrMalformedPrivacyTx naming the proof
+// and its field if the proof could not be decoded, fail if it did not hold and
+// nil otherwise.
+func proofError(label string, ok bool, err error, fail error) error {
+	if err != nil {
+		return fmt.Errorf("%w: %s.%v", ErrMalformedPrivacyTx, label, err)
+	}
+	if !ok {
+		return fail
+	}
+	return nil
+}
+
+// recoverMalformed turns a panic raised while verifying a proof into
+// ErrMalformedPrivacyTx.
+func recoverMalformed(tx *types.Transaction, err *error) {
+	if r := recover(); r != nil {
+		log.Debug("Recovered from malformed privacy transaction", "hash", tx.Hash(), "panic", r)
+		*err = ErrMalformedPrivacyTx
+	}
+}
@@ -0,0 +1,348 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/privtx"
+	"github.com/ethereum/go-ethereum/rlp"
+	"maskchain/privacy/ecc"
+)
+
+// pinnedValidator pins the regulator key in the chain config from the genesis
+// block on and returns the validator of the genesis block.
+func pinnedValidator(config *params.ChainConfig, regulator ecc.PublicKey) *PrivacyValidator {
+	config.RegulatorKeys = []params.PrivacyKey{types.PubKey(regulator).ConfigKey(common.Big0)}
+	return NewPrivacyValidator(config).At(common.Big0)
+}
+
+// testTransfer builds a transfer of spend out of a commitment of 10 under the
+// regulator key, with proofs of the given version bound to chain 1.
+func testTransfer(t *testing.T, regulator ecc.PublicKey, spend uint64, version uint8) *types.Transaction {
+	sender, _, _ := ecc.GenerateKeys("sender")
+	receiver, _, _ := ecc.GenerateKeys("receiver")
+	encode := func(pub ecc.PublicKey) string {
+		return fmt.Sprintf("%064x%x%x%x", pub.P, pub.G1, pub.G2, pub.H)
+	}
+	_, spent, _ := ecc.EncryptValue(regulator, 10)
+	proofs, err := privtx.BuildTransfer(&privtx.Transfer{
+		Sender:    encode(sender),
+		Receiver:  encode(receiver),
+		Regulator: regulator,
+		ChainID:   big.NewInt(1),
+		Spend:     spend,
+		Change:    10 - spend,
+		CmO:       spent.Commitment,
+		VoR:       spent.R,
+		Version:   version,
+	})
+	if err != nil {
+		t.Fatalf("failed to build transfer: %v", err)
+	}
+	to := common.HexToAddress("0x01")
+	return proofs.NewTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil)
+}
+
+// legacyTransfer re-encodes a transfer in the flat pre-envelope layout, with
+// or without the range proof, and decodes it again.
+func legacyTransfer(t *testing.T, tx *types.Transaction, withRP bool) *types.Transaction {
+	fields := []interface{}{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), uint64(0)}
+	payload := tx.Transfer().Fields()
+	for i, field := range payload {
+		if i == 63 {
+			// Purchase fields of the legacy layout
+			for j := 0; j < 9; j++ {
+				fields = append(fields, []byte{})
+			}
+		}
+		if i == len(payload)-1 && !withRP {
+			break
+		}
+		fields = append(fields, *field)
+	}
+	blob, err := rlp.EncodeToBytes(append(fields, big.NewInt(37), big.NewInt(1), big.NewInt(1), []byte{}))
+	if err != nil {
+		t.Fatalf("failed to encode legacy transfer: %v", err)
+	}
+	legacy := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, legacy); err != nil {
+		t.Fatalf("failed to decode legacy transfer: %v", err)
+	}
+	return legacy
+}
+
+// Tests that a chain config pinning the generators only accepts transfers
+// under a regulator key using them.
+func TestPinnedGenerators(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	tx := testTransfer(t, regulator, 7, types.PrivacyVersion)
+
+	config := &params.ChainConfig{ChainID: big.NewInt(1), PrivacyGenerators: params.DefaultPrivacyGenerators}
+	if err := CheckRegulatorKey(types.Regulator{PubK: types.PubKey(regulator)}, config.PrivacyGenerators); err != nil {
+		t.Fatalf("regulator key with the standard generators rejected: %v", err)
+	}
+	// A key of the old kind with a known logarithm of G1
+	regulator.G1 = new(big.Int).Set(regulator.H)
+	if err := pinnedValidator(config, regulator).VerifyTransferProofs(tx); err != ErrRegulatorGenerators {
+		t.Fatalf("transfer under a key with other generators: have %v, want %v", err, ErrRegulatorGenerators)
+	}
+}
+
+// Tests that transfers are verified against the regulator key the chain config
+// pins for their block.
+func TestRegulatorKeyRotation(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	rotated, _, _ := ecc.GenerateKeys("rotated")
+	tx := testTransfer(t, regulator, 7, types.PrivacyVersion)
+
+	config := &params.ChainConfig{ChainID: big.NewInt(1), RegulatorKeys: []params.PrivacyKey{
+		types.PubKey(regulator).ConfigKey(big.NewInt(0)),
+		types.PubKey(rotated).ConfigKey(big.NewInt(5)),
+	}}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("pinned keys rejected: %v", err)
+	}
+	validator := NewPrivacyValidator(config)
+	tests := []struct {
+		validator *PrivacyValidator
+		err       error
+	}{
+		{validator, ErrNoRegulatorKey},
+		{validator.At(big.NewInt(4)), nil},
+		{validator.At(big.NewInt(5)), ErrVerifyRangeProof},
+	}
+	for i, tt := range tests {
+		if err := tt.validator.VerifyTransferProofs(tx); err != tt.err {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that a chain moving to pinned keys verifies the transfers before the
+// pinned keys fork against the legacy key of the node.
+func TestLegacyKeys(t *testing.T) {
+	legacy, _, _ := ecc.GenerateKeys("legacy")
+	pinned, _, _ := ecc.GenerateKeys("pinned")
+	tx := testTransfer(t, legacy, 7, types.PrivacyVersion)
+
+	keys := []params.PrivacyKey{types.PubKey(pinned).ConfigKey(big.NewInt(5))}
+	config := &params.ChainConfig{ChainID: big.NewInt(1), RegulatorKeys: keys, ExchangeKeys: keys, PinnedKeysBlock: big.NewInt(5)}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("pinned keys fork rejected: %v", err)
+	}
+	validator := NewPrivacyValidator(config)
+	fallback := validator.WithLegacyKeys(NewLegacyKeys(types.Regulator{PubK: types.PubKey(legacy)}, types.Exchange{}))
+	tests := []struct {
+		validator *PrivacyValidator
+		err       error
+	}{
+		{validator.At(big.NewInt(4)), ErrNoRegulatorKey},
+		{fallback.At(big.NewInt(4)), nil},
+		{fallback.At(big.NewInt(5)), ErrVerifyRangeProof},
+	}
+	for i, tt := range tests {
+		if err := tt.validator.VerifyTransferProofs(tx); err != tt.err {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Without the fork the legacy key stands in until the config pins one
+	config.PinnedKeysBlock = nil
+	if err := fallback.At(big.NewInt(4)).VerifyTransferProofs(tx); err != nil {
+		t.Errorf("legacy key without pinned keys fork: %v", err)
+	}
+	if key := NewLegacyKeys(types.Regulator{}, types.Exchange{}).KeyAt(config, nil, types.ExchangeKeyKind, big.NewInt(4)); key != nil {
+		t.Errorf("empty legacy key used: %v", key)
+	}
+}
+
+// Tests that key rotations need a quorum of authorities and a future block,
+// and that transfers of old blocks still verify under the retired key.
+func TestKeyRotation(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	rotated, _, _ := ecc.GenerateKeys("rotated")
+
+	var authorities []common.Address
+	var signers []*ecdsa.PrivateKey
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		signers = append(signers, key)
+		authorities = append(authorities, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	outsider, _ := crypto.GenerateKey()
+
+	config := &params.ChainConfig{
+		ChainID:       big.NewInt(1),
+		RegulatorKeys: []params.PrivacyKey{types.PubKey(regulator).ConfigKey(common.Big0)},
+		Governance:    &params.GovernanceConfig{Authorities: authorities, Threshold: 2, Delay: 2},
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	validator := NewPrivacyValidator(config).WithState(statedb)
+
+	key := types.PubKey(rotated).ConfigKey(big.NewInt(10))
+	payload := &types.KeyRotationPayload{Kind: types.RegulatorKeyKind, Block: 10, G1: key.G1, G2: key.G2, H: key.H}
+	rotation := func() *types.Transaction {
+		return types.NewPrivacyTransaction(0, &common.Address{}, new(big.Int), 21000, big.NewInt(1), nil, payload)
+	}
+	// Signatures are added one after the other, repeated and foreign ones do
+	// not count towards the quorum
+	for i, tt := range []struct {
+		signer    *ecdsa.PrivateKey
+		validator *PrivacyValidator
+		err       error
+	}{
+		{signers[0], validator.At(big.NewInt(5)), ErrKeyRotationQuorum},
+		{signers[0], validator.At(big.NewInt(5)), ErrKeyRotationQuorum},
+		{outsider, validator.At(big.NewInt(5)), ErrKeyRotationQuorum},
+		{signers[1], validator.At(big.NewInt(5)), nil},
+		{nil, validator.At(big.NewInt(8)), ErrKeyRotationBlock},
+		{nil, NewPrivacyValidator(&params.ChainConfig{ChainID: big.NewInt(1)}).At(big.NewInt(5)), ErrNoGovernance},
+	} {
+		if tt.signer != nil {
+			if err := payload.Sign(config.ChainID, tt.signer); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tt.validator.VerifyKeyRotation(rotation()); err != tt.err {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Once scheduled, the key takes over at its block but the old one is kept
+	statedb.ScheduleKey(payload.Kind, payload.Key())
+	if err := validator.At(big.NewInt(5)).VerifyKeyRotation(rotation()); err != ErrKeyRotationBlock {
+		t.Errorf("rotation before the last scheduled key: have %v, want %v", err, ErrKeyRotationBlock)
+	}
+	tx := testTransfer(t, regulator, 7, types.PrivacyVersion)
+	for _, tt := range []struct {
+		block int64
+		key   *params.PrivacyKey
+		err   error
+	}{
+		{9, &config.RegulatorKeys[0], nil},
+		{10, &key, ErrVerifyRangeProof},
+	} {
+		if have := PrivacyKeyAt(config, statedb, types.RegulatorKeyKind, big.NewInt(tt.block)); !have.Equal(tt.key) {
+			t.Errorf("block %d: key mismatch: have %+v", tt.block, have)
+		}
+		if err := validator.At(big.NewInt(tt.block)).VerifyTransferProofs(tx); err != tt.err {
+			t.Errorf("block %d: transfer under the retired key: have %v, want %v", tt.block, err, tt.err)
+		}
+	}
+}
+
+// Tests that legacy transfers, with and without the range proof, verify until
+// the range proof and the bound proof fork respectively, and that transcript
+// bound proofs are not accepted in the legacy layout.
+func TestLegacyTransferProofs(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	config := &params.ChainConfig{ChainID: big.NewInt(1), RangeProofBlock: big.NewInt(5), BoundProofBlock: big.NewInt(10)}
+	validator := pinnedValidator(config, regulator)
+
+	legacy, bound := testTransfer(t, regulator, 7, types.LegacyPrivacyVersion), testTransfer(t, regulator, 7, types.PrivacyVersion)
+	tests := []struct {
+		tx     *types.Transaction
+		withRP bool
+		block  int64
+		fail   bool
+		err    error
+	}{
+		{legacy, true, 0, false, nil},
+		{legacy, false, 0, false, nil},
+		// From the range proof fork on the range proof is mandatory
+		{legacy, true, 5, false, nil},
+		{legacy, false, 5, false, ErrMalformedPrivacyTx},
+		// From the bound proof fork on legacy proofs are rejected
+		{legacy, true, 10, false, ErrLegacyProofs},
+		{bound, true, 0, true, nil},
+	}
+	for i, tt := range tests {
+		tx := legacyTransfer(t, tt.tx, tt.withRP)
+		err := validator.At(big.NewInt(tt.block)).VerifyTransferProofs(tx)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: transcript bound proofs accepted in the legacy layout", i)
+			}
+		} else if err != tt.err {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the proofs of a transfer are bound to its chain and commitments,
+// so they can be neither replayed on another chain nor lifted into another
+// transfer.
+func TestTransferBinding(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	tx, other := testTransfer(t, regulator, 7, types.PrivacyVersion), testTransfer(t, regulator, 4, types.PrivacyVersion)
+
+	// The sender proof of the other transfer is valid on its own, but bound to
+	// the commitments of the other transfer
+	lifted := *tx.Transfer()
+	lifted.SpkEP = other.Transfer().SpkEP
+	to := common.HexToAddress("0x01")
+
+	tests := []struct {
+		tx      *types.Transaction
+		chainID int64
+		err     error
+	}{
+		{tx, 1, nil},
+		{tx, 2, ErrVerifyEvSFormatProof},
+		{types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &lifted), 1, ErrVerifySpkEqualityProof},
+	}
+	for i, tt := range tests {
+		validator := pinnedValidator(&params.ChainConfig{ChainID: big.NewInt(tt.chainID)}, regulator)
+		if err := validator.VerifyTransferProofs(tt.tx); err != tt.err {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the transfers of a block are verified in one batch and that an
+// invalid proof hidden in the batch is pinned to its transaction.
+func TestVerifyTransfers(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	to := common.HexToAddress("0x01")
+
+	var txs []*types.Transaction
+	for i := 0; i < 3; i++ {
+		tx := testTransfer(t, regulator, uint64(i+1), types.PrivacyVersion)
+		if i == 1 {
+			// A wrong response passes the challenge check, only the group
+			// equation of the batch catches it
+			payload := *tx.Transfer()
+			s := new(big.Int).SetBytes(payload.RpkEP.S)
+			payload.RpkEP.S = s.Add(s, big.NewInt(1)).Bytes()
+			tx = types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &payload)
+		}
+		txs = append(txs, tx)
+	}
+	validator := pinnedValidator(&params.ChainConfig{ChainID: big.NewInt(1)}, regulator)
+
+	plain := types.NewTransaction(0, to, new(big.Int), 21000, big.NewInt(1), nil)
+	tests := []struct {
+		txs  []*types.Transaction
+		errs []error
+	}{
+		{[]*types.Transaction{txs[0], plain, txs[2]}, nil},
+		{txs, []error{nil, ErrVerifyRpkEqualityProof, nil}},
+	}
+	for i, tt := range tests {
+		errs := validator.VerifyTransfers(tt.txs)
+		if len(errs) != len(tt.errs) {
+			t.Fatalf("test %d: error count mismatch: have %d, want %d", i, len(errs), len(tt.errs))
+		}
+		for j, err := range errs {
+			if err != tt.errs[j] {
+				t.Errorf("test %d, transfer %d: have %v, want %v", i, j, err, tt.errs[j])
+			}
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -629,7 +630,7 @@ func HasCM(db ethdb.Database, hash common.Hash) bool {
 	return true
 }
 
-func WriteCM(db ethdb.Database, hash common.Hash, CM *types.CM) {
+func WriteCM(db ethdb.KeyValueWriter, hash common.Hash, CM *types.CM) {
 	data, err := rlp.EncodeToBytes(CM) // 对CM进行RLP编码
 	if err != nil {
 		log.Crit("Failed to RLP encode CM", "err", err)
@@ -660,37 +661,308 @@ func ReadCM(db ethdb.Database, hash common.Hash) *types.CM {
 	return CM
 }
 
-func DeleteCM(db ethdb.Database, hash common.Hash) {
+func DeleteCM(db ethdb.KeyValueWriter, hash common.Hash) {
 	if err := db.Delete(CMKey(hash)); err != nil {
 		log.Crit("Failed to delete CM", "err", err)
 	}
 }
 
-func WriteAllCM(db ethdb.Database, block *types.Block) {
-	// TODO:张锐改，20201103
+// HasNullifier checks whether a nullifier is in the nullifier set, i.e. the
+// commitment it belongs to is spent. The nullifier of a transparent spend is
+// the hash of the spent CM, the one of a shielded spend types.NullifierHash.
+func HasNullifier(db ethdb.KeyValueReader, hash common.Hash) bool {
+	if has, err := db.Has(CMNullifierKey(hash)); !has || err != nil {
+		return false
+	}
+	return true
+}
+
+// WriteNullifier adds a nullifier to the nullifier set.
+func WriteNullifier(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(CMNullifierKey(hash), []byte{1}); err != nil {
+		log.Crit("Failed to store nullifier", "err", err)
+	}
+}
+
+// DeleteNullifier removes a nullifier from the nullifier set.
+func DeleteNullifier(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(CMNullifierKey(hash)); err != nil {
+		log.Crit("Failed to delete nullifier", "err", err)
+	}
+}
 
+// CMJournalEntry is the value a commitment or a nullifier had before a block
+// modified it.
+type CMJournalEntry struct {
+	Hash      common.Hash
+	Nullifier bool // Entry of the nullifier set rather than a commitment
+	Existed   bool
+	CM        types.CM
+}
+
+// HasCMJournal checks whether the commitments of a block have been applied.
+func HasCMJournal(db ethdb.Reader, hash common.Hash) bool {
+	if has, err := db.Has(CMJournalKey(hash)); !has || err != nil {
+		return false
+	}
+	return true
+}
+
+// ReadCMJournal retrieves the commitment journal of a block.
+func ReadCMJournal(db ethdb.Reader, hash common.Hash) []CMJournalEntry {
+	data, _ := db.Get(CMJournalKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var journal []CMJournalEntry
+	if err := rlp.DecodeBytes(data, &journal); err != nil {
+		log.Error("Invalid CM journal RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return journal
+}
+
+// WriteCMJournal stores the commitment journal of a block.
+func WriteCMJournal(db ethdb.KeyValueWriter, hash common.Hash, journal []CMJournalEntry) {
+	data, err := rlp.EncodeToBytes(journal)
+	if err != nil {
+		log.Crit("Failed to RLP encode CM journal", "err", err)
+	}
+	if err := db.Put(CMJournalKey(hash), data); err != nil {
+		log.Crit("Failed to store CM journal", "err", err)
+	}
+}
+
+// DeleteCMJournal removes the commitment journal of a block.
+func DeleteCMJournal(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(CMJournalKey(hash)); err != nil {
+		log.Crit("Failed to delete CM journal", "err", err)
+	}
+}
+
+// cmEntryKey identifies a commitment or a nullifier entry of CMdb.
+type cmEntryKey struct {
+	hash      common.Hash
+	nullifier bool
+}
+
+// WriteAllCM applies the commitments of a canonical block to CMdb: the CmV of
+// purchases and the outputs of transfers are added, the commitments spent by
+// transparent transfers and the nullifiers revealed by shielded transfers are
+// added to the nullifier set. The previous values of all touched entries are
+// journaled under the block hash, so that RevertAllCM can undo the block when
+// it leaves the canonical chain. Applying a block whose journal already exists
+// is a no-op.
+func WriteAllCM(db ethdb.Database, block *types.Block) {
+	if HasCMJournal(db, block.Hash()) {
+		return
+	}
+	var (
+		batch   = db.NewBatch()
+		journal []CMJournalEntry
+		touched = make(map[cmEntryKey]bool)
+	)
+	// Only the value before the block is needed to revert it
+	touch := func(hash common.Hash, nullifier bool) {
+		key := cmEntryKey{hash, nullifier}
+		if touched[key] {
+			return
+		}
+		entry := CMJournalEntry{Hash: hash, Nullifier: nullifier}
+		if nullifier {
+			entry.Existed = HasNullifier(db, hash)
+		} else if prev := ReadCM(db, hash); prev != nil {
+			entry.Existed, entry.CM = true, *prev
+		}
+		journal = append(journal, entry)
+		touched[key] = true
+	}
+	write := func(CM *types.CM) common.Hash {
+		hash := CM.Hash()
+		touch(hash, false)
+		WriteCM(batch, hash, CM)
+		return hash
+	}
+	nullify := func(hash common.Hash) {
+		touch(hash, true)
+		WriteNullifier(batch, hash)
+	}
 	for _, tx := range block.Transactions() {
 		if tx.ID() == 1 {
 			// 购币交易
 			CmV := types.NewDefaultCM(tx.CmV())
-			hashV := CmV.Hash()
-			WriteCM(db, hashV, CmV)
+			hashV := write(CmV)
 			log.Info("Succeed to store CMV into CMdb", "CMV", CmV, "hash", hashV)
 		}
-		if tx.ID() == 0 {
-			// 转账交易
-			CmO := types.NewCM(tx.CmO(), true)
-			hashO := CmO.Hash()
-			WriteCM(db, hashO, CmO)
-			log.Info("Succeed to store CMO into CMdb", "CMO", CmO, "hash", hashO)
-			CmS := types.NewDefaultCM(tx.CmS())
-			hashS := CmS.Hash()
-			WriteCM(db, hashS, CmS)
-			log.Info("Succeed to store CMS into CMdb", "CMS", CmS, "hash", hashS)
-			CmR := types.NewDefaultCM(tx.CmR())
-			hashR := CmR.Hash()
-			WriteCM(db, hashR, CmR)
-			log.Info("Succeed to store CMR into CMdb", "CMR", CmR, "hash", hashR)
+		if tx.IsTransfer() {
+			// 转账交易，包括多输入多输出转账和隐匿转账
+			for _, cm := range tx.SpentCMs() {
+				CmO := types.NewDefaultCM(cm)
+				hashO := write(CmO)
+				nullify(hashO)
+				log.Info("Succeed to store CMO into CMdb", "CMO", CmO, "hash", hashO)
+			}
+			for _, nullifier := range tx.Nullifiers() {
+				nullify(types.NullifierHash(*nullifier))
+				log.Info("Succeed to store nullifier into CMdb", "nullifier", nullifier)
+			}
+			for _, cm := range tx.CreatedCMs() {
+				CmN := types.NewDefaultCM(cm)
+				hashN := write(CmN)
+				log.Info("Succeed to store new CM into CMdb", "CM", CmN, "hash", hashN)
+			}
+		}
+	}
+	WriteCMJournal(batch, block.Hash(), journal)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to store block commitments", "err", err)
+	}
+}
+
+// RevertAllCM undoes the commitment changes of a block which is removed from
+// the canonical chain, restoring the journaled values. Blocks which were never
+// applied are ignored.
+func RevertAllCM(db ethdb.Database, hash common.Hash) {
+	if !HasCMJournal(db, hash) {
+		return
+	}
+	journal := ReadCMJournal(db, hash)
+	batch := db.NewBatch()
+	for i := len(journal) - 1; i >= 0; i-- {
+		entry := journal[i]
+		switch {
+		case entry.Nullifier && entry.Existed:
+			WriteNullifier(batch, entry.Hash)
+		case entry.Nullifier:
+			DeleteNullifier(batch, entry.Hash)
+		case entry.Existed:
+			WriteCM(batch, entry.Hash, &entry.CM)
+		default:
+			DeleteCM(batch, entry.Hash)
 		}
 	}
+	DeleteCMJournal(batch, hash)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to revert block commitments", "err", err)
+	}
+	log.Info("Reverted block commitments", "hash", hash, "count", len(journal))
+}
+
+// CMdbVersion is the layout version of CMdb written by this node. Version 1
+// moved the spent flag of the CMs into the nullifier set.
+const CMdbVersion = 1
+
+// legacyCM is a CM of CMdb version 0, which still carried the spent flag.
+type legacyCM struct {
+	Cm    *hexutil.Bytes
+	Spent bool
+	Lock  bool
+}
+
+// legacyCMJournalEntry is a journal entry of CMdb version 0.
+type legacyCMJournalEntry struct {
+	Hash    common.Hash
+	Existed bool
+	CM      legacyCM
+}
+
+// ReadCMdbVersion retrieves the layout version of CMdb, 0 if not set.
+func ReadCMdbVersion(db ethdb.KeyValueReader) uint64 {
+	var version uint64
+	enc, _ := db.Get(cmdbVersionKey)
+	if len(enc) == 0 {
+		return 0
+	}
+	if err := rlp.DecodeBytes(enc, &version); err != nil {
+		return 0
+	}
+	return version
+}
+
+// WriteCMdbVersion stores the layout version of CMdb.
+func WriteCMdbVersion(db ethdb.KeyValueWriter, version uint64) {
+	enc, err := rlp.EncodeToBytes(version)
+	if err != nil {
+		log.Crit("Failed to encode CMdb version", "err", err)
+	}
+	if err := db.Put(cmdbVersionKey, enc); err != nil {
+		log.Crit("Failed to store CMdb version", "err", err)
+	}
+}
+
+// MigrateCMdb upgrades CMdb to CMdbVersion. The spent CMs of version 0 are
+// added to the nullifier set and their journal entries split into the entry
+// of the CM and the one of its nullifier, so that blocks applied before the
+// upgrade can still be reverted.
+func MigrateCMdb(db ethdb.Database) error {
+	if ReadCMdbVersion(db) >= CMdbVersion {
+		return nil
+	}
+	var (
+		batch = db.NewBatch()
+		cms   int
+	)
+	flush := func() error {
+		if batch.ValueSize() < ethdb.IdealBatchSize {
+			return nil
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+	it := db.NewIteratorWithPrefix(CMHashPrefix)
+	for it.Next() {
+		if len(it.Key()) != len(CMHashPrefix)+common.HashLength {
+			continue
+		}
+		var CM legacyCM
+		if err := rlp.DecodeBytes(it.Value(), &CM); err != nil {
+			it.Release()
+			return fmt.Errorf("invalid legacy CM %x: %v", it.Key(), err)
+		}
+		hash := common.BytesToHash(it.Key()[len(CMHashPrefix):])
+		WriteCM(batch, hash, &types.CM{Cm: CM.Cm, Lock: CM.Lock})
+		if CM.Spent {
+			WriteNullifier(batch, hash)
+		}
+		cms++
+		if err := flush(); err != nil {
+			it.Release()
+			return err
+		}
+	}
+	it.Release()
+
+	it = db.NewIteratorWithPrefix(CMJournalPrefix)
+	for it.Next() {
+		if len(it.Key()) != len(CMJournalPrefix)+common.HashLength {
+			continue
+		}
+		var legacy []legacyCMJournalEntry
+		if err := rlp.DecodeBytes(it.Value(), &legacy); err != nil {
+			it.Release()
+			return fmt.Errorf("invalid legacy CM journal %x: %v", it.Key(), err)
+		}
+		journal := make([]CMJournalEntry, 0, 2*len(legacy))
+		for _, entry := range legacy {
+			journal = append(journal,
+				CMJournalEntry{Hash: entry.Hash, Existed: entry.Existed, CM: types.CM{Cm: entry.CM.Cm, Lock: entry.CM.Lock}},
+				CMJournalEntry{Hash: entry.Hash, Nullifier: true, Existed: entry.Existed && entry.CM.Spent})
+		}
+		WriteCMJournal(batch, common.BytesToHash(it.Key()[len(CMJournalPrefix):]), journal)
+		if err := flush(); err != nil {
+			it.Release()
+			return err
+		}
+	}
+	it.Release()
+
+	WriteCMdbVersion(batch, CMdbVersion)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Upgraded CMdb", "version", CMdbVersion, "cms", cms)
+	return nil
 }
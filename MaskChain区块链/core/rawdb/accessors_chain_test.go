@@ -26,7 +26,9 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"golang.org/x/crypto/sha3"
@@ -424,3 +426,125 @@ func TestAncientStorage(t *testing.T) {
 		t.Fatalf("invalid td returned")
 	}
 }
+
+// Tests that a CMdb of version 0 moves its spent flags into the nullifier set
+// and that the blocks applied before the upgrade can still be reverted.
+func TestMigrateCMdb(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		spent   = hexutil.Bytes{1}
+		unspent = hexutil.Bytes{2}
+		block   = common.Hash{3}
+	)
+	put := func(key []byte, value interface{}) {
+		enc, err := rlp.EncodeToBytes(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.Put(key, enc)
+	}
+	spentHash, unspentHash := types.NewDefaultCM(&spent).Hash(), types.NewDefaultCM(&unspent).Hash()
+	put(CMKey(spentHash), legacyCM{Cm: &spent, Spent: true})
+	put(CMKey(unspentHash), legacyCM{Cm: &unspent, Lock: true})
+	// The block spent the first commitment and created the second one
+	put(CMJournalKey(block), []legacyCMJournalEntry{
+		{Hash: spentHash, Existed: true, CM: legacyCM{Cm: &spent}},
+		{Hash: unspentHash},
+	})
+
+	if err := MigrateCMdb(db); err != nil {
+		t.Fatalf("failed to migrate CMdb: %v", err)
+	}
+	if version := ReadCMdbVersion(db); version != CMdbVersion {
+		t.Fatalf("CMdb version mismatch: have %d, want %d", version, CMdbVersion)
+	}
+	if !HasNullifier(db, spentHash) || HasNullifier(db, unspentHash) {
+		t.Fatalf("spent flags not moved into the nullifier set")
+	}
+	if cm := ReadCM(db, unspentHash); cm == nil || !cm.Lock || !bytes.Equal(*cm.Cm, unspent) {
+		t.Fatalf("migrated CM mismatch: %v", cm)
+	}
+	RevertAllCM(db, block)
+	if HasNullifier(db, spentHash) || ReadCM(db, spentHash) == nil || HasCM(db, unspentHash) {
+		t.Fatalf("migrated journal not reverted")
+	}
+	if err := MigrateCMdb(db); err != nil {
+		t.Fatalf("failed to migrate an upgraded CMdb: %v", err)
+	}
+}
+
+// dumpDatabase returns the entire content of a database.
+func dumpDatabase(db interface{ NewIterator() ethdb.Iterator }) map[string]string {
+	dump := make(map[string]string)
+	it := db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		dump[string(it.Key())] = string(it.Value())
+	}
+	return dump
+}
+
+// Tests that reverting the commitments of a block restores the exact CMs and
+// nullifier set CMdb had before the block was applied.
+func TestCMJournalRevert(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	cm := func(b byte) *hexutil.Bytes { return &hexutil.Bytes{b} }
+	// A purchase locked by the tx pool, an unspent output and an earlier spend
+	WriteCM(db, types.NewDefaultCM(cm(1)).Hash(), &types.CM{Cm: cm(1), Lock: true})
+	WriteCM(db, types.NewDefaultCM(cm(2)).Hash(), types.NewDefaultCM(cm(2)))
+	WriteCM(db, types.NewDefaultCM(cm(3)).Hash(), types.NewDefaultCM(cm(3)))
+	WriteNullifier(db, types.NewDefaultCM(cm(3)).Hash())
+	before := dumpDatabase(db)
+
+	// The block mines the purchase, spends the output into two new ones of
+	// which the first is spent again, and reveals a shielded nullifier
+	to := common.Address{0x01}
+	txs := []*types.Transaction{
+		types.NewPrivacyTransaction(0, &to, new(big.Int), 0, new(big.Int), nil, &types.PurchasePayload{CmV: *cm(1)}),
+		types.NewPrivacyTransaction(1, &to, new(big.Int), 0, new(big.Int), nil, &types.TransferPayload{CmO: *cm(2), CmS: *cm(4), CmR: *cm(5)}),
+		types.NewPrivacyTransaction(2, &to, new(big.Int), 0, new(big.Int), nil, &types.TransferPayload{CmO: *cm(4), CmS: *cm(6), CmR: *cm(7)}),
+		types.NewPrivacyTransaction(3, &to, new(big.Int), 0, new(big.Int), nil, &types.ShieldedTransferPayload{
+			Inputs:  []types.ShieldedInput{{Nullifier: []byte{8}}},
+			Outputs: []types.TransferOutput{{Cm: *cm(9)}},
+		}),
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, txs, nil, nil)
+
+	WriteAllCM(db, block)
+	if stored := ReadCM(db, types.NewDefaultCM(cm(1)).Hash()); stored == nil || stored.Lock {
+		t.Fatalf("purchase not unlocked: %v", stored)
+	}
+	for _, spent := range []byte{2, 4} {
+		if !HasNullifier(db, types.NewDefaultCM(cm(spent)).Hash()) {
+			t.Errorf("commitment %d not spent", spent)
+		}
+	}
+	for _, created := range []byte{5, 6, 7, 9} {
+		if !HasCM(db, types.NewDefaultCM(cm(created)).Hash()) {
+			t.Errorf("commitment %d not created", created)
+		}
+	}
+	if !HasNullifier(db, types.NullifierHash([]byte{8})) {
+		t.Errorf("shielded nullifier not revealed")
+	}
+	// Applying the block twice must not overwrite its journal
+	WriteAllCM(db, block)
+
+	RevertAllCM(db, block.Hash())
+	after := dumpDatabase(db)
+	if len(after) != len(before) {
+		t.Errorf("entry count mismatch after revert: have %d, want %d", len(after), len(before))
+	}
+	for key, value := range before {
+		if after[key] != value {
+			t.Errorf("entry %x mismatch after revert: have %x, want %x", key, after[key], value)
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			t.Errorf("entry %x kept after revert", key)
+		}
+	}
+}
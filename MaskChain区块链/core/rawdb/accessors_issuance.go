@@ -0,0 +1,65 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Issuance is the amount issued by the purchases of a chain up to and including
+// a block.
+type Issuance struct {
+	Amount    *big.Int
+	Purchases uint64
+}
+
+// ReadIssuance retrieves the issuance of the chain up to the block with the
+// given hash, nil if it has not been computed yet.
+func ReadIssuance(db ethdb.KeyValueReader, hash common.Hash) *Issuance {
+	data, _ := db.Get(issuanceKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	issuance := new(Issuance)
+	if err := rlp.DecodeBytes(data, issuance); err != nil {
+		log.Error("Invalid issuance RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return issuance
+}
+
+// WriteIssuance stores the issuance of the chain up to the block with the given
+// hash. Being keyed by block hash, the entries of blocks that were reorged out
+// are never wrong, only unused.
+func WriteIssuance(db ethdb.KeyValueWriter, hash common.Hash, issuance *Issuance) {
+	data, err := rlp.EncodeToBytes(issuance)
+	if err != nil {
+		log.Crit("Failed to RLP encode issuance", "err", err)
+	}
+	if err := db.Put(issuanceKey(hash), data); err != nil {
+		log.Crit("Failed to store issuance", "err", err)
+	}
+}
+
+// ReadIssuanceProgress retrieves the number of the canonical block the
+// issuance was last indexed up to, nil if it was never indexed.
+func ReadIssuanceProgress(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(issuanceProgressKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteIssuanceProgress stores the number of the canonical block the issuance
+// is indexed up to, so that indexing resumes from it across restarts.
+func WriteIssuanceProgress(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(issuanceProgressKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store issuance progress", "err", err)
+	}
+}
@@ -0,0 +1,39 @@
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that the issuance of a block and the indexing progress can be stored
+// and retrieved.
+func TestIssuanceStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	hash := common.Hash{0x01}
+	if entry := ReadIssuance(db, hash); entry != nil {
+		t.Fatalf("non existent issuance returned: %v", entry)
+	}
+	issuance := &Issuance{Amount: new(big.Int).Lsh(common.Big1, 70), Purchases: 42}
+	WriteIssuance(db, hash, issuance)
+	if entry := ReadIssuance(db, hash); entry == nil {
+		t.Fatalf("stored issuance not found")
+	} else if entry.Amount.Cmp(issuance.Amount) != 0 || entry.Purchases != issuance.Purchases {
+		t.Fatalf("retrieved issuance mismatch: have %v in %d purchases, want %v in %d", entry.Amount, entry.Purchases, issuance.Amount, issuance.Purchases)
+	}
+	if entry := ReadIssuance(db, common.Hash{0x02}); entry != nil {
+		t.Fatalf("issuance of another block returned: %v", entry)
+	}
+
+	if progress := ReadIssuanceProgress(db); progress != nil {
+		t.Fatalf("non existent progress returned: %d", *progress)
+	}
+	for _, number := range []uint64{0, 1024} {
+		WriteIssuanceProgress(db, number)
+		if progress := ReadIssuanceProgress(db); progress == nil || *progress != number {
+			t.Fatalf("retrieved progress mismatch: have %v, want %d", progress, number)
+		}
+	}
+}
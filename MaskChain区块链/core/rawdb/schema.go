@@ -56,6 +56,18 @@ var (
 	// author : zr
 	// CMHashPrefix + hash -> CM
 	CMHashPrefix = []byte("c")
+	// CMJournalPrefix + block hash -> CM values overwritten by the block
+	CMJournalPrefix = []byte("j")
+	// CMNullifierPrefix + nullifier hash -> spent marker
+	CMNullifierPrefix = []byte("n")
+	// cmdbVersionKey tracks the layout version of CMdb
+	cmdbVersionKey = []byte("CMdbVersion")
+
+	// Issuance of the purchases of the chain, see accessors_issuance.go.
+	// issuancePrefix + block hash -> issuance of the chain up to the block
+	issuancePrefix = []byte("issuance-")
+	// issuanceProgressKey tracks the canonical block the issuance is indexed up to
+	issuanceProgressKey = []byte("IssuanceProgress")
 
 	blockBodyPrefix     = []byte("b") // blockBodyPrefix + num (uint64 big endian) + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
@@ -183,3 +195,18 @@ func configKey(hash common.Hash) []byte {
 func CMKey(hash common.Hash) []byte {
 	return append(CMHashPrefix, hash.Bytes()...)
 }
+
+// CMNullifierKey = CMNullifierPrefix + nullifier hash
+func CMNullifierKey(hash common.Hash) []byte {
+	return append(CMNullifierPrefix, hash.Bytes()...)
+}
+
+// CMJournalKey = CMJournalPrefix + block hash
+func CMJournalKey(hash common.Hash) []byte {
+	return append(CMJournalPrefix, hash.Bytes()...)
+}
+
+// issuanceKey = issuancePrefix + block hash
+func issuanceKey(hash common.Hash) []byte {
+	return append(issuancePrefix, hash.Bytes()...)
+}
@@ -0,0 +1,171 @@
+package state
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"maskchain/privacy/ecc"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CommitmentPoolAddress is the system account whose storage holds the
+// commitment pool. Every commitment ever created on chain is a storage slot
+// keyed by the commitment hash, so the storage root of the account commits to
+// the spent/unspent set and membership can be proven with eth_getProof.
+//
+// From the shielded fork on, the account also holds the commitment accumulator
+// (see ecc.Accumulator): its size and branch, its leaves and their spend keys
+// by index, the index of
+// every leaf by commitment hash, the size of the accumulator at each of its
+// past roots (the anchors) and the nullifiers revealed by shielded spends. All
+// of them live under keys derived with accumulatorKey, apart from the
+// commitment hashes.
+var CommitmentPoolAddress = common.HexToAddress("0x000000000000000000000000000000000000c001")
+
+// CommitmentStatus is the value stored for a commitment in the pool.
+type CommitmentStatus byte
+
+const (
+	CommitmentUnknown  CommitmentStatus = iota // Never created on chain
+	CommitmentUnspent                          // Created and spendable
+	CommitmentSpent                            // Consumed by a transfer
+	CommitmentShielded                         // Created after the shielded fork, spendable by nullifier only
+)
+
+// GetCommitment retrieves the status of a commitment from the commitment pool.
+func (s *StateDB) GetCommitment(hash common.Hash) CommitmentStatus {
+	value := s.GetState(CommitmentPoolAddress, hash)
+	return CommitmentStatus(value[common.HashLength-1])
+}
+
+// SetCommitment sets the status of a commitment in the commitment pool.
+func (s *StateDB) SetCommitment(hash common.Hash, status CommitmentStatus) {
+	s.setPool(hash, common.BytesToHash([]byte{byte(status)}))
+}
+
+// CommitmentRoot returns the root of the commitment pool. It is only up to
+// date after the state has been hashed with IntermediateRoot.
+func (s *StateDB) CommitmentRoot() common.Hash {
+	stateObject := s.getStateObject(CommitmentPoolAddress)
+	if stateObject == nil {
+		return emptyRoot
+	}
+	return stateObject.data.Root
+}
+
+// accumulatorKey returns the storage key of an item of the accumulator.
+func accumulatorKey(item string, id []byte) common.Hash {
+	return crypto.Keccak256Hash([]byte("accumulator/"+item), id)
+}
+
+func indexKey(item string, i uint64) common.Hash {
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], i)
+	return accumulatorKey(item, id[:])
+}
+
+// setPool sets a storage slot of the commitment pool.
+func (s *StateDB) setPool(key, value common.Hash) {
+	// A nonce keeps the pool account from being pruned as empty (EIP-161)
+	if s.GetNonce(CommitmentPoolAddress) == 0 {
+		s.SetNonce(CommitmentPoolAddress, 1)
+	}
+	s.SetState(CommitmentPoolAddress, key, value)
+}
+
+func (s *StateDB) accumulatorSize() uint64 {
+	size := s.GetState(CommitmentPoolAddress, accumulatorKey("size", nil))
+	return new(big.Int).SetBytes(size[:]).Uint64()
+}
+
+// Accumulator returns the commitment accumulator.
+func (s *StateDB) Accumulator() *ecc.Accumulator {
+	acc := &ecc.Accumulator{Size: s.accumulatorSize()}
+	for h := range acc.Branch {
+		acc.Branch[h] = s.GetState(CommitmentPoolAddress, indexKey("branch", uint64(h)))
+	}
+	return acc
+}
+
+// AppendCommitments appends the commitments with their spend keys, both in
+// their uncompressed encoding, to the accumulator and records the resulting
+// root as an anchor.
+func (s *StateDB) AppendCommitments(cms, keys [][]byte) error {
+	if len(cms) == 0 {
+		return nil
+	}
+	if len(keys) != len(cms) {
+		return ecc.ErrInvalidPoint
+	}
+	acc := s.Accumulator()
+	for i, cm := range cms {
+		key := keys[i]
+		for _, p := range [][]byte{cm, key} {
+			if len(p) != 2*common.HashLength+1 || p[0] != 4 {
+				return ecc.ErrInvalidPoint
+			}
+		}
+		index := acc.Size
+		if err := acc.Append(cm, key); err != nil {
+			return err
+		}
+		s.setPool(indexKey("leaf/x", index), common.BytesToHash(cm[1:1+common.HashLength]))
+		s.setPool(indexKey("leaf/y", index), common.BytesToHash(cm[1+common.HashLength:]))
+		s.setPool(indexKey("key/x", index), common.BytesToHash(key[1:1+common.HashLength]))
+		s.setPool(indexKey("key/y", index), common.BytesToHash(key[1+common.HashLength:]))
+		s.setPool(accumulatorKey("index", crypto.Keccak256(cm)), common.BigToHash(new(big.Int).SetUint64(index+1)))
+	}
+	s.setPool(accumulatorKey("size", nil), common.BigToHash(new(big.Int).SetUint64(acc.Size)))
+	for h := range acc.Branch {
+		s.setPool(indexKey("branch", uint64(h)), acc.Branch[h])
+	}
+	root := acc.Root()
+	s.setPool(accumulatorKey("anchor", root[:]), common.BigToHash(new(big.Int).SetUint64(acc.Size)))
+	return nil
+}
+
+// AccumulatorLeaf returns the commitment at the given index of the
+// accumulator and its spend key, nil if there is none.
+func (s *StateDB) AccumulatorLeaf(index uint64) (cm, key []byte) {
+	if index >= s.accumulatorSize() {
+		return nil, nil
+	}
+	point := func(item string) []byte {
+		x := s.GetState(CommitmentPoolAddress, indexKey(item+"/x", index))
+		y := s.GetState(CommitmentPoolAddress, indexKey(item+"/y", index))
+		return append(append([]byte{4}, x[:]...), y[:]...)
+	}
+	return point("leaf"), point("key")
+}
+
+// AccumulatorIndex returns the index of a commitment in the accumulator.
+func (s *StateDB) AccumulatorIndex(cm []byte) (uint64, bool) {
+	value := s.GetState(CommitmentPoolAddress, accumulatorKey("index", crypto.Keccak256(cm)))
+	if index := new(big.Int).SetBytes(value[:]).Uint64(); index > 0 {
+		return index - 1, true
+	}
+	return 0, false
+}
+
+// AnchorSize returns the size the accumulator had when its root was anchor,
+// false if the root never was the one of the accumulator.
+func (s *StateDB) AnchorSize(anchor common.Hash) (uint64, bool) {
+	value := s.GetState(CommitmentPoolAddress, accumulatorKey("anchor", anchor[:]))
+	if value == (common.Hash{}) {
+		return 0, false
+	}
+	return new(big.Int).SetBytes(value[:]).Uint64(), true
+}
+
+// HasNullifier reports whether a shielded spend revealed the nullifier with the
+// given hash (see types.NullifierHash).
+func (s *StateDB) HasNullifier(hash common.Hash) bool {
+	return s.GetState(CommitmentPoolAddress, accumulatorKey("nullifier", hash[:])) != (common.Hash{})
+}
+
+// AddNullifier records the nullifier with the given hash as revealed.
+func (s *StateDB) AddNullifier(hash common.Hash) {
+	s.setPool(accumulatorKey("nullifier", hash[:]), common.BytesToHash([]byte{1}))
+}
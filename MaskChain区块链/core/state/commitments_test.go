@@ -0,0 +1,111 @@
+package state
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"testing"
+
+	"maskchain/privacy/ecc"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// Tests that the commitment pool survives EIP-161 empty account pruning and
+// that its root tracks every status change.
+func TestCommitmentPool(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()))
+
+	state.IntermediateRoot(true)
+	if root := state.CommitmentRoot(); root != emptyRoot {
+		t.Fatalf("empty pool root mismatch: have %x, want %x", root, emptyRoot)
+	}
+	cm := common.HexToHash("0x01")
+	if status := state.GetCommitment(cm); status != CommitmentUnknown {
+		t.Fatalf("unknown commitment status mismatch: have %d, want %d", status, CommitmentUnknown)
+	}
+	state.SetCommitment(cm, CommitmentUnspent)
+	state.IntermediateRoot(true)
+	unspent := state.CommitmentRoot()
+	if unspent == emptyRoot {
+		t.Fatalf("pool root not updated after creating a commitment")
+	}
+	if status := state.GetCommitment(cm); status != CommitmentUnspent {
+		t.Fatalf("created commitment status mismatch: have %d, want %d", status, CommitmentUnspent)
+	}
+	state.SetCommitment(cm, CommitmentSpent)
+	state.IntermediateRoot(true)
+	if root := state.CommitmentRoot(); root == unspent {
+		t.Fatalf("pool root not updated after spending a commitment")
+	}
+	if status := state.GetCommitment(cm); status != CommitmentSpent {
+		t.Fatalf("spent commitment status mismatch: have %d, want %d", status, CommitmentSpent)
+	}
+}
+
+// Tests that the accumulator kept in the commitment pool matches the one of
+// the ECC package and remembers its leaves, their spend keys and indices and
+// its anchors.
+func TestCommitmentAccumulator(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()))
+
+	var (
+		acc  ecc.Accumulator
+		cms  [][]byte
+		keys [][]byte
+	)
+	for i := 0; i < 3; i++ {
+		p := ecc.HashToCurve("accumulator test", []byte{byte(i)})
+		k := ecc.HashToCurve("accumulator test key", []byte{byte(i)})
+		cms = append(cms, elliptic.Marshal(ecc.EC.C, p.X, p.Y))
+		keys = append(keys, elliptic.Marshal(ecc.EC.C, k.X, k.Y))
+	}
+	if err := state.AppendCommitments(cms[:2], keys[:2]); err != nil {
+		t.Fatal(err)
+	}
+	acc.Append(cms[0], keys[0])
+	acc.Append(cms[1], keys[1])
+	first := acc.Root()
+	if err := state.AppendCommitments(cms[2:], keys[2:]); err != nil {
+		t.Fatal(err)
+	}
+	acc.Append(cms[2], keys[2])
+	if have := state.Accumulator(); *have != acc {
+		t.Fatalf("accumulator mismatch: have size %d root %x, want size %d root %x", have.Size, have.Root(), acc.Size, acc.Root())
+	}
+	for i, cm := range cms {
+		if leaf, key := state.AccumulatorLeaf(uint64(i)); !bytes.Equal(leaf, cm) || !bytes.Equal(key, keys[i]) {
+			t.Errorf("leaf %d mismatch: have %x, %x, want %x, %x", i, leaf, key, cm, keys[i])
+		}
+		if index, ok := state.AccumulatorIndex(cm); !ok || index != uint64(i) {
+			t.Errorf("index of leaf %d mismatch: have %d, %v", i, index, ok)
+		}
+	}
+	if leaf, _ := state.AccumulatorLeaf(3); leaf != nil {
+		t.Errorf("leaf beyond the size: %x", leaf)
+	}
+	if size, ok := state.AnchorSize(first); !ok || size != 2 {
+		t.Errorf("anchor size mismatch: have %d, %v, want 2", size, ok)
+	}
+	if size, ok := state.AnchorSize(acc.Root()); !ok || size != 3 {
+		t.Errorf("anchor size mismatch: have %d, %v, want 3", size, ok)
+	}
+	if _, ok := state.AnchorSize(common.Hash{1}); ok {
+		t.Errorf("unknown anchor accepted")
+	}
+	if err := state.AppendCommitments([][]byte{cms[0][:33]}, keys[:1]); err == nil {
+		t.Errorf("compressed commitment appended")
+	}
+	if err := state.AppendCommitments(cms[:1], nil); err == nil {
+		t.Errorf("commitment without spend key appended")
+	}
+
+	nullifier := common.HexToHash("0x02")
+	if state.HasNullifier(nullifier) {
+		t.Fatalf("unknown nullifier reported")
+	}
+	state.AddNullifier(nullifier)
+	if !state.HasNullifier(nullifier) {
+		t.Fatalf("nullifier not recorded")
+	}
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// GovernanceAddress is the system account whose storage holds the keys of the
+// regulator and the exchange scheduled by key rotation transactions, for each
+// kind of key (see types.RegulatorKeyKind) the number of scheduled keys and
+// every key by its index. Keys are never removed, so the keys purchases and
+// transfers of old blocks were made under can still be looked up.
+var GovernanceAddress = common.HexToAddress("0x000000000000000000000000000000000000c002")
+
+// governanceKey returns the storage key of an item of the i-th scheduled key
+// of the given kind.
+func governanceKey(item string, kind uint8, i uint64) common.Hash {
+	var id [9]byte
+	id[0] = kind
+	binary.BigEndian.PutUint64(id[1:], i)
+	return crypto.Keccak256Hash([]byte("governance/"+item), id[:])
+}
+
+func (s *StateDB) setGovernance(key, value common.Hash) {
+	// A nonce keeps the account from being pruned as empty (EIP-161)
+	if s.GetNonce(GovernanceAddress) == 0 {
+		s.SetNonce(GovernanceAddress, 1)
+	}
+	s.SetState(GovernanceAddress, key, value)
+}
+
+func (s *StateDB) scheduledCount(kind uint8) uint64 {
+	count := s.GetState(GovernanceAddress, governanceKey("count", kind, 0))
+	return new(big.Int).SetBytes(count[:]).Uint64()
+}
+
+// ScheduledKeys returns the keys of the given kind scheduled by key rotations,
+// in the order of their activation blocks.
+func (s *StateDB) ScheduledKeys(kind uint8) []params.PrivacyKey {
+	count := s.scheduledCount(kind)
+	if count == 0 {
+		return nil
+	}
+	point := func(item string, i uint64) []byte {
+		x := s.GetState(GovernanceAddress, governanceKey(item+"/x", kind, i))
+		y := s.GetState(GovernanceAddress, governanceKey(item+"/y", kind, i))
+		return append(append([]byte{4}, x[:]...), y[:]...)
+	}
+	keys := make([]params.PrivacyKey, count)
+	for i := range keys {
+		block := s.GetState(GovernanceAddress, governanceKey("block", kind, uint64(i)))
+		keys[i] = params.PrivacyKey{
+			Block: new(big.Int).SetBytes(block[:]),
+			G1:    point("g1", uint64(i)),
+			G2:    point("g2", uint64(i)),
+			H:     point("h", uint64(i)),
+		}
+	}
+	return keys
+}
+
+// ScheduleKey appends a key of the given kind. The caller ensures that its
+// points are uncompressed and that it is activated after all keys scheduled
+// before.
+func (s *StateDB) ScheduleKey(kind uint8, key params.PrivacyKey) {
+	i := s.scheduledCount(kind)
+	point := func(item string, p []byte) {
+		s.setGovernance(governanceKey(item+"/x", kind, i), common.BytesToHash(p[1:1+common.HashLength]))
+		s.setGovernance(governanceKey(item+"/y", kind, i), common.BytesToHash(p[1+common.HashLength:]))
+	}
+	s.setGovernance(governanceKey("block", kind, i), common.BigToHash(key.Block))
+	point("g1", key.G1)
+	point("g2", key.G2)
+	point("h", key.H)
+	s.setGovernance(governanceKey("count", kind, 0), common.BigToHash(new(big.Int).SetUint64(i+1)))
+}
@@ -0,0 +1,55 @@
+package state
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"maskchain/privacy/ecc"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that scheduled keys are kept per kind, in order, across commits and
+// that the governance account survives EIP-161 empty account pruning.
+func TestScheduledKeys(t *testing.T) {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	state, _ := New(common.Hash{}, db)
+
+	key := func(block int64, seed byte) params.PrivacyKey {
+		point := func(i byte) []byte {
+			p := ecc.HashToCurve("governance test", []byte{seed, i})
+			return elliptic.Marshal(ecc.EC.C, p.X, p.Y)
+		}
+		return params.PrivacyKey{Block: big.NewInt(block), G1: point(1), G2: point(2), H: point(3)}
+	}
+	if keys := state.ScheduledKeys(0); len(keys) != 0 {
+		t.Fatalf("empty state has %d scheduled keys", len(keys))
+	}
+	want := []params.PrivacyKey{key(10, 1), key(20, 2)}
+	for _, k := range want {
+		state.ScheduleKey(0, k)
+	}
+	state.ScheduleKey(1, key(15, 3))
+
+	root, err := state.Commit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ = New(root, db)
+	have := state.ScheduledKeys(0)
+	if len(have) != len(want) {
+		t.Fatalf("scheduled key count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if !have[i].Equal(&want[i]) {
+			t.Errorf("scheduled key %d mismatch: have %+v, want %+v", i, have[i], want[i])
+		}
+	}
+	if have := state.ScheduledKeys(1); len(have) != 1 || !bytes.Equal(have[0].H, key(15, 3).H) {
+		t.Errorf("keys of another kind mixed up: %+v", have)
+	}
+}
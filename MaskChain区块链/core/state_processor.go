@@ -17,13 +17,18 @@
 package core
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -65,6 +70,9 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	if err := ApplyCMPoolFork(p.config, p.bc, statedb, header); err != nil {
+		return nil, nil, 0, err
+	}
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
 		statedb.Prepare(tx.Hash(), block.Hash(), i)
@@ -101,6 +109,13 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	if err != nil {
 		return nil, err
 	}
+	if err := applyCommitments(config, header.Number, statedb, tx); err != nil {
+		return nil, err
+	}
+	if err := applyKeyRotation(config, header.Number, statedb, tx); err != nil {
+		return nil, err
+	}
+
 	// Update the state with pending changes
 	var root []byte
 	if config.IsByzantium(header.Number) {
@@ -128,3 +143,144 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 
 	return receipt, err
 }
+
+// applyCommitments records the commitments created and spent by a privacy
+// transaction in the commitment pool of the state, from the commitment pool
+// fork on. A transaction spending a commitment which is not unspent in the
+// pool, or creating one which is already known, is invalid. From the shielded
+// fork on, created commitments are appended to the accumulator along with
+// their spend keys and can only be spent by shielded transfers and
+// redemptions, whose nullifiers are recorded instead. A nullifier revealed
+// before, or a created commitment without spend key, invalidates the
+// transaction.
+func applyCommitments(config *params.ChainConfig, num *big.Int, statedb *state.StateDB, tx *types.Transaction) error {
+	if !config.IsCMPool(num) {
+		return nil
+	}
+	var created []*hexutil.Bytes
+	switch tx.ID() {
+	case 1:
+		created = []*hexutil.Bytes{tx.CmV()}
+	case 0, 3, uint64(types.ShieldedTransferTxType), uint64(types.RedeemTxType):
+		for _, cm := range tx.SpentCMs() {
+			hash := types.NewDefaultCM(cm).Hash()
+			switch statedb.GetCommitment(hash) {
+			case state.CommitmentUnspent:
+			case state.CommitmentUnknown:
+				return ErrInvalidCM
+			case state.CommitmentShielded:
+				return ErrShieldedCM
+			default:
+				return ErrDoubleSpentCM
+			}
+			statedb.SetCommitment(hash, state.CommitmentSpent)
+		}
+		for _, nullifier := range tx.Nullifiers() {
+			hash := types.NullifierHash(*nullifier)
+			if statedb.HasNullifier(hash) {
+				return ErrDoubleSpentCM
+			}
+			statedb.AddNullifier(hash)
+		}
+		created = tx.CreatedCMs()
+	}
+	status := state.CommitmentUnspent
+	if config.IsShielded(num) {
+		status = state.CommitmentShielded
+	}
+	cms := make([][]byte, len(created))
+	for i, cm := range created {
+		hash := types.NewDefaultCM(cm).Hash()
+		if statedb.GetCommitment(hash) != state.CommitmentUnknown {
+			return ErrExistedCM
+		}
+		statedb.SetCommitment(hash, status)
+		cms[i] = *cm
+	}
+	if status != state.CommitmentShielded {
+		return nil
+	}
+	spendKeys := tx.SpendKeys()
+	if len(spendKeys) != len(cms) {
+		return ErrMissingSpendKey
+	}
+	keys := make([][]byte, len(spendKeys))
+	for i, key := range spendKeys {
+		keys[i] = *key
+	}
+	return statedb.AppendCommitments(cms, keys)
+}
+
+// ApplyCMPoolFork seeds the commitment pool of the state of the commitment
+// pool fork block with the commitments of all blocks before it, i.e. of the
+// chain ending at the parent of header. Before the fork the commitments were
+// only checked by the transaction pools of the miners, so the history is taken
+// as it was mined: every commitment created is unspent unless a later block
+// spent it. It does nothing for other blocks and for a fork at genesis.
+func ApplyCMPoolFork(config *params.ChainConfig, chain blockReader, statedb *state.StateDB, header *types.Header) error {
+	if fork := config.CMPoolBlock; fork == nil || fork.Sign() == 0 || fork.Cmp(header.Number) != 0 {
+		return nil
+	}
+	var (
+		spent   = make(map[common.Hash]bool)
+		created []common.Hash
+	)
+	// Walk back from the parent, a commitment is spent if spent by any block
+	// after the one creating it
+	for hash, number := header.ParentHash, header.Number.Uint64()-1; ; number-- {
+		block := chain.GetBlock(hash, number)
+		if block == nil {
+			return fmt.Errorf("commitment pool fork: missing block %d [%x]", number, hash)
+		}
+		for _, tx := range block.Transactions() {
+			switch tx.ID() {
+			case 1:
+				created = append(created, types.NewDefaultCM(tx.CmV()).Hash())
+			case 0, 3, uint64(types.RedeemTxType):
+				for _, cm := range tx.SpentCMs() {
+					spent[types.NewDefaultCM(cm).Hash()] = true
+				}
+				for _, cm := range tx.CreatedCMs() {
+					created = append(created, types.NewDefaultCM(cm).Hash())
+				}
+			}
+		}
+		if number == 0 {
+			break
+		}
+		hash = block.ParentHash()
+	}
+	for _, hash := range created {
+		if spent[hash] {
+			statedb.SetCommitment(hash, state.CommitmentSpent)
+		} else {
+			statedb.SetCommitment(hash, state.CommitmentUnspent)
+		}
+	}
+	log.Info("Seeded commitment pool", "block", header.Number, "commitments", len(created), "spent", len(spent))
+	return nil
+}
+
+// blockReader reads the blocks of a chain, it is implemented by *BlockChain.
+type blockReader interface {
+	GetBlock(hash common.Hash, number uint64) *types.Block
+}
+
+// applyKeyRotation schedules the key of a key rotation transaction in the
+// governance account of the state. The activation block is checked again
+// against the state the transaction is applied to, so that the miner drops
+// rotations that became stale while waiting in the pool.
+func applyKeyRotation(config *params.ChainConfig, num *big.Int, statedb *state.StateDB, tx *types.Transaction) error {
+	p := tx.KeyRotation()
+	if p == nil {
+		return nil
+	}
+	if config.Governance == nil {
+		return ErrNoGovernance
+	}
+	if err := checkRotationBlock(config, statedb, num, p); err != nil {
+		return err
+	}
+	statedb.ScheduleKey(p.Kind, p.Key())
+	return nil
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that blocks spending a commitment which is not unspent in the
+// commitment pool of their parent state are rejected by the state processor.
+func TestProcessCommitmentSpends(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{addr: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	purchase := func(nonce uint64, cm []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewPrivacyTransaction(nonce, &addr, new(big.Int), params.TxGas, nil, nil, &types.PurchasePayload{CmV: cm}), signer, key)
+		return tx
+	}
+	transfer := func(nonce uint64, spent, payment, change []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewPrivacyTransaction(nonce, &addr, new(big.Int), params.TxGas, nil, nil, &types.TransferPayload{CmO: spent, CmS: payment, CmR: change}), signer, key)
+		return tx
+	}
+	// Purchase a commitment in block 1 and spend it in block 2
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
+		switch i {
+		case 0:
+			gen.AddTx(purchase(gen.TxNonce(addr), []byte{0x01}))
+		case 1:
+			gen.AddTx(transfer(gen.TxNonce(addr), []byte{0x01}, []byte{0x02}, []byte{0x03}))
+		}
+	})
+	chain, err := NewBlockChain(db, nil, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to get head state: %v", err)
+	}
+	for cm, want := range map[byte]state.CommitmentStatus{0x01: state.CommitmentSpent, 0x02: state.CommitmentUnspent, 0x03: state.CommitmentUnspent} {
+		if have := statedb.GetCommitment(types.NewDefaultCM(&hexutil.Bytes{cm}).Hash()); have != want {
+			t.Errorf("commitment %#x: status mismatch: have %v, want %v", cm, have, want)
+		}
+	}
+	// Blocks spending a missing and an already spent commitment must fail
+	tests := []struct {
+		spent []byte
+		err   error
+	}{
+		{[]byte{0x04}, ErrInvalidCM},
+		{[]byte{0x01}, ErrDoubleSpentCM},
+	}
+	parent := chain.CurrentBlock()
+	for i, tt := range tests {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			GasLimit:   parent.GasLimit(),
+			Difficulty: parent.Difficulty(),
+			Time:       parent.Time() + 10,
+		}
+		tx := transfer(parent.Transactions()[0].Nonce()+1, tt.spent, []byte{byte(0x10 + i)}, []byte{byte(0x20 + i)})
+		block := types.NewBlock(header, types.Transactions{tx}, nil, nil)
+
+		statedb, _ := chain.State()
+		if _, _, _, err := chain.Processor().Process(block, statedb, vm.Config{}); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
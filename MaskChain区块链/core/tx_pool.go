@@ -30,7 +30,6 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	ecc "github.com/ethereum/go-ethereum/crypto/ECC"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -100,6 +99,12 @@ var (
 	// cannot be verified using the given signature
 	ErrVerifySig = errors.New("verify purchase signature failed")
 
+	// ErrPurchaseAmount is returned if the exchange did not sign the purchase
+	// ID followed by the issued amount, so that the purchase could not be
+	// accounted for in the total issuance. Blocks accept such purchases until
+	// the issuance fork, the pool never does.
+	ErrPurchaseAmount = errors.New("purchase signature does not hold the issued amount")
+
 	ErrVerifyEvSFormatProof = errors.New("verify EvS FormatProof failed")
 
 	ErrVerifyEvRFormatProof = errors.New("verify EvR FormatProof failed")
@@ -112,7 +117,23 @@ var (
 
 	ErrVerifyRpkEqualityProof = errors.New("verify Rpk equality proof failed")
 
-	ErrIDFormat = errors.New("ID is not 1 or 0, or ID format is wrong")
+	ErrVerifyRangeProof = errors.New("verify CmS/CmR range proof failed")
+
+	ErrVerifyOutputFormatProof = errors.New("verify output FormatProof failed")
+
+	ErrVerifyPseudoFormatProof = errors.New("verify pseudo commitment FormatProof failed")
+
+	ErrVerifyMembershipProof = errors.New("verify membership proof failed")
+
+	ErrVerifyRedeemProof = errors.New("verify redeem cipher equality proof failed")
+
+	// ErrLegacyProofs is returned if a transfer carries proofs of the legacy
+	// privacy version, which are not bound to the transaction and could have
+	// been lifted from another one. Blocks accept them until the bound proof
+	// fork, the pool never does.
+	ErrLegacyProofs = errors.New("transfer proofs of legacy version")
+
+	ErrIDFormat = errors.New("ID is not 0, 1, 3, 4, 5 or 6, or ID format is wrong")
 
 	// err信息
 	ErrExistedCM = errors.New("existed commitment to purchase coins")
@@ -183,13 +204,14 @@ type TxPoolConfig struct {
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
-	AccountSlots uint64          // Number of executable transaction slots guaranteed per account
-	GlobalSlots  uint64          // Maximum number of executable transaction slots for all accounts
-	AccountQueue uint64          // Maximum number of non-executable transaction slots permitted per account
-	GlobalQueue  uint64          // Maximum number of non-executable transaction slots for all accounts
-	Exchange     types.Exchange  // @xzliu exchange
-	Regulator    types.Regulator //regulator
-	Lifetime     time.Duration   // Maximum amount of time non-executable transaction are queued
+	AccountSlots uint64 // Number of executable transaction slots guaranteed per account
+	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
+	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
+	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
+
+	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	LegacyKeys *LegacyKeys `toml:"-"` // Keys standing in for unpinned ones before the pinned keys fork, nil if none
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -264,8 +286,7 @@ type TxPool struct {
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
-	Exchange    types.Exchange
-	Regulator   types.Regulator
+	privacy     *PrivacyValidator // Verifier of purchase signatures and transfer proofs
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 
@@ -319,6 +340,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		reorgDoneCh:     make(chan chan struct{}),
 		reorgShutdownCh: make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
+		privacy:         NewPrivacyValidator(chainconfig).WithLegacyKeys(config.LegacyKeys),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
@@ -606,95 +628,127 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
-// @mzliu 11/14 verify that thing, you know
-func (pool *TxPool) validateSign(tx *types.Transaction) error {
-	sig := ecc.Signature{}
-	sig.M = tx.SigM().Btob()
-	sig.M_hash = tx.SigMHash().Btob()
-	sig.R = tx.SigR().Btob()
-	sig.S = tx.SigS().Btob()
-	v := ecc.Verify(ecc.PublicKey(pool.config.Exchange.PubKey), sig)
-	if v == false {
-		return ErrVerifySig
-	} else {
-		log.Info("Succeed to verify purchase sig ", "fullhash", tx.Hash().Hex())
-		return nil
+// pendingPrivacy returns the privacy validator for the pending block, i.e. with
+// the keys in effect for the block after the current head. statedb has to be
+// the state of the current head, it may be nil if neither shielded transfers
+// nor key rotations are verified.
+func (pool *TxPool) pendingPrivacy(statedb *state.StateDB) *PrivacyValidator {
+	privacy := pool.privacy
+	if statedb != nil {
+		privacy = privacy.WithState(statedb)
 	}
+	return privacy.At(new(big.Int).Add(pool.chain.CurrentBlock().Number(), big.NewInt(1)))
 }
 
-func (pool *TxPool) verifyzkp(tx *types.Transaction) error {
-	verify1 := ecc.VerifyFormatProof(tx.EVS(), tx.CMsFP())
-	if verify1 == false {
-		return ErrVerifyEvSFormatProof
-	}
-	//log.Trace("txhash:",tx.Hash(),"花费额承诺，格式正确证明:", erify1)
-	verify2 := ecc.VerifyFormatProof(tx.EVR(), tx.CMrFP())
-	if verify2 == false {
-		return ErrVerifyEvRFormatProof
+// @mzliu 11/14 verify that thing, you know
+func (pool *TxPool) validateSign(tx *types.Transaction) error {
+	if err := pool.pendingPrivacy(pool.currentState).VerifyPurchaseSign(tx); err != nil {
+		return err
 	}
-	//fmt.Println("txhash:",tx.Hash(),"找零承诺，格式正确证明:", verify2)
-	verify3 := ecc.VerifyBalanceProof(tx.CmR().Btob(), tx.CmS().Btob(), tx.CmO().Btob(), tx.BP())
-	//fmt.Println("txhash:",tx.Hash(),"会计平衡证明txpool:", verify3)
-	if verify3 == false {
-		return ErrVerifyBalanceProof
+	// 交易池只接受金额可计入发行总额的购币交易，发行分叉之前也是如此
+	if _, ok := tx.Purchase().Amount(); !ok {
+		return ErrPurchaseAmount
 	}
-	verify4 := ecc.VerifyEqualityProof(tx.EvoEP())
-	//fmt.Println("txpool ",zkp.PublicKey(pool.config.Regulator.PubK), zkp.PublicKey(pool.config.Regulator.PubK), tx.EVO(), zkp.CypherText{C1: nil, C2: tx.CmO().Btob()},tx.EvoEP())
-	//fmt.Println("txhash:",tx.Hash(),"总额度相等证明:", verify4)
-	if verify4 == false {
-		return ErrVerifyTotalEqualityProof
+	log.Info("Succeed to verify purchase sig ", "fullhash", tx.Hash().Hex())
+	return nil
+}
+
+// validateRotation verifies the authority signatures and the activation block
+// of a key rotation against the state of the current head.
+func (pool *TxPool) validateRotation(tx *types.Transaction) error {
+	if err := pool.pendingPrivacy(pool.currentState).VerifyKeyRotation(tx); err != nil {
+		return err
 	}
-	verify5 := ecc.VerifyEqualityProof(tx.ErpkEP())
-	//fmt.Println("txhash:",tx.Hash(),"接收方公钥相等证明:", verify5)
-	if verify5 == false {
-		return ErrVerifyRpkEqualityProof
+	log.Info("Succeed to verify key rotation", "fullhash", tx.Hash().Hex())
+	return nil
+}
+
+// verifyzkps verifies the proofs of all transfers among txs in one batch. It
+// returns nil if all passed, otherwise the error of every transaction.
+// Shielded transfers and rotated keys are verified against the state of the
+// current head, which is opened here as the pool lock is not held.
+func (pool *TxPool) verifyzkps(txs []*types.Transaction) []error {
+	var statedb *state.StateDB
+	if pool.chainconfig.Governance != nil || pool.chainconfig.IsShielded(new(big.Int).Add(pool.chain.CurrentBlock().Number(), big.NewInt(1))) {
+		statedb, _ = pool.chain.StateAt(pool.chain.CurrentBlock().Root())
 	}
-	verify6 := ecc.VerifyEqualityProof(tx.EspkEP())
-	//fmt.Println("txhash:",tx.Hash(),"发送方公钥相等证明:", verify6)
-	if verify6 == false {
-		return ErrVerifySpkEqualityProof
+	errs := pool.pendingPrivacy(statedb).VerifyTransfers(txs)
+	for i, tx := range txs {
+		if !tx.IsTransfer() {
+			continue
+		}
+		if tx.Version() == types.LegacyPrivacyVersion {
+			if errs == nil {
+				errs = make([]error, len(txs))
+			}
+			errs[i] = ErrLegacyProofs
+		}
+		if errs == nil || errs[i] == nil {
+			log.Info("All zero knowledge proofs passed", "fullhash", tx.Hash().Hex())
+		}
 	}
-	log.Info("All zero knowledge proofs passed", "fullhash", tx.Hash().Hex())
-	return nil
+	return errs
 }
 
 // validateCM 验证CM的有效性
 func (pool *TxPool) validateCM(tx *types.Transaction) error {
-	// 三种情况报错：
+	// 四种情况报错：
 	// 1、购币交易的购币承诺已存在于CMdb中
-	// 2、转账交易的CmO 不存在 或 存在但已使用
-	// 3、交易ID既不为0也不为1,暂未知类型交易
+	// 2、转账交易的被花费承诺不存在 或 存在但已使用，或新承诺已存在
+	// 3、隐匿转账的零化符已存在，或隐匿分叉后公开花费累加器中的承诺，或新承诺缺少花费公钥
+	// 4、交易ID不为0、1、3、4、5、6,暂未知类型交易
+	// 密钥轮换交易不涉及承诺，由 validateRotation 验证
 
+	if tx.ID() == uint64(types.KeyRotationTxType) {
+		return nil
+	}
+	// 未配置承诺池分叉的链无法在区块中校验承诺，不接受涉及承诺的交易
+	if pool.chainconfig.CMPoolBlock == nil {
+		return ErrNoCMPoolFork
+	}
 	CMdb := pool.chain.GetCMdb()
+	shielded := pool.chainconfig.IsShielded(new(big.Int).Add(pool.chain.CurrentBlock().Number(), big.NewInt(1)))
 	if tx.ID() == 1 {
 		// 购币交易
 		CmV := types.NewDefaultCM(tx.CmV())
 		hash := CmV.Hash()
 		if rawdb.HasCM(CMdb, hash) {
 			return ErrExistedCM
-		} else {
-			return nil
 		}
+		if err := pool.privacy.checkShieldedCmV(tx, shielded); err != nil {
+			return err
+		}
+		return checkSpendKeys(tx, shielded)
+	}
+	if tx.IsShielded() && !shielded {
+		return ErrShieldedFork
 	}
-	if tx.ID() == 0 {
+	if err := checkSpendKeys(tx, shielded); err != nil {
+		return err
+	}
+	if tx.IsTransfer() {
 		// used to debug
 		//return nil
-		// 转账交易
-		CmO := types.NewDefaultCM(tx.CmO())
-		hash := CmO.Hash()
-		CmO_ := rawdb.ReadCM(CMdb, hash)
-		if CmO_ == nil || CmO_.Spent == true || CmO_.Lock == false {
-			return ErrInvalidCM
+		// 转账交易，所有被花费承诺须已上链且未花费，所有新承诺须不存在
+		for _, cm := range tx.SpentCMs() {
+			CmO := types.NewDefaultCM(cm)
+			CmO_ := rawdb.ReadCM(CMdb, CmO.Hash())
+			if CmO_ == nil || rawdb.HasNullifier(CMdb, CmO.Hash()) || CmO_.Lock == false {
+				return ErrInvalidCM
+			}
+			if shielded && pool.currentState.GetCommitment(CmO.Hash()) == state.CommitmentShielded {
+				return ErrShieldedCM
+			}
 		}
-		CmS := types.NewDefaultCM(tx.CmS())
-		hash = CmS.Hash()
-		if rawdb.HasCM(CMdb, hash) {
-			return ErrExistedCM
+		for _, nullifier := range tx.Nullifiers() {
+			if rawdb.HasNullifier(CMdb, types.NullifierHash(*nullifier)) {
+				return ErrDoubleSpentCM
+			}
 		}
-		CmR := types.NewDefaultCM(tx.CmR())
-		hash = CmR.Hash()
-		if rawdb.HasCM(CMdb, hash) {
-			return ErrExistedCM
+		for _, cm := range tx.CreatedCMs() {
+			if rawdb.HasCM(CMdb, types.NewDefaultCM(cm).Hash()) {
+				return ErrExistedCM
+			}
 		}
 		return nil
 	}
@@ -712,14 +766,16 @@ func (pool *TxPool) processCM(tx *types.Transaction) {
 		rawdb.WriteCM(CMdb, hashV, CmV)
 		log.Info("Succeed to Lock CMV into CMdb", "CMV", CmV, "hash", hashV)
 	}
-	if tx.ID() == 0 {
+	if tx.IsTransfer() {
 		// 转账交易
 		//CmO := types.NewCM(tx.CmO(), true)
-		CmO := types.NewDefaultCM(tx.CmO())
-		CmO.Lock = true
-		hashO := CmO.Hash()
-		rawdb.WriteCM(CMdb, hashO, CmO)
-		log.Info("Succeed to Lock CMO into CMdb", "CMO", CmO, "hash", hashO)
+		for _, cm := range tx.SpentCMs() {
+			CmO := types.NewDefaultCM(cm)
+			CmO.Lock = true
+			hashO := CmO.Hash()
+			rawdb.WriteCM(CMdb, hashO, CmO)
+			log.Info("Succeed to Lock CMO into CMdb", "CMO", CmO, "hash", hashO)
+		}
 	}
 }
 
@@ -733,12 +789,14 @@ func (pool *TxPool) reorgCM(tx *types.Transaction) {
 		rawdb.WriteCM(CMdb, hashV, CmV)
 		log.Info("Succeed to unlock CMV from CMdb", "CMV", CmV, "hash", hashV)
 	}
-	if tx.ID() == 0 {
+	if tx.IsTransfer() {
 		// 转账交易
-		CmO := types.NewDefaultCM(tx.CmO())
-		hashO := CmO.Hash()
-		rawdb.WriteCM(CMdb, hashO, CmO)
-		log.Info("Succeed to unlock CMO from CMdb", "CMO", CmO, "hash", hashO)
+		for _, cm := range tx.SpentCMs() {
+			CmO := types.NewDefaultCM(cm)
+			hashO := CmO.Hash()
+			rawdb.WriteCM(CMdb, hashO, CmO)
+			log.Info("Succeed to unlock CMO from CMdb", "CMO", CmO, "hash", hashO)
+		}
 	}
 }
 
@@ -751,6 +809,10 @@ func (pool *TxPool) reorgCM(tx *types.Transaction) {
 // due to pricing constraints.
 // @author mzliu 20200918
 // add validate Sign
+//
+// The proofs of transfers are not verified here: addTxs verifies them in one
+// batch before taking the pool lock, reinjected transactions were verified
+// with their block.
 func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err error) {
 	// If the transaction is already known, discard it
 	// 若交易已在交易池，丢弃
@@ -774,14 +836,13 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			invalidTxMeter.Mark(1)
 			return false, err
 		}
-	} else if tx.ID() == 0 {
-		//verify zkp
-		if err := pool.verifyzkp(tx); err != nil {
+	} else if tx.ID() == uint64(types.KeyRotationTxType) {
+		if err := pool.validateRotation(tx); err != nil {
 			log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
 			invalidTxMeter.Mark(1)
 			return false, err
 		}
-	} else {
+	} else if !tx.IsTransfer() {
 		err := ErrIDFormat
 		log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
 		invalidTxMeter.Mark(1)
@@ -1020,6 +1081,30 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	for _, tx := range news {
 		types.Sender(pool.signer, tx)
 	}
+	// Verify the proofs of all new transfers in one batch, also before
+	// obtaining the lock, and drop the invalid ones
+	if zkpErrs := pool.verifyzkps(news); zkpErrs != nil {
+		var (
+			valid   = make([]*types.Transaction, 0, len(news))
+			nilSlot = 0
+		)
+		for i, tx := range news {
+			for errs[nilSlot] != nil {
+				nilSlot++
+			}
+			if err := zkpErrs[i]; err != nil {
+				log.Trace("Discarding invalid transaction", "hash", tx.Hash(), "err", err)
+				invalidTxMeter.Mark(1)
+				errs[nilSlot] = err
+			} else {
+				valid = append(valid, tx)
+			}
+			nilSlot++
+		}
+		if news = valid; len(news) == 0 {
+			return errs
+		}
+	}
 	// Process all the new transaction and merge any errors into the original slice
 	pool.mu.Lock()
 	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
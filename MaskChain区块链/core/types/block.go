@@ -83,6 +83,87 @@ type Header struct {
 	Extra       []byte         `json:"extraData"        gencodec:"required"` //32字节以内的任意数据，如果支持DAO分叉，需要在里面写数据
 	MixDigest   common.Hash    `json:"mixHash"`                              //kec256哈希值与nonce一起证明当前区块承载了足够的计算量
 	Nonce       BlockNonce     `json:"nonce"`                                //64位的值，用来与mixhash一起证明当前区块承载了足够多的的计算量
+	CMRoot      *common.Hash   `json:"commitmentsRoot,omitempty"`            //承诺池树根，承诺池分叉（CMPoolBlock）之前为nil
+}
+
+// headerRLP is the RLP encoding of a header. The root of the commitment pool
+// is an optional trailing field, present only from the commitment pool fork
+// on, so that the headers before the fork keep their encoding and hash.
+type headerRLP struct {
+	ParentHash  common.Hash
+	UncleHash   common.Hash
+	Coinbase    common.Address
+	Root        common.Hash
+	TxHash      common.Hash
+	ReceiptHash common.Hash
+	Bloom       Bloom
+	Difficulty  *big.Int
+	Number      *big.Int
+	GasLimit    uint64
+	GasUsed     uint64
+	Time        uint64
+	Extra       []byte
+	MixDigest   common.Hash
+	Nonce       BlockNonce
+	CMRoot      []common.Hash `rlp:"tail"`
+}
+
+// EncodeRLP serializes h into the Ethereum RLP header format.
+func (h *Header) EncodeRLP(w io.Writer) error {
+	enc := headerRLP{
+		ParentHash:  h.ParentHash,
+		UncleHash:   h.UncleHash,
+		Coinbase:    h.Coinbase,
+		Root:        h.Root,
+		TxHash:      h.TxHash,
+		ReceiptHash: h.ReceiptHash,
+		Bloom:       h.Bloom,
+		Difficulty:  h.Difficulty,
+		Number:      h.Number,
+		GasLimit:    h.GasLimit,
+		GasUsed:     h.GasUsed,
+		Time:        h.Time,
+		Extra:       h.Extra,
+		MixDigest:   h.MixDigest,
+		Nonce:       h.Nonce,
+	}
+	if h.CMRoot != nil {
+		enc.CMRoot = []common.Hash{*h.CMRoot}
+	}
+	return rlp.Encode(w, &enc)
+}
+
+// DecodeRLP decodes a header in the Ethereum RLP header format, with or
+// without the root of the commitment pool.
+func (h *Header) DecodeRLP(s *rlp.Stream) error {
+	var dec headerRLP
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	if len(dec.CMRoot) > 1 {
+		return fmt.Errorf("rlp: too many trailing header fields (%d)", len(dec.CMRoot))
+	}
+	*h = Header{
+		ParentHash:  dec.ParentHash,
+		UncleHash:   dec.UncleHash,
+		Coinbase:    dec.Coinbase,
+		Root:        dec.Root,
+		TxHash:      dec.TxHash,
+		ReceiptHash: dec.ReceiptHash,
+		Bloom:       dec.Bloom,
+		Difficulty:  dec.Difficulty,
+		Number:      dec.Number,
+		GasLimit:    dec.GasLimit,
+		GasUsed:     dec.GasUsed,
+		Time:        dec.Time,
+		Extra:       dec.Extra,
+		MixDigest:   dec.MixDigest,
+		Nonce:       dec.Nonce,
+	}
+	if len(dec.CMRoot) == 1 {
+		h.CMRoot = &dec.CMRoot[0]
+	}
+	return nil
 }
 
 // field type overrides for gencodec
@@ -253,6 +334,10 @@ func CopyHeader(h *Header) *Header {
 		cpy.Extra = make([]byte, len(h.Extra))
 		copy(cpy.Extra, h.Extra)
 	}
+	if h.CMRoot != nil {
+		root := *h.CMRoot
+		cpy.CMRoot = &root
+	}
 	return &cpy
 }
 
@@ -3,32 +3,31 @@ package types
 import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // author : zr
-// CM 承诺结构，包含承诺字段以及判断该承诺是否被使用过的spent字段，true表示已使用
+// CM 承诺结构。承诺是否已被花费不再记录在承诺中，而是由CMdb中的零化符集合决定：
+// 公开花费的承诺以其哈希为零化符，隐匿花费的承诺以NullifierHash为零化符。
 type CM struct {
-	Cm    *hexutil.Bytes
-	Spent bool
-	Lock  bool
+	Cm   *hexutil.Bytes
+	Lock bool
 }
 
 func NewDefaultCM(Cm *hexutil.Bytes) *CM {
 	return &CM{
-		Cm:    Cm,
-		Spent: false,
-		Lock:  false,
-	}
-}
-
-func NewCM(Cm *hexutil.Bytes, Spent bool) *CM {
-	return &CM{
-		Cm:    Cm,
-		Spent: Spent,
-		Lock:  false,
+		Cm:   Cm,
+		Lock: false,
 	}
 }
 
 func (cm *CM) Hash() common.Hash {
 	return rlpHash(cm.Cm)
 }
+
+// NullifierHash returns the key of the nullifier revealed by a shielded spend
+// in the nullifier sets of CMdb and of the state. It is domain separated from
+// the hash of a commitment, which is the nullifier of a transparent spend.
+func NullifierHash(nullifier []byte) common.Hash {
+	return crypto.Keccak256Hash([]byte("nullifier"), nullifier)
+}
@@ -22,9 +22,13 @@ func (t txdata) MarshalJSON() ([]byte, error) {
 		Recipient    *common.Address `json:"to"       rlp:"nil"`
 		Amount       *hexutil.Big    `json:"value"    gencodec:"required"`
 		Payload      hexutil.Bytes   `json:"input"    gencodec:"required"`
+		Type         hexutil.Uint64  `json:"type"     gencodec:"required"`
+		Version      hexutil.Uint64  `json:"version"  gencodec:"required"`
+		Privacy      hexutil.Bytes   `json:"privacy"  gencodec:"required"`
 		V            *hexutil.Big    `json:"v" gencodec:"required"`
 		R            *hexutil.Big    `json:"r" gencodec:"required"`
 		S            *hexutil.Big    `json:"s" gencodec:"required"`
+		PK           hexutil.Bytes   `json:"pk"`
 		Hash         *common.Hash    `json:"hash" rlp:"-"`
 	}
 	var enc txdata
@@ -34,9 +38,13 @@ func (t txdata) MarshalJSON() ([]byte, error) {
 	enc.Recipient = t.Recipient
 	enc.Amount = (*hexutil.Big)(t.Amount)
 	enc.Payload = t.Payload
+	enc.Type = hexutil.Uint64(t.Type)
+	enc.Version = hexutil.Uint64(t.Version)
+	enc.Privacy = t.Privacy
 	enc.V = (*hexutil.Big)(t.V)
 	enc.R = (*hexutil.Big)(t.R)
 	enc.S = (*hexutil.Big)(t.S)
+	enc.PK = t.PK
 	enc.Hash = t.Hash
 	return json.Marshal(&enc)
 }
@@ -50,9 +58,13 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 		Recipient    *common.Address `json:"to"       rlp:"nil"`
 		Amount       *hexutil.Big    `json:"value"    gencodec:"required"`
 		Payload      *hexutil.Bytes  `json:"input"    gencodec:"required"`
+		Type         *hexutil.Uint64 `json:"type"     gencodec:"required"`
+		Version      *hexutil.Uint64 `json:"version"  gencodec:"required"`
+		Privacy      *hexutil.Bytes  `json:"privacy"  gencodec:"required"`
 		V            *hexutil.Big    `json:"v" gencodec:"required"`
 		R            *hexutil.Big    `json:"r" gencodec:"required"`
 		S            *hexutil.Big    `json:"s" gencodec:"required"`
+		PK           *hexutil.Bytes  `json:"pk"`
 		Hash         *common.Hash    `json:"hash" rlp:"-"`
 	}
 	var dec txdata
@@ -82,6 +94,18 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 'input' for txdata")
 	}
 	t.Payload = *dec.Payload
+	if dec.Type == nil {
+		return errors.New("missing required field 'type' for txdata")
+	}
+	t.Type = uint8(*dec.Type)
+	if dec.Version == nil {
+		return errors.New("missing required field 'version' for txdata")
+	}
+	t.Version = uint8(*dec.Version)
+	if dec.Privacy == nil {
+		return errors.New("missing required field 'privacy' for txdata")
+	}
+	t.Privacy = *dec.Privacy
 	if dec.V == nil {
 		return errors.New("missing required field 'v' for txdata")
 	}
@@ -94,6 +118,9 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 's' for txdata")
 	}
 	t.S = (*big.Int)(dec.S)
+	if dec.PK != nil {
+		t.PK = *dec.PK
+	}
 	if dec.Hash != nil {
 		t.Hash = dec.Hash
 	}
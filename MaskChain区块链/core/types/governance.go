@@ -0,0 +1,91 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// 密钥轮换交易所轮换密钥的种类
+const (
+	RegulatorKeyKind uint8 = iota // 监管者加密公钥
+	ExchangeKeyKind               // 交易所（发行者）签名公钥
+)
+
+// keyRotationDomain separates the hash signed by the authorities from any
+// other message they sign.
+const keyRotationDomain = "MaskChain key rotation"
+
+var errRotationSignature = errors.New("invalid key rotation signature")
+
+// KeyRotationPayload schedules a new key of the regulator or the exchange from
+// block Block on. The authorities of the governance config sign SigHash, the
+// payload is valid with the signatures of a quorum of them.
+type KeyRotationPayload struct {
+	Kind      uint8    // 轮换的密钥种类
+	Block     uint64   // 新密钥生效的区块
+	G1, G2, H []byte   // 新公钥，点为65字节的非压缩编码
+	Sigs      [][]byte // 授权者对SigHash的签名
+}
+
+func (p *KeyRotationPayload) txType() uint8 { return KeyRotationTxType }
+
+// Fields implements PrivacyPayload.
+func (p *KeyRotationPayload) Fields() []*[]byte {
+	fields := []*[]byte{&p.G1, &p.G2, &p.H}
+	for i := range p.Sigs {
+		fields = append(fields, &p.Sigs[i])
+	}
+	return fields
+}
+
+// Key returns the key scheduled by the rotation.
+func (p *KeyRotationPayload) Key() params.PrivacyKey {
+	return params.PrivacyKey{
+		Block: new(big.Int).SetUint64(p.Block),
+		G1:    common.CopyBytes(p.G1),
+		G2:    common.CopyBytes(p.G2),
+		H:     common.CopyBytes(p.H),
+	}
+}
+
+// SigHash returns the hash the authorities sign, which binds the rotation to
+// the chain.
+func (p *KeyRotationPayload) SigHash(chainID *big.Int) common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{chainID, p.Kind, p.Block, p.G1, p.G2, p.H})
+	return crypto.Keccak256Hash([]byte(keyRotationDomain), enc)
+}
+
+// Sign adds the signature of an authority to the rotation.
+func (p *KeyRotationPayload) Sign(chainID *big.Int, prv *ecdsa.PrivateKey) error {
+	hash := p.SigHash(chainID)
+	sig, err := crypto.Sign(hash[:], prv)
+	if err != nil {
+		return err
+	}
+	p.Sigs = append(p.Sigs, sig)
+	return nil
+}
+
+// Signers returns the addresses which signed the rotation, in the order of
+// the signatures.
+func (p *KeyRotationPayload) Signers(chainID *big.Int) ([]common.Address, error) {
+	hash := p.SigHash(chainID)
+	signers := make([]common.Address, len(p.Sigs))
+	for i, sig := range p.Sigs {
+		if len(sig) != crypto.SignatureLength {
+			return nil, errRotationSignature
+		}
+		pub, err := crypto.SigToPub(hash[:], sig)
+		if err != nil || pub == nil {
+			return nil, errRotationSignature
+		}
+		signers[i] = crypto.PubkeyToAddress(*pub)
+	}
+	return signers, nil
+}
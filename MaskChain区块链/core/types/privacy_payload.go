@@ -0,0 +1,608 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"maskchain/privacy/ecc"
+)
+
+// 交易类型，即交易的类型字节（旧版交易中的ID字段）
+const (
+	TransferTxType         uint8 = iota // 转账交易
+	PurchaseTxType                      // 购币交易
+	PlainTxType                         // 不带隐私数据的普通交易
+	MultiTransferTxType                 // 多输入多输出转账交易
+	ShieldedTransferTxType              // 隐匿转账交易，以零化符花费累加器中的承诺
+	KeyRotationTxType                   // 密钥轮换交易，由治理授权者签名
+	RedeemTxType                        // 赎回交易，销毁承诺并由交易所兑付法币
+)
+
+// 多输入多输出转账交易的输入、输出数量上限
+const (
+	MaxTransferInputs  = 16
+	MaxTransferOutputs = ecc.MaxRangeProofValues
+)
+
+// PrivacyVersion is the version of the privacy payload encoding, i.e. of the
+// proof system, of transactions created by this node. Payloads of other
+// versions still decode at the envelope level but are rejected by validation.
+//
+// Proofs of version 2 derive their challenges from the transcript of the
+// transaction (see TransferPayload.Transcript). Proofs of the otherwise
+// identically encoded LegacyPrivacyVersion are not bound to the transaction;
+// they only exist in transactions of the flat legacy layout and are accepted
+// in blocks before the bound proof fork.
+const (
+	LegacyPrivacyVersion uint8 = 1
+	PrivacyVersion       uint8 = 2
+)
+
+// 交易中各证明在转录中的分支标签
+const (
+	RpkEPLabel = "RpkEP" // 接收方地址公钥相等证明
+	SpkEPLabel = "SpkEP" // 发送方地址公钥相等证明
+	ScmFPLabel = "ScmFP" // 发送金额承诺格式证明
+	RcmFPLabel = "RcmFP" // 找零金额承诺格式证明
+	VoEPLabel  = "VoEP"  // 被花费承诺相等证明
+	BPLabel    = "BP"    // 会计平衡证明
+	RPLabel    = "RP"    // 范围证明
+	EPLabel    = "EP"    // 多输入多输出转账中被花费承诺相等证明
+	FPLabel    = "FP"    // 多输入多输出转账中输出金额承诺格式证明
+	PFPLabel   = "PFP"   // 隐匿转账中伪承诺格式证明
+	MPLabel    = "MP"    // 隐匿转账中成员证明
+	CEPLabel   = "CEP"   // 赎回交易中监管者密文与交易所密文的相等证明
+)
+
+// IndexedLabel returns the transcript label of a proof of the i-th input or
+// output of a multi transfer.
+func IndexedLabel(label string, i int) string { return label + "/" + strconv.Itoa(i) }
+
+var (
+	ErrPrivacyType    = errors.New("unknown privacy transaction type")
+	ErrPrivacyVersion = errors.New("unsupported privacy payload version")
+
+	errPlainPrivacy = errors.New("plain transaction with privacy payload")
+)
+
+// CypherText is an ElGamal ciphertext as carried by transactions.
+type CypherText struct {
+	C1, C2 []byte
+}
+
+// FormatProof proves that a ciphertext and a commitment are well formed.
+type FormatProof struct {
+	G1, G2 []byte
+	Y1, Y2 []byte
+	T1, T2 []byte
+	S      []byte
+	C      []byte
+}
+
+// EqualityProof proves that two commitments hide the same value.
+type EqualityProof FormatProof
+
+// BalanceProof proves that the spent commitment equals the sum of the outputs.
+type BalanceProof struct {
+	Y, T          []byte
+	Sn1, Sn2, Sn3 []byte
+	C             []byte
+}
+
+// MultiBalanceProof proves that the inputs of a transfer sum up to its outputs.
+type MultiBalanceProof struct {
+	Y, T []byte
+	Sn   [][]byte
+	C    []byte
+}
+
+// PurchaseSignature is the signature of the exchange on a purchase.
+type PurchaseSignature struct {
+	M, MHash, R, S []byte
+}
+
+func (c CypherText) ECC() ecc.CypherText { return ecc.CypherText(c) }
+
+func (p FormatProof) ECC() ecc.FormatProof { return ecc.FormatProof(p) }
+
+func (p EqualityProof) ECC() ecc.EqualityProof {
+	return ecc.EqualityProof{FormatProof: ecc.FormatProof(p)}
+}
+
+func (p BalanceProof) ECC() ecc.BalanceProof {
+	return ecc.BalanceProof{Y: p.Y, T: p.T, Sn_1: p.Sn1, Sn_2: p.Sn2, Sn_3: p.Sn3, C: p.C}
+}
+
+func (p MultiBalanceProof) ECC() ecc.MultiBalanceProof { return ecc.MultiBalanceProof(p) }
+
+func (s PurchaseSignature) ECC() ecc.Signature {
+	return ecc.Signature{M: s.M, M_hash: s.MHash, R: s.R, S: s.S}
+}
+
+// NewCypherText converts a ciphertext of the ECC package.
+func NewCypherText(c ecc.CypherText) CypherText { return CypherText(c) }
+
+// NewFormatProof converts a format proof of the ECC package.
+func NewFormatProof(p ecc.FormatProof) FormatProof { return FormatProof(p) }
+
+// NewEqualityProof converts an equality proof of the ECC package.
+func NewEqualityProof(p ecc.EqualityProof) EqualityProof { return EqualityProof(p.FormatProof) }
+
+// NewBalanceProof converts a balance proof of the ECC package.
+func NewBalanceProof(p ecc.BalanceProof) BalanceProof {
+	return BalanceProof{Y: p.Y, T: p.T, Sn1: p.Sn_1, Sn2: p.Sn_2, Sn3: p.Sn_3, C: p.C}
+}
+
+// NewMultiBalanceProof converts a multi balance proof of the ECC package.
+func NewMultiBalanceProof(p ecc.MultiBalanceProof) MultiBalanceProof { return MultiBalanceProof(p) }
+
+// NewPurchaseSignature converts a signature of the ECC package.
+func NewPurchaseSignature(s ecc.Signature) PurchaseSignature {
+	return PurchaseSignature{M: s.M, MHash: s.M_hash, R: s.R, S: s.S}
+}
+
+// PrivacyPayload is the typed privacy part of a transaction, either a
+// *TransferPayload, a *MultiTransferPayload, a *ShieldedTransferPayload, a
+// *PurchasePayload, a *KeyRotationPayload or a *RedeemPayload.
+type PrivacyPayload interface {
+	txType() uint8
+	// Fields returns pointers to all fields of the payload in their flat
+	// order, which for transfers and purchases is the one of the legacy
+	// transaction layout.
+	Fields() []*[]byte
+}
+
+// TransferPayload carries the ciphertexts, commitments and proofs of a
+// transfer (ID=0) which spends CmO into CmS and the change CmR.
+type TransferPayload struct {
+	Erpk, Espk   CypherText    // 接收方、发送方地址公钥密文
+	CMRpk, CMSpk []byte        // 接收方、发送方地址公钥承诺
+	RpkEP, SpkEP EqualityProof // 接收方、发送方地址公钥相等证明
+	EvS, EvR     CypherText    // 发送金额、找零金额密文
+	CmS, CmR     []byte        // 发送金额承诺、找零金额承诺
+	ScmFP, RcmFP FormatProof   // 发送金额、找零金额承诺格式证明
+	EvsBs        CypherText    // 接收方公钥加密的发送金额
+	EvO          CypherText    // 被花费承诺金额密文
+	CmO          []byte        // 被花费承诺
+	VoEP         EqualityProof // 被花费承诺相等证明
+	BP           BalanceProof  // 会计平衡证明
+	CmSR, CmRR   CypherText    // 接收方公钥加密的发送承诺随机数、发送方公钥加密的找零承诺随机数
+	RP           []byte        // CmS和CmR的聚合范围证明
+	SpendKeys    [][]byte      `rlp:"tail"` // CmS和CmR的一次性花费公钥，隐匿分叉起必须携带
+}
+
+func (p *TransferPayload) txType() uint8 { return TransferTxType }
+
+// Fields implements PrivacyPayload.
+func (p *TransferPayload) Fields() []*[]byte {
+	return []*[]byte{
+		&p.Erpk.C1, &p.Erpk.C2, &p.Espk.C1, &p.Espk.C2, &p.CMRpk, &p.CMSpk,
+		&p.RpkEP.G1, &p.RpkEP.G2, &p.RpkEP.Y1, &p.RpkEP.Y2, &p.RpkEP.T1, &p.RpkEP.T2, &p.RpkEP.S, &p.RpkEP.C,
+		&p.SpkEP.G1, &p.SpkEP.G2, &p.SpkEP.Y1, &p.SpkEP.Y2, &p.SpkEP.T1, &p.SpkEP.T2, &p.SpkEP.S, &p.SpkEP.C,
+		&p.EvS.C1, &p.EvS.C2, &p.EvR.C1, &p.EvR.C2, &p.CmS, &p.CmR,
+		&p.ScmFP.G1, &p.ScmFP.G2, &p.ScmFP.Y1, &p.ScmFP.Y2, &p.ScmFP.T1, &p.ScmFP.T2, &p.ScmFP.S, &p.ScmFP.C,
+		&p.RcmFP.G1, &p.RcmFP.G2, &p.RcmFP.Y1, &p.RcmFP.Y2, &p.RcmFP.T1, &p.RcmFP.T2, &p.RcmFP.S, &p.RcmFP.C,
+		&p.EvsBs.C1, &p.EvsBs.C2, &p.EvO.C1, &p.EvO.C2, &p.CmO,
+		&p.VoEP.G1, &p.VoEP.G2, &p.VoEP.Y1, &p.VoEP.Y2, &p.VoEP.T1, &p.VoEP.T2, &p.VoEP.S, &p.VoEP.C,
+		&p.BP.Y, &p.BP.T, &p.BP.Sn1, &p.BP.Sn2, &p.BP.Sn3, &p.BP.C,
+		&p.CmSR.C1, &p.CmSR.C2, &p.CmRR.C1, &p.CmRR.C2, &p.RP,
+	}
+}
+
+// Complete reports whether every field of the transfer is present. The range
+// proof is only required if rangeProof is set, i.e. from the range proof fork
+// on, as legacy transfers were encoded without it.
+func (p *TransferPayload) Complete(rangeProof bool) bool {
+	for _, field := range p.Fields() {
+		if len(*field) == 0 && (rangeProof || field != &p.RP) {
+			return false
+		}
+	}
+	return true
+}
+
+// Spent returns the commitment spent by the transfer.
+func (p *TransferPayload) Spent() [][]byte { return [][]byte{p.CmO} }
+
+// Created returns the payment and change commitments created by the transfer.
+func (p *TransferPayload) Created() [][]byte { return [][]byte{p.CmS, p.CmR} }
+
+// Transcript returns the transcript the proofs of the transfer are bound to:
+// the chain ID, the transaction type, the address commitments and the spent
+// and created commitments. Each proof uses a fork of it under its label.
+func (p *TransferPayload) Transcript(chainID *big.Int) *ecc.Transcript {
+	return ecc.TxTranscript(chainID, TransferTxType, p.CMRpk, p.CMSpk, p.CmO, p.CmS, p.CmR)
+}
+
+// TransferInput is a commitment spent by a multi transfer.
+type TransferInput struct {
+	Cm []byte        // 被花费承诺
+	Ev CypherText    // 被花费承诺金额密文
+	EP EqualityProof // 被花费承诺相等证明
+}
+
+// TransferOutput is a commitment created by a multi transfer for one
+// recipient, which may be the sender itself for the change.
+type TransferOutput struct {
+	Erpk  CypherText    // 接收方地址公钥密文
+	CMRpk []byte        // 接收方地址公钥承诺
+	RpkEP EqualityProof // 接收方地址公钥相等证明
+	Ev    CypherText    // 金额密文
+	Cm    []byte        // 金额承诺
+	FP    FormatProof   // 金额承诺格式证明
+	EvBs  CypherText    // 接收方公钥加密的金额
+	CmR   CypherText    // 接收方公钥加密的承诺随机数
+}
+
+// MultiTransferPayload carries a transfer (ID=3) which spends several
+// commitments into several outputs, e.g. to merge small coins or to pay
+// several recipients at once.
+type MultiTransferPayload struct {
+	Espk    CypherText    // 发送方地址公钥密文
+	CMSpk   []byte        // 发送方地址公钥承诺
+	SpkEP   EqualityProof // 发送方地址公钥相等证明
+	Inputs  []TransferInput
+	Outputs []TransferOutput
+	BP      MultiBalanceProof // 会计平衡证明，输入总额等于输出总额
+	RP      []byte            // 全部输出承诺的聚合范围证明
+
+	SpendKeys [][]byte `rlp:"tail"` // 每个输出承诺的一次性花费公钥，隐匿分叉起必须携带
+}
+
+func (p *MultiTransferPayload) txType() uint8 { return MultiTransferTxType }
+
+// Fields implements PrivacyPayload.
+func (p *MultiTransferPayload) Fields() []*[]byte {
+	fields := []*[]byte{
+		&p.Espk.C1, &p.Espk.C2, &p.CMSpk,
+		&p.SpkEP.G1, &p.SpkEP.G2, &p.SpkEP.Y1, &p.SpkEP.Y2, &p.SpkEP.T1, &p.SpkEP.T2, &p.SpkEP.S, &p.SpkEP.C,
+	}
+	for i := range p.Inputs {
+		in := &p.Inputs[i]
+		fields = append(fields, &in.Cm, &in.Ev.C1, &in.Ev.C2,
+			&in.EP.G1, &in.EP.G2, &in.EP.Y1, &in.EP.Y2, &in.EP.T1, &in.EP.T2, &in.EP.S, &in.EP.C)
+	}
+	for i := range p.Outputs {
+		out := &p.Outputs[i]
+		fields = append(fields, &out.Erpk.C1, &out.Erpk.C2, &out.CMRpk,
+			&out.RpkEP.G1, &out.RpkEP.G2, &out.RpkEP.Y1, &out.RpkEP.Y2, &out.RpkEP.T1, &out.RpkEP.T2, &out.RpkEP.S, &out.RpkEP.C,
+			&out.Ev.C1, &out.Ev.C2, &out.Cm,
+			&out.FP.G1, &out.FP.G2, &out.FP.Y1, &out.FP.Y2, &out.FP.T1, &out.FP.T2, &out.FP.S, &out.FP.C,
+			&out.EvBs.C1, &out.EvBs.C2, &out.CmR.C1, &out.CmR.C2)
+	}
+	fields = append(fields, &p.BP.Y, &p.BP.T)
+	for i := range p.BP.Sn {
+		fields = append(fields, &p.BP.Sn[i])
+	}
+	return append(fields, &p.BP.C, &p.RP)
+}
+
+// Complete reports whether the transfer has between one and the maximum
+// number of inputs and outputs, a balance response for each of them and
+// every field present.
+func (p *MultiTransferPayload) Complete() bool {
+	if len(p.Inputs) == 0 || len(p.Inputs) > MaxTransferInputs {
+		return false
+	}
+	if len(p.Outputs) == 0 || len(p.Outputs) > MaxTransferOutputs {
+		return false
+	}
+	if len(p.BP.Sn) != len(p.Inputs)+len(p.Outputs) {
+		return false
+	}
+	for _, field := range p.Fields() {
+		if len(*field) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Spent returns the commitments spent by the transfer.
+func (p *MultiTransferPayload) Spent() [][]byte {
+	cms := make([][]byte, len(p.Inputs))
+	for i, in := range p.Inputs {
+		cms[i] = in.Cm
+	}
+	return cms
+}
+
+// Created returns the commitments created by the transfer.
+func (p *MultiTransferPayload) Created() [][]byte {
+	cms := make([][]byte, len(p.Outputs))
+	for i, out := range p.Outputs {
+		cms[i] = out.Cm
+	}
+	return cms
+}
+
+// Transcript returns the transcript the proofs of the transfer are bound to:
+// the chain ID, the transaction type, the sender address commitment, the spent
+// commitments and the address and value commitment of every output.
+func (p *MultiTransferPayload) Transcript(chainID *big.Int) *ecc.Transcript {
+	cms := append([][]byte{p.CMSpk}, p.Spent()...)
+	for _, out := range p.Outputs {
+		cms = append(cms, out.CMRpk, out.Cm)
+	}
+	return ecc.TxTranscript(chainID, MultiTransferTxType, cms...)
+}
+
+// ShieldedInput is a commitment spent by a shielded transfer. Instead of the
+// commitment it names a ring of the accumulator below an anchor and reveals
+// the nullifier of the spent member, whose value a fresh pseudo commitment,
+// the C1 of Ev, takes over.
+type ShieldedInput struct {
+	Anchor    []byte      // 累加器根，须为链上出现过的根
+	Ring      uint64      // 环编号，即累加器中第Ring组RingSize个叶子
+	Nullifier []byte      // 被花费承诺的零化符
+	Ev        CypherText  // 伪承诺金额密文，C1即伪承诺
+	FP        FormatProof // 伪承诺格式证明
+	MP        []byte      // 成员证明，伪承诺与环中某一承诺金额相同且零化符属于该承诺
+}
+
+// ShieldedTransferPayload carries a transfer (ID=4) which spends commitments
+// of the accumulator without revealing them. Its outputs are the ones of a
+// multi transfer.
+type ShieldedTransferPayload struct {
+	Espk    CypherText    // 发送方地址公钥密文
+	CMSpk   []byte        // 发送方地址公钥承诺
+	SpkEP   EqualityProof // 发送方地址公钥相等证明
+	Inputs  []ShieldedInput
+	Outputs []TransferOutput
+	BP      MultiBalanceProof // 会计平衡证明，伪承诺总额等于输出总额
+	RP      []byte            // 全部输出承诺的聚合范围证明
+
+	SpendKeys [][]byte `rlp:"tail"` // 每个输出承诺的一次性花费公钥
+}
+
+func (p *ShieldedTransferPayload) txType() uint8 { return ShieldedTransferTxType }
+
+// Fields implements PrivacyPayload. The ring numbers of the inputs are no
+// byte fields and thus not included.
+func (p *ShieldedTransferPayload) Fields() []*[]byte {
+	fields := []*[]byte{
+		&p.Espk.C1, &p.Espk.C2, &p.CMSpk,
+		&p.SpkEP.G1, &p.SpkEP.G2, &p.SpkEP.Y1, &p.SpkEP.Y2, &p.SpkEP.T1, &p.SpkEP.T2, &p.SpkEP.S, &p.SpkEP.C,
+	}
+	for i := range p.Inputs {
+		in := &p.Inputs[i]
+		fields = append(fields, &in.Anchor, &in.Nullifier, &in.Ev.C1, &in.Ev.C2,
+			&in.FP.G1, &in.FP.G2, &in.FP.Y1, &in.FP.Y2, &in.FP.T1, &in.FP.T2, &in.FP.S, &in.FP.C, &in.MP)
+	}
+	for i := range p.Outputs {
+		out := &p.Outputs[i]
+		fields = append(fields, &out.Erpk.C1, &out.Erpk.C2, &out.CMRpk,
+			&out.RpkEP.G1, &out.RpkEP.G2, &out.RpkEP.Y1, &out.RpkEP.Y2, &out.RpkEP.T1, &out.RpkEP.T2, &out.RpkEP.S, &out.RpkEP.C,
+			&out.Ev.C1, &out.Ev.C2, &out.Cm,
+			&out.FP.G1, &out.FP.G2, &out.FP.Y1, &out.FP.Y2, &out.FP.T1, &out.FP.T2, &out.FP.S, &out.FP.C,
+			&out.EvBs.C1, &out.EvBs.C2, &out.CmR.C1, &out.CmR.C2)
+	}
+	fields = append(fields, &p.BP.Y, &p.BP.T)
+	for i := range p.BP.Sn {
+		fields = append(fields, &p.BP.Sn[i])
+	}
+	return append(fields, &p.BP.C, &p.RP)
+}
+
+// Complete reports whether the transfer has between one and the maximum
+// number of inputs and outputs, a balance response for each of them and
+// every field present.
+func (p *ShieldedTransferPayload) Complete() bool {
+	if len(p.Inputs) == 0 || len(p.Inputs) > MaxTransferInputs {
+		return false
+	}
+	if len(p.Outputs) == 0 || len(p.Outputs) > MaxTransferOutputs {
+		return false
+	}
+	if len(p.BP.Sn) != len(p.Inputs)+len(p.Outputs) {
+		return false
+	}
+	for _, field := range p.Fields() {
+		if len(*field) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Spent returns no commitment: a shielded transfer does not reveal the
+// commitments it spends, see Nullifiers.
+func (p *ShieldedTransferPayload) Spent() [][]byte { return nil }
+
+// Created returns the commitments created by the transfer.
+func (p *ShieldedTransferPayload) Created() [][]byte {
+	cms := make([][]byte, len(p.Outputs))
+	for i, out := range p.Outputs {
+		cms[i] = out.Cm
+	}
+	return cms
+}
+
+// Nullifiers returns the nullifiers of the commitments spent by the transfer.
+func (p *ShieldedTransferPayload) Nullifiers() [][]byte {
+	nullifiers := make([][]byte, len(p.Inputs))
+	for i, in := range p.Inputs {
+		nullifiers[i] = in.Nullifier
+	}
+	return nullifiers
+}
+
+// Pseudo returns the pseudo commitments of the inputs, which take the place of
+// the spent commitments in the balance proof.
+func (p *ShieldedTransferPayload) Pseudo() [][]byte {
+	cms := make([][]byte, len(p.Inputs))
+	for i, in := range p.Inputs {
+		cms[i] = in.Ev.C1
+	}
+	return cms
+}
+
+// Transcript returns the transcript the proofs of the transfer are bound to:
+// the chain ID, the transaction type, the sender address commitment, the
+// anchor, ring number, nullifier and pseudo commitment of every input and the
+// address and value commitment of every output.
+func (p *ShieldedTransferPayload) Transcript(chainID *big.Int) *ecc.Transcript {
+	cms := [][]byte{p.CMSpk}
+	for _, in := range p.Inputs {
+		var ring [8]byte
+		binary.BigEndian.PutUint64(ring[:], in.Ring)
+		cms = append(cms, in.Anchor, ring[:], in.Nullifier, in.Ev.C1)
+	}
+	for _, out := range p.Outputs {
+		cms = append(cms, out.CMRpk, out.Cm)
+	}
+	return ecc.TxTranscript(chainID, ShieldedTransferTxType, cms...)
+}
+
+// PurchasePayload carries the coin commitment of a purchase (ID=1) and the
+// signature of the exchange which issued it.
+type PurchasePayload struct {
+	Epkr CypherText        // 用户公钥加密的随机数r
+	Epkp CypherText        // 监管者公钥加密的publickey+amount
+	Sig  PurchaseSignature // 发行者签名
+	CmV  []byte            // 本次购币的承诺
+
+	SpendKeys [][]byte `rlp:"tail"` // CmV的一次性花费公钥，隐匿分叉起必须携带
+}
+
+func (p *PurchasePayload) txType() uint8 { return PurchaseTxType }
+
+// Fields implements PrivacyPayload.
+func (p *PurchasePayload) Fields() []*[]byte {
+	return []*[]byte{
+		&p.Epkr.C1, &p.Epkr.C2, &p.Epkp.C1, &p.Epkp.C2,
+		&p.Sig.M, &p.Sig.MHash, &p.Sig.R, &p.Sig.S, &p.CmV,
+	}
+}
+
+// Amount returns the amount issued by the purchase. The exchange signs the
+// purchase ID 1 followed by the decimal amount, false is returned if the signed
+// message is of another form.
+func (p *PurchasePayload) Amount() (uint64, bool) {
+	m := string(p.Sig.M)
+	if len(m) < 2 || m[0] != '1' || (m[1] == '0' && len(m) > 2) {
+		return 0, false
+	}
+	for _, c := range m[1:] {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	amount, err := strconv.ParseUint(m[1:], 10, 64)
+	return amount, err == nil
+}
+
+// RedeemPayload carries a redemption (ID=6), which burns one commitment and
+// encrypts its value for the exchange, which pays it out in fiat. The value is
+// also encrypted for the regulator and both ciphertexts are proven to hold the
+// same value.
+//
+// The commitment is either spent in public, then Ev.C1 is the commitment
+// itself, or from the shielded fork on like an input of a shielded transfer,
+// then Ev.C1 is a pseudo commitment to its value and the ring, nullifier and
+// membership proof are present.
+type RedeemPayload struct {
+	Ev        CypherText // 监管者公钥加密的赎回金额，C1为被赎回承诺或伪承诺
+	Ex        CypherText // 交易所公钥加密的赎回金额
+	CEP       []byte     // Ev与Ex加密同一金额的相等证明
+	Anchor    []byte     // 隐匿赎回时的累加器根
+	Ring      uint64     // 隐匿赎回时的环编号
+	Nullifier []byte     // 隐匿赎回时被赎回承诺的零化符
+	MP        []byte     // 隐匿赎回时的成员证明
+}
+
+func (p *RedeemPayload) txType() uint8 { return RedeemTxType }
+
+// Fields implements PrivacyPayload. The ring number is no byte field and thus
+// not included.
+func (p *RedeemPayload) Fields() []*[]byte {
+	return []*[]byte{&p.Ev.C1, &p.Ev.C2, &p.Ex.C1, &p.Ex.C2, &p.CEP, &p.Anchor, &p.Nullifier, &p.MP}
+}
+
+// Shielded reports whether the redemption spends a commitment of the
+// accumulator by its nullifier.
+func (p *RedeemPayload) Shielded() bool { return len(p.Nullifier) != 0 }
+
+// Complete reports whether the ciphertexts and the proof are present, and
+// either all or none of the fields of a shielded spend.
+func (p *RedeemPayload) Complete() bool {
+	for _, field := range p.Fields()[:5] {
+		if len(*field) == 0 {
+			return false
+		}
+	}
+	if p.Shielded() {
+		return len(p.Anchor) != 0 && len(p.MP) != 0
+	}
+	return len(p.Anchor) == 0 && len(p.MP) == 0 && p.Ring == 0
+}
+
+// Spent returns the commitment burnt by a public redemption, nil for shielded
+// ones.
+func (p *RedeemPayload) Spent() [][]byte {
+	if p.Shielded() {
+		return nil
+	}
+	return [][]byte{p.Ev.C1}
+}
+
+// Created returns no commitment, the value of a redemption leaves the chain.
+func (p *RedeemPayload) Created() [][]byte { return nil }
+
+// Nullifiers returns the nullifier of the commitment burnt by a shielded
+// redemption, nil for public ones.
+func (p *RedeemPayload) Nullifiers() [][]byte {
+	if !p.Shielded() {
+		return nil
+	}
+	return [][]byte{p.Nullifier}
+}
+
+// Transcript returns the transcript the proofs of the redemption are bound
+// to: the chain ID, the transaction type, both ciphertexts and, for shielded
+// redemptions, the anchor, ring number and nullifier.
+func (p *RedeemPayload) Transcript(chainID *big.Int) *ecc.Transcript {
+	var ring [8]byte
+	binary.BigEndian.PutUint64(ring[:], p.Ring)
+	return ecc.TxTranscript(chainID, RedeemTxType, p.Ev.C1, p.Ev.C2, p.Ex.C1, p.Ex.C2, p.Anchor, ring[:], p.Nullifier)
+}
+
+// DecodePrivacyPayload decodes the privacy payload of a transaction of the
+// given type and payload version. Plain transactions carry no payload.
+func DecodePrivacyPayload(typ, version uint8, blob []byte) (PrivacyPayload, error) {
+	if typ == PlainTxType {
+		if len(blob) != 0 {
+			return nil, errPlainPrivacy
+		}
+		return nil, nil
+	}
+	var payload PrivacyPayload
+	switch typ {
+	case TransferTxType:
+		payload = new(TransferPayload)
+	case PurchaseTxType:
+		payload = new(PurchasePayload)
+	case MultiTransferTxType:
+		payload = new(MultiTransferPayload)
+	case ShieldedTransferTxType:
+		payload = new(ShieldedTransferPayload)
+	case KeyRotationTxType:
+		payload = new(KeyRotationPayload)
+	case RedeemTxType:
+		payload = new(RedeemPayload)
+	default:
+		return nil, ErrPrivacyType
+	}
+	// Legacy payloads are converted by the legacy decoder, a typed envelope
+	// must not claim the unbound proofs of the legacy version
+	if version != PrivacyVersion {
+		return nil, ErrPrivacyVersion
+	}
+	if err := rlp.DecodeBytes(blob, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
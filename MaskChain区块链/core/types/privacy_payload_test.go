@@ -0,0 +1,145 @@
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// fillPayload sets every byte field of the payload to a distinct value. The
+// encoding does not look into the fields, so they need not be valid proofs.
+func fillPayload(p PrivacyPayload) PrivacyPayload {
+	for i, field := range p.Fields() {
+		*field = []byte{byte(i + 1), 0xff}
+	}
+	return p
+}
+
+func testTransferPayload() *TransferPayload {
+	p := fillPayload(new(TransferPayload)).(*TransferPayload)
+	p.SpendKeys = [][]byte{{0xaa}, {0xbb}}
+	return p
+}
+
+// Tests that the typed envelope survives the wire and binds the payload to
+// the sender signature.
+func TestPrivacyEnvelope(t *testing.T) {
+	multi := &MultiTransferPayload{
+		Inputs:    make([]TransferInput, 2),
+		Outputs:   make([]TransferOutput, 1),
+		BP:        MultiBalanceProof{Sn: make([][]byte, 3)},
+		SpendKeys: [][]byte{{0xcc}},
+	}
+	redeem := &RedeemPayload{Ring: 3}
+
+	key, _ := crypto.GenerateKey()
+	signer := NewEIP155Signer(big.NewInt(1))
+	to := common.HexToAddress("0x01")
+
+	tests := []struct {
+		payload PrivacyPayload
+		typ     uint8
+		decoded func(*Transaction) PrivacyPayload
+	}{
+		{testTransferPayload(), TransferTxType, func(tx *Transaction) PrivacyPayload { return tx.Transfer() }},
+		{fillPayload(multi), MultiTransferTxType, func(tx *Transaction) PrivacyPayload { return tx.MultiTransfer() }},
+		{fillPayload(redeem), RedeemTxType, func(tx *Transaction) PrivacyPayload { return tx.Redeem() }},
+	}
+	for i, tt := range tests {
+		tx, err := SignTx(NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, tt.payload), signer, key)
+		if err != nil {
+			t.Fatalf("test %d: failed to sign: %v", i, err)
+		}
+		blob, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			t.Fatalf("test %d: failed to encode: %v", i, err)
+		}
+		decoded := new(Transaction)
+		if err := rlp.DecodeBytes(blob, decoded); err != nil {
+			t.Fatalf("test %d: failed to decode: %v", i, err)
+		}
+		if decoded.Hash() != tx.Hash() {
+			t.Errorf("test %d: hash mismatch: have %x, want %x", i, decoded.Hash(), tx.Hash())
+		}
+		if decoded.Type() != tt.typ || decoded.Version() != PrivacyVersion {
+			t.Errorf("test %d: envelope mismatch: type %d version %d", i, decoded.Type(), decoded.Version())
+		}
+		if !reflect.DeepEqual(tt.decoded(decoded), tt.payload) {
+			t.Errorf("test %d: payload mismatch", i)
+		}
+		if from, err := Sender(signer, decoded); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Errorf("test %d: sender mismatch: %x, %v", i, from, err)
+		}
+	}
+	// Swapping the payload must invalidate the signature
+	payload := testTransferPayload()
+	tx, _ := SignTx(NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, payload), signer, key)
+
+	forged := *payload
+	forged.CmSR, forged.CmRR = payload.CmRR, payload.CmSR
+	unsigned := NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &forged)
+	v, r, s := tx.RawSignatureValues()
+	sig := append(append(common.LeftPadBytes(r.Bytes(), 32), common.LeftPadBytes(s.Bytes(), 32)...), byte(v.Uint64()-35-2))
+	sig = append(sig, tx.Pk()...)
+	if resigned, err := unsigned.WithSignature(signer, sig); err == nil {
+		if from, err := Sender(signer, resigned); err == nil && from == crypto.PubkeyToAddress(key.PublicKey) {
+			t.Fatalf("signature valid for a different payload")
+		}
+	}
+}
+
+// Tests that transfers in the flat pre-envelope layout, with and without the
+// range proof, still decode and re-encode to the mined bytes, and that the
+// legacy version cannot be claimed inside the typed envelope.
+func TestLegacyTransfer(t *testing.T) {
+	to := common.HexToAddress("0x01")
+	payload := testTransferPayload()
+
+	for _, withRP := range []bool{true, false} {
+		fields := []interface{}{uint64(0), big.NewInt(1), uint64(21000), &to, new(big.Int), []byte{}, uint64(0)}
+		bundle := payload.Fields()
+		for i, field := range bundle {
+			if i == legacyTransferHead {
+				// Purchase fields of the legacy layout
+				for j := 0; j < 9; j++ {
+					fields = append(fields, []byte{})
+				}
+			}
+			if i == len(bundle)-1 && !withRP {
+				break
+			}
+			fields = append(fields, *field)
+		}
+		blob, err := rlp.EncodeToBytes(append(fields, big.NewInt(37), big.NewInt(1), big.NewInt(1), []byte{}))
+		if err != nil {
+			t.Fatalf("failed to encode legacy transfer: %v", err)
+		}
+		tx := new(Transaction)
+		if err := rlp.DecodeBytes(blob, tx); err != nil {
+			t.Fatalf("failed to decode legacy transfer (rp %v): %v", withRP, err)
+		}
+		if tx.ID() != 0 || tx.Transfer() == nil || tx.Version() != LegacyPrivacyVersion {
+			t.Fatalf("legacy transfer not recognised (rp %v)", withRP)
+		}
+		if reenc, _ := rlp.EncodeToBytes(tx); !bytes.Equal(reenc, blob) {
+			t.Errorf("legacy transfer re-encoded differently (rp %v)", withRP)
+		}
+		if tx.Hash() != crypto.Keccak256Hash(blob) {
+			t.Errorf("legacy transfer hash changed (rp %v)", withRP)
+		}
+		v, r, s := tx.RawSignatureValues()
+		typed, _ := rlp.EncodeToBytes([]interface{}{uint64(0), big.NewInt(1), uint64(21000), &to, new(big.Int), []byte{}, TransferTxType, LegacyPrivacyVersion, tx.Privacy(), v, r, s, []byte{}})
+		retyped := new(Transaction)
+		if err := rlp.DecodeBytes(typed, retyped); err != nil {
+			t.Fatalf("failed to decode typed envelope: %v", err)
+		}
+		if _, err := retyped.PrivacyPayload(); err != ErrPrivacyVersion {
+			t.Errorf("legacy version in the typed envelope (rp %v): have %v, want %v", withRP, err, ErrPrivacyVersion)
+		}
+	}
+}
@@ -2,12 +2,16 @@ package types
 
 import (
 	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"maskchain/privacy/ecc"
 )
 
+// Regulator is the regulator whose key the commitments and the range proofs
+// of transfers are made under.
 type Regulator struct {
 	PubK PubKey
-	IP   string
-	Port int
 }
 
 type PubKey struct {
@@ -16,8 +20,43 @@ type PubKey struct {
 	P  *big.Int
 	H  *big.Int
 }
+
+// Exchange is the exchange whose key signs purchases.
 type Exchange struct {
 	PubKey PubKey
-	IP     string
-	Port   int
+}
+
+// NewRegulator returns the regulator with the key of the chain config or of a
+// key rotation. The key is empty for a nil key.
+func NewRegulator(key *params.PrivacyKey) Regulator {
+	return Regulator{PubK: configPubKey(key)}
+}
+
+// NewExchange returns the exchange with the key of the chain config or of a
+// key rotation. The key is empty for a nil key.
+func NewExchange(key *params.PrivacyKey) Exchange {
+	return Exchange{PubKey: configPubKey(key)}
+}
+
+func configPubKey(key *params.PrivacyKey) PubKey {
+	if key == nil {
+		return PubKey{}
+	}
+	return PubKey{
+		G1: new(big.Int).SetBytes(key.G1),
+		G2: new(big.Int).SetBytes(key.G2),
+		P:  new(big.Int).Set(ecc.EC.N),
+		H:  new(big.Int).SetBytes(key.H),
+	}
+}
+
+// ConfigKey returns the key as pinned by the chain config from block num on.
+func (pub PubKey) ConfigKey(num *big.Int) params.PrivacyKey {
+	point := func(x *big.Int) []byte {
+		if x == nil {
+			return make([]byte, 65)
+		}
+		return common.LeftPadBytes(x.Bytes(), 65)
+	}
+	return params.PrivacyKey{Block: new(big.Int).Set(num), G1: point(pub.G1), G2: point(pub.G2), H: point(pub.H)}
 }
@@ -47,17 +47,26 @@ var (
 		common.FromHex("5544"),
 	).WithSignature(
 		HomesteadSigner{},
-		common.Hex2Bytes("98ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4a8887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a301"),
+		common.Hex2Bytes("98ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4a8887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a3010000000000000000000000000000000000000000000000000000000000000000"),
 	)
 )
 
 func TestTransactionSigHash(t *testing.T) {
 	var homestead HomesteadSigner
-	if homestead.Hash(emptyTx) != common.HexToHash("c775b99e7ad12f50d819fcd602390467e28141316969f4b57f0626f74fe3b386") {
-		t.Errorf("empty transaction hash mismatch, got %x", emptyTx.Hash())
+	// Typed transactions also sign their privacy type, version and payload
+	if hash := homestead.Hash(emptyTx); hash != common.HexToHash("ab05d72add897c36742abbbd13a6eb2aca414aecb1bae01b8c540a9c8da5e67f") {
+		t.Errorf("empty transaction hash mismatch, got %x", hash)
 	}
-	if homestead.Hash(rightvrsTx) != common.HexToHash("fe7a79529ed5f7c3375d06b26b186a8644e0e16c373d7a12be41c62d6042b77a") {
-		t.Errorf("RightVRS transaction hash mismatch, got %x", rightvrsTx.Hash())
+	if hash := homestead.Hash(rightvrsTx); hash != common.HexToHash("7565cea13745d20d0f527eaadfac54c165619f48b73de6dc596b71f75a6b2b43") {
+		t.Errorf("RightVRS transaction hash mismatch, got %x", hash)
+	}
+	// Legacy transactions keep the upstream signing hash
+	legacy, err := decodeUpstreamTx(common.FromHex("f86103018207d094b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a8255441ca098ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4aa08887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a3"))
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if hash := homestead.Hash(legacy); hash != common.HexToHash("fe7a79529ed5f7c3375d06b26b186a8644e0e16c373d7a12be41c62d6042b77a") {
+		t.Errorf("legacy RightVRS transaction hash mismatch, got %x", hash)
 	}
 }
 
@@ -66,17 +75,17 @@ func TestTransactionEncode(t *testing.T) {
 	if err != nil {
 		t.Fatalf("encode error: %v", err)
 	}
-	should := common.FromHex("f86103018207d094b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a8255441ca098ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4aa08887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a3")
+	should, err := encodeUpstreamTx(common.FromHex("f86103018207d094b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a8255441ca098ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4aa08887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a3"))
+	if err != nil {
+		t.Fatalf("fixture error: %v", err)
+	}
 	if !bytes.Equal(txb, should) {
 		t.Errorf("encoded RLP mismatch, got %x", txb)
 	}
 }
 
 func decodeTx(data []byte) (*Transaction, error) {
-	var tx Transaction
-	t, err := &tx, rlp.Decode(bytes.NewReader(data), &tx)
-
-	return t, err
+	return decodeUpstreamTx(data)
 }
 
 func defaultTestKey() (*ecdsa.PrivateKey, common.Address) {
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// upstreamTx is the Ethereum transaction encoding the RLP fixtures of this
+// package are taken from.
+type upstreamTx struct {
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	V, R, S      *big.Int
+}
+
+// transaction converts an Ethereum transaction into a plain transaction,
+// keeping its signature.
+func (dec *upstreamTx) transaction() *Transaction {
+	var tx *Transaction
+	if dec.Recipient == nil {
+		tx = NewContractCreation(dec.AccountNonce, dec.Amount, dec.GasLimit, dec.Price, dec.Payload)
+	} else {
+		tx = NewTransaction(dec.AccountNonce, *dec.Recipient, dec.Amount, dec.GasLimit, dec.Price, dec.Payload)
+	}
+	tx.data.V, tx.data.R, tx.data.S = dec.V, dec.R, dec.S
+	return tx
+}
+
+// decodeUpstreamTx decodes an Ethereum encoded transaction into a plain
+// transaction of the flat legacy layout. Legacy transactions keep the upstream
+// signing hash, so the senders of the fixtures can still be recovered.
+func decodeUpstreamTx(data []byte) (*Transaction, error) {
+	var dec upstreamTx
+	if err := rlp.DecodeBytes(data, &dec); err != nil {
+		return nil, err
+	}
+	fields := []interface{}{dec.AccountNonce, dec.Price, dec.GasLimit, dec.Recipient, dec.Amount, dec.Payload, uint64(PlainTxType)}
+	for len(fields) < legacyTxFields-4 {
+		fields = append(fields, []byte{})
+	}
+	enc, err := rlp.EncodeToBytes(append(fields, dec.V, dec.R, dec.S, []byte{}))
+	if err != nil {
+		return nil, err
+	}
+	tx := new(Transaction)
+	return tx, rlp.DecodeBytes(enc, tx)
+}
+
+// encodeUpstreamTx re-encodes an Ethereum encoded transaction in the encoding
+// of this chain.
+func encodeUpstreamTx(data []byte) ([]byte, error) {
+	var dec upstreamTx
+	if err := rlp.DecodeBytes(data, &dec); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(dec.transaction())
+}
+
+// encodeUpstreamBlock re-encodes the transactions of an Ethereum encoded block
+// in the encoding of this chain.
+func encodeUpstreamBlock(data []byte) ([]byte, error) {
+	var dec struct {
+		Header *Header
+		Txs    []upstreamTx
+		Uncles []*Header
+	}
+	if err := rlp.DecodeBytes(data, &dec); err != nil {
+		return nil, err
+	}
+	txs := make([]*Transaction, len(dec.Txs))
+	for i := range dec.Txs {
+		txs[i] = dec.Txs[i].transaction()
+	}
+	return rlp.EncodeToBytes(extblock{Header: dec.Header, Txs: txs, Uncles: dec.Uncles})
+}
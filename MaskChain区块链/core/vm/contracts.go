@@ -107,8 +107,12 @@ func (c *ecrecover) Run(input []byte) ([]byte, error) {
 	if !allZero(input[32:63]) || !crypto.ValidateSignatureValues(v, r, s, false) {
 		return nil, nil
 	}
-	// v needs to be at the end for libsecp256k1
-	pubKey, err := crypto.Ecrecover(input[:32], append(input[64:128], v))
+	// v needs to be at the end for libsecp256k1, copy the signature out so the
+	// caller's input is not overwritten
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, input[64:128])
+	sig[64] = v
+	pubKey, err := crypto.Ecrecover(input[:32], sig)
 	// make sure the public key is a valid one
 	if err != nil {
 		return nil, nil
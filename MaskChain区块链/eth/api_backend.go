@@ -314,10 +314,25 @@ func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 	}
 }
 
+// RegulatorKey returns the regulator with the key in effect for the pending
+// block, as pinned by the chain config, rotated by governance or configured
+// as legacy key.
 func (b *EthAPIBackend) RegulatorKey() types.Regulator {
-	return b.eth.config.Regulator
+	return types.NewRegulator(b.pendingKey(types.RegulatorKeyKind))
 }
 
+// ExchangeKey returns the exchange with the key in effect for the pending
+// block, see RegulatorKey.
 func (b *EthAPIBackend) ExchangeKey() types.Exchange {
-	return b.eth.config.Exchange
+	return types.NewExchange(b.pendingKey(types.ExchangeKeyKind))
+}
+
+func (b *EthAPIBackend) pendingKey(kind uint8) *params.PrivacyKey {
+	next := new(big.Int).Add(b.CurrentBlock().Number(), big.NewInt(1))
+	legacy := b.eth.blockchain.LegacyKeys()
+	statedb, err := b.eth.blockchain.State()
+	if err != nil {
+		return legacy.KeyAt(b.ChainConfig(), nil, kind, next)
+	}
+	return legacy.KeyAt(b.ChainConfig(), statedb, kind, next)
 }
@@ -18,7 +18,6 @@
 package eth
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -218,6 +217,29 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := core.CheckPrivacyKeys(chainConfig); err != nil {
+		return nil, err
+	}
+	// Legacy keys stand in for the keys the chain config does not pin before
+	// the pinned keys fork, so that chains initialised without keys can move
+	// to pinned ones.
+	legacyKeys := core.NewLegacyKeys(config.Regulator, config.Exchange)
+	if legacyKeys.Regulator != nil {
+		if err := core.CheckRegulatorKey(config.Regulator, chainConfig.PrivacyGenerators); err != nil {
+			return nil, err
+		}
+	}
+	if legacyKeys.Regulator != nil || legacyKeys.Exchange != nil {
+		log.Info("Using legacy keys where the chain config pins none", "until", chainConfig.PinnedKeysBlock)
+	}
+	eth.blockchain.SetLegacyKeys(legacyKeys)
+	config.TxPool.LegacyKeys = legacyKeys
+	if (len(chainConfig.RegulatorKeys) == 0 && legacyKeys.Regulator == nil) || (len(chainConfig.ExchangeKeys) == 0 && legacyKeys.Exchange == nil) {
+		log.Warn("Chain config pins no regulator or exchange key, privacy transactions will be rejected")
+	}
+	if chainConfig.CMPoolBlock == nil {
+		log.Warn("Chain config schedules no commitment pool fork, privacy transactions will be rejected")
+	}
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
@@ -232,8 +254,6 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
 	// 初始化eth 区块链的交易池，存储本地生产的和P2P网络同步过来的交易。
-	config.TxPool.Exchange = config.Exchange
-	config.TxPool.Regulator = config.Regulator
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -615,22 +635,20 @@ func (s *Ethereum) Stop() error {
 	return nil
 }
 
-// CountCm 返回有效承诺的个数和无效承诺的个数
+// CountCm 返回有效承诺的个数和无效承诺的个数。隐匿花费不暴露被花费的承诺，
+// 因此无效承诺的个数即零化符的个数，有效承诺的个数为承诺总数与之的差
 func (s *Ethereum) CountCm() (int, int) {
-	valid := 0
-	invalid := 0
 	CMdb := s.CMDb()
-	it := CMdb.NewIterator()
-	defer it.Release()
-
-	for it.Next() {
-		CM := new(types.CM)
-		value := it.Value()
-		if _ = rlp.Decode(bytes.NewReader(value), CM); CM.Spent == false {
-			valid += 1
-		} else {
-			invalid += 1
+	count := func(prefix []byte) int {
+		it := CMdb.NewIteratorWithPrefix(prefix)
+		defer it.Release()
+
+		n := 0
+		for it.Next() {
+			n++
 		}
+		return n
 	}
-	return valid, invalid
+	cms, nullifiers := count(rawdb.CMHashPrefix), count(rawdb.CMNullifierPrefix)
+	return cms - nullifiers, nullifiers
 }
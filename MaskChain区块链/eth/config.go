@@ -169,9 +169,8 @@ type Config struct {
 	// cryptotype gm or gj
 	CryptoType  uint8
 
-	//监管者
-	Regulator types.Regulator
-
-	//the handsome exchange
-	Exchange types.Exchange
+	// 监管者与交易所的旧版公钥，启动时向其服务请求获得。仅在pinnedKeysBlock之前、
+	// 链配置未固定密钥的区块中代替链配置的密钥，见core.LegacyKeys。
+	Regulator types.Regulator `toml:"-"`
+	Exchange  types.Exchange  `toml:"-"`
 }
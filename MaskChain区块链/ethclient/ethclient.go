@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/privtx"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -517,6 +518,34 @@ func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) er
 	return ec.c.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
 }
 
+// SendPrivateTransaction submits a transfer whose ciphertexts and proofs were
+// built locally with the privtx package. The node signs it with the unlocked
+// account msg.From, missing gas, gas price and nonce are filled in by the node.
+func (ec *Client) SendPrivateTransaction(ctx context.Context, msg ethereum.CallMsg, proofs *privtx.Proofs) (common.Hash, error) {
+	arg := struct {
+		From     common.Address  `json:"from"`
+		To       *common.Address `json:"to"`
+		Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+		GasPrice *hexutil.Big    `json:"gasPrice,omitempty"`
+		Value    *hexutil.Big    `json:"value,omitempty"`
+		Data     hexutil.Bytes   `json:"data,omitempty"`
+		*privtx.Proofs
+	}{
+		From:     msg.From,
+		To:       msg.To,
+		GasPrice: (*hexutil.Big)(msg.GasPrice),
+		Value:    (*hexutil.Big)(msg.Value),
+		Data:     msg.Data,
+		Proofs:   proofs,
+	}
+	if msg.Gas != 0 {
+		arg.Gas = (*hexutil.Uint64)(&msg.Gas)
+	}
+	var hash common.Hash
+	err := ec.c.CallContext(ctx, &hash, "eth_sendPrivateTransaction", arg)
+	return hash, err
+}
+
 func toCallArg(msg ethereum.CallMsg) interface{} {
 	arg := map[string]interface{}{
 		"from": msg.From,
@@ -69,4 +69,7 @@ require (
 	gopkg.in/olebedev/go-duktape.v3 v3.0.0-20190213234257-ec84240a7772
 	gopkg.in/urfave/cli.v1 v1.20.0
 	gotest.tools v2.2.0+incompatible // indirect
+	maskchain/privacy v0.0.0
 )
+
+replace maskchain/privacy => ../MaskChain隐私计算
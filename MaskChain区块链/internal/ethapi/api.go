@@ -19,7 +19,6 @@ package ethapi
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
 	"errors"
 	"fmt"
 	"github.com/davecgh/go-spew/spew"
@@ -36,13 +35,14 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
-	ecc "github.com/ethereum/go-ethereum/crypto/ECC"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/privtx"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/tyler-smith/go-bip39"
+	"maskchain/privacy/ecc"
 	Math "math"
 	"math/big"
 	"strconv"
@@ -618,6 +618,56 @@ func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Addre
 	}, state.Error()
 }
 
+// CommitmentRing is a ring of the commitment accumulator a shielded transfer
+// can spend from, together with the anchor it is taken below.
+type CommitmentRing struct {
+	Anchor  common.Hash     `json:"anchor"`
+	Size    hexutil.Uint64  `json:"size"`    // Size of the accumulator at the anchor
+	Ring    hexutil.Uint64  `json:"ring"`    // Ring number
+	Members []hexutil.Bytes `json:"members"` // ecc.RingSize members, empty for positions without a commitment
+	Keys    []hexutil.Bytes `json:"keys"`    // Spend keys of the members
+}
+
+// GetCommitmentRing returns ring number ring of the commitment accumulator
+// below its root at the given block.
+func (s *PublicBlockChainAPI) GetCommitmentRing(ctx context.Context, ring hexutil.Uint64, blockNrOrHash rpc.BlockNumberOrHash) (*CommitmentRing, error) {
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	acc := state.Accumulator()
+	from, to, ok := ecc.RingBounds(uint64(ring), acc.Size)
+	if !ok {
+		return nil, fmt.Errorf("ring %d beyond accumulator size %d", ring, acc.Size)
+	}
+	result := &CommitmentRing{
+		Anchor:  acc.Root(),
+		Size:    hexutil.Uint64(acc.Size),
+		Ring:    ring,
+		Members: make([]hexutil.Bytes, ecc.RingSize),
+		Keys:    make([]hexutil.Bytes, ecc.RingSize),
+	}
+	for i := from; i < to; i++ {
+		result.Members[i-from], result.Keys[i-from] = state.AccumulatorLeaf(i)
+	}
+	return result, state.Error()
+}
+
+// GetCommitmentIndex returns the index of a commitment in the accumulator at
+// the given block, nil if it is not in the accumulator. Note, the node learns
+// which commitment the caller is interested in.
+func (s *PublicBlockChainAPI) GetCommitmentIndex(ctx context.Context, cm hexutil.Bytes, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	index, ok := state.AccumulatorIndex(cm)
+	if !ok {
+		return nil, state.Error()
+	}
+	return (*hexutil.Uint64)(&index), state.Error()
+}
+
 // GetHeaderByNumber returns the requested canonical block header.
 // * When blockNr is -1 the chain head is returned.
 // * When blockNr is -2 the pending chain head is returned.
@@ -1030,7 +1080,7 @@ func FormatLogs(logs []vm.StructLog) []StructLogRes {
 
 // RPCMarshalHeader converts the given header to the RPC output .
 func RPCMarshalHeader(head *types.Header) map[string]interface{} {
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"number":           (*hexutil.Big)(head.Number),
 		"hash":             head.Hash(),
 		"parentHash":       head.ParentHash,
@@ -1049,6 +1099,11 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 		"transactionsRoot": head.TxHash,
 		"receiptsRoot":     head.ReceiptHash,
 	}
+	// Headers carry the commitment root from the commitment pool fork on
+	if head.CMRoot != nil {
+		result["commitmentsRoot"] = head.CMRoot
+	}
+	return result
 }
 
 // RPCMarshalBlock converts the given block to the RPC output which depends on fullTx. If inclTx is true transactions are
@@ -1125,69 +1180,8 @@ type RPCTransaction struct {
 	R                *hexutil.Big    `json:"r"`
 	S                *hexutil.Big    `json:"s"`
 	ID               hexutil.Uint64  `json:"ID"`
-	ErpkC1           *hexutil.Bytes  `json:"erpkc1"`
-	ErpkC2           *hexutil.Bytes  `json:"erpkc2"`
-	EspkC1           *hexutil.Bytes  `json:"espkc1"`
-	EspkC2           *hexutil.Bytes  `json:"espkc2"`
-	CMRpk            *hexutil.Bytes  `json:"cmrpk"`
-	CMSpk            *hexutil.Bytes  `json:"cmspk"`
-	RpkEPg1          *hexutil.Bytes  `json:"rpkepg1"` //接收方地址公钥相等证明字段g1
-	RpkEPg2      	 *hexutil.Bytes  `json:"rpkepg2"` //接收方地址公钥相等证明字段g2
-	RpkEPy1     	 *hexutil.Bytes  `json:"rpkepy1"` //接收方地址公钥相等证明字段y1
-	RpkEPy2    	     *hexutil.Bytes  `json:"rpkepy2"` //接收方地址公钥相等证明字段y2
-	RpkEPt1      	 *hexutil.Bytes  `json:"rpkept1"` //接收方地址公钥相等证明字段t1
-	RpkEPt2      	 *hexutil.Bytes  `json:"rpkept2"` //接收方地址公钥相等证明字段t2
-	RpkEPs       	 *hexutil.Bytes  `json:"rpkeps"` //接收方地址公钥相等证明字段s
-	RpkEPc       	 *hexutil.Bytes  `json:"rpkepc"` //接收方地址公钥相等证明字段c
-	SpkEPg1      	 *hexutil.Bytes  `json:"spkepg1"` //发送方地址公钥相等证明字段g1
-	SpkEPg2      	 *hexutil.Bytes  `json:"spkepg2"` //发送方地址公钥相等证明字段g2
-	SpkEPy1      	 *hexutil.Bytes  `json:"spkepy1"` //发送方地址公钥相等证明字段y1
-	SpkEPy2      	 *hexutil.Bytes  `json:"spkepy2"` //发送方地址公钥相等证明字段y2
-	SpkEPt1      	 *hexutil.Bytes  `json:"spkept1"` //发送方地址公钥相等证明字段t1
-	SpkEPt2      	 *hexutil.Bytes  `json:"spkept2"` //发送方地址公钥相等证明字段t2
-	SpkEPs       	 *hexutil.Bytes  `json:"spkeps"` //发送方地址公钥相等证明字段s
-	SpkEPc       	 *hexutil.Bytes  `json:"spkepc"` //发送方地址公钥相等证明字段c
-	EvSC1            *hexutil.Bytes  `json:"evsc1"`
-	EvSC2            *hexutil.Bytes  `json:"evsc2"`
-	EvRC1            *hexutil.Bytes  `json:"evrc1"`
-	EvRC2            *hexutil.Bytes  `json:"evrc2"`
-	CmS              *hexutil.Bytes  `json:"cms"`
-	CmR              *hexutil.Bytes  `json:"cmr"`
-	ScmFPg1      	 *hexutil.Bytes  `json:"scmfpg1"` //发送金额承诺格式证明字段g1
-	ScmFPg2      	 *hexutil.Bytes  `json:"scmfpg2"` //发送金额承诺格式证明字段g2
-	ScmFPy1      	 *hexutil.Bytes  `json:"scmfpy1"` //发送金额承诺格式证明字段y1
-	ScmFPy2      	 *hexutil.Bytes  `json:"scmfpy2"` //发送金额承诺格式证明字段y2
-	ScmFPt1      	 *hexutil.Bytes  `json:"scmfpt1"` //发送金额承诺格式证明字段t1
-	ScmFPt2      	 *hexutil.Bytes  `json:"scmfpt2"` //发送金额承诺格式证明字段t2
-	ScmFPs       	 *hexutil.Bytes  `json:"scmfps"` //发送金额承诺格式证明字段s
-	ScmFPc       	 *hexutil.Bytes  `json:"scmfpc"` //发送金额承诺格式证明字段c
-	RcmFPg1      	 *hexutil.Bytes  `json:"rcmfpg1"` //接收金额承诺格式证明字段g1
-	RcmFPg2      	 *hexutil.Bytes  `json:"rcmfpg2"` //接收金额承诺格式证明字段g2
-	RcmFPy1      	 *hexutil.Bytes  `json:"rcmfpy1"` //接收金额承诺格式证明字段y1
-	RcmFPy2      	 *hexutil.Bytes  `json:"rcmfpy2"` //接收金额承诺格式证明字段y2
-	RcmFPt1      	 *hexutil.Bytes  `json:"rcmfpt1"` //接收金额承诺格式证明字段t1
-	RcmFPt2      	 *hexutil.Bytes  `json:"rcmfpt2"` //接收金额承诺格式证明字段t2
-	RcmFPs       	 *hexutil.Bytes  `json:"rcmfps"` //接收金额承诺格式证明字段s
-	RcmFPc       	 *hexutil.Bytes  `json:"rcmfpc"` //接收金额承诺格式证明字段c
-	EvsBsC1          *hexutil.Bytes  `json:"evsbsc1"`
-	EvsBsC2          *hexutil.Bytes  `json:"evsbsc2"`
-	EvOC1            *hexutil.Bytes  `json:"evoc1"`
-	EvOC2            *hexutil.Bytes  `json:"evoc2"`
-	CmO              *hexutil.Bytes  `json:"cmo"`
-	VoEPg1       	 *hexutil.Bytes  `json:"voepg1"` //被花费承诺相等证明字段g1
-	VoEPg2       	 *hexutil.Bytes  `json:"voepg2"` //被花费承诺相等证明字段g2
-	VoEPy1       	 *hexutil.Bytes  `json:"voepy1"` //被花费承诺相等证明字段y1
-	VoEPy2       	 *hexutil.Bytes  `json:"voepy2"` //被花费承诺相等证明字段y2
-	VoEPt1       	 *hexutil.Bytes  `json:"voept1"` //被花费承诺相等证明字段t1
-	VoEPt2       	 *hexutil.Bytes  `json:"voept2"` //被花费承诺相等证明字段t2
-	VoEPs        	 *hexutil.Bytes  `json:"voeps"` //被花费承诺相等证明字段s
-	VoEPc        	 *hexutil.Bytes  `json:"voepc"` //被花费承诺相等证明字段c
-	BPy          	 *hexutil.Bytes  `json:"bpy"` //会计平衡证明字段y
-	BPt          	 *hexutil.Bytes  `json:"bpt"` //会计平衡证明字段t
-	BPsn1        	 *hexutil.Bytes  `json:"bpsn1"` //会计平衡证明字段sn1
-	BPsn2        	 *hexutil.Bytes  `json:"bpsn2"` //会计平衡证明字段sn2
-	BPsn3        	 *hexutil.Bytes  `json:"bpsn3"` //会计平衡证明字段sn3
-	BPc          	 *hexutil.Bytes  `json:"bpc"` //会计平衡证明字段c
+	Version          hexutil.Uint64  `json:"version"`
+	*privtx.Proofs                   // 转账交易的密文、承诺和证明
 	EpkrC1           *hexutil.Bytes  `json:"epkrc1"`
 	EpkrC2           *hexutil.Bytes  `json:"epkrc2"`
 	EpkpC1           *hexutil.Bytes  `json:"epkpc1"`
@@ -1197,10 +1191,10 @@ type RPCTransaction struct {
 	SigR             *hexutil.Bytes  `json:"sigr"`
 	SigS             *hexutil.Bytes  `json:"sigs"`
 	CmV              *hexutil.Bytes  `json:"cmv"`
-	CmSRC1           *hexutil.Bytes  `json:"cmsrc1"`
-	CmSRC2           *hexutil.Bytes  `json:"cmsrc2"`
-	CmRRC1           *hexutil.Bytes  `json:"cmrrc1"`
-	CmRRC2           *hexutil.Bytes  `json:"cmrrc2"`
+	SpendKey         *hexutil.Bytes  `json:"spendkey,omitempty"` // 购币承诺的花费公钥
+	ExC1             *hexutil.Bytes  `json:"exc1,omitempty"`     // 赎回交易中交易所公钥下的金额密文
+	ExC2             *hexutil.Bytes  `json:"exc2,omitempty"`
+	Privacy          *hexutil.Bytes  `json:"privacy,omitempty"` // 多输入多输出转账、隐匿转账与赎回的RLP编码隐私数据
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1226,82 +1220,32 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		R:        (*hexutil.Big)(r),
 		S:        (*hexutil.Big)(s),
 		ID:       hexutil.Uint64(tx.ID()),
-		ErpkC1:   tx.ErpkC1(),
-		ErpkC2:   tx.ErpkC2(),
-		EspkC1:   tx.EspkC1(),
-		EspkC2:   tx.EspkC2(),
-		CMRpk:    tx.CMRpk(),
-		CMSpk:    tx.CMSpk(),
-		RpkEPg1:  tx.RpkEPg1(),
-		RpkEPg2:  tx.RpkEPg2(),
-		RpkEPy1:  tx.RpkEPy1(),
-		RpkEPy2:  tx.RpkEPy2(),
-		RpkEPt1:  tx.RpkEPt1(),
-		RpkEPt2:  tx.RpkEPt2(),
-		RpkEPs:   tx.RpkEPs(),
-		RpkEPc:   tx.RpkEPc(),
-		SpkEPg1:  tx.SpkEPg1(),
-		SpkEPg2:  tx.SpkEPg2(),
-		SpkEPy1:  tx.SpkEPy1(),
-		SpkEPy2:  tx.SpkEPy2(),
-		SpkEPt1:  tx.SpkEPt1(),
-		SpkEPt2:  tx.SpkEPt2(),
-		SpkEPs:   tx.SpkEPs(),
-		SpkEPc:   tx.SpkEPc(),
-		EvSC1:    tx.EvSC1(),
-		EvSC2:    tx.EvSC2(),
-		EvRC1:    tx.EvRC1(),
-		EvRC2:    tx.EvRC2(),
-		CmS:      tx.CmS(),
-		CmR:      tx.CmR(),
-		ScmFPg1:  tx.ScmFPg1(),
-		ScmFPg2:  tx.ScmFPg2(),
-		ScmFPy1:  tx.ScmFPy1(),
-		ScmFPy2:  tx.ScmFPy2(),
-		ScmFPt1:  tx.ScmFPt1(),
-		ScmFPt2:  tx.ScmFPt2(),
-		ScmFPs:   tx.ScmFPs(),
-		ScmFPc:   tx.ScmFPc(),
-		RcmFPg1:  tx.RcmFPg1(),
-		RcmFPg2:  tx.RcmFPg2(),
-		RcmFPy1:  tx.RcmFPy1(),
-		RcmFPy2:  tx.RcmFPy2(),
-		RcmFPt1:  tx.RcmFPt1(),
-		RcmFPt2:  tx.RcmFPt2(),
-		RcmFPs:   tx.RcmFPs(),
-		RcmFPc:   tx.RcmFPc(),
-		EvsBsC1:  tx.EvsBsC1(),
-		EvsBsC2:  tx.EvsBsC2(),
-		EvOC1:    tx.EvOC1(),
-		EvOC2:    tx.EvOC2(),
-		CmO:      tx.CmO(),
-		VoEPg1:   tx.VoEPg1(),
-		VoEPg2:   tx.VoEPg2(),
-		VoEPy1:   tx.VoEPy1(),
-		VoEPy2:   tx.VoEPy2(),
-		VoEPt1:   tx.VoEPt1(),
-		VoEPt2:   tx.VoEPt2(),
-		VoEPs:    tx.VoEPs(),
-		VoEPc:    tx.VoEPc(),
-		BPy:      tx.BPy(),
-		BPt:      tx.BPt(),
-		BPsn1:    tx.BPsn1(),
-		BPsn2:    tx.BPsn2(),
-		BPsn3:    tx.BPsn3(),
-		BPc:      tx.BPc(),
-		EpkrC1:   tx.EpkrC1(),
-		EpkrC2:   tx.EpkrC2(),
-		EpkpC1:   tx.EpkpC1(),
-		EpkpC2:   tx.EpkpC2(),
-		SigM:     tx.SigM(),
-		SigMHash: tx.SigMHash(),
-		SigR:     tx.SigR(),
-		SigS:     tx.SigS(),
-		CmV:      tx.CmV(),
-		CmSRC1:   tx.CmSRC1(),
-		CmSRC2:   tx.CmSRC2(),
-		CmRRC1:   tx.CmRRC1(),
-		CmRRC2:   tx.CmRRC2(),
+		Version:  hexutil.Uint64(tx.Version()),
+	}
+	if p := tx.Transfer(); p != nil {
+		result.Proofs = privtx.NewProofs(p)
+	}
+	if tx.MultiTransfer() != nil || tx.ShieldedTransfer() != nil || tx.Redeem() != nil {
+		privacy := hexutil.Bytes(tx.Privacy())
+		result.Privacy = &privacy
+	}
+	if p := tx.Redeem(); p != nil {
+		result.ExC1 = (*hexutil.Bytes)(&p.Ex.C1)
+		result.ExC2 = (*hexutil.Bytes)(&p.Ex.C2)
+	}
+	if p := tx.Purchase(); p != nil {
+		result.EpkrC1 = (*hexutil.Bytes)(&p.Epkr.C1)
+		result.EpkrC2 = (*hexutil.Bytes)(&p.Epkr.C2)
+		result.EpkpC1 = (*hexutil.Bytes)(&p.Epkp.C1)
+		result.EpkpC2 = (*hexutil.Bytes)(&p.Epkp.C2)
+		result.SigM = (*hexutil.Bytes)(&p.Sig.M)
+		result.SigMHash = (*hexutil.Bytes)(&p.Sig.MHash)
+		result.SigR = (*hexutil.Bytes)(&p.Sig.R)
+		result.SigS = (*hexutil.Bytes)(&p.Sig.S)
+		result.CmV = (*hexutil.Bytes)(&p.CmV)
+		if len(p.SpendKeys) > 0 {
+			result.SpendKey = (*hexutil.Bytes)(&p.SpendKeys[0])
+		}
 	}
 	if blockHash != (common.Hash{}) {
 		result.BlockHash = &blockHash
@@ -1648,6 +1592,7 @@ type SendTxArgs struct {
 	SigR     *hexutil.Bytes  `json:"sigr"`
 	SigS     *hexutil.Bytes  `json:"sigs"`
 	CmV      *hexutil.Bytes  `json:"cmv"`
+	SpendKey *hexutil.Bytes  `json:"spendkey"` // 购币承诺的花费公钥，隐匿分叉起必须携带
 	CmSRC1   *hexutil.Bytes  `json:"cmsrc1"`
 	CmSRC2   *hexutil.Bytes  `json:" cmsrc2"`
 	CmRRC1   *hexutil.Bytes  `json:" cmrrc1"`
@@ -1713,28 +1658,6 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	}
 	return nil
 }
-func paraPK(pk string) (ecc.PublicKey, error) {
-	if len(pk) != 256 {
-		//return errors.New(`public key length should be 256`)
-	}
-	P, _ := new(big.Int).SetString(pk[:64], 16)
-	G1, _ := new(big.Int).SetString(pk[64:193], 16)
-	G2, _ := new(big.Int).SetString(pk[193:322], 16)
-	H, _ := new(big.Int).SetString(pk[322:], 16)
-	pubK := ecc.PublicKey{
-		P:  P,
-		G1: G1,
-		G2: G2,
-		H:  H,
-	}
-	return pubK, nil
-}
-func Hash(str string) []byte {
-	//使用sha256哈希函数
-	h := sha256.New()
-	h.Write([]byte(str))
-	return h.Sum(nil)
-}
 func (args *SendTxArgs) checkParameter() error {
 	lackofParameterError := errors.New(`lack of parameter`)
 	if args.ID == nil {
@@ -1757,198 +1680,54 @@ func (args *SendTxArgs) checkParameter() error {
 
 	return nil
 }
-func (args *SendTxArgs) toZeroTransaction(regulator types.Regulator) (*types.Transaction, error) {
-
-	Vs := uint64(*args.Vs)
-	Vr := uint64(*args.Vr)
-	Rpk, _ := paraPK(*args.Rpk)                    // 接收方公钥，不写入交易
-	Spk, _ := paraPK(*args.Spk)                    // 发送方公钥，不写入交易
-	VoR := []byte(*args.VoR)                       // 被花费承诺的随机数，不写入交易
-	CmO := []byte(*args.CmO)                       // 被花费承诺
-	regulatorPubk := ecc.PublicKey(regulator.PubK) // 监管者公钥，不写入交易
-	addrpkt := new(big.Int).SetBytes(Hash(*args.Rpk))
-	addrpk := addrpkt.Mod(addrpkt, regulatorPubk.P).Bytes() //接收方地址公钥，不写入交易
-	addspkt := new(big.Int).SetBytes(Hash(*args.Spk))
-	addspk := addspkt.Mod(addspkt, regulatorPubk.P).Bytes() //发送方地址公钥，不写入交易
-	// 加密并承诺双方地址公钥
-	Erpk, _CMrpk, _ := ecc.EncryptAddress(regulatorPubk, addrpk)
-	Espk, _CMspk, _ := ecc.EncryptAddress(regulatorPubk, addspk)
-	_, CMrpk, _ := ecc.EncryptAddress(regulatorPubk, addrpk)
-	_, CMspk, _ := ecc.EncryptAddress(regulatorPubk, addspk)
-	// 双方地址公钥相等证明
-	RpkEP := ecc.GenerateAddressEqualityProof(regulatorPubk, regulatorPubk, CMrpk, _CMrpk, addrpk)
-	SpkEP := ecc.GenerateAddressEqualityProof(regulatorPubk, regulatorPubk, CMspk, _CMspk, addspk)
-	// 花费额承诺，格式正确证明
-	EvS, CmS, _ := ecc.EncryptValue(regulatorPubk, Vs)
-	ScmFP := ecc.GenerateFormatProof(regulatorPubk, Vs, CmS.R, EvS)
-	Evs, _, _ := ecc.EncryptValue(Rpk, Vs) // 接收方公钥加密发送金额
-	CmSR := ecc.Encrypt(Rpk, CmS.R)
-	// 找零承诺，格式正确证明
-	EvR, CmR, _ := ecc.EncryptValue(regulatorPubk, Vr)
-	RcmFP := ecc.GenerateFormatProof(regulatorPubk, Vr, CmR.R, EvR)
-	CmRR := ecc.Encrypt(Spk, CmR.R)
-	// 总花费额，由找零和发出相加求得
-	EvO, CMo, _ := ecc.EncryptValue(regulatorPubk, Vr+Vs)
-	// 总额度相等证明
-	VoEP := ecc.GenerateEqualityProof(regulatorPubk, regulatorPubk, CMo, ecc.Commitment{
-		Commitment: CmO,
-		R:          VoR,
-	}, uint(Vr+Vs))
-	// 会计平衡证明
-	BP := ecc.GenerateBalanceProof(Vr, Vs, Vr+Vs, CmR.Commitment, CmS.Commitment, CmO)
-	// 将需要编码进入交易的量转换成*big.Int或*hexutil.Uint64或*hexutil.Bytes
-	// CmO是 *hexutil.Bytes，不需编码
-	ErpkC1, ErpkC2 := hexutil.Bytes(Erpk.C1), hexutil.Bytes(Erpk.C2)
-	EspkC1, EspkC2 := hexutil.Bytes(Espk.C1), hexutil.Bytes(Espk.C2)
-	CMRpk, CMSpk := hexutil.Bytes(CMrpk.Commitment), hexutil.Bytes(CMspk.Commitment)
-	RpkEPg1, RpkEPg2, RpkEPy1, RpkEPy2, RpkEPt1, RpkEPt2, RpkEPs, RpkEPc := hexutil.Bytes(RpkEP.G1), hexutil.Bytes(RpkEP.G2), hexutil.Bytes(RpkEP.Y1), hexutil.Bytes(RpkEP.Y2), hexutil.Bytes(RpkEP.T1), hexutil.Bytes(RpkEP.T2), hexutil.Bytes(RpkEP.S), hexutil.Bytes(RpkEP.C)
-	SpkEPg1, SpkEPg2, SpkEPy1, SpkEPy2, SpkEPt1, SpkEPt2, SpkEPs, SpkEPc := hexutil.Bytes(SpkEP.G1), hexutil.Bytes(SpkEP.G2), hexutil.Bytes(SpkEP.Y1), hexutil.Bytes(SpkEP.Y2), hexutil.Bytes(SpkEP.T1), hexutil.Bytes(SpkEP.T2), hexutil.Bytes(SpkEP.S), hexutil.Bytes(SpkEP.C)
-	EvSC1, EvSC2 := hexutil.Bytes(EvS.C1), hexutil.Bytes(EvS.C2)
-	EvRC1, EvRC2 := hexutil.Bytes(EvR.C1), hexutil.Bytes(EvR.C2)
-	_CmS, _CmR := hexutil.Bytes(CmS.Commitment), hexutil.Bytes(CmR.Commitment)
-	_CmSRC1, _CmSRC2, _CmRRC1, _CmRRC2 := hexutil.Bytes(CmSR.C1), hexutil.Bytes(CmSR.C2), hexutil.Bytes(CmRR.C1), hexutil.Bytes(CmRR.C2)
-	ScmFPg1, ScmFPg2, ScmFPy1, ScmFPy2, ScmFPt1, ScmFPt2, ScmFPs, ScmFPc := hexutil.Bytes(ScmFP.G1), hexutil.Bytes(ScmFP.G2), hexutil.Bytes(ScmFP.Y1), hexutil.Bytes(ScmFP.Y2), hexutil.Bytes(ScmFP.T1), hexutil.Bytes(ScmFP.T2), hexutil.Bytes(ScmFP.S), hexutil.Bytes(ScmFP.C)
-	RcmFPg1, RcmFPg2, RcmFPy1, RcmFPy2, RcmFPt1, RcmFPt2, RcmFPs, RcmFPc := hexutil.Bytes(RcmFP.G1), hexutil.Bytes(RcmFP.G2), hexutil.Bytes(RcmFP.Y1), hexutil.Bytes(RcmFP.Y2), hexutil.Bytes(RcmFP.T1), hexutil.Bytes(RcmFP.T2), hexutil.Bytes(RcmFP.S), hexutil.Bytes(RcmFP.C)
-	EvsBsC1, EvsBsC2 := hexutil.Bytes(Evs.C1), hexutil.Bytes(Evs.C2)
-	EvOC1, EvOC2 := hexutil.Bytes(EvO.C1), hexutil.Bytes(EvO.C2)
-	_CmO := hexutil.Bytes(CmO)
-	VoEPg1, VoEPg2, VoEPy1, VoEPy2, VoEPt1, VoEPt2, VoEPs, VoEPc := hexutil.Bytes(VoEP.G1), hexutil.Bytes(VoEP.G2), hexutil.Bytes(VoEP.Y1), hexutil.Bytes(VoEP.Y2), hexutil.Bytes(VoEP.T1), hexutil.Bytes(VoEP.T2), hexutil.Bytes(VoEP.S), hexutil.Bytes(VoEP.C)
-	BPy, BPt, BPsn1, BPsn2, BPsn3, BPc  := hexutil.Bytes(BP.Y), hexutil.Bytes(BP.T), hexutil.Bytes(BP.Sn_1), hexutil.Bytes(BP.Sn_2), hexutil.Bytes(BP.Sn_3), hexutil.Bytes(BP.C)
-	// TODO:产生签名Sig
-	// fmt.Println(ErpkC1, ErpkC2, EspkC1, EspkC2, CMRpk, CMSpk, ErpkEPs0, ErpkEPs1, ErpkEPs2, ErpkEPs3, ErpkEPt, EspkEPs0, EspkEPs1, EspkEPs2, EspkEPs3, EspkEPt, EvSC1, EvSC2, EvRC1, EvRC2, _CmS, _CmR, CMsFPC, CMsFPZ1, CMsFPZ2, CMrFPC, CMrFPZ1, CMrFPZ2, EvsBsC1, EvsBsC2, EvOC1, EvOC2, _CmO, EvOEPs0, EvOEPs1, EvOEPs2, EvOEPs3, EvOEPt, BPC, BPRV, BPRR, BPSV, BPSR, BPSOr)
-	// 以上
-
-	//fmt.Println(Erpk.C1, Erpk.C2, Espk.C1, Espk.C2)            // 4个字节数组
-	//fmt.Println(ErpkEP, EspkEP)                                //
-	//fmt.Println(addrpk, addspk)                                //
-	//fmt.Println(Rpk, Spk)                                      // 不需要编码
-	//fmt.Println(VoR)                                           // 字节数组
-	//fmt.Println(EvS.C1, EvS.C2, CMsFP.C, CMsFP.Z1, CMsFP.Z2)   // 5个字节数组
-	//fmt.Println(Evs.C1, Evs.C2)                                // 2个字节数组
-	//fmt.Println(EvR.C1, EvR.C2, CMrFP.C, CMrFP.Z1, CMrFP.Z2)   // 5个字节数组
-	// fmt.Println(BPy, BPt, BPsn1, BPsn2, BPsn3, BPc) // 6个字节数组
-	//fmt.Println(EvoEP)                                         // 总额度相等证明
-	// 验证
-	//verify := zkp.VerifyFormatProof(EvS, regulatorPubk, CMsFP)
-	//fmt.Println("花费额承诺，格式正确证明:", verify,"EVS: ",EvS,"pub: ",regulatorPubk,"CMSFP:",CMsFP)
-	//verify = zkp.VerifyFormatProof(EvR, regulatorPubk, CMrFP)
-	//fmt.Println("找零承诺，格式正确证明:", verify)
-	//verify := zkp.VerifyBalanceProof(CmR.Commitment, CmS.Commitment, CmO, regulatorPubk, BP)
-	//fmt.Println(CmR.Commitment, CmS.Commitment, CmO, regulatorPubk, BP)
-	//fmt.Println("会计平衡证明:", verify)
-	//verify := zkp.VerifyEqualityProof(regulatorPubk, regulatorPubk, EvO, zkp.CypherText{C1: nil, C2: CmO}, EvoEP) //EvO和CmO里面的金额相等
-	//fmt.Println(regulatorPubk, regulatorPubk, EvO, zkp.CypherText{C1: nil, C2: CmO}, EvoEP)
-	//fmt.Println("lenS: ",len(EvoEP.S),"lenT: ",len(EvoEP.T))
-	//fmt.Println("总额度相等证明:", verify)
-	//s1 := make([][]byte,4)
-	//t1 := make([]byte,32)
-	//l := zkp.LinearEquationProof{s1,t1}
-	//e := zkp.EqualityProof{l}
-	//
-	//e.LinearEquationProof.S[0] = EvOEPs0.Btob()
-	//e.LinearEquationProof.S[1] = EvOEPs1.Btob()
-	//e.LinearEquationProof.S[2] = EvOEPs2.Btob()
-	//e.LinearEquationProof.S[3] = EvOEPs3.Btob()
-	//e.LinearEquationProof.T= EvOEPt.Btob()
-	//fmt.Println("ten")
-	//verify = zkp.VerifyEqualityProof(regulatorPubk, regulatorPubk, EvO, zkp.CypherText{C1: nil, C2: CmO}, e)
-	//fmt.Println("总额度相等证明2:", verify)
-	//verify = zkp.VerifyEqualityProof(regulatorPubk, regulatorPubk, Erpk, zkp.CypherText{C1: nil, C2: CMrpk.Commitment}, ErpkEP) //EvO和CmO里面的金额相等
-	//fmt.Println("接收方公钥相等证明:", verify)
-	//verify = zkp.VerifyEqualityProof(regulatorPubk, regulatorPubk, Espk, zkp.CypherText{C1: nil, C2: CMspk.Commitment}, EspkEP) //EvO和CmO里面的金额相等
-	//fmt.Println("发送方公钥相等证明:", verify)
-
+func (args *SendTxArgs) toZeroTransaction(regulator types.Regulator, chainID *big.Int, rangeBits int) (*types.Transaction, error) {
+	// 由节点代为生成密文和证明，明文金额对节点可见。
+	// 客户端应使用privtx自行构造，并通过eth_sendPrivateTransaction或eth_sendRawTransaction提交
+	proofs, err := privtx.BuildTransfer(&privtx.Transfer{
+		Sender:    *args.Spk,
+		Receiver:  *args.Rpk,
+		Regulator: ecc.PublicKey(regulator.PubK),
+		ChainID:   chainID,
+		Spend:     uint64(*args.Vs),
+		Change:    uint64(*args.Vr),
+		CmO:       *args.CmO,
+		VoR:       *args.VoR,
+		RangeBits: rangeBits,
+	})
+	if err != nil {
+		return nil, err
+	}
 	var input []byte
 	if args.Input != nil {
 		input = *args.Input
 	} else if args.Data != nil {
 		input = *args.Data
 	}
-	if args.To == nil {
-		return types.NewContractCreation(uint64(*args.Nonce), (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, 0, &ErpkC1, &ErpkC2, &EspkC1, &EspkC2, &CMRpk, &CMSpk, &RpkEPg1,&RpkEPg2,&RpkEPy1, &RpkEPy2, &RpkEPt1, &RpkEPt2, &RpkEPs, &RpkEPc, &SpkEPg1, &SpkEPg2, &SpkEPy1, &SpkEPy2, &SpkEPt1, &SpkEPt2, &SpkEPs, &SpkEPc, &EvSC1, &EvSC2, &EvRC1, &EvRC2, &_CmS, &_CmR, &ScmFPg1, &ScmFPg2, &ScmFPy1, &ScmFPy2, &ScmFPt1, &ScmFPt2, &ScmFPs, &ScmFPc, &RcmFPg1, &RcmFPg2, &RcmFPy1, &RcmFPy2, &RcmFPt1, &RcmFPt2, &RcmFPs, &RcmFPc, &EvsBsC1, &EvsBsC2, &EvOC1, &EvOC2, &_CmO,  &VoEPg1, &VoEPg2, &VoEPy1, &VoEPy2, &VoEPt1, &VoEPt2, &VoEPs, &VoEPc, &BPy, &BPt, &BPsn1, &BPsn2, &BPsn3, &BPc, args.EpkrC1, args.EpkrC2, args.EpkpC1, args.EpkpC2, args.SigM, args.SigMHash, args.SigR, args.SigS, args.CmV, &_CmSRC1, &_CmSRC2, &_CmRRC1, &_CmRRC2), nil
-	}
-	return types.NewTransaction(uint64(*args.Nonce), *args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, 0, &ErpkC1, &ErpkC2, &EspkC1, &EspkC2, &CMRpk, &CMSpk, &RpkEPg1,&RpkEPg2,&RpkEPy1, &RpkEPy2, &RpkEPt1, &RpkEPt2, &RpkEPs, &RpkEPc, &SpkEPg1, &SpkEPg2, &SpkEPy1, &SpkEPy2, &SpkEPt1, &SpkEPt2, &SpkEPs, &SpkEPc, &EvSC1, &EvSC2, &EvRC1, &EvRC2, &_CmS, &_CmR, &ScmFPg1, &ScmFPg2, &ScmFPy1, &ScmFPy2, &ScmFPt1, &ScmFPt2, &ScmFPs, &ScmFPc, &RcmFPg1, &RcmFPg2, &RcmFPy1, &RcmFPy2, &RcmFPt1, &RcmFPt2, &RcmFPs, &RcmFPc, &EvsBsC1, &EvsBsC2, &EvOC1, &EvOC2, &_CmO,  &VoEPg1, &VoEPg2, &VoEPy1, &VoEPy2, &VoEPt1, &VoEPt2, &VoEPs, &VoEPc, &BPy, &BPt, &BPsn1, &BPsn2, &BPsn3, &BPc, args.EpkrC1, args.EpkrC2, args.EpkpC1, args.EpkpC2, args.SigM, args.SigMHash, args.SigR, args.SigS, args.CmV, &_CmSRC1, &_CmSRC2, &_CmRRC1, &_CmRRC2), nil
+	return proofs.NewTransaction(uint64(*args.Nonce), args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input), nil
 }
 
-func (args *SendTxArgs) toExTransaction(regulator types.Regulator) (*types.Transaction, error) {
+func (args *SendTxArgs) toExTransaction() (*types.Transaction, error) {
 	var input []byte
 	if args.Input != nil {
 		input = *args.Input
 	} else if args.Data != nil {
 		input = *args.Data
 	}
-	ErpkC1 := hexutil.Bytes(nil)
-	ErpkC2 := hexutil.Bytes(nil)
-	EspkC1 := hexutil.Bytes(nil)
-	EspkC2 := hexutil.Bytes(nil)
-	CMRpk := hexutil.Bytes(nil)
-	CMSpk := hexutil.Bytes(nil)
-	RpkEPg1 := hexutil.Bytes(nil)
-	RpkEPg2 :=  hexutil.Bytes(nil)
-	RpkEPy1 :=  hexutil.Bytes(nil)
-	RpkEPy2 :=  hexutil.Bytes(nil)
-	RpkEPt1 :=  hexutil.Bytes(nil)
-	RpkEPt2 :=  hexutil.Bytes(nil)
-	RpkEPs  :=  hexutil.Bytes(nil)
-	RpkEPc  :=  hexutil.Bytes(nil)
-	SpkEPg1 :=  hexutil.Bytes(nil)
-	SpkEPg2 :=  hexutil.Bytes(nil)
-	SpkEPy1 :=  hexutil.Bytes(nil)
-	SpkEPy2 := hexutil.Bytes(nil)
-	SpkEPt1 := hexutil.Bytes(nil)
-	SpkEPt2 :=  hexutil.Bytes(nil)
-	SpkEPs  :=  hexutil.Bytes(nil)
-	SpkEPc  :=  hexutil.Bytes(nil)
-	EvSC1 := hexutil.Bytes(nil)
-	EvSC2 := hexutil.Bytes(nil)
-	EvRC1 := hexutil.Bytes(nil)
-	EvRC2 := hexutil.Bytes(nil)
-	_CmS := hexutil.Bytes(nil)
-	_CmR := hexutil.Bytes(nil)
-	ScmFPg1 := hexutil.Bytes(nil)
-	ScmFPg2 := hexutil.Bytes(nil)
-	ScmFPy1 := hexutil.Bytes(nil)
-	ScmFPy2 := hexutil.Bytes(nil)
-	ScmFPt1 := hexutil.Bytes(nil)
-	ScmFPt2 := hexutil.Bytes(nil)
-	ScmFPs := hexutil.Bytes(nil)
-	ScmFPc := hexutil.Bytes(nil)
-	RcmFPg1 := hexutil.Bytes(nil)
-	RcmFPg2 := hexutil.Bytes(nil)
-	RcmFPy1 := hexutil.Bytes(nil)
-	RcmFPy2 := hexutil.Bytes(nil)
-	RcmFPt1 := hexutil.Bytes(nil)
-	RcmFPt2 := hexutil.Bytes(nil)
-	RcmFPs := hexutil.Bytes(nil)
-	RcmFPc := hexutil.Bytes(nil)
-	EvsBsC1 := hexutil.Bytes(nil)
-	EvsBsC2 := hexutil.Bytes(nil)
-	EvOC1 := hexutil.Bytes(nil)
-	EvOC2 := hexutil.Bytes(nil)
-	_CmO := hexutil.Bytes(nil)
-	VoEPg1 := hexutil.Bytes(nil)
-	VoEPg2 := hexutil.Bytes(nil)
-	VoEPy1 := hexutil.Bytes(nil)
-	VoEPy2 := hexutil.Bytes(nil)
-	VoEPt1 := hexutil.Bytes(nil)
-	VoEPt2 := hexutil.Bytes(nil)
-	VoEPs := hexutil.Bytes(nil)
-	VoEPc := hexutil.Bytes(nil)
-	BPy := hexutil.Bytes(nil)
-	BPt := hexutil.Bytes(nil)
-	BPsn1 := hexutil.Bytes(nil)
-	BPsn2 := hexutil.Bytes(nil)
-	BPsn3 := hexutil.Bytes(nil)
-	BPc := hexutil.Bytes(nil)
-	CmSRC1 := hexutil.Bytes(nil)
-	CmSRC2 := hexutil.Bytes(nil)
-	CmRRC1 := hexutil.Bytes(nil)
-	CmRRC2 := hexutil.Bytes(nil)
-	if args.To == nil {
-		return types.NewContractCreation(uint64(*args.Nonce), (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, 1, &ErpkC1, &ErpkC2, &EspkC1, &EspkC2, &CMRpk, &CMSpk, &RpkEPg1,&RpkEPg2,&RpkEPy1, &RpkEPy2, &RpkEPt1, &RpkEPt2, &RpkEPs, &RpkEPc, &SpkEPg1, &SpkEPg2, &SpkEPy1, &SpkEPy2, &SpkEPt1, &SpkEPt2, &SpkEPs, &SpkEPc, &EvSC1, &EvSC2, &EvRC1, &EvRC2, &_CmS, &_CmR,  &ScmFPg1, &ScmFPg2, &ScmFPy1, &ScmFPy2, &ScmFPt1, &ScmFPt2, &ScmFPs, &ScmFPc, &RcmFPg1, &RcmFPg2, &RcmFPy1, &RcmFPy2, &RcmFPt1, &RcmFPt2, &RcmFPs, &RcmFPc, &EvsBsC1, &EvsBsC2, &EvOC1, &EvOC2, &_CmO, &VoEPg1, &VoEPg2, &VoEPy1, &VoEPy2, &VoEPt1, &VoEPt2, &VoEPs, &VoEPc, &BPy, &BPt, &BPsn1, &BPsn2, &BPsn3, &BPc, args.EpkrC1, args.EpkrC2, args.EpkpC1, args.EpkpC2, args.SigM, args.SigMHash, args.SigR, args.SigS, args.CmV, &CmSRC1, &CmSRC2, &CmRRC1, &CmRRC2), nil
+	payload := &types.PurchasePayload{
+		Epkr: types.CypherText{C1: *args.EpkrC1, C2: *args.EpkrC2},
+		Epkp: types.CypherText{C1: *args.EpkpC1, C2: *args.EpkpC2},
+		Sig: types.PurchaseSignature{
+			M:     *args.SigM,
+			MHash: *args.SigMHash,
+			R:     *args.SigR,
+			S:     *args.SigS,
+		},
+		CmV: *args.CmV,
+	}
+	if args.SpendKey != nil {
+		payload.SpendKeys = [][]byte{*args.SpendKey}
 	}
-	comtransaction := types.NewTransaction(uint64(*args.Nonce), *args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, 1, &ErpkC1, &ErpkC2, &EspkC1, &EspkC2, &CMRpk, &CMSpk, &RpkEPg1,&RpkEPg2,&RpkEPy1, &RpkEPy2, &RpkEPt1, &RpkEPt2, &RpkEPs, &RpkEPc, &SpkEPg1, &SpkEPg2, &SpkEPy1, &SpkEPy2, &SpkEPt1, &SpkEPt2, &SpkEPs, &SpkEPc, &EvSC1, &EvSC2, &EvRC1, &EvRC2, &_CmS, &_CmR, &ScmFPg1, &ScmFPg2, &ScmFPy1, &ScmFPy2, &ScmFPt1, &ScmFPt2, &ScmFPs, &ScmFPc, &RcmFPg1, &RcmFPg2, &RcmFPy1, &RcmFPy2, &RcmFPt1, &RcmFPt2, &RcmFPs, &RcmFPc, &EvsBsC1, &EvsBsC2, &EvOC1, &EvOC2, &_CmO, &VoEPg1, &VoEPg2, &VoEPy1, &VoEPy2, &VoEPt1, &VoEPt2, &VoEPs, &VoEPc, &BPy, &BPt, &BPsn1, &BPsn2, &BPsn3, &BPc, args.EpkrC1, args.EpkrC2, args.EpkpC1, args.EpkpC2, args.SigM, args.SigMHash, args.SigR, args.SigS, args.CmV, &CmSRC1, &CmSRC2, &CmRRC1, &CmRRC2)
-	return comtransaction, nil
+	return types.NewPrivacyTransaction(uint64(*args.Nonce), args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, payload), nil
 }
 
 func (args *SendTxArgs) toTransaction() (*types.Transaction, error) {
@@ -2030,7 +1809,7 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 	}
 	// Assemble the transaction and sign with the wallet
 	if *args.ID == 0x0 {
-		tx, err := args.toZeroTransaction(s.b.RegulatorKey())
+		tx, err := args.toZeroTransaction(s.b.RegulatorKey(), s.b.ChainConfig().ChainID, s.b.ChainConfig().RangeProofWidth())
 		if err != nil {
 			return common.Hash{}, err
 		}
@@ -2040,7 +1819,7 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		}
 		return SubmitTransaction(ctx, s.b, signed)
 	} else if *args.ID == 0x1 {
-		tx, err := args.toExTransaction(s.b.RegulatorKey())
+		tx, err := args.toExTransaction()
 		if err != nil {
 			return common.Hash{}, err
 		}
@@ -2054,6 +1833,67 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 	}
 }
 
+// PrivateTxArgs represents the arguments of a transfer whose ciphertexts and
+// proofs were built by the client with the privtx package.
+type PrivateTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+	privtx.Proofs
+}
+
+// SendPrivateTransaction signs a transfer carrying a client built proof bundle
+// with the account of from and submits it to the transaction pool. The node
+// never sees the amounts or blinding factors, it only verifies the proofs.
+func (s *PublicTransactionPoolAPI) SendPrivateTransaction(ctx context.Context, args PrivateTxArgs) (common.Hash, error) {
+	if !args.Proofs.Complete() {
+		return common.Hash{}, errors.New(`incomplete proof bundle`)
+	}
+	account := accounts.Account{Address: args.From}
+
+	wallet, err := s.b.AccountManager().Find(account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if args.Nonce == nil {
+		// Hold the addresse's mutex around signing to prevent concurrent assignment of
+		// the same nonce to multiple accounts.
+		s.nonceLock.LockAddr(args.From)
+		defer s.nonceLock.UnlockAddr(args.From)
+	}
+	txArgs := SendTxArgs{
+		From:     args.From,
+		To:       args.To,
+		Gas:      args.Gas,
+		GasPrice: args.GasPrice,
+		Value:    args.Value,
+		Nonce:    args.Nonce,
+		Data:     args.Data,
+		Input:    args.Input,
+	}
+	if err := txArgs.setDefaults(ctx, s.b); err != nil {
+		return common.Hash{}, err
+	}
+	var input []byte
+	if txArgs.Input != nil {
+		input = *txArgs.Input
+	} else if txArgs.Data != nil {
+		input = *txArgs.Data
+	}
+	tx := args.Proofs.NewTransaction(uint64(*txArgs.Nonce), txArgs.To, (*big.Int)(txArgs.Value), uint64(*txArgs.Gas), (*big.Int)(txArgs.GasPrice), input)
+
+	signed, err := wallet.SignTx(account, tx, s.b.ChainConfig().ChainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return SubmitTransaction(ctx, s.b, signed)
+}
+
 // FillTransaction fills the defaults (nonce, gas, gasPrice) on a given unsigned transaction,
 // and returns it to the caller for further processing (signing + broadcast)
 func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args SendTxArgs) (*SignTransactionResult, error) {
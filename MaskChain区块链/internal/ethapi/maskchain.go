@@ -0,0 +1,51 @@
+package ethapi
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PublicMaskChainAPI provides the MaskChain specific APIs, e.g. for auditing
+// the money supply.
+type PublicMaskChainAPI struct {
+	b Backend
+}
+
+// NewPublicMaskChainAPI creates a new MaskChain API.
+func NewPublicMaskChainAPI(b Backend) *PublicMaskChainAPI {
+	return &PublicMaskChainAPI{b}
+}
+
+// Issuance is the amount issued by the purchases of the chain up to a block.
+type Issuance struct {
+	BlockHash   common.Hash    `json:"blockHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Amount      *hexutil.Big   `json:"amount"`    // 发行总额，即购币交易中发行者签名的金额之和
+	Purchases   hexutil.Uint64 `json:"purchases"` // 购币交易数
+}
+
+// TotalIssued returns the amount issued by all purchases up to and including
+// the given block. Auditors can check it against the fiat reserves of the
+// exchange, less the redemptions it paid out. Right after the node upgraded or
+// fast synced it may fail for a while, until the issuance of the older blocks
+// is indexed in the background.
+func (api *PublicMaskChainAPI) TotalIssued(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*Issuance, error) {
+	block, err := api.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	issuance, err := core.TotalIssued(api.b.ChainDb(), block)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuance{
+		BlockHash:   block.Hash(),
+		BlockNumber: hexutil.Uint64(block.NumberU64()),
+		Amount:      (*hexutil.Big)(issuance.Amount),
+		Purchases:   hexutil.Uint64(issuance.Purchases),
+	}, nil
+}
@@ -287,10 +287,22 @@ func (b *LesApiBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 	}
 }
 
+// RegulatorKey returns the regulator with the key the chain config pins for
+// the pending block, or the configured legacy key before the pinned keys fork.
+// Light clients hold no state, so keys rotated by governance are not known to
+// them.
 func (b *LesApiBackend) RegulatorKey() types.Regulator {
-	return b.eth.config.Regulator
+	next := new(big.Int).Add(b.CurrentBlock().Number(), big.NewInt(1))
+	return types.NewRegulator(b.legacyKeys().KeyAt(b.ChainConfig(), nil, types.RegulatorKeyKind, next))
 }
 
-func (b *LesApiBackend) Exchange() types.Exchange {
-	return b.eth.config.Exchange
+// ExchangeKey returns the exchange with the key the chain config pins for the
+// pending block, see RegulatorKey.
+func (b *LesApiBackend) ExchangeKey() types.Exchange {
+	next := new(big.Int).Add(b.CurrentBlock().Number(), big.NewInt(1))
+	return types.NewExchange(b.legacyKeys().KeyAt(b.ChainConfig(), nil, types.ExchangeKeyKind, next))
+}
+
+func (b *LesApiBackend) legacyKeys() *core.LegacyKeys {
+	return core.NewLegacyKeys(b.eth.config.Regulator, b.eth.config.Exchange)
 }
@@ -22,7 +22,6 @@ package les
 import (
 	"context"
 	"crypto/rand"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"math/big"
 	"testing"
 	"time"
@@ -105,7 +104,6 @@ func prepare(n int, backend *backends.SimulatedBackend) {
 	var (
 		ctx    = context.Background()
 		signer = types.HomesteadSigner{}
-		rnd    = hexutil.Bytes("0")
 	)
 	for i := 0; i < n; i++ {
 		switch i {
@@ -114,43 +112,43 @@ func prepare(n int, backend *backends.SimulatedBackend) {
 			registrarAddr, _, _, _ = contract.DeployCheckpointOracle(bind.NewKeyedTransactor(bankKey), backend, []common.Address{signerAddr}, sectionSize, processConfirms, big.NewInt(1))
 			// bankUser transfers some ether to user1
 			nonce, _ := backend.PendingNonceAt(ctx, bankAddr)
-			tx, _ := types.SignTx(types.NewTransaction(nonce, userAddr1, big.NewInt(10000), params.TxGas, nil, nil, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, userKey1)
+			tx, _ := types.SignTx(types.NewTransaction(nonce, userAddr1, big.NewInt(10000), params.TxGas, nil, nil), signer, userKey1)
 			backend.SendTransaction(ctx, tx)
 		case 1:
 			bankNonce, _ := backend.PendingNonceAt(ctx, bankAddr)
 			userNonce1, _ := backend.PendingNonceAt(ctx, userAddr1)
 
 			// bankUser transfers more ether to user1
-			tx1, _ := types.SignTx(types.NewTransaction(bankNonce, userAddr1, big.NewInt(1000), params.TxGas, nil, nil, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, bankKey)
+			tx1, _ := types.SignTx(types.NewTransaction(bankNonce, userAddr1, big.NewInt(1000), params.TxGas, nil, nil), signer, bankKey)
 			backend.SendTransaction(ctx, tx1)
 
 			// user1 relays ether to user2
-			tx2, _ := types.SignTx(types.NewTransaction(userNonce1, userAddr2, big.NewInt(1000), params.TxGas, nil, nil, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, userKey1)
+			tx2, _ := types.SignTx(types.NewTransaction(userNonce1, userAddr2, big.NewInt(1000), params.TxGas, nil, nil), signer, userKey1)
 			backend.SendTransaction(ctx, tx2)
 
 			// user1 deploys a test contract
-			tx3, _ := types.SignTx(types.NewContractCreation(userNonce1+1, big.NewInt(0), 200000, big.NewInt(0), testContractCode, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, userKey1)
+			tx3, _ := types.SignTx(types.NewContractCreation(userNonce1+1, big.NewInt(0), 200000, big.NewInt(0), testContractCode), signer, userKey1)
 			backend.SendTransaction(ctx, tx3)
 			testContractAddr = crypto.CreateAddress(userAddr1, userNonce1+1)
 
 			// user1 deploys a event contract
-			tx4, _ := types.SignTx(types.NewContractCreation(userNonce1+2, big.NewInt(0), 200000, big.NewInt(0), testEventEmitterCode, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, userKey1)
+			tx4, _ := types.SignTx(types.NewContractCreation(userNonce1+2, big.NewInt(0), 200000, big.NewInt(0), testEventEmitterCode), signer, userKey1)
 			backend.SendTransaction(ctx, tx4)
 		case 2:
 			// bankUser transfer some ether to signer
 			bankNonce, _ := backend.PendingNonceAt(ctx, bankAddr)
-			tx1, _ := types.SignTx(types.NewTransaction(bankNonce, signerAddr, big.NewInt(1000000000), params.TxGas, nil, nil, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, userKey1)
+			tx1, _ := types.SignTx(types.NewTransaction(bankNonce, signerAddr, big.NewInt(1000000000), params.TxGas, nil, nil), signer, userKey1)
 			backend.SendTransaction(ctx, tx1)
 
 			// invoke test contract
 			data := common.Hex2Bytes("C16431B900000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001")
-			tx2, _ := types.SignTx(types.NewTransaction(bankNonce+1, testContractAddr, big.NewInt(0), 100000, nil, data, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, userKey1)
+			tx2, _ := types.SignTx(types.NewTransaction(bankNonce+1, testContractAddr, big.NewInt(0), 100000, nil, data), signer, userKey1)
 			backend.SendTransaction(ctx, tx2)
 		case 3:
 			// invoke test contract
 			bankNonce, _ := backend.PendingNonceAt(ctx, bankAddr)
 			data := common.Hex2Bytes("C16431B900000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000002")
-			tx, _ := types.SignTx(types.NewTransaction(bankNonce, testContractAddr, big.NewInt(0), 100000, nil, data, 0, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd,&rnd, &rnd,  &rnd,&rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd, &rnd), signer, userKey1)
+			tx, _ := types.SignTx(types.NewTransaction(bankNonce, testContractAddr, big.NewInt(0), 100000, nil, data), signer, userKey1)
 			backend.SendTransaction(ctx, tx)
 		}
 		backend.Commit()
@@ -976,6 +976,10 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	if w.chainConfig.DAOForkSupport && w.chainConfig.DAOForkBlock != nil && w.chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
 		misc.ApplyDAOHardFork(env.state)
 	}
+	if err := core.ApplyCMPoolFork(w.chainConfig, w.chain, env.state, header); err != nil {
+		log.Error("Failed to seed commitment pool", "err", err)
+		return
+	}
 	// Accumulate the uncles for the current block
 	uncles := make([]*types.Header, 0, 2) //长度为0，预留总长度为2
 	commitUncles := func(blocks map[common.Hash]*types.Block) {
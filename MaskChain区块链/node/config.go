@@ -19,7 +19,6 @@ package node
 import (
 	"crypto/ecdsa"
 	"fmt"
-	"github.com/ethereum/go-ethereum/core/types"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -189,9 +188,6 @@ type Config struct {
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
-	//监管者配置
-	Regulator              types.Regulator
-	Exchange 			   types.Exchange
 	staticNodesWarning     bool
 	trustedNodesWarning    bool
 	oldGethResourceWarning bool
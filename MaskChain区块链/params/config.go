@@ -17,12 +17,15 @@
 package params
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"maskchain/privacy/ecc"
 )
 
 // Genesis hashes to enforce below configs on.
@@ -215,16 +218,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil,0}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, 0, 0, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), nil, nil, nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000},0}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000},0, 0, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), nil, nil, nil, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, 0}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, 0, 0, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), nil, nil, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -302,6 +305,74 @@ type ChainConfig struct {
 	Clique *CliqueConfig `json:"clique,omitempty"`
 
 	CryptoType          uint8 `json:"cryptoType"`
+
+	RangeProofBits  uint8    `json:"rangeProofBits,omitempty"`  // Bit width of the transfer output range proofs (0 = DefaultRangeProofBits)
+	RangeProofBlock *big.Int `json:"rangeProofBlock,omitempty"` // Switch block to transfers required to carry range proofs (nil = range proofs optional)
+	BoundProofBlock *big.Int `json:"boundProofBlock,omitempty"` // Switch block to transfers required to carry proofs bound to the transaction (nil = legacy proofs accepted in blocks)
+	IssuanceBlock   *big.Int `json:"issuanceBlock,omitempty"`   // Switch block to purchases required to sign their issued amount (nil = amount optional)
+
+	PrivacyGenerators *GeneratorsConfig `json:"privacyGenerators,omitempty"` // Generators the regulator key must use (nil = not pinned)
+
+	CMPoolBlock *big.Int `json:"cmPoolBlock,omitempty"` // Switch block to the commitment pool in state and its root in the header (nil = no commitment pool)
+
+	ShieldedBlock *big.Int `json:"shieldedBlock,omitempty"` // Switch block to the commitment accumulator and shielded transfers (nil = no shielded pool)
+
+	RegulatorKeys []PrivacyKey `json:"regulatorKeys,omitempty"` // Encryption keys of the regulator by activation block (nil = not pinned)
+	ExchangeKeys  []PrivacyKey `json:"exchangeKeys,omitempty"`  // Signing keys of the exchange by activation block (nil = not pinned)
+
+	Governance *GovernanceConfig `json:"governance,omitempty"` // Authorities rotating the keys on chain (nil = keys only change with the config)
+
+	PinnedKeysBlock *big.Int `json:"pinnedKeysBlock,omitempty"` // Switch block to keys pinned by the config or rotated on chain only (nil = keys the config lacks fall back to the node's legacy keys)
+}
+
+// GeneratorsConfig pins the value generator G1 and the encryption generator G2
+// of the privacy keys network-wide, in the uncompressed point encoding. The
+// generators of ecc.StandardGenerators are DefaultPrivacyGenerators.
+type GeneratorsConfig struct {
+	G1 hexutil.Bytes `json:"g1"`
+	G2 hexutil.Bytes `json:"g2"`
+}
+
+// DefaultPrivacyGenerators are the generators of ecc.StandardGenerators.
+var DefaultPrivacyGenerators = &GeneratorsConfig{
+	G1: hexutil.MustDecode("0x04162f325b9d9537507e02ea57d8daee35f20fe01c93de18674088ee908fe1168c83bd5f56fe68ba3aa7e84c884ec35ae287403ca598efed01f2e3eaa057b22f7c"),
+	G2: hexutil.MustDecode("0x0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"),
+}
+
+// PrivacyKey is a public key of the regulator or the exchange pinned by the
+// chain config, in effect from block Block on until the block of the next key.
+// The points are in the uncompressed encoding, the order of the group is
+// implied by the curve.
+type PrivacyKey struct {
+	Block *big.Int      `json:"block"`
+	G1    hexutil.Bytes `json:"g1"`
+	G2    hexutil.Bytes `json:"g2"`
+	H     hexutil.Bytes `json:"h"`
+}
+
+// Equal reports whether both keys are the same and activated at the same block.
+func (k *PrivacyKey) Equal(o *PrivacyKey) bool {
+	return configNumEqual(k.Block, o.Block) && bytes.Equal(k.G1, o.G1) && bytes.Equal(k.G2, o.G2) && bytes.Equal(k.H, o.H)
+}
+
+// GovernanceConfig is the set of authorities whose quorum may schedule new
+// keys of the regulator and the exchange with key rotation transactions. The
+// keys of RegulatorKeys and ExchangeKeys stay in effect until the first key
+// scheduled on chain.
+type GovernanceConfig struct {
+	Authorities []common.Address `json:"authorities"`     // Addresses of the authority keys
+	Threshold   uint64           `json:"threshold"`       // Number of authority signatures a rotation needs
+	Delay       uint64           `json:"delay,omitempty"` // Minimum number of blocks between a rotation and the activation of its key
+}
+
+// IsAuthority reports whether addr is one of the authorities.
+func (c *GovernanceConfig) IsAuthority(addr common.Address) bool {
+	for _, authority := range c.Authorities {
+		if authority == addr {
+			return true
+		}
+	}
+	return false
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -408,6 +479,77 @@ func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
 }
 
+// IsCMPool returns whether the commitments of block num are tracked in the
+// commitment pool of the state and committed to by the CMRoot of its header.
+func (c *ChainConfig) IsCMPool(num *big.Int) bool {
+	return isForked(c.CMPoolBlock, num)
+}
+
+// IsShielded returns whether the commitments created in block num enter the
+// commitment accumulator and may only be spent by shielded transfers.
+func (c *ChainConfig) IsShielded(num *big.Int) bool {
+	return isForked(c.ShieldedBlock, num)
+}
+
+// IsRangeProof returns whether the transfers of block num must carry range
+// proofs on their outputs.
+func (c *ChainConfig) IsRangeProof(num *big.Int) bool {
+	return isForked(c.RangeProofBlock, num)
+}
+
+// IsBoundProof returns whether the transfers of block num must carry proofs
+// bound to the transaction, i.e. proofs of the legacy privacy version are
+// rejected.
+func (c *ChainConfig) IsBoundProof(num *big.Int) bool {
+	return isForked(c.BoundProofBlock, num)
+}
+
+// IsIssuance returns whether the exchange signature of the purchases of block
+// num must hold the issued amount.
+func (c *ChainConfig) IsIssuance(num *big.Int) bool {
+	return isForked(c.IssuanceBlock, num)
+}
+
+// IsPinnedKeys returns whether the keys of the regulator and the exchange in
+// effect at block num must be pinned by the config or rotated on chain, i.e.
+// the keys a node was configured with before chain configs pinned them no
+// longer stand in for missing ones.
+func (c *ChainConfig) IsPinnedKeys(num *big.Int) bool {
+	return isForked(c.PinnedKeysBlock, num)
+}
+
+// RangeProofWidth returns the bit width the range proofs of transfer outputs
+// are generated and verified with.
+func (c *ChainConfig) RangeProofWidth() int {
+	if c == nil || c.RangeProofBits == 0 {
+		return DefaultRangeProofBits
+	}
+	return int(c.RangeProofBits)
+}
+
+// RegulatorKey returns the key of the regulator in effect at block num, nil if
+// none is pinned for it.
+func (c *ChainConfig) RegulatorKey(num *big.Int) *PrivacyKey {
+	return keyAt(c.RegulatorKeys, num)
+}
+
+// ExchangeKey returns the key of the exchange in effect at block num, nil if
+// none is pinned for it.
+func (c *ChainConfig) ExchangeKey(num *big.Int) *PrivacyKey {
+	return keyAt(c.ExchangeKeys, num)
+}
+
+func keyAt(keys []PrivacyKey, num *big.Int) *PrivacyKey {
+	var key *PrivacyKey
+	for i := range keys {
+		if !isForked(keys[i].Block, num) {
+			break
+		}
+		key = &keys[i]
+	}
+	return key
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -460,6 +602,58 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		}
 		lastFork = cur
 	}
+	if c.RangeProofBits != 0 && !ecc.ValidRangeProofBits(int(c.RangeProofBits)) {
+		return fmt.Errorf("unsupported range proof bit width %d", c.RangeProofBits)
+	}
+	// The accumulator of the shielded pool lives in the commitment pool
+	if c.ShieldedBlock != nil {
+		if c.CMPoolBlock == nil {
+			return fmt.Errorf("unsupported fork ordering: cmPoolBlock not enabled, but shieldedBlock enabled at %v", c.ShieldedBlock)
+		}
+		if c.CMPoolBlock.Cmp(c.ShieldedBlock) > 0 {
+			return fmt.Errorf("unsupported fork ordering: cmPoolBlock enabled at %v, but shieldedBlock enabled at %v", c.CMPoolBlock, c.ShieldedBlock)
+		}
+	}
+	if err := checkKeyOrder("regulatorKeys", c.RegulatorKeys); err != nil {
+		return err
+	}
+	if err := checkKeyOrder("exchangeKeys", c.ExchangeKeys); err != nil {
+		return err
+	}
+	// Without a fallback from the pinned keys fork on, the config must pin keys for it
+	if c.PinnedKeysBlock != nil && (c.RegulatorKey(c.PinnedKeysBlock) == nil || c.ExchangeKey(c.PinnedKeysBlock) == nil) {
+		return fmt.Errorf("pinnedKeysBlock enabled at %v, but no regulator and exchange key pinned for it", c.PinnedKeysBlock)
+	}
+	if g := c.Governance; g != nil {
+		if g.Threshold == 0 || g.Threshold > uint64(len(g.Authorities)) {
+			return fmt.Errorf("governance threshold %d out of range for %d authorities", g.Threshold, len(g.Authorities))
+		}
+		seen := make(map[common.Address]bool)
+		for _, authority := range g.Authorities {
+			if seen[authority] {
+				return fmt.Errorf("duplicate governance authority %x", authority)
+			}
+			seen[authority] = true
+		}
+	}
+	return nil
+}
+
+// checkKeyOrder checks that the keys are activated at strictly increasing
+// blocks and that their points are complete.
+func checkKeyOrder(name string, keys []PrivacyKey) error {
+	for i, key := range keys {
+		if key.Block == nil {
+			return fmt.Errorf("%s[%d]: missing activation block", name, i)
+		}
+		if i > 0 && keys[i-1].Block.Cmp(key.Block) >= 0 {
+			return fmt.Errorf("unsupported key ordering: %s[%d] activated at %v, but %s[%d] at %v",
+				name, i-1, keys[i-1].Block, name, i, key.Block)
+		}
+		if len(key.G1) != 65 || len(key.G2) != 65 || len(key.H) != 65 {
+			return fmt.Errorf("%s[%d]: points must be 65 bytes uncompressed", name, i)
+		}
+	}
 	return nil
 }
 
@@ -503,9 +697,93 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.RangeProofBlock, newcfg.RangeProofBlock, head) {
+		return newCompatError("range proof fork block", c.RangeProofBlock, newcfg.RangeProofBlock)
+	}
+	if isForked(c.RangeProofBlock, head) && c.RangeProofWidth() != newcfg.RangeProofWidth() {
+		return newCompatError("range proof bit width", c.RangeProofBlock, newcfg.RangeProofBlock)
+	}
+	if isForkIncompatible(c.BoundProofBlock, newcfg.BoundProofBlock, head) {
+		return newCompatError("bound proof fork block", c.BoundProofBlock, newcfg.BoundProofBlock)
+	}
+	if isForkIncompatible(c.IssuanceBlock, newcfg.IssuanceBlock, head) {
+		return newCompatError("issuance fork block", c.IssuanceBlock, newcfg.IssuanceBlock)
+	}
+	if isForkIncompatible(c.CMPoolBlock, newcfg.CMPoolBlock, head) {
+		return newCompatError("commitment pool fork block", c.CMPoolBlock, newcfg.CMPoolBlock)
+	}
+	if isForkIncompatible(c.ShieldedBlock, newcfg.ShieldedBlock, head) {
+		return newCompatError("shielded fork block", c.ShieldedBlock, newcfg.ShieldedBlock)
+	}
+	if stored, updated := keysIncompatible(c.RegulatorKeys, newcfg.RegulatorKeys, head); stored != nil || updated != nil {
+		return newCompatError("regulator key", stored, updated)
+	}
+	if stored, updated := keysIncompatible(c.ExchangeKeys, newcfg.ExchangeKeys, head); stored != nil || updated != nil {
+		return newCompatError("exchange key", stored, updated)
+	}
+	if isForkIncompatible(c.PinnedKeysBlock, newcfg.PinnedKeysBlock, head) {
+		return newCompatError("pinned keys fork block", c.PinnedKeysBlock, newcfg.PinnedKeysBlock)
+	}
+	// The generators and the governance are in effect from the genesis block
+	// on, no rewind can make a chain past it consistent with other ones.
+	if head.Sign() > 0 && !generatorsEqual(c.PrivacyGenerators, newcfg.PrivacyGenerators) {
+		return newGenesisCompatError("privacy generators")
+	}
+	if head.Sign() > 0 && !governanceEqual(c.Governance, newcfg.Governance) {
+		return newGenesisCompatError("governance")
+	}
 	return nil
 }
 
+func generatorsEqual(x, y *GeneratorsConfig) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return bytes.Equal(x.G1, y.G1) && bytes.Equal(x.G2, y.G2)
+}
+
+func governanceEqual(x, y *GovernanceConfig) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	if x.Threshold != y.Threshold || x.Delay != y.Delay || len(x.Authorities) != len(y.Authorities) {
+		return false
+	}
+	for i := range x.Authorities {
+		if x.Authorities[i] != y.Authorities[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keysIncompatible returns the activation blocks of the first stored and updated
+// key which differ although head is already past one of them. Pinning keys
+// where none were pinned is compatible, as the keys were then configured out
+// of band.
+func keysIncompatible(stored, updated []PrivacyKey, head *big.Int) (*big.Int, *big.Int) {
+	if len(stored) == 0 {
+		return nil, nil
+	}
+	for i := 0; i < len(stored) || i < len(updated); i++ {
+		var s, n *big.Int
+		if i < len(stored) {
+			s = stored[i].Block
+		}
+		if i < len(updated) {
+			n = updated[i].Block
+		}
+		if i < len(stored) && i < len(updated) && stored[i].Equal(&updated[i]) {
+			continue
+		}
+		if isForked(s, head) || isForked(n, head) {
+			return s, n
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -538,6 +816,9 @@ type ConfigCompatError struct {
 	StoredConfig, NewConfig *big.Int
 	// the block number to which the local chain must be rewound to correct the error
 	RewindTo uint64
+	// whether the setting is in effect from the genesis block on, so that no
+	// rewind corrects the error
+	Genesis bool
 }
 
 func newCompatError(what string, storedblock, newblock *big.Int) *ConfigCompatError {
@@ -550,14 +831,21 @@ func newCompatError(what string, storedblock, newblock *big.Int) *ConfigCompatEr
 	default:
 		rew = newblock
 	}
-	err := &ConfigCompatError{what, storedblock, newblock, 0}
+	err := &ConfigCompatError{what, storedblock, newblock, 0, false}
 	if rew != nil && rew.Sign() > 0 {
 		err.RewindTo = rew.Uint64() - 1
 	}
 	return err
 }
 
+func newGenesisCompatError(what string) *ConfigCompatError {
+	return &ConfigCompatError{What: what, Genesis: true}
+}
+
 func (err *ConfigCompatError) Error() string {
+	if err.Genesis {
+		return fmt.Sprintf("mismatching %s in database (in effect from the genesis block on)", err.What)
+	}
 	return fmt.Sprintf("mismatching %s in database (have %d, want %d, rewindto %d)", err.What, err.StoredConfig, err.NewConfig, err.RewindTo)
 }
 
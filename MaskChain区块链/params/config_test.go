@@ -17,9 +17,13 @@
 package params
 
 import (
+	"bytes"
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"maskchain/privacy/ecc"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -79,3 +83,223 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestPrivacyKeys(t *testing.T) {
+	key := func(block int64, h byte) PrivacyKey {
+		point := make([]byte, 65)
+		point[0], point[64] = 4, h
+		return PrivacyKey{Block: big.NewInt(block), G1: point, G2: point, H: point}
+	}
+	config := &ChainConfig{RegulatorKeys: []PrivacyKey{key(0, 1), key(10, 2)}}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("ordered keys rejected: %v", err)
+	}
+	if k := config.ExchangeKey(big.NewInt(0)); k != nil {
+		t.Errorf("exchange key without pinned keys: %v", k)
+	}
+	for num, want := range map[int64]byte{0: 1, 9: 1, 10: 2, 100: 2} {
+		if k := config.RegulatorKey(big.NewInt(num)); k == nil || k.H[64] != want {
+			t.Errorf("block %d: have regulator key %v, want %d", num, k, want)
+		}
+	}
+	unordered := &ChainConfig{RegulatorKeys: []PrivacyKey{key(10, 1), key(10, 2)}}
+	if err := unordered.CheckConfigForkOrder(); err == nil {
+		t.Errorf("keys activated at the same block accepted")
+	}
+
+	tests := []struct {
+		stored, new []PrivacyKey
+		head        uint64
+		wantErr     *ConfigCompatError
+	}{
+		// Pinning keys configured out of band so far
+		{stored: nil, new: []PrivacyKey{key(0, 1)}, head: 100},
+		// Scheduling a rotation ahead of the head
+		{stored: []PrivacyKey{key(0, 1)}, new: []PrivacyKey{key(0, 1), key(20, 2)}, head: 19},
+		{
+			stored:  []PrivacyKey{key(0, 1), key(10, 2)},
+			new:     []PrivacyKey{key(0, 1), key(20, 2)},
+			head:    15,
+			wantErr: &ConfigCompatError{What: "regulator key", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(20), RewindTo: 9},
+		},
+		{
+			stored:  []PrivacyKey{key(0, 1)},
+			new:     []PrivacyKey{key(0, 2)},
+			head:    5,
+			wantErr: &ConfigCompatError{What: "regulator key", StoredConfig: big.NewInt(0), NewConfig: big.NewInt(0), RewindTo: 0},
+		},
+	}
+	for i, test := range tests {
+		stored, new := &ChainConfig{RegulatorKeys: test.stored}, &ChainConfig{RegulatorKeys: test.new}
+		if err := stored.CheckCompatible(new, test.head); !reflect.DeepEqual(err, test.wantErr) {
+			t.Errorf("test %d: have %v, want %v", i, err, test.wantErr)
+		}
+	}
+}
+
+func TestRangeProofConfig(t *testing.T) {
+	for bits, valid := range map[uint8]bool{0: true, 8: true, 16: true, 32: true, 64: true, 4: false, 24: false, 128: false} {
+		err := (&ChainConfig{RangeProofBits: bits}).CheckConfigForkOrder()
+		if valid && err != nil {
+			t.Errorf("bit width %d rejected: %v", bits, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("bit width %d accepted", bits)
+		}
+	}
+	tests := []struct {
+		stored, new *ChainConfig
+		head        uint64
+		wantErr     *ConfigCompatError
+	}{
+		// Changing the bit width before range proofs are required
+		{
+			stored: &ChainConfig{RangeProofBlock: big.NewInt(10)},
+			new:    &ChainConfig{RangeProofBlock: big.NewInt(10), RangeProofBits: 64},
+			head:   9,
+		},
+		// The default bit width stays the same when spelled out
+		{
+			stored: &ChainConfig{RangeProofBlock: big.NewInt(0)},
+			new:    &ChainConfig{RangeProofBlock: big.NewInt(0), RangeProofBits: DefaultRangeProofBits},
+			head:   100,
+		},
+		{
+			stored:  &ChainConfig{RangeProofBlock: big.NewInt(10)},
+			new:     &ChainConfig{RangeProofBlock: big.NewInt(10), RangeProofBits: 64},
+			head:    10,
+			wantErr: &ConfigCompatError{What: "range proof bit width", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(10), RewindTo: 9},
+		},
+		{
+			stored:  &ChainConfig{RangeProofBlock: big.NewInt(10)},
+			new:     &ChainConfig{RangeProofBlock: big.NewInt(20)},
+			head:    15,
+			wantErr: &ConfigCompatError{What: "range proof fork block", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(20), RewindTo: 9},
+		},
+		{
+			stored:  &ChainConfig{BoundProofBlock: big.NewInt(10)},
+			new:     &ChainConfig{},
+			head:    15,
+			wantErr: &ConfigCompatError{What: "bound proof fork block", StoredConfig: big.NewInt(10), NewConfig: nil, RewindTo: 9},
+		},
+		{
+			stored:  &ChainConfig{IssuanceBlock: big.NewInt(10)},
+			new:     &ChainConfig{IssuanceBlock: big.NewInt(20)},
+			head:    15,
+			wantErr: &ConfigCompatError{What: "issuance fork block", StoredConfig: big.NewInt(10), NewConfig: big.NewInt(20), RewindTo: 9},
+		},
+	}
+	for i, test := range tests {
+		if err := test.stored.CheckCompatible(test.new, test.head); !reflect.DeepEqual(err, test.wantErr) {
+			t.Errorf("test %d: have %v, want %v", i, err, test.wantErr)
+		}
+	}
+}
+
+func TestPrivacyGenerators(t *testing.T) {
+	g1, g2 := ecc.MarshalGenerators(ecc.StandardGenerators())
+	if !bytes.Equal(g1, DefaultPrivacyGenerators.G1) || !bytes.Equal(g2, DefaultPrivacyGenerators.G2) {
+		t.Fatalf("default generators differ from the library's")
+	}
+	other := &GeneratorsConfig{G1: DefaultPrivacyGenerators.G2, G2: DefaultPrivacyGenerators.G1}
+	tests := []struct {
+		stored, new *GeneratorsConfig
+		head        uint64
+		wantErr     *ConfigCompatError
+	}{
+		{stored: DefaultPrivacyGenerators, new: &GeneratorsConfig{G1: g1, G2: g2}, head: 100},
+		// Nothing but the genesis block is committed to yet
+		{stored: nil, new: DefaultPrivacyGenerators, head: 0},
+		{stored: nil, new: DefaultPrivacyGenerators, head: 1, wantErr: &ConfigCompatError{What: "privacy generators", Genesis: true}},
+		{stored: DefaultPrivacyGenerators, new: other, head: 100, wantErr: &ConfigCompatError{What: "privacy generators", Genesis: true}},
+	}
+	for i, test := range tests {
+		stored, new := &ChainConfig{PrivacyGenerators: test.stored}, &ChainConfig{PrivacyGenerators: test.new}
+		if err := stored.CheckCompatible(new, test.head); !reflect.DeepEqual(err, test.wantErr) {
+			t.Errorf("test %d: have %v, want %v", i, err, test.wantErr)
+		}
+	}
+}
+
+func TestGovernanceConfig(t *testing.T) {
+	a, b := common.Address{1}, common.Address{2}
+	tests := []struct {
+		governance *GovernanceConfig
+		valid      bool
+	}{
+		{&GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 2, Delay: 2}, true},
+		{&GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 1}, true},
+		{&GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 0}, false},
+		{&GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 3}, false},
+		{&GovernanceConfig{Authorities: []common.Address{a, a}, Threshold: 1}, false},
+	}
+	for i, test := range tests {
+		err := (&ChainConfig{Governance: test.governance}).CheckConfigForkOrder()
+		if test.valid && err != nil {
+			t.Errorf("test %d: valid governance rejected: %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("test %d: invalid governance accepted", i)
+		}
+	}
+	governance := &GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 2, Delay: 2}
+	compat := []struct {
+		stored, new *GovernanceConfig
+		head        uint64
+		wantErr     *ConfigCompatError
+	}{
+		{stored: governance, new: &GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 2, Delay: 2}, head: 100},
+		{stored: nil, new: governance, head: 0},
+		{stored: nil, new: governance, head: 100, wantErr: &ConfigCompatError{What: "governance", Genesis: true}},
+		{stored: governance, new: nil, head: 100, wantErr: &ConfigCompatError{What: "governance", Genesis: true}},
+		{stored: governance, new: &GovernanceConfig{Authorities: []common.Address{b, a}, Threshold: 2, Delay: 2}, head: 100, wantErr: &ConfigCompatError{What: "governance", Genesis: true}},
+		{stored: governance, new: &GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 1, Delay: 2}, head: 100, wantErr: &ConfigCompatError{What: "governance", Genesis: true}},
+		{stored: governance, new: &GovernanceConfig{Authorities: []common.Address{a, b}, Threshold: 2}, head: 100, wantErr: &ConfigCompatError{What: "governance", Genesis: true}},
+	}
+	for i, test := range compat {
+		stored, new := &ChainConfig{Governance: test.stored}, &ChainConfig{Governance: test.new}
+		if err := stored.CheckCompatible(new, test.head); !reflect.DeepEqual(err, test.wantErr) {
+			t.Errorf("compat test %d: have %v, want %v", i, err, test.wantErr)
+		}
+	}
+}
+
+func TestPinnedKeysBlock(t *testing.T) {
+	point := make([]byte, 65)
+	point[0] = 4
+	keys := []PrivacyKey{{Block: big.NewInt(10), G1: point, G2: point, H: point}}
+
+	tests := []struct {
+		config *ChainConfig
+		valid  bool
+	}{
+		{&ChainConfig{}, true},
+		{&ChainConfig{PinnedKeysBlock: big.NewInt(10), RegulatorKeys: keys, ExchangeKeys: keys}, true},
+		{&ChainConfig{PinnedKeysBlock: big.NewInt(20), RegulatorKeys: keys, ExchangeKeys: keys}, true},
+		{&ChainConfig{PinnedKeysBlock: big.NewInt(9), RegulatorKeys: keys, ExchangeKeys: keys}, false},
+		{&ChainConfig{PinnedKeysBlock: big.NewInt(10), RegulatorKeys: keys}, false},
+	}
+	for i, test := range tests {
+		err := test.config.CheckConfigForkOrder()
+		if test.valid && err != nil {
+			t.Errorf("test %d: valid config rejected: %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("test %d: invalid config accepted", i)
+		}
+	}
+	for num, want := range map[int64]bool{0: false, 9: false, 10: true, 100: true} {
+		if have := tests[1].config.IsPinnedKeys(big.NewInt(num)); have != want {
+			t.Errorf("block %d: have pinned keys %v, want %v", num, have, want)
+		}
+	}
+	stored := &ChainConfig{RegulatorKeys: keys, ExchangeKeys: keys}
+	// Scheduling the fork ahead of the head migrates a chain to pinned keys
+	if err := stored.CheckCompatible(tests[2].config, 15); err != nil {
+		t.Errorf("pinned keys fork ahead of the head rejected: %v", err)
+	}
+	want := &ConfigCompatError{What: "pinned keys fork block", StoredConfig: nil, NewConfig: big.NewInt(20), RewindTo: 19}
+	if err := stored.CheckCompatible(tests[2].config, 25); !reflect.DeepEqual(err, want) {
+		t.Errorf("have %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,693 @@
+// Package privtx builds the ciphertexts, commitments and zero-knowledge proofs
+// of privacy transactions on the client side, so that spend and change amounts
+// and blinding factors never leave the wallet. The resulting proof bundle is
+// either signed into a raw transaction locally (eth_sendRawTransaction) or
+// submitted to a node for signing (eth_sendPrivateTransaction).
+package privtx
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"maskchain/privacy/ecc"
+)
+
+var (
+	errInvalidPublicKey = errors.New("invalid public key")
+	errMissingSpent     = errors.New("missing spent commitment or its blinding factor")
+	errTransferCount    = errors.New("invalid number of transfer inputs or outputs")
+	errUnbalanced       = errors.New("transfer inputs and outputs do not balance")
+	errNotInRing        = errors.New("spent commitment is not a member of its ring")
+)
+
+// Transfer contains the secrets of a transfer (ID=0) known only to the sender.
+type Transfer struct {
+	Sender    string        // 发送方公钥（十六进制编码）
+	Receiver  string        // 接收方公钥（十六进制编码）
+	Regulator ecc.PublicKey // 监管者公钥
+	ChainID   *big.Int      // 链ID，证明与之绑定
+
+	Spend  uint64 // 花费金额
+	Change uint64 // 找零金额
+
+	CmO []byte // 被花费承诺
+	VoR []byte // 被花费承诺的随机数
+
+	RangeBits int // 范围证明位宽，0表示params.DefaultRangeProofBits
+
+	// 证明版本，0表示types.PrivacyVersion。types.LegacyPrivacyVersion生成不绑定交易的旧版本证明，只在边界证明分叉前有效
+	Version uint8
+}
+
+// Input is a commitment spent by a multi transfer together with its secrets.
+type Input struct {
+	Cm    []byte // 被花费承诺
+	R     []byte // 被花费承诺的随机数
+	Value uint64 // 被花费承诺金额
+}
+
+// Output is a payment of a multi transfer. Change is an output to the sender.
+type Output struct {
+	Receiver string // 接收方公钥（十六进制编码）
+	Value    uint64 // 金额
+}
+
+// MultiTransfer contains the secrets of a multi transfer (ID=3), which spends
+// several commitments of the sender into several outputs.
+type MultiTransfer struct {
+	Sender    string        // 发送方公钥（十六进制编码）
+	Regulator ecc.PublicKey // 监管者公钥
+	ChainID   *big.Int      // 链ID，证明与之绑定
+
+	Inputs  []Input
+	Outputs []Output
+
+	RangeBits int // 范围证明位宽，0表示params.DefaultRangeProofBits
+}
+
+// Proofs is the bundle of ciphertexts, commitments and proofs of a transfer.
+// The JSON field names are the ones of the transaction itself.
+type Proofs struct {
+	ErpkC1  hexutil.Bytes `json:"erpkc1"`
+	ErpkC2  hexutil.Bytes `json:"erpkc2"`
+	EspkC1  hexutil.Bytes `json:"espkc1"`
+	EspkC2  hexutil.Bytes `json:"espkc2"`
+	CMRpk   hexutil.Bytes `json:"cmrpk"`
+	CMSpk   hexutil.Bytes `json:"cmspk"`
+	RpkEPg1 hexutil.Bytes `json:"rpkepg1"`
+	RpkEPg2 hexutil.Bytes `json:"rpkepg2"`
+	RpkEPy1 hexutil.Bytes `json:"rpkepy1"`
+	RpkEPy2 hexutil.Bytes `json:"rpkepy2"`
+	RpkEPt1 hexutil.Bytes `json:"rpkept1"`
+	RpkEPt2 hexutil.Bytes `json:"rpkept2"`
+	RpkEPs  hexutil.Bytes `json:"rpkeps"`
+	RpkEPc  hexutil.Bytes `json:"rpkepc"`
+	SpkEPg1 hexutil.Bytes `json:"spkepg1"`
+	SpkEPg2 hexutil.Bytes `json:"spkepg2"`
+	SpkEPy1 hexutil.Bytes `json:"spkepy1"`
+	SpkEPy2 hexutil.Bytes `json:"spkepy2"`
+	SpkEPt1 hexutil.Bytes `json:"spkept1"`
+	SpkEPt2 hexutil.Bytes `json:"spkept2"`
+	SpkEPs  hexutil.Bytes `json:"spkeps"`
+	SpkEPc  hexutil.Bytes `json:"spkepc"`
+	EvSC1   hexutil.Bytes `json:"evsc1"`
+	EvSC2   hexutil.Bytes `json:"evsc2"`
+	EvRC1   hexutil.Bytes `json:"evrc1"`
+	EvRC2   hexutil.Bytes `json:"evrc2"`
+	CmS     hexutil.Bytes `json:"cms"`
+	CmR     hexutil.Bytes `json:"cmr"`
+	ScmFPg1 hexutil.Bytes `json:"scmfpg1"`
+	ScmFPg2 hexutil.Bytes `json:"scmfpg2"`
+	ScmFPy1 hexutil.Bytes `json:"scmfpy1"`
+	ScmFPy2 hexutil.Bytes `json:"scmfpy2"`
+	ScmFPt1 hexutil.Bytes `json:"scmfpt1"`
+	ScmFPt2 hexutil.Bytes `json:"scmfpt2"`
+	ScmFPs  hexutil.Bytes `json:"scmfps"`
+	ScmFPc  hexutil.Bytes `json:"scmfpc"`
+	RcmFPg1 hexutil.Bytes `json:"rcmfpg1"`
+	RcmFPg2 hexutil.Bytes `json:"rcmfpg2"`
+	RcmFPy1 hexutil.Bytes `json:"rcmfpy1"`
+	RcmFPy2 hexutil.Bytes `json:"rcmfpy2"`
+	RcmFPt1 hexutil.Bytes `json:"rcmfpt1"`
+	RcmFPt2 hexutil.Bytes `json:"rcmfpt2"`
+	RcmFPs  hexutil.Bytes `json:"rcmfps"`
+	RcmFPc  hexutil.Bytes `json:"rcmfpc"`
+	EvsBsC1 hexutil.Bytes `json:"evsbsc1"`
+	EvsBsC2 hexutil.Bytes `json:"evsbsc2"`
+	EvOC1   hexutil.Bytes `json:"evoc1"`
+	EvOC2   hexutil.Bytes `json:"evoc2"`
+	CmO     hexutil.Bytes `json:"cmo"`
+	VoEPg1  hexutil.Bytes `json:"voepg1"`
+	VoEPg2  hexutil.Bytes `json:"voepg2"`
+	VoEPy1  hexutil.Bytes `json:"voepy1"`
+	VoEPy2  hexutil.Bytes `json:"voepy2"`
+	VoEPt1  hexutil.Bytes `json:"voept1"`
+	VoEPt2  hexutil.Bytes `json:"voept2"`
+	VoEPs   hexutil.Bytes `json:"voeps"`
+	VoEPc   hexutil.Bytes `json:"voepc"`
+	BPy     hexutil.Bytes `json:"bpy"`
+	BPt     hexutil.Bytes `json:"bpt"`
+	BPsn1   hexutil.Bytes `json:"bpsn1"`
+	BPsn2   hexutil.Bytes `json:"bpsn2"`
+	BPsn3   hexutil.Bytes `json:"bpsn3"`
+	BPc     hexutil.Bytes `json:"bpc"`
+	CmSRC1  hexutil.Bytes `json:"cmsrc1"`
+	CmSRC2  hexutil.Bytes `json:"cmsrc2"`
+	CmRRC1  hexutil.Bytes `json:"cmrrc1"`
+	CmRRC2  hexutil.Bytes `json:"cmrrc2"`
+	RP      hexutil.Bytes `json:"rp"`
+
+	// CmS和CmR的一次性花费公钥，不属于证明字段，隐匿分叉起必须携带
+	SpendKeys []hexutil.Bytes `json:"spendkeys,omitempty"`
+}
+
+// ParsePublicKey parses a hex encoded public key, i.e. P || G1 || G2 || H.
+func ParsePublicKey(pk string) (ecc.PublicKey, error) {
+	if len(pk) < 322 {
+		return ecc.PublicKey{}, errInvalidPublicKey
+	}
+	P, ok1 := new(big.Int).SetString(pk[:64], 16)
+	G1, ok2 := new(big.Int).SetString(pk[64:193], 16)
+	G2, ok3 := new(big.Int).SetString(pk[193:322], 16)
+	H, ok4 := new(big.Int).SetString(pk[322:], 16)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return ecc.PublicKey{}, errInvalidPublicKey
+	}
+	return ecc.PublicKey{P: P, G1: G1, G2: G2, H: H}, nil
+}
+
+// addressKey derives the address public key of an account from its encoded
+// public key, reduced into the group of the regulator.
+func addressKey(pk string, regulator ecc.PublicKey) []byte {
+	h := sha256.Sum256([]byte(pk))
+	addr := new(big.Int).SetBytes(h[:])
+	return addr.Mod(addr, regulator.P).Bytes()
+}
+
+// BuildTransfer encrypts the amounts and addresses of a transfer under the
+// regulator key and generates every proof the node checks for it. Unless the
+// legacy version is asked for, the proofs are bound to the chain ID and the
+// commitments of the transfer.
+func BuildTransfer(t *Transfer) (*Proofs, error) {
+	if len(t.CmO) == 0 || len(t.VoR) == 0 {
+		return nil, errMissingSpent
+	}
+	if t.Regulator.P == nil || t.Regulator.G1 == nil || t.Regulator.G2 == nil || t.Regulator.H == nil {
+		return nil, errInvalidPublicKey
+	}
+	Rpk, err := ParsePublicKey(t.Receiver)
+	if err != nil {
+		return nil, err
+	}
+	Spk, err := ParsePublicKey(t.Sender)
+	if err != nil {
+		return nil, err
+	}
+	bits := t.RangeBits
+	if bits == 0 {
+		bits = params.DefaultRangeProofBits
+	}
+	var (
+		regulator = t.Regulator
+		Vs, Vr    = t.Spend, t.Change
+		addrpk    = addressKey(t.Receiver, regulator) // 接收方地址公钥
+		addspk    = addressKey(t.Sender, regulator)   // 发送方地址公钥
+	)
+	if Vs+Vr < Vs {
+		return nil, errors.New("transfer amount overflow")
+	}
+	// 加密并承诺双方地址公钥
+	Erpk, _CMrpk, _ := ecc.EncryptAddress(regulator, addrpk)
+	Espk, _CMspk, _ := ecc.EncryptAddress(regulator, addspk)
+	_, CMrpk, _ := ecc.EncryptAddress(regulator, addrpk)
+	_, CMspk, _ := ecc.EncryptAddress(regulator, addspk)
+
+	// 花费额承诺
+	EvS, CmS, _ := ecc.EncryptValue(regulator, Vs)
+	Evs, _, _ := ecc.EncryptValue(Rpk, Vs)           // 接收方公钥加密发送金额
+	CmSR, KS, err := ecc.EncryptBlindKey(Rpk, CmS.R) // 接收方可解密的发送承诺随机数及其花费公钥
+	if err != nil {
+		return nil, err
+	}
+
+	// 找零承诺
+	EvR, CmR, _ := ecc.EncryptValue(regulator, Vr)
+	CmRR, KR, err := ecc.EncryptBlindKey(Spk, CmR.R) // 发送方可解密的找零承诺随机数及其花费公钥
+	if err != nil {
+		return nil, err
+	}
+	EvO, CMo, _ := ecc.EncryptValue(regulator, Vr+Vs)
+
+	payload := &types.TransferPayload{
+		Erpk: types.NewCypherText(Erpk), Espk: types.NewCypherText(Espk),
+		CMRpk: CMrpk.Commitment, CMSpk: CMspk.Commitment,
+		EvS: types.NewCypherText(EvS), EvR: types.NewCypherText(EvR),
+		CmS: CmS.Commitment, CmR: CmR.Commitment,
+		EvsBs: types.NewCypherText(Evs),
+		EvO:   types.NewCypherText(EvO),
+		CmO:   t.CmO,
+		CmSR:  types.NewCypherText(CmSR), CmRR: types.NewCypherText(CmRR),
+		SpendKeys: [][]byte{KS, KR},
+	}
+	// 全部证明绑定到链ID和交易的承诺，旧版本的证明不绑定
+	var tr *ecc.Transcript
+	if t.Version != types.LegacyPrivacyVersion {
+		tr = payload.Transcript(t.ChainID)
+	}
+
+	// 地址公钥相等证明
+	payload.RpkEP = types.NewEqualityProof(ecc.GenerateAddressEqualityProof(tr.Fork(types.RpkEPLabel), regulator, regulator, CMrpk, _CMrpk, addrpk))
+	payload.SpkEP = types.NewEqualityProof(ecc.GenerateAddressEqualityProof(tr.Fork(types.SpkEPLabel), regulator, regulator, CMspk, _CMspk, addspk))
+
+	// 花费额和找零承诺格式正确证明
+	payload.ScmFP = types.NewFormatProof(ecc.GenerateFormatProof(tr.Fork(types.ScmFPLabel), regulator, Vs, CmS.R, EvS))
+	payload.RcmFP = types.NewFormatProof(ecc.GenerateFormatProof(tr.Fork(types.RcmFPLabel), regulator, Vr, CmR.R, EvR))
+
+	// 总额度相等证明和会计平衡证明
+	payload.VoEP = types.NewEqualityProof(ecc.GenerateEqualityProof(tr.Fork(types.VoEPLabel), regulator, regulator, CMo, ecc.Commitment{
+		Commitment: t.CmO,
+		R:          t.VoR,
+	}, uint(Vr+Vs)))
+	payload.BP = types.NewBalanceProof(ecc.GenerateBalanceProof(tr.Fork(types.BPLabel), Vr, Vs, Vr+Vs, CmR.Commitment, CmS.Commitment, t.CmO))
+
+	// 花费额和找零的范围证明
+	if payload.RP, err = ecc.GenerateTransferRangeProof(tr.Fork(types.RPLabel), regulator, []uint64{Vs, Vr}, [][]byte{CmS.R, CmR.R}, bits); err != nil {
+		return nil, err
+	}
+	return NewProofs(payload), nil
+}
+
+// BuildMultiTransfer encrypts the amounts and addresses of a multi transfer
+// under the regulator key and generates every proof the node checks for it.
+// The values of the inputs must sum up to the values of the outputs. The
+// proofs are bound to the chain ID and the commitments of the transfer.
+func BuildMultiTransfer(t *MultiTransfer) (*types.MultiTransferPayload, error) {
+	if len(t.Inputs) == 0 || len(t.Inputs) > types.MaxTransferInputs || len(t.Outputs) == 0 || len(t.Outputs) > types.MaxTransferOutputs {
+		return nil, errTransferCount
+	}
+	if t.Regulator.P == nil || t.Regulator.G1 == nil || t.Regulator.G2 == nil || t.Regulator.H == nil {
+		return nil, errInvalidPublicKey
+	}
+	if _, err := ParsePublicKey(t.Sender); err != nil {
+		return nil, err
+	}
+	bits := t.RangeBits
+	if bits == 0 {
+		bits = params.DefaultRangeProofBits
+	}
+	var (
+		regulator = t.Regulator
+		payload   = new(types.MultiTransferPayload)
+		addspk    = addressKey(t.Sender, regulator) // 发送方地址公钥
+
+		vIn   []uint64
+		cmIn  [][]byte
+		sumIn uint64
+
+		inCMs []ecc.Commitment // 被花费承诺金额的新承诺
+	)
+	// 加密并承诺发送方地址公钥
+	Espk, _CMspk, _ := ecc.EncryptAddress(regulator, addspk)
+	_, CMspk, _ := ecc.EncryptAddress(regulator, addspk)
+	payload.Espk = types.NewCypherText(Espk)
+	payload.CMSpk = CMspk.Commitment
+
+	// 每个被花费承诺的金额密文
+	for _, in := range t.Inputs {
+		if len(in.Cm) == 0 || len(in.R) == 0 {
+			return nil, errMissingSpent
+		}
+		if sumIn+in.Value < sumIn {
+			return nil, errors.New("transfer amount overflow")
+		}
+		sumIn += in.Value
+
+		Ev, CM, _ := ecc.EncryptValue(regulator, in.Value)
+		payload.Inputs = append(payload.Inputs, types.TransferInput{
+			Cm: common.CopyBytes(in.Cm),
+			Ev: types.NewCypherText(Ev),
+		})
+		vIn, cmIn, inCMs = append(vIn, in.Value), append(cmIn, in.Cm), append(inCMs, CM)
+	}
+	outs, err := encryptOutputs(regulator, t.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	if sumIn != outs.sum {
+		return nil, errUnbalanced
+	}
+	payload.Outputs, payload.SpendKeys = outs.payload, outs.keys
+
+	// 全部证明绑定到链ID和交易的承诺
+	tr := payload.Transcript(t.ChainID)
+
+	// 发送方地址公钥相等证明和每个被花费承诺的相等证明
+	payload.SpkEP = types.NewEqualityProof(ecc.GenerateAddressEqualityProof(tr.Fork(types.SpkEPLabel), regulator, regulator, CMspk, _CMspk, addspk))
+	for i, in := range t.Inputs {
+		EP := ecc.GenerateEqualityProof(tr.Fork(types.IndexedLabel(types.EPLabel, i)), regulator, regulator, inCMs[i], ecc.Commitment{Commitment: in.Cm, R: in.R}, uint(in.Value))
+		payload.Inputs[i].EP = types.NewEqualityProof(EP)
+	}
+	outs.prove(tr, regulator)
+
+	// 会计平衡证明和全部输出的聚合范围证明
+	BP, err := ecc.GenerateMultiBalanceProof(tr.Fork(types.BPLabel), vIn, outs.values, cmIn, outs.cms)
+	if err != nil {
+		return nil, err
+	}
+	payload.BP = types.NewMultiBalanceProof(BP)
+
+	if payload.RP, err = ecc.GenerateTransferRangeProof(tr.Fork(types.RPLabel), regulator, outs.values, outs.blinds, bits); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// ShieldedInput is a commitment spent by a shielded transfer together with its
+// secrets and the ring of the accumulator which hides it, as returned by
+// eth_getCommitmentRing. The spend secret is recovered by ecc.SpendKey from
+// the blinding ciphertext the commitment was received with; the creator of
+// the commitment, who only knows its blinding factor, cannot spend it.
+type ShieldedInput struct {
+	Input
+	Anchor  common.Hash // 累加器根
+	Ring    uint64      // 环编号
+	Members [][]byte    // 环成员，共ecc.RingSize个，没有承诺的位置为空
+	Keys    [][]byte    // 环成员的花费公钥，与Members一一对应
+	SK      []byte      // 被花费承诺的花费私钥，零化符由其导出
+}
+
+// ShieldedTransfer contains the secrets of a shielded transfer (ID=4), which
+// spends commitments of the accumulator without revealing them.
+type ShieldedTransfer struct {
+	Sender    string        // 发送方公钥（十六进制编码）
+	Regulator ecc.PublicKey // 监管者公钥
+	ChainID   *big.Int      // 链ID，证明与之绑定
+
+	Inputs  []ShieldedInput
+	Outputs []Output
+
+	RangeBits int // 范围证明位宽，0表示params.DefaultRangeProofBits
+}
+
+// BuildShieldedTransfer encrypts the amounts and addresses of a shielded
+// transfer under the regulator key and generates every proof the node checks
+// for it. Every input is replaced by a pseudo commitment to its value, proven
+// to belong to a member of its ring, and reveals its nullifier. The values of
+// the inputs must sum up to the values of the outputs.
+func BuildShieldedTransfer(t *ShieldedTransfer) (*types.ShieldedTransferPayload, error) {
+	if len(t.Inputs) == 0 || len(t.Inputs) > types.MaxTransferInputs || len(t.Outputs) == 0 || len(t.Outputs) > types.MaxTransferOutputs {
+		return nil, errTransferCount
+	}
+	if t.Regulator.P == nil || t.Regulator.G1 == nil || t.Regulator.G2 == nil || t.Regulator.H == nil {
+		return nil, errInvalidPublicKey
+	}
+	if _, err := ParsePublicKey(t.Sender); err != nil {
+		return nil, err
+	}
+	bits := t.RangeBits
+	if bits == 0 {
+		bits = params.DefaultRangeProofBits
+	}
+	var (
+		regulator = t.Regulator
+		payload   = new(types.ShieldedTransferPayload)
+		addspk    = addressKey(t.Sender, regulator) // 发送方地址公钥
+
+		vIn    []uint64
+		sumIn  uint64
+		pseudo []ecc.Commitment // 伪承诺
+		index  []int            // 被花费承诺在环中的位置
+	)
+	// 加密并承诺发送方地址公钥
+	Espk, _CMspk, _ := ecc.EncryptAddress(regulator, addspk)
+	_, CMspk, _ := ecc.EncryptAddress(regulator, addspk)
+	payload.Espk = types.NewCypherText(Espk)
+	payload.CMSpk = CMspk.Commitment
+
+	// 每个输入的零化符和伪承诺
+	for _, in := range t.Inputs {
+		if len(in.Cm) == 0 || len(in.R) == 0 {
+			return nil, errMissingSpent
+		}
+		l := -1
+		for i, member := range in.Members {
+			if bytes.Equal(member, in.Cm) {
+				l = i
+			}
+		}
+		if l < 0 {
+			return nil, errNotInRing
+		}
+		if sumIn+in.Value < sumIn {
+			return nil, errors.New("transfer amount overflow")
+		}
+		sumIn += in.Value
+
+		nullifier, err := ecc.Nullifier(in.SK)
+		if err != nil {
+			return nil, err
+		}
+		Ev, CM, _ := ecc.EncryptValue(regulator, in.Value)
+		payload.Inputs = append(payload.Inputs, types.ShieldedInput{
+			Anchor:    in.Anchor.Bytes(),
+			Ring:      in.Ring,
+			Nullifier: nullifier,
+			Ev:        types.NewCypherText(Ev),
+		})
+		vIn, pseudo, index = append(vIn, in.Value), append(pseudo, CM), append(index, l)
+	}
+	outs, err := encryptOutputs(regulator, t.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	if sumIn != outs.sum {
+		return nil, errUnbalanced
+	}
+	payload.Outputs, payload.SpendKeys = outs.payload, outs.keys
+
+	// 全部证明绑定到链ID、输入的锚点、零化符、伪承诺和输出的承诺
+	tr := payload.Transcript(t.ChainID)
+
+	// 发送方地址公钥相等证明，每个输入的伪承诺格式证明和成员证明
+	payload.SpkEP = types.NewEqualityProof(ecc.GenerateAddressEqualityProof(tr.Fork(types.SpkEPLabel), regulator, regulator, CMspk, _CMspk, addspk))
+	for i, in := range t.Inputs {
+		FP := ecc.GenerateFormatProof(tr.Fork(types.IndexedLabel(types.PFPLabel, i)), regulator, in.Value, pseudo[i].R, payload.Inputs[i].Ev.ECC())
+		payload.Inputs[i].FP = types.NewFormatProof(FP)
+
+		spent := ecc.Commitment{Commitment: in.Cm, R: in.R}
+		MP, _, err := ecc.GenerateMembershipProof(tr.Fork(types.IndexedLabel(types.MPLabel, i)), regulator, in.Members, in.Keys, index[i], spent, pseudo[i], in.SK)
+		if err != nil {
+			return nil, err
+		}
+		if payload.Inputs[i].MP, err = MP.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	outs.prove(tr, regulator)
+
+	// 伪承诺与输出的会计平衡证明和全部输出的聚合范围证明
+	BP, err := ecc.GenerateMultiBalanceProof(tr.Fork(types.BPLabel), vIn, outs.values, payload.Pseudo(), outs.cms)
+	if err != nil {
+		return nil, err
+	}
+	payload.BP = types.NewMultiBalanceProof(BP)
+
+	if payload.RP, err = ecc.GenerateTransferRangeProof(tr.Fork(types.RPLabel), regulator, outs.values, outs.blinds, bits); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Redemption contains the secrets of a redemption (ID=6), which burns a
+// commitment of the sender in exchange for fiat money paid out by the
+// exchange.
+type Redemption struct {
+	Regulator ecc.PublicKey // 监管者公钥
+	Exchange  ecc.PublicKey // 交易所（发行者）公钥
+	ChainID   *big.Int      // 链ID，证明与之绑定
+
+	// 被赎回的承诺。Members为空时公开花费该承诺，否则如隐匿转账的输入以零化符花费
+	Input ShieldedInput
+}
+
+// BuildRedemption encrypts the value of the redeemed commitment for the
+// regulator and the exchange and proves that both ciphertexts hold the same
+// value. A public redemption reuses the commitment as the regulator
+// ciphertext, a shielded one replaces it by a pseudo commitment proven to
+// belong to a member of its ring.
+func BuildRedemption(t *Redemption) (*types.RedeemPayload, error) {
+	for _, pub := range []ecc.PublicKey{t.Regulator, t.Exchange} {
+		if pub.P == nil || pub.G1 == nil || pub.G2 == nil || pub.H == nil {
+			return nil, errInvalidPublicKey
+		}
+	}
+	in := t.Input
+	if len(in.Cm) == 0 || len(in.R) == 0 {
+		return nil, errMissingSpent
+	}
+	var (
+		regulator = t.Regulator
+		payload   = new(types.RedeemPayload)
+		Ev        ecc.CypherText // 监管者公钥加密的赎回金额
+		blind     []byte         // Ev的随机数
+		index     = -1           // 被赎回承诺在环中的位置
+	)
+	if in.Members == nil {
+		// 承诺本身即监管者密文的C1，补上C2 = r*G2
+		r := new(big.Int).SetBytes(in.R)
+		C2 := ecc.ConvertPub(regulator).G2.MultSecret(r)
+		ecc.ZeroizeInt(r)
+		Ev, blind = ecc.CypherText{C1: common.CopyBytes(in.Cm), C2: elliptic.Marshal(ecc.EC.C, C2.X, C2.Y)}, in.R
+	} else {
+		for i, member := range in.Members {
+			if bytes.Equal(member, in.Cm) {
+				index = i
+			}
+		}
+		if index < 0 {
+			return nil, errNotInRing
+		}
+		nullifier, err := ecc.Nullifier(in.SK)
+		if err != nil {
+			return nil, err
+		}
+		var pseudo ecc.Commitment
+		Ev, pseudo, _ = ecc.EncryptValue(regulator, in.Value)
+		blind = pseudo.R
+		payload.Anchor, payload.Ring, payload.Nullifier = in.Anchor.Bytes(), in.Ring, nullifier
+	}
+	Ex, ExCm, _ := ecc.EncryptValue(t.Exchange, in.Value)
+	payload.Ev, payload.Ex = types.NewCypherText(Ev), types.NewCypherText(Ex)
+
+	// 证明绑定到链ID、两个密文以及隐匿赎回的锚点、环编号和零化符
+	tr := payload.Transcript(t.ChainID)
+	if index >= 0 {
+		spent := ecc.Commitment{Commitment: in.Cm, R: in.R}
+		pseudo := ecc.Commitment{Commitment: Ev.C1, R: blind}
+		MP, _, err := ecc.GenerateMembershipProof(tr.Fork(types.MPLabel), regulator, in.Members, in.Keys, index, spent, pseudo, in.SK)
+		if err != nil {
+			return nil, err
+		}
+		if payload.MP, err = MP.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	CEP, err := ecc.GenerateCipherEqualityProof(tr.Fork(types.CEPLabel), regulator, Ev, blind, t.Exchange, Ex, ExCm.R, in.Value)
+	if err != nil {
+		return nil, err
+	}
+	if payload.CEP, err = CEP.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// outputs are the encrypted outputs of a multi or shielded transfer together
+// with the secrets their proofs are generated from.
+type outputs struct {
+	payload []types.TransferOutput
+	values  []uint64
+	cms     [][]byte
+	blinds  [][]byte
+	addrpks [][]byte // 每个输出的接收方地址公钥
+	keys    [][]byte // 每个输出的一次性花费公钥
+	rpkCMs  [][2]ecc.Commitment
+	sum     uint64
+}
+
+// encryptOutputs encrypts the addresses and amounts of the outputs under the
+// regulator key, and the amounts and blinding factors for their receivers.
+func encryptOutputs(regulator ecc.PublicKey, outs []Output) (*outputs, error) {
+	o := new(outputs)
+	// 每个输出的接收方地址、金额承诺，以及给接收方的金额和随机数密文
+	for _, out := range outs {
+		Rpk, err := ParsePublicKey(out.Receiver)
+		if err != nil {
+			return nil, err
+		}
+		if o.sum+out.Value < o.sum {
+			return nil, errors.New("transfer amount overflow")
+		}
+		o.sum += out.Value
+
+		addrpk := addressKey(out.Receiver, regulator)
+		Erpk, _CMrpk, _ := ecc.EncryptAddress(regulator, addrpk)
+		_, CMrpk, _ := ecc.EncryptAddress(regulator, addrpk)
+
+		Ev, Cm, _ := ecc.EncryptValue(regulator, out.Value)
+		EvBs, _, _ := ecc.EncryptValue(Rpk, out.Value)
+		CmR, key, err := ecc.EncryptBlindKey(Rpk, Cm.R)
+		if err != nil {
+			return nil, err
+		}
+
+		o.payload = append(o.payload, types.TransferOutput{
+			Erpk:  types.NewCypherText(Erpk),
+			CMRpk: CMrpk.Commitment,
+			Ev:    types.NewCypherText(Ev),
+			Cm:    Cm.Commitment,
+			EvBs:  types.NewCypherText(EvBs),
+			CmR:   types.NewCypherText(CmR),
+		})
+		o.values, o.cms, o.blinds = append(o.values, out.Value), append(o.cms, Cm.Commitment), append(o.blinds, Cm.R)
+		o.addrpks, o.rpkCMs, o.keys = append(o.addrpks, addrpk), append(o.rpkCMs, [2]ecc.Commitment{CMrpk, _CMrpk}), append(o.keys, key)
+	}
+	return o, nil
+}
+
+// prove generates the address equality and format proof of every output into
+// its payload, which the payload of the transfer shares.
+func (o *outputs) prove(tr *ecc.Transcript, regulator ecc.PublicKey) {
+	// 每个输出的接收方地址公钥相等证明和金额承诺格式证明
+	for i := range o.payload {
+		RpkEP := ecc.GenerateAddressEqualityProof(tr.Fork(types.IndexedLabel(types.RpkEPLabel, i)), regulator, regulator, o.rpkCMs[i][0], o.rpkCMs[i][1], o.addrpks[i])
+		FP := ecc.GenerateFormatProof(tr.Fork(types.IndexedLabel(types.FPLabel, i)), regulator, o.values[i], o.blinds[i], o.payload[i].Ev.ECC())
+		o.payload[i].RpkEP = types.NewEqualityProof(RpkEP)
+		o.payload[i].FP = types.NewFormatProof(FP)
+	}
+}
+
+// NewProofs flattens a transfer payload into a proof bundle.
+func NewProofs(payload *types.TransferPayload) *Proofs {
+	p := new(Proofs)
+	fields := p.fields()
+	for i, field := range payload.Fields() {
+		*fields[i] = common.CopyBytes(*field)
+	}
+	for _, key := range payload.SpendKeys {
+		p.SpendKeys = append(p.SpendKeys, common.CopyBytes(key))
+	}
+	return p
+}
+
+// Payload converts the bundle into the typed payload of a transfer.
+func (p *Proofs) Payload() *types.TransferPayload {
+	payload := new(types.TransferPayload)
+	fields := p.fields()
+	for i, field := range payload.Fields() {
+		*field = common.CopyBytes(*fields[i])
+	}
+	for _, key := range p.SpendKeys {
+		payload.SpendKeys = append(payload.SpendKeys, common.CopyBytes(key))
+	}
+	return payload
+}
+
+// Complete reports whether every field of the bundle is present.
+func (p *Proofs) Complete() bool {
+	for _, field := range p.fields() {
+		if len(*field) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fields returns the bundle fields in the order of types.TransferPayload.Fields.
+func (p *Proofs) fields() []*hexutil.Bytes {
+	return []*hexutil.Bytes{
+		&p.ErpkC1, &p.ErpkC2, &p.EspkC1, &p.EspkC2, &p.CMRpk, &p.CMSpk,
+		&p.RpkEPg1, &p.RpkEPg2, &p.RpkEPy1, &p.RpkEPy2, &p.RpkEPt1, &p.RpkEPt2, &p.RpkEPs, &p.RpkEPc,
+		&p.SpkEPg1, &p.SpkEPg2, &p.SpkEPy1, &p.SpkEPy2, &p.SpkEPt1, &p.SpkEPt2, &p.SpkEPs, &p.SpkEPc,
+		&p.EvSC1, &p.EvSC2, &p.EvRC1, &p.EvRC2, &p.CmS, &p.CmR,
+		&p.ScmFPg1, &p.ScmFPg2, &p.ScmFPy1, &p.ScmFPy2, &p.ScmFPt1, &p.ScmFPt2, &p.ScmFPs, &p.ScmFPc,
+		&p.RcmFPg1, &p.RcmFPg2, &p.RcmFPy1, &p.RcmFPy2, &p.RcmFPt1, &p.RcmFPt2, &p.RcmFPs, &p.RcmFPc,
+		&p.EvsBsC1, &p.EvsBsC2, &p.EvOC1, &p.EvOC2, &p.CmO,
+		&p.VoEPg1, &p.VoEPg2, &p.VoEPy1, &p.VoEPy2, &p.VoEPt1, &p.VoEPt2, &p.VoEPs, &p.VoEPc,
+		&p.BPy, &p.BPt, &p.BPsn1, &p.BPsn2, &p.BPsn3, &p.BPc,
+		&p.CmSRC1, &p.CmSRC2, &p.CmRRC1, &p.CmRRC2, &p.RP,
+	}
+}
+
+// NewTransaction creates an unsigned transfer transaction carrying the bundle.
+// A nil recipient creates a contract.
+func (p *Proofs) NewTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *types.Transaction {
+	return types.NewPrivacyTransaction(nonce, to, amount, gasLimit, gasPrice, data, p.Payload())
+}
@@ -0,0 +1,536 @@
+package privtx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"maskchain/privacy/ecc"
+)
+
+// encodeKey encodes a public key the way wallets hand them to the node.
+func encodeKey(pub ecc.PublicKey) string {
+	return fmt.Sprintf("%064x%x%x%x", pub.P, pub.G1, pub.G2, pub.H)
+}
+
+// pinnedValidator pins the regulator key in the chain config from the genesis
+// block on and returns the validator of the genesis block.
+func pinnedValidator(config *params.ChainConfig, regulator ecc.PublicKey) *core.PrivacyValidator {
+	config.RegulatorKeys = []params.PrivacyKey{types.PubKey(regulator).ConfigKey(common.Big0)}
+	return core.NewPrivacyValidator(config).At(common.Big0)
+}
+
+// Tests that a transfer built on the client side passes the node's checks.
+func TestBuildTransfer(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	sender, _, _ := ecc.GenerateKeys("sender")
+	receiver, _, _ := ecc.GenerateKeys("receiver")
+
+	_, spent, _ := ecc.EncryptValue(regulator, 10)
+	proofs, err := BuildTransfer(&Transfer{
+		Sender:    encodeKey(sender),
+		Receiver:  encodeKey(receiver),
+		Regulator: regulator,
+		ChainID:   big.NewInt(1),
+		Spend:     7,
+		Change:    3,
+		CmO:       spent.Commitment,
+		VoR:       spent.R,
+	})
+	if err != nil {
+		t.Fatalf("failed to build transfer: %v", err)
+	}
+	if !proofs.Complete() {
+		t.Fatalf("incomplete proof bundle")
+	}
+	// The bundle must survive the JSON round trip of eth_sendPrivateTransaction
+	blob, err := json.Marshal(proofs)
+	if err != nil {
+		t.Fatalf("failed to encode bundle: %v", err)
+	}
+	decoded := new(Proofs)
+	if err := json.Unmarshal(blob, decoded); err != nil {
+		t.Fatalf("failed to decode bundle: %v", err)
+	}
+	to := common.HexToAddress("0x01")
+	tx := decoded.NewTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil)
+
+	validator := pinnedValidator(&params.ChainConfig{ChainID: big.NewInt(1)}, regulator)
+	if err := validator.VerifyTransferProofs(tx); err != nil {
+		t.Fatalf("client built transfer rejected: %v", err)
+	}
+	// Tampering with the bundle must be caught by the node
+	decoded.CmR = proofs.CmS
+	tx = decoded.NewTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil)
+	if err := validator.VerifyTransferProofs(tx); err == nil {
+		t.Fatalf("tampered transfer accepted")
+	}
+	// Points off the curve are reported with the proof and field they are in
+	decoded.CmR = proofs.CmR
+	decoded.RcmFPt1 = append(hexutil.Bytes{}, proofs.RcmFPt1...)
+	decoded.RcmFPt1[64] ^= 1
+	tx = decoded.NewTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil)
+	err = validator.VerifyTransferProofs(tx)
+	if !errors.Is(err, core.ErrMalformedPrivacyTx) || !strings.Contains(err.Error(), types.RcmFPLabel+".T1: "+ecc.ErrPointNotOnCurve.Error()) {
+		t.Fatalf("transfer with invalid point: have %v", err)
+	}
+}
+
+func TestBuildTransferInvalid(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	sender, _, _ := ecc.GenerateKeys("sender")
+
+	_, spent, _ := ecc.EncryptValue(regulator, 10)
+	tests := []*Transfer{
+		{Sender: encodeKey(sender), Receiver: "0x00", Regulator: regulator, Spend: 1, CmO: spent.Commitment, VoR: spent.R},
+		{Sender: encodeKey(sender), Receiver: encodeKey(sender), Regulator: regulator, Spend: 1},
+		{Sender: encodeKey(sender), Receiver: encodeKey(sender), Spend: 1, CmO: spent.Commitment, VoR: spent.R},
+		{Sender: encodeKey(sender), Receiver: encodeKey(sender), Regulator: regulator, Spend: 1 << 32, CmO: spent.Commitment, VoR: spent.R},
+	}
+	for i, tt := range tests {
+		if _, err := BuildTransfer(tt); err == nil {
+			t.Errorf("test %d: invalid transfer accepted", i)
+		}
+	}
+}
+
+// Tests that a multi transfer merging several coins into several outputs
+// passes the node's checks and survives the wire.
+func TestBuildMultiTransfer(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	sender, _, _ := ecc.GenerateKeys("sender")
+	alice, _, _ := ecc.GenerateKeys("alice")
+	bob, _, _ := ecc.GenerateKeys("bob")
+
+	var inputs []Input
+	for _, v := range []uint64{4, 5, 6} {
+		_, cm, _ := ecc.EncryptValue(regulator, v)
+		inputs = append(inputs, Input{Cm: cm.Commitment, R: cm.R, Value: v})
+	}
+	payload, err := BuildMultiTransfer(&MultiTransfer{
+		Sender:    encodeKey(sender),
+		Regulator: regulator,
+		ChainID:   big.NewInt(1),
+		Inputs:    inputs,
+		Outputs: []Output{
+			{Receiver: encodeKey(alice), Value: 7},
+			{Receiver: encodeKey(bob), Value: 2},
+			{Receiver: encodeKey(sender), Value: 6},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to build multi transfer: %v", err)
+	}
+	validator := pinnedValidator(&params.ChainConfig{ChainID: big.NewInt(1)}, regulator)
+
+	to := common.HexToAddress("0x01")
+	tx := types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, payload)
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatalf("failed to encode multi transfer: %v", err)
+	}
+	decoded := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, decoded); err != nil {
+		t.Fatalf("failed to decode multi transfer: %v", err)
+	}
+	if decoded.ID() != uint64(types.MultiTransferTxType) || !decoded.IsTransfer() {
+		t.Fatalf("multi transfer not recognised: ID %d", decoded.ID())
+	}
+	if len(decoded.SpentCMs()) != 3 || len(decoded.CreatedCMs()) != 3 {
+		t.Fatalf("commitment count mismatch: spent %d, created %d", len(decoded.SpentCMs()), len(decoded.CreatedCMs()))
+	}
+	if err := validator.VerifyTransfer(decoded); err != nil {
+		t.Fatalf("client built multi transfer rejected: %v", err)
+	}
+	// Tampering with the outputs or listing an input twice must be caught
+	tampered := *payload
+	tampered.Outputs = append([]types.TransferOutput{}, payload.Outputs...)
+	tampered.Outputs[0], tampered.Outputs[1] = payload.Outputs[1], payload.Outputs[0]
+	if err := validator.VerifyTransfer(types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &tampered)); err == nil {
+		t.Errorf("multi transfer with reordered outputs accepted")
+	}
+	tampered = *payload
+	tampered.Inputs = append([]types.TransferInput{}, payload.Inputs...)
+	tampered.Inputs[1] = payload.Inputs[0]
+	if err := validator.VerifyTransfer(types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &tampered)); err != core.ErrDoubleSpentCM {
+		t.Errorf("multi transfer with duplicate input: have %v, want %v", err, core.ErrDoubleSpentCM)
+	}
+	tampered = *payload
+	tampered.Outputs = payload.Outputs[:2]
+	if err := validator.VerifyTransfer(types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &tampered)); err != core.ErrMalformedPrivacyTx {
+		t.Errorf("multi transfer with dropped output: have %v, want %v", err, core.ErrMalformedPrivacyTx)
+	}
+}
+
+func TestBuildMultiTransferInvalid(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	sender, _, _ := ecc.GenerateKeys("sender")
+
+	_, cm, _ := ecc.EncryptValue(regulator, 10)
+	input := Input{Cm: cm.Commitment, R: cm.R, Value: 10}
+	tests := []*MultiTransfer{
+		// Unbalanced
+		{Sender: encodeKey(sender), Regulator: regulator, Inputs: []Input{input}, Outputs: []Output{{Receiver: encodeKey(sender), Value: 11}}},
+		// No inputs or outputs
+		{Sender: encodeKey(sender), Regulator: regulator, Outputs: []Output{{Receiver: encodeKey(sender), Value: 10}}},
+		{Sender: encodeKey(sender), Regulator: regulator, Inputs: []Input{input}},
+		// Missing blinding factor
+		{Sender: encodeKey(sender), Regulator: regulator, Inputs: []Input{{Cm: cm.Commitment, Value: 10}}, Outputs: []Output{{Receiver: encodeKey(sender), Value: 10}}},
+		// Too many outputs
+		{Sender: encodeKey(sender), Regulator: regulator, Inputs: []Input{input}, Outputs: make([]Output, types.MaxTransferOutputs+1)},
+	}
+	for i, tt := range tests {
+		if _, err := BuildMultiTransfer(tt); err == nil {
+			t.Errorf("test %d: invalid multi transfer accepted", i)
+		}
+	}
+}
+
+// shieldedRing returns ring number ring of the accumulator in statedb the
+// way eth_getCommitmentRing does.
+func shieldedRing(statedb *state.StateDB, ring uint64) ShieldedInput {
+	acc := statedb.Accumulator()
+	from, to, _ := ecc.RingBounds(ring, acc.Size)
+	in := ShieldedInput{Anchor: acc.Root(), Ring: ring, Members: make([][]byte, ecc.RingSize), Keys: make([][]byte, ecc.RingSize)}
+	for i := from; i < to; i++ {
+		in.Members[i-from], in.Keys[i-from] = statedb.AccumulatorLeaf(i)
+	}
+	return in
+}
+
+// ownedCoin creates a commitment to value paid to pub the way transfers do,
+// returning it with its secrets, its spend key and the spend secret its owner
+// recovers with priv.
+func ownedCoin(t *testing.T, regulator, pub ecc.PublicKey, priv ecc.PrivateKey, value uint64) (Input, []byte, []byte) {
+	_, cm, _ := ecc.EncryptValue(regulator, value)
+	C, key, err := ecc.EncryptBlindKey(pub, cm.R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, err := ecc.SpendKey(priv, C, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Input{Cm: cm.Commitment, R: cm.R, Value: value}, key, sk
+}
+
+// Tests that a shielded transfer spending commitments of the accumulator
+// passes the node's checks, that its nullifiers cannot be revealed twice and
+// that commitments of the accumulator cannot be spent transparently.
+func TestBuildShieldedTransfer(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	sender, senderPriv, _ := ecc.GenerateKeys("sender")
+	alice, _, _ := ecc.GenerateKeys("alice")
+
+	// Two coins of the sender among the commitments of others, in two rings
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	var (
+		cms, keys [][]byte
+		coins     []Input
+		sks       [][]byte
+	)
+	for i := 0; i < ecc.RingSize+8; i++ {
+		coin, key, sk := ownedCoin(t, regulator, sender, senderPriv, uint64(i))
+		cms, keys = append(cms, coin.Cm), append(keys, key)
+		if i == 3 || i == ecc.RingSize+5 {
+			coins, sks = append(coins, coin), append(sks, sk)
+		}
+	}
+	if err := statedb.AppendCommitments(cms, keys); err != nil {
+		t.Fatal(err)
+	}
+	for _, cm := range cms {
+		statedb.SetCommitment(types.NewDefaultCM((*hexutil.Bytes)(&cm)).Hash(), state.CommitmentShielded)
+	}
+	inputs := []ShieldedInput{shieldedRing(statedb, 0), shieldedRing(statedb, 1)}
+	inputs[0].Input, inputs[1].Input = coins[0], coins[1]
+	inputs[0].SK, inputs[1].SK = sks[0], sks[1]
+
+	payload, err := BuildShieldedTransfer(&ShieldedTransfer{
+		Sender:    encodeKey(sender),
+		Regulator: regulator,
+		ChainID:   big.NewInt(1),
+		Inputs:    inputs,
+		Outputs: []Output{
+			{Receiver: encodeKey(alice), Value: 30},
+			{Receiver: encodeKey(sender), Value: 10},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to build shielded transfer: %v", err)
+	}
+	var (
+		config    = &params.ChainConfig{ChainID: big.NewInt(1), CMPoolBlock: big.NewInt(0), ShieldedBlock: big.NewInt(1)}
+		validator = pinnedValidator(config, regulator)
+		to        = common.HexToAddress("0x01")
+	)
+	tx := types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, payload)
+	blob, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatalf("failed to encode shielded transfer: %v", err)
+	}
+	decoded := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, decoded); err != nil {
+		t.Fatalf("failed to decode shielded transfer: %v", err)
+	}
+	if !decoded.IsShielded() || !decoded.IsTransfer() || len(decoded.SpentCMs()) != 0 || len(decoded.Nullifiers()) != 2 {
+		t.Fatalf("shielded transfer not recognised: ID %d", decoded.ID())
+	}
+	for i, sk := range sks {
+		if nullifier, _ := ecc.Nullifier(sk); !bytes.Equal(*decoded.Nullifiers()[i], nullifier) {
+			t.Errorf("nullifier %d mismatch", i)
+		}
+	}
+	if len(decoded.SpendKeys()) != 2 {
+		t.Fatalf("spend key count mismatch: have %d, want 2", len(decoded.SpendKeys()))
+	}
+	if err := validator.VerifyTransfer(decoded); err != core.ErrNoShieldedState {
+		t.Fatalf("shielded transfer without state: have %v, want %v", err, core.ErrNoShieldedState)
+	}
+	shielded := validator.WithState(statedb)
+	if err := shielded.VerifyTransfer(decoded); err != nil {
+		t.Fatalf("client built shielded transfer rejected: %v", err)
+	}
+
+	// Anchors and rings the accumulator never had, a moved ring and a swapped
+	// nullifier must be caught
+	tamper := func(f func(in *types.ShieldedInput)) error {
+		tampered := *payload
+		tampered.Inputs = append([]types.ShieldedInput{}, payload.Inputs...)
+		f(&tampered.Inputs[0])
+		return shielded.VerifyTransfer(types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &tampered))
+	}
+	if err := tamper(func(in *types.ShieldedInput) { in.Anchor = common.Hash{1}.Bytes() }); err != core.ErrUnknownAnchor {
+		t.Errorf("unknown anchor: have %v, want %v", err, core.ErrUnknownAnchor)
+	}
+	if err := tamper(func(in *types.ShieldedInput) { in.Ring = 2 }); err != core.ErrUnknownAnchor {
+		t.Errorf("ring beyond the anchor: have %v, want %v", err, core.ErrUnknownAnchor)
+	}
+	if err := tamper(func(in *types.ShieldedInput) { in.Ring = 1 }); err == nil {
+		t.Errorf("shielded transfer with moved ring accepted")
+	}
+	if err := tamper(func(in *types.ShieldedInput) { in.Nullifier = payload.Inputs[1].Nullifier }); err != core.ErrDoubleSpentCM {
+		t.Errorf("duplicate nullifier: have %v, want %v", err, core.ErrDoubleSpentCM)
+	}
+	other, _ := ecc.Nullifier(ecc.RandScalar().Bytes())
+	if err := tamper(func(in *types.ShieldedInput) { in.Nullifier = other }); err == nil {
+		t.Errorf("shielded transfer with foreign nullifier accepted")
+	}
+
+	// Whoever paid the coins knows their blinding factors, but not the spend
+	// secrets: spending them with the blinding factor as secret must fail
+	stolen := append([]ShieldedInput{}, inputs...)
+	for i := range stolen {
+		stolen[i].SK = stolen[i].R
+	}
+	theft, err := BuildShieldedTransfer(&ShieldedTransfer{
+		Sender:    encodeKey(alice),
+		Regulator: regulator,
+		ChainID:   big.NewInt(1),
+		Inputs:    stolen,
+		Outputs:   []Output{{Receiver: encodeKey(alice), Value: 40}},
+	})
+	if err != nil {
+		t.Fatalf("failed to build shielded transfer: %v", err)
+	}
+	if bytes.Equal(theft.Inputs[0].Nullifier, payload.Inputs[0].Nullifier) {
+		t.Errorf("nullifier derived from the blinding factor")
+	}
+	if err := shielded.VerifyTransfer(types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, theft)); err != core.ErrVerifyMembershipProof {
+		t.Errorf("spend with the blinding factor: have %v, want %v", err, core.ErrVerifyMembershipProof)
+	}
+
+	// The block of the transfer is valid after the fork only, and a nullifier
+	// revealed in the parent state cannot be revealed again
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{decoded}, nil, nil)
+	if err := shielded.ValidateBlock(block); err != nil {
+		t.Fatalf("shielded transfer block rejected: %v", err)
+	}
+	keyless := *payload
+	keyless.SpendKeys = payload.SpendKeys[:1]
+	if err := shielded.ValidateBlock(types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &keyless)}, nil, nil)); err == nil || !strings.Contains(err.Error(), core.ErrMissingSpendKey.Error()) {
+		t.Errorf("output without spend key: have %v, want %v", err, core.ErrMissingSpendKey)
+	}
+	early := types.NewBlock(&types.Header{Number: big.NewInt(0)}, []*types.Transaction{decoded}, nil, nil)
+	if err := shielded.ValidateBlock(early); err == nil || !strings.Contains(err.Error(), core.ErrShieldedFork.Error()) {
+		t.Errorf("shielded transfer before the fork: have %v, want %v", err, core.ErrShieldedFork)
+	}
+	revealed := statedb.Copy()
+	revealed.AddNullifier(types.NullifierHash(*decoded.Nullifiers()[0]))
+	if err := validator.WithState(revealed).ValidateBlock(types.NewBlock(&types.Header{Number: big.NewInt(2)}, []*types.Transaction{decoded}, nil, nil)); err == nil || !strings.Contains(err.Error(), core.ErrDoubleSpentCM.Error()) {
+		t.Errorf("nullifier revealed twice: have %v, want %v", err, core.ErrDoubleSpentCM)
+	}
+
+	// The coins of the accumulator can no longer be spent transparently
+	transparent, err := BuildMultiTransfer(&MultiTransfer{
+		Sender:    encodeKey(sender),
+		Regulator: regulator,
+		ChainID:   big.NewInt(1),
+		Inputs:    coins,
+		Outputs:   []Output{{Receiver: encodeKey(alice), Value: 40}},
+	})
+	if err != nil {
+		t.Fatalf("failed to build multi transfer: %v", err)
+	}
+	spend := types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, transparent)
+	if err := shielded.ValidateBlock(types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{spend}, nil, nil)); err == nil || !strings.Contains(err.Error(), core.ErrShieldedCM.Error()) {
+		t.Errorf("transparent spend of a shielded commitment: have %v, want %v", err, core.ErrShieldedCM)
+	}
+}
+
+// redeemedValue decrypts the value a redemption encrypts for the exchange.
+func redeemedValue(t *testing.T, priv ecc.PrivateKey, p *types.RedeemPayload) uint64 {
+	c1, err := ecc.DecodePoint(p.Ex.C1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := ecc.DecodePoint(p.Ex.C2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := ecc.ConvertPriv(priv).DecryptCM(ecc.Enc{P1: c1, P2: c2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// Tests that public and shielded redemptions pass the node's checks, reveal
+// their value to the exchange only and burn their commitment once.
+func TestBuildRedemption(t *testing.T) {
+	regulator, _, _ := ecc.GenerateKeys("regulator")
+	exchange, exchangePriv, _ := ecc.GenerateKeys("exchange")
+	owner, ownerPriv, _ := ecc.GenerateKeys("owner")
+	_, coin, _ := ecc.EncryptValue(regulator, 25)
+
+	var (
+		config = &params.ChainConfig{
+			ChainID:       big.NewInt(1),
+			ExchangeKeys:  []params.PrivacyKey{types.PubKey(exchange).ConfigKey(common.Big0)},
+			CMPoolBlock:   big.NewInt(0),
+			ShieldedBlock: big.NewInt(5),
+		}
+		validator = pinnedValidator(config, regulator)
+		to        = common.HexToAddress("0x01")
+	)
+	payload, err := BuildRedemption(&Redemption{
+		Regulator: regulator,
+		Exchange:  exchange,
+		ChainID:   big.NewInt(1),
+		Input:     ShieldedInput{Input: Input{Cm: coin.Commitment, R: coin.R, Value: 25}},
+	})
+	if err != nil {
+		t.Fatalf("failed to build redemption: %v", err)
+	}
+	blob, err := rlp.EncodeToBytes(types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, payload))
+	if err != nil {
+		t.Fatalf("failed to encode redemption: %v", err)
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, tx); err != nil {
+		t.Fatalf("failed to decode redemption: %v", err)
+	}
+	if !tx.IsTransfer() || tx.IsShielded() || len(tx.SpentCMs()) != 1 || !bytes.Equal(*tx.SpentCMs()[0], coin.Commitment) || len(tx.CreatedCMs()) != 0 {
+		t.Fatalf("redemption not recognised: ID %d", tx.ID())
+	}
+	if err := validator.VerifyTransfer(tx); err != nil {
+		t.Fatalf("client built redemption rejected: %v", err)
+	}
+	if have := redeemedValue(t, exchangePriv, payload); have != 25 {
+		t.Errorf("exchange decrypted %d, want 25", have)
+	}
+	if err := core.NewPrivacyValidator(&params.ChainConfig{ChainID: big.NewInt(1), RegulatorKeys: config.RegulatorKeys}).At(common.Big0).VerifyTransfer(tx); err != core.ErrNoExchangeKey {
+		t.Errorf("redemption without exchange key: have %v, want %v", err, core.ErrNoExchangeKey)
+	}
+	// A ciphertext of another value for the exchange must be caught
+	tampered := *payload
+	Ex, _, _ := ecc.EncryptValue(exchange, 250)
+	tampered.Ex = types.NewCypherText(Ex)
+	if err := validator.VerifyTransfer(types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, &tampered)); err != core.ErrVerifyRedeemProof {
+		t.Errorf("redemption of another value: have %v, want %v", err, core.ErrVerifyRedeemProof)
+	}
+
+	// The commitment has to be unspent in the parent state of the block
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{tx}, nil, nil)
+	if err := validator.ValidateBlock(block); err != core.ErrNoCMPoolState {
+		t.Errorf("redemption block without state: have %v, want %v", err, core.ErrNoCMPoolState)
+	}
+	pool, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	unpooled := *config
+	unpooled.CMPoolBlock, unpooled.ShieldedBlock = nil, nil
+	if err := core.NewPrivacyValidator(&unpooled).WithState(pool).ValidateBlock(block); err == nil || !strings.Contains(err.Error(), core.ErrNoCMPoolFork.Error()) {
+		t.Errorf("redemption block without commitment pool fork: have %v, want %v", err, core.ErrNoCMPoolFork)
+	}
+	if err := validator.WithState(pool).ValidateBlock(block); err == nil || !strings.Contains(err.Error(), core.ErrInvalidCM.Error()) {
+		t.Errorf("redemption of an unknown commitment: have %v, want %v", err, core.ErrInvalidCM)
+	}
+	hash := types.NewDefaultCM((*hexutil.Bytes)(&coin.Commitment)).Hash()
+	pool.SetCommitment(hash, state.CommitmentUnspent)
+	if err := validator.WithState(pool).ValidateBlock(block); err != nil {
+		t.Fatalf("redemption block rejected: %v", err)
+	}
+	pool.SetCommitment(hash, state.CommitmentSpent)
+	if err := validator.WithState(pool).ValidateBlock(types.NewBlock(&types.Header{Number: big.NewInt(2)}, []*types.Transaction{tx}, nil, nil)); err == nil || !strings.Contains(err.Error(), core.ErrDoubleSpentCM.Error()) {
+		t.Errorf("commitment redeemed twice: have %v, want %v", err, core.ErrDoubleSpentCM)
+	}
+
+	// Commitments of the accumulator are redeemed by their nullifier
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	var (
+		cms, keys [][]byte
+		kept      Input
+		keptSK    []byte
+	)
+	for i := 0; i < 8; i++ {
+		coin, key, sk := ownedCoin(t, regulator, owner, ownerPriv, uint64(i))
+		cms, keys = append(cms, coin.Cm), append(keys, key)
+		if i == 6 {
+			kept, keptSK = coin, sk
+		}
+	}
+	if err := statedb.AppendCommitments(cms, keys); err != nil {
+		t.Fatal(err)
+	}
+	input := shieldedRing(statedb, 0)
+	input.Input, input.SK = kept, keptSK
+	shieldedPayload, err := BuildRedemption(&Redemption{Regulator: regulator, Exchange: exchange, ChainID: big.NewInt(1), Input: input})
+	if err != nil {
+		t.Fatalf("failed to build shielded redemption: %v", err)
+	}
+	shieldedTx := types.NewPrivacyTransaction(0, &to, new(big.Int), 21000, big.NewInt(1), nil, shieldedPayload)
+	if !shieldedTx.IsShielded() || len(shieldedTx.SpentCMs()) != 0 || len(shieldedTx.Nullifiers()) != 1 {
+		t.Fatalf("shielded redemption not recognised")
+	}
+	if nullifier, _ := ecc.Nullifier(keptSK); !bytes.Equal(*shieldedTx.Nullifiers()[0], nullifier) {
+		t.Errorf("nullifier mismatch")
+	}
+	if err := validator.VerifyTransfer(shieldedTx); err != core.ErrNoShieldedState {
+		t.Errorf("shielded redemption without state: have %v, want %v", err, core.ErrNoShieldedState)
+	}
+	shielded := validator.WithState(statedb)
+	if err := shielded.VerifyTransfer(shieldedTx); err != nil {
+		t.Fatalf("client built shielded redemption rejected: %v", err)
+	}
+	if have := redeemedValue(t, exchangePriv, shieldedPayload); have != 6 {
+		t.Errorf("exchange decrypted %d, want 6", have)
+	}
+	early := types.NewBlock(&types.Header{Number: big.NewInt(4)}, []*types.Transaction{shieldedTx}, nil, nil)
+	if err := shielded.ValidateBlock(early); err == nil || !strings.Contains(err.Error(), core.ErrShieldedFork.Error()) {
+		t.Errorf("shielded redemption before the fork: have %v, want %v", err, core.ErrShieldedFork)
+	}
+	if err := shielded.ValidateBlock(types.NewBlock(&types.Header{Number: big.NewInt(5)}, []*types.Transaction{shieldedTx}, nil, nil)); err != nil {
+		t.Errorf("shielded redemption block rejected: %v", err)
+	}
+}
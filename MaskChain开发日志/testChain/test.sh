@@ -6,8 +6,8 @@ SM="0" #和genesis.json中cryptoType值相等，若genesis.json中无此值，
 genesisPath=""$gethDir"/dev/testChain/genesis.json"
 gethCodeDir=""$gethDir"/go-ethereum-release-1.9"
 gethBinDir=""$gethCodeDir"/build/bin/geth"
-regulatorIP="39.106.173.191"
-exchangeIP="127.0.0.1"
+regulatorURL="http://39.106.173.191:1423/regkey?chainID=1"
+exchangeURL="http://127.0.0.1:1323/pubpub"
 rpcAPI="eth,net,web3,personal,admin,txpool,debug,miner"
 identity="666"
 kill -9 $(lsof -i:8545 | awk '{print $2}')
@@ -31,11 +31,12 @@ cd $gethCodeDir \
 && echo "helloworld" >> init \
 && rm -rf ./* \
 && mkdir node0 node1 node2 node3 node4\
-&& $gethBinDir --datadir node0 --regulatorip $regulatorIP --exchangeip $exchangeIP init $genesisPath \
-&& $gethBinDir --datadir node1 --regulatorip $regulatorIP --exchangeip $exchangeIP init $genesisPath \
-&& $gethBinDir --datadir node2 --regulatorip $regulatorIP --exchangeip $exchangeIP init $genesisPath \
-&& $gethBinDir --datadir node3 --regulatorip $regulatorIP --exchangeip $exchangeIP init $genesisPath \
-&& $gethBinDir --datadir node4 --regulatorip $regulatorIP --exchangeip $exchangeIP init $genesisPath \
+&& sleep 3 && curl -s $regulatorURL > regulator.json && curl -s $exchangeURL > exchange.json \
+&& $gethBinDir --datadir node0 --regulatorkey regulator.json --exchangekey exchange.json init $genesisPath \
+&& $gethBinDir --datadir node1 --regulatorkey regulator.json --exchangekey exchange.json init $genesisPath \
+&& $gethBinDir --datadir node2 --regulatorkey regulator.json --exchangekey exchange.json init $genesisPath \
+&& $gethBinDir --datadir node3 --regulatorkey regulator.json --exchangekey exchange.json init $genesisPath \
+&& $gethBinDir --datadir node4 --regulatorkey regulator.json --exchangekey exchange.json init $genesisPath \
 && cd $gethCodeDir \
 && if [ "$SM" -eq "0" ];then
         cd ../dev/testChain/keys \
@@ -53,8 +54,8 @@ cd $gethCodeDir \
         && cp UTC--2021-01-15T06-40-38.096210000Z--20bb6449fdb0685696a6f48566e9899d95b3684d ""$testDataDir"/node4/keystore"
 fi \
 && cd $gethCodeDir
-./build/bin/geth --identity $identity --rpc --rpcport "8545" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node0 --ipcpath "$testDataDir"/node0/geth.ipc --port "30303" --ipcpath "node0.rpc" --nodiscover --allow-insecure-unlock --regulatorip $regulatorIP --exchangeip $exchangeIP 2>>"$testDataDir"/node0/log.log&
-./build/bin/geth --identity $identity --rpc --rpcport "8546" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node1 --ipcpath "$testDataDir"/node1/geth.ipc --port "30304" --ipcpath "node1.rpc" --nodiscover --allow-insecure-unlock --regulatorip $regulatorIP --exchangeip $exchangeIP 2>>"$testDataDir"/node1/log.log&
-./build/bin/geth --identity $identity --rpc --rpcport "8547" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node2 --ipcpath "$testDataDir"/node2/geth.ipc --port "30305" --ipcpath "node2.rpc" --nodiscover --allow-insecure-unlock --regulatorip $regulatorIP --exchangeip $exchangeIP 2>>"$testDataDir"/node2/log.log&
-./build/bin/geth --identity $identity --rpc --rpcport "8548" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node3 --ipcpath "$testDataDir"/node3/geth.ipc --port "30306" --ipcpath "node3.rpc" --nodiscover --allow-insecure-unlock --regulatorip $regulatorIP --exchangeip $exchangeIP 2>>"$testDataDir"/node3/log.log&
-./build/bin/geth --identity $identity --rpc --rpcport "8549" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node4 --ipcpath "$testDataDir"/node4/geth.ipc --port "30307" --ipcpath "node4.rpc" --nodiscover --allow-insecure-unlock --regulatorip $regulatorIP --exchangeip $exchangeIP 2>>"$testDataDir"/node4/log.log&
+./build/bin/geth --identity $identity --rpc --rpcport "8545" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node0 --ipcpath "$testDataDir"/node0/geth.ipc --port "30303" --ipcpath "node0.rpc" --nodiscover --allow-insecure-unlock 2>>"$testDataDir"/node0/log.log&
+./build/bin/geth --identity $identity --rpc --rpcport "8546" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node1 --ipcpath "$testDataDir"/node1/geth.ipc --port "30304" --ipcpath "node1.rpc" --nodiscover --allow-insecure-unlock 2>>"$testDataDir"/node1/log.log&
+./build/bin/geth --identity $identity --rpc --rpcport "8547" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node2 --ipcpath "$testDataDir"/node2/geth.ipc --port "30305" --ipcpath "node2.rpc" --nodiscover --allow-insecure-unlock 2>>"$testDataDir"/node2/log.log&
+./build/bin/geth --identity $identity --rpc --rpcport "8548" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node3 --ipcpath "$testDataDir"/node3/geth.ipc --port "30306" --ipcpath "node3.rpc" --nodiscover --allow-insecure-unlock 2>>"$testDataDir"/node3/log.log&
+./build/bin/geth --identity $identity --rpc --rpcport "8549" --rpccorsdomain "*" --rpcapi $rpcAPI --datadir "$testDataDir"/node4 --ipcpath "$testDataDir"/node4/geth.ipc --port "30307" --ipcpath "node4.rpc" --nodiscover --allow-insecure-unlock 2>>"$testDataDir"/node4/log.log&
@@ -1,8 +0,0 @@
-package bp
-
-import "testing"
-
-func Testdev(t *testing.T) {
-
-}
-
@@ -1,26 +0,0 @@
-package bp
-
-import (
-	"testing"
-)
-
-// 这个例子就是证明，我不告诉你x1, x2, 但x1, x2 满足 3*x1 + 4* x2 = 11
-func TestLepVerify1(t *testing.T) {
-	//EC = NewECPrimeGroupKey(64)
-	//// Testing smallest number in range
-	//g1 := EC.G.Mult(big.NewInt(1))
-	//g2 := EC.G.Mult(big.NewInt(2))
-	//
-	//x1 := big.NewInt(1)
-	//x2 := big.NewInt(2)
-	//a1 := big.NewInt(3)
-	//a2 := big.NewInt(4)
-	//b := big.NewInt(11)
-	//
-	//
-	//if LepVerify(Linear_equation_proof([]ECPoint{g1,g2},[]*big.Int{x1,x2},[]*big.Int{a1,a2},b)) {
-	//	fmt.Println("Linear_equation_proof Verification works")
-	//} else {
-	//	t.Error("*****Linear_equation_proof FAILURE")
-	//}
-}
\ No newline at end of file
@@ -0,0 +1,222 @@
+// Package coindb 是钱包本地的承诺（币）数据库。它记录用户拥有的承诺及其随机数和金额，
+// 在承诺被花费时将其标记为已花费，并为付款金额自动选择要花费的承诺。
+//
+// 数据库以JSON文件的形式保存在本地，每个用户一个文件。
+package coindb
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient unspent coins")
+	ErrUnknownCoin       = errors.New("unknown coin")
+)
+
+// Coin 用户拥有的一个承诺
+type Coin struct {
+	Cm      string `json:"cm"`                // 承诺，0x开头十六进制
+	Vor     string `json:"vor"`               // 承诺随机数，0x开头十六进制
+	Amount  uint64 `json:"amount"`            // 金额
+	Hash    string `json:"hash"`              // 产生该承诺的交易哈希
+	Block   uint64 `json:"block"`             // 产生该承诺的区块号
+	Spent   bool   `json:"spent"`             // 是否已花费
+	SpentBy string `json:"spentBy,omitempty"` // 花费该承诺的交易哈希
+	Locked  string `json:"locked,omitempty"`  // 已发出但尚未上链的花费交易哈希
+	Error   string `json:"error,omitempty"`   // 无法打开承诺或求出花费私钥的原因，此时不计入余额
+
+	// 隐匿分叉起创建的承诺带有一次性花费公钥，只能以其私钥隐匿花费
+	SK        string `json:"sk,omitempty"`        // 花费私钥，0x开头十六进制
+	Nullifier string `json:"nullifier,omitempty"` // 零化符，承诺被隐匿花费时公开
+}
+
+// DB 一个用户的承诺数据库
+type DB struct {
+	path string
+	lock sync.Mutex
+
+	Head  uint64           `json:"head"`  // 已扫描到的区块号
+	Coins map[string]*Coin `json:"coins"` // 以承诺为索引
+}
+
+// Open 打开（不存在时新建）指定路径的承诺数据库
+func Open(path string) (*DB, error) {
+	db := &DB{path: path, Coins: make(map[string]*Coin)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, db); err != nil {
+		return nil, err
+	}
+	if db.Coins == nil {
+		db.Coins = make(map[string]*Coin)
+	}
+	return db, nil
+}
+
+// Save 将数据库写回文件，先写临时文件再替换，避免写入中断损坏数据库
+func (db *DB) Save() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(db.path), 0700); err != nil {
+		return err
+	}
+	tmp := db.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, db.path)
+}
+
+func (db *DB) head() uint64 {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	return db.Head
+}
+
+func (db *DB) setHead(number uint64) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	db.Head = number
+}
+
+// Add 记录一个新拥有的承诺，已存在的承诺不会被覆盖，只补上其缺少的花费私钥。返回是否为新承诺
+func (db *DB) Add(coin Coin) bool {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	key := strings.ToLower(coin.Cm)
+	if old, ok := db.Coins[key]; ok {
+		if old.SK == "" && coin.SK != "" {
+			old.SK, old.Nullifier = coin.SK, coin.Nullifier
+		}
+		return false
+	}
+	coin.Cm = key
+	db.Coins[key] = &coin
+	return true
+}
+
+// MarkSpent 将承诺标记为被交易hash花费。返回该承诺是否属于用户
+func (db *DB) MarkSpent(cm string, hash string) bool {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	coin, ok := db.Coins[strings.ToLower(cm)]
+	if !ok {
+		return false
+	}
+	coin.Spent, coin.SpentBy, coin.Locked = true, hash, ""
+	return true
+}
+
+// MarkNullified 将零化符为nullifier的承诺标记为被隐匿交易hash花费。返回该承诺是否属于用户
+func (db *DB) MarkNullified(nullifier string, hash string) bool {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	nullifier = strings.ToLower(nullifier)
+	for _, coin := range db.Coins {
+		if coin.Nullifier != "" && strings.ToLower(coin.Nullifier) == nullifier {
+			coin.Spent, coin.SpentBy, coin.Locked = true, hash, ""
+			return true
+		}
+	}
+	return false
+}
+
+// Lock 标记承诺已被尚未上链的交易hash花费，自动选择时不再选中它
+func (db *DB) Lock(cm string, hash string) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	coin, ok := db.Coins[strings.ToLower(cm)]
+	if !ok {
+		return ErrUnknownCoin
+	}
+	coin.Locked = hash
+	return nil
+}
+
+// Unlock 释放因交易失败而未被花费的承诺
+func (db *DB) Unlock(cm string) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	coin, ok := db.Coins[strings.ToLower(cm)]
+	if !ok {
+		return ErrUnknownCoin
+	}
+	coin.Locked = ""
+	return nil
+}
+
+// Unspent 返回所有未花费、未锁定且金额已知的承诺，按金额从小到大排列
+func (db *DB) Unspent() []Coin {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	var coins []Coin
+	for _, coin := range db.Coins {
+		if !coin.Spent && coin.Locked == "" && coin.Error == "" {
+			coins = append(coins, *coin)
+		}
+	}
+	sort.Slice(coins, func(i, j int) bool {
+		if coins[i].Amount != coins[j].Amount {
+			return coins[i].Amount < coins[j].Amount
+		}
+		return coins[i].Cm < coins[j].Cm
+	})
+	return coins
+}
+
+// Balance 返回未花费承诺的总金额
+func (db *DB) Balance() uint64 {
+	var total uint64
+	for _, coin := range db.Unspent() {
+		total += coin.Amount
+	}
+	return total
+}
+
+// Select 为付款金额amount选择要花费的承诺，返回所选承诺和找零金额。
+//
+// 优先选择能够覆盖金额的最小单个承诺，这样只需一个输入的普通转账；
+// 否则从大到小累加承诺直至覆盖金额，此时需要多输入转账。
+func (db *DB) Select(amount uint64) ([]Coin, uint64, error) {
+	coins := db.Unspent()
+	for _, coin := range coins {
+		if coin.Amount >= amount {
+			return []Coin{coin}, coin.Amount - amount, nil
+		}
+	}
+	var (
+		selected []Coin
+		total    uint64
+	)
+	for i := len(coins) - 1; i >= 0 && total < amount; i-- {
+		selected = append(selected, coins[i])
+		total += coins[i].Amount
+	}
+	if total < amount {
+		return nil, 0, ErrInsufficientFunds
+	}
+	return selected, total - amount, nil
+}
@@ -0,0 +1,225 @@
+package coindb
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"maskchain/privacy/ecc"
+	"wallet/utils"
+)
+
+func TestSelect(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "coins.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, amount := range []uint64{5, 20, 50, 8} {
+		db.Add(Coin{Cm: fmt.Sprintf("0x%02x", i), Amount: amount})
+	}
+	tests := []struct {
+		amount uint64
+		coins  []uint64
+		change uint64
+	}{
+		{8, []uint64{8}, 0},             // 恰好相等
+		{10, []uint64{20}, 10},          // 最小的足够单个承诺
+		{60, []uint64{50, 20}, 10},      // 从大到小累加
+		{83, []uint64{50, 20, 8, 5}, 0}, // 全部花费
+	}
+	for _, tt := range tests {
+		coins, change, err := db.Select(tt.amount)
+		if err != nil {
+			t.Fatalf("select %d: %v", tt.amount, err)
+		}
+		var amounts []uint64
+		for _, coin := range coins {
+			amounts = append(amounts, coin.Amount)
+		}
+		if fmt.Sprint(amounts) != fmt.Sprint(tt.coins) || change != tt.change {
+			t.Errorf("select %d: have %v change %d, want %v change %d", tt.amount, amounts, change, tt.coins, tt.change)
+		}
+	}
+	if _, _, err := db.Select(84); err != ErrInsufficientFunds {
+		t.Errorf("select more than balance: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	// 已锁定和已花费的承诺不再被选中
+	db.Lock("0x02", "0xaa")
+	db.MarkSpent("0x01", "0xbb")
+	if coins, _, _ := db.Select(10); len(coins) != 2 || db.Balance() != 13 {
+		t.Errorf("locked or spent coin selected: %v, balance %d", coins, db.Balance())
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coins.json")
+	db, _ := Open(path)
+	db.Add(Coin{Cm: "0xAB", Vor: "0x01", Amount: 7})
+	db.MarkSpent("0xab", "0x11")
+	db.Add(Coin{Cm: "0xcd", Vor: "0x02", Amount: 9})
+	db.setHead(12)
+	if err := db.Save(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Head != 12 || len(db.Coins) != 2 || !db.Coins["0xab"].Spent || db.Balance() != 9 {
+		t.Errorf("database not restored: head %d, coins %v", db.Head, db.Coins)
+	}
+}
+
+type fakeChain []*utils.RPCBlock
+
+func (c fakeChain) BlockNumber() (uint64, error) { return uint64(len(c)), nil }
+
+func (c fakeChain) BlockByNumber(number uint64) (*utils.RPCBlock, error) {
+	return c[number-1], nil
+}
+
+// rlpBytes和rlpList是测试中构造多输入多输出转账隐私数据的最小RLP编码
+func rlpBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	var content []byte
+	for _, item := range items {
+		content = append(content, item...)
+	}
+	return append(rlpHeader(0xc0, len(content)), content...)
+}
+
+func rlpHeader(base byte, size int) []byte {
+	if size < 56 {
+		return []byte{base + byte(size)}
+	}
+	var n []byte
+	for ; size > 0; size >>= 8 {
+		n = append([]byte{byte(size)}, n...)
+	}
+	return append([]byte{base + 55 + byte(len(n))}, n...)
+}
+
+func TestScanner(t *testing.T) {
+	regPub, _, _ := ecc.GenerateKeys("regulator")
+	pub, priv, _ := ecc.GenerateKeys("alice")
+	otherPub, _, _ := ecc.GenerateKeys("bob")
+
+	// 铸造一个承诺：金额承诺在监管者公钥下生成，随机数加密给owner并给出owner的一次性花费公钥
+	mint := func(owner ecc.PublicKey, v uint64) (cm []byte, blind ecc.CypherText, key []byte, evbs ecc.CypherText) {
+		_, comm, _ := ecc.EncryptValue(regPub, v)
+		blind, key, err := ecc.EncryptBlindKey(owner, comm.R)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evbs, _, _ = ecc.EncryptValue(owner, v)
+		return comm.Commitment, blind, key, evbs
+	}
+	// 用户的花费私钥导出的零化符
+	nullifier := func(blind ecc.CypherText, key []byte) []byte {
+		_, J, err := SpendSecret(priv, encodeHex(blind.C1), encodeHex(blind.C2), encodeHex(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := decodeHex(J)
+		return b
+	}
+	cmV, epkr, keyV, _ := mint(pub, 30)
+	cmS, cmSR, _, evs := mint(pub, 12)
+	cmR, cmRR, _, _ := mint(otherPub, 7)
+	cmOut, cmOutR, keyOut, evOut := mint(pub, 12)
+	cmBob, cmBobR, keyBob, evBob := mint(otherPub, 5)
+	// 随机数加密给用户但与承诺不匹配，金额无法求出
+	_, badR, _, _ := mint(pub, 9)
+	// 隐匿转账花费cmOut，产生两个用户的承诺，其中cmSh1随后被隐匿赎回
+	cmSh1, cmSh1R, keySh1, evSh1 := mint(pub, 7)
+	cmSh2, cmSh2R, keySh2, evSh2 := mint(pub, 5)
+
+	ct := func(c ecc.CypherText) []byte { return rlpList(rlpBytes(c.C1), rlpBytes(c.C2)) }
+	output := func(cm []byte, evbs, cmr ecc.CypherText) []byte {
+		empty := rlpBytes(nil)
+		return rlpList(empty, empty, empty, empty, rlpBytes(cm), empty, ct(evbs), ct(cmr))
+	}
+	privacy := rlpList(rlpBytes(nil), rlpBytes(nil), rlpBytes(nil),
+		rlpList(rlpList(rlpBytes(cmS), rlpBytes(nil), rlpBytes(nil))),
+		rlpList(output(cmOut, evOut, cmOutR), output(cmBob, evBob, cmBobR)),
+		rlpBytes(nil), rlpBytes(nil), rlpBytes(keyOut), rlpBytes(keyBob))
+	shielded := rlpList(rlpBytes(nil), rlpBytes(nil), rlpBytes(nil),
+		rlpList(rlpList(rlpBytes(nil), rlpBytes(nil), rlpBytes(nullifier(cmOutR, keyOut)))),
+		rlpList(output(cmSh1, evSh1, cmSh1R), output(cmSh2, evSh2, cmSh2R)),
+		rlpBytes(nil), rlpBytes(nil), rlpBytes(keySh1), rlpBytes(keySh2))
+	redeem := rlpList(ct(ecc.CypherText{}), ct(ecc.CypherText{}), rlpBytes(nil), rlpBytes(nil), rlpBytes(nil),
+		rlpBytes(nullifier(cmSh1R, keySh1)), rlpBytes(nil))
+
+	chain := fakeChain{
+		{Transactions: []utils.RPCTransaction{{
+			ID: "0x1", Hash: "0x01", CmV: encodeHex(cmV), EpkrC1: encodeHex(epkr.C1), EpkrC2: encodeHex(epkr.C2), SpendKey: encodeHex(keyV),
+		}}},
+		{Transactions: []utils.RPCTransaction{{
+			ID: "0x0", Hash: "0x02", CmO: encodeHex(cmV),
+			CmS: encodeHex(cmS), CmSRC1: encodeHex(cmSR.C1), CmSRC2: encodeHex(cmSR.C2),
+			EvsBsC1: encodeHex(evs.C1), EvsBsC2: encodeHex(evs.C2),
+			CmR: encodeHex(cmR), CmRRC1: encodeHex(cmRR.C1), CmRRC2: encodeHex(cmRR.C2),
+		}}},
+		{Transactions: []utils.RPCTransaction{{ID: "0x3", Hash: "0x03", Privacy: encodeHex(privacy)}}},
+		{Transactions: []utils.RPCTransaction{{
+			ID: "0x0", Hash: "0x04", CmS: encodeHex(cmBob), CmSRC1: encodeHex(badR.C1), CmSRC2: encodeHex(badR.C2),
+		}}},
+		{Transactions: []utils.RPCTransaction{{ID: "0x4", Hash: "0x05", Privacy: encodeHex(shielded)}}},
+		{Transactions: []utils.RPCTransaction{{ID: "0x6", Hash: "0x06", Privacy: encodeHex(redeem)}}},
+		{}, // 未确认的区块
+	}
+	db, _ := Open(filepath.Join(t.TempDir(), "coins.json"))
+	scanner := NewScanner(chain, priv, regPub)
+	scanner.Confirmations = 1
+
+	found, err := scanner.Scan(db)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(found) != 6 || db.Head != 6 {
+		t.Fatalf("found %d coins up to block %d, want 6 up to block 6", len(found), db.Head)
+	}
+	want := map[string]struct {
+		amount uint64
+		spent  bool
+	}{
+		encodeHex(cmV):   {30, true},
+		encodeHex(cmS):   {12, true},
+		encodeHex(cmOut): {12, true},
+		encodeHex(cmSh1): {7, true},
+		encodeHex(cmSh2): {5, false},
+	}
+	for cm, w := range want {
+		coin, ok := db.Coins[cm]
+		if !ok {
+			t.Fatalf("coin %s not found", cm)
+		}
+		if coin.Amount != w.amount || coin.Spent != w.spent {
+			t.Errorf("coin %s: have amount %d spent %v, want %d %v", cm, coin.Amount, coin.Spent, w.amount, w.spent)
+		}
+	}
+	if coin := db.Coins[encodeHex(cmBob)]; coin.Error == "" || coin.Amount != 0 {
+		t.Errorf("unopenable coin not flagged: %+v", coin)
+	}
+	for _, cm := range [][]byte{cmV, cmOut, cmSh1, cmSh2} {
+		if coin := db.Coins[encodeHex(cm)]; coin.SK == "" || coin.Nullifier == "" {
+			t.Errorf("coin %x: missing spend key", cm)
+		}
+	}
+	if by := db.Coins[encodeHex(cmSh1)].SpentBy; by != "0x06" {
+		t.Errorf("shielded redemption not recorded: spent by %q", by)
+	}
+	if db.Balance() != 5 {
+		t.Errorf("balance mismatch: have %d, want 5", db.Balance())
+	}
+	// 再次扫描不会重复记录
+	if found, err := scanner.Scan(db); err != nil || len(found) != 0 {
+		t.Errorf("rescan found %d coins, err %v", len(found), err)
+	}
+}
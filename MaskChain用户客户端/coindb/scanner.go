@@ -0,0 +1,269 @@
+package coindb
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"maskchain/privacy/ecc"
+	"wallet/utils"
+)
+
+// Confirmations 默认只扫描已有足够确认数的区块，避免分叉回滚后记录错误的承诺
+const Confirmations = 6
+
+const (
+	transferTx         = 0 // 转账交易
+	purchaseTx         = 1 // 购币交易
+	multiTransferTx    = 3 // 多输入多输出转账交易
+	shieldedTransferTx = 4 // 隐匿转账交易
+	redeemTx           = 6 // 赎回交易
+)
+
+var (
+	errCoinValue         = errors.New("commitment value out of range")
+	errInvalidCypherText = errors.New("invalid amount ciphertext")
+)
+
+// Chain 扫描所需的节点接口，由utils.NodeClient实现
+type Chain interface {
+	BlockNumber() (uint64, error)
+	BlockByNumber(number uint64) (*utils.RPCBlock, error)
+}
+
+// Scanner 扫描新区块，用用户私钥试解密交易中的承诺随机数，记录属于用户的承诺
+type Scanner struct {
+	Chain         Chain
+	Key           ecc.PrivateKey // 用户私钥
+	Regulator     ecc.PublicKey  // 监管者公钥，链上承诺均在其下生成
+	Confirmations uint64         // 扫描到最新区块之前的确认数
+}
+
+// NewScanner 创建使用默认确认数的扫描器
+func NewScanner(chain Chain, key ecc.PrivateKey, regulator ecc.PublicKey) *Scanner {
+	return &Scanner{Chain: chain, Key: key, Regulator: regulator, Confirmations: Confirmations}
+}
+
+// Scan 扫描db中记录的区块之后所有已确认的区块，返回新发现的承诺，并保存数据库
+func (s *Scanner) Scan(db *DB) ([]Coin, error) {
+	latest, err := s.Chain.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	if latest < s.Confirmations {
+		return nil, nil
+	}
+	var found []Coin
+	for number := db.head() + 1; number <= latest-s.Confirmations; number++ {
+		block, err := s.Chain.BlockByNumber(number)
+		if err != nil {
+			return found, err
+		}
+		for _, tx := range block.Transactions {
+			coins, err := s.scanTx(db, number, tx)
+			if err != nil {
+				return found, fmt.Errorf("block %d tx %s: %v", number, tx.Hash, err)
+			}
+			for _, coin := range coins {
+				if db.Add(coin) {
+					found = append(found, coin)
+				}
+			}
+		}
+		db.setHead(number)
+	}
+	return found, db.Save()
+}
+
+// scanTx 标记交易花费的用户承诺，返回交易产生的属于用户的承诺
+func (s *Scanner) scanTx(db *DB, number uint64, tx utils.RPCTransaction) ([]Coin, error) {
+	id, err := utils.ParseHexUint(tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	var coins []Coin
+	// 随机数密文是加密给用户的承诺属于用户，无法打开时仍记录并注明原因，而不是记为金额为0的承诺。
+	// 带花费公钥的承诺同时求出花费私钥和零化符，以便隐匿花费并识别花费它的交易。
+	// (ev1, ev2)为加密给用户的金额，只在承诺属于用户时才解密
+	add := func(cm, c1, c2 string, key []byte, ev1, ev2 string) {
+		vor, ok := s.decryptBlind(c1, c2)
+		if !ok {
+			return
+		}
+		var (
+			hint    uint64
+			hintErr error
+		)
+		if ev1 != "" {
+			hint, hintErr = s.decryptValue(ev1, ev2)
+		}
+		coin := Coin{Cm: cm, Vor: fmt.Sprintf("0x%x", vor), Hash: tx.Hash, Block: number}
+		amount, err := s.open(cm, vor, hint)
+		switch {
+		case err != nil && hintErr != nil:
+			coin.Error = fmt.Sprintf("%v, amount ciphertext: %v", err, hintErr)
+		case err != nil:
+			coin.Error = err.Error()
+		default:
+			coin.Amount = amount
+		}
+		if len(key) != 0 {
+			if coin.SK, coin.Nullifier, err = SpendSecret(s.Key, c1, c2, encodeHex(key)); err != nil && coin.Error == "" {
+				coin.Error = "spend key: " + err.Error()
+			}
+		}
+		coins = append(coins, coin)
+	}
+	switch id {
+	case transferTx:
+		db.MarkSpent(tx.CmO, tx.Hash)
+		keys := make([][]byte, 2)
+		for i := 0; i < len(tx.SpendKeys) && i < len(keys); i++ {
+			if keys[i], err = decodeHex(tx.SpendKeys[i]); err != nil {
+				return nil, err
+			}
+		}
+		add(tx.CmS, tx.CmSRC1, tx.CmSRC2, keys[0], tx.EvsBsC1, tx.EvsBsC2)
+		add(tx.CmR, tx.CmRRC1, tx.CmRRC2, keys[1], "", "")
+	case purchaseTx:
+		key, err := decodeHex(tx.SpendKey)
+		if err != nil {
+			return nil, err
+		}
+		add(tx.CmV, tx.EpkrC1, tx.EpkrC2, key, "", "")
+	case multiTransferTx, shieldedTransferTx:
+		blob, err := decodeHex(tx.Privacy)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := decodeRLP(blob)
+		if err != nil {
+			return nil, err
+		}
+		// 多输入转账公开被花费的承诺，隐匿转账只公开其零化符
+		for _, in := range payload.field(3).List {
+			if id == shieldedTransferTx {
+				db.MarkNullified(encodeHex(in.field(2).Bytes), tx.Hash)
+			} else {
+				db.MarkSpent(encodeHex(in.field(0).Bytes), tx.Hash)
+			}
+		}
+		// 花费公钥以tail形式编码在范围证明之后，与输出一一对应
+		for i, out := range payload.field(4).List {
+			evbs, cmr := out.field(6), out.field(7)
+			add(encodeHex(out.field(4).Bytes), encodeHex(cmr.field(0).Bytes), encodeHex(cmr.field(1).Bytes),
+				payload.field(7+i).Bytes, encodeHex(evbs.field(0).Bytes), encodeHex(evbs.field(1).Bytes))
+		}
+	case redeemTx:
+		blob, err := decodeHex(tx.Privacy)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := decodeRLP(blob)
+		if err != nil {
+			return nil, err
+		}
+		// 公开赎回时Ev.C1即被销毁的承诺，隐匿赎回只公开零化符，赎回不产生新承诺
+		if nullifier := payload.field(5).Bytes; len(nullifier) != 0 {
+			db.MarkNullified(encodeHex(nullifier), tx.Hash)
+		} else {
+			db.MarkSpent(encodeHex(payload.field(0).field(0).Bytes), tx.Hash)
+		}
+	}
+	return coins, nil
+}
+
+// decryptBlind 试解密承诺随机数密文，密文不是加密给用户的则返回false
+func (s *Scanner) decryptBlind(c1, c2 string) ([]byte, bool) {
+	C1, err1 := decodeHex(c1)
+	C2, err2 := decodeHex(c2)
+	if err1 != nil || err2 != nil || len(C1) == 0 {
+		return nil, false
+	}
+	vor, err := ecc.DecryptBlind(s.Key, ecc.CypherText{C1: C1, C2: C2})
+	return vor, err == nil
+}
+
+// SpendSecret 由加密给用户的承诺随机数密文(c1, c2)和承诺的一次性花费公钥key求出花费私钥和零化符，
+// 均为0x开头十六进制。隐匿分叉起创建的承诺只能以该私钥隐匿花费，承诺的创建者无法求得
+func SpendSecret(priv ecc.PrivateKey, c1, c2, key string) (sk, nullifier string, err error) {
+	C1, err := decodeHex(c1)
+	if err != nil {
+		return "", "", err
+	}
+	C2, err := decodeHex(c2)
+	if err != nil {
+		return "", "", err
+	}
+	K, err := decodeHex(key)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := ecc.SpendKey(priv, ecc.CypherText{C1: C1, C2: C2}, K)
+	if err != nil {
+		return "", "", err
+	}
+	defer ecc.Zeroize(secret)
+	J, err := ecc.Nullifier(secret)
+	if err != nil {
+		return "", "", err
+	}
+	return encodeHex(secret), encodeHex(J), nil
+}
+
+// decryptValue 解密加密给用户的金额，只在随机数已解密成功即交易属于用户时调用。
+// 结果只作为打开承诺时的提示，解密失败时承诺仍可用小步大步法打开
+func (s *Scanner) decryptValue(c1, c2 string) (uint64, error) {
+	C1, err1 := decodeHex(c1)
+	C2, err2 := decodeHex(c2)
+	if err1 != nil || err2 != nil || len(C1) == 0 {
+		return 0, errInvalidCypherText
+	}
+	return ecc.DecryptValue(s.Key, ecc.CypherText{C1: C1, C2: C2})
+}
+
+// open 用随机数vor打开承诺cm，返回承诺金额。优先尝试hint，否则用小步大步法求解
+func (s *Scanner) open(cm string, vor []byte, hint uint64) (uint64, error) {
+	data, err := decodeHex(cm)
+	if err != nil {
+		return 0, err
+	}
+	return OpenCommitment(s.Regulator, data, vor, hint)
+}
+
+// OpenCommitment 在已知随机数vor时求出承诺cm = v*G1 + vor*H中的金额v
+func OpenCommitment(pub ecc.PublicKey, cm []byte, vor []byte, hint uint64) (uint64, error) {
+	x, y := elliptic.Unmarshal(ecc.EC.C, cm)
+	if x == nil {
+		return 0, errors.New("invalid commitment")
+	}
+	pubb := ecc.ConvertPub(pub)
+	// vG = cm - vor*H
+	vG := ecc.ECPoint{X: x, Y: y}.Add(pubb.H.Mult(new(big.Int).SetBytes(vor)).Neg())
+	if vG.X.Sign() == 0 && vG.Y.Sign() == 0 {
+		return 0, nil
+	}
+	if hint != 0 && samePoint(pubb.G1.Mult(new(big.Int).SetUint64(hint)), vG) {
+		return hint, nil
+	}
+	v, err := ecc.DiscreteLog(pubb.G1, vG)
+	if err != nil {
+		return 0, errCoinValue
+	}
+	return v, nil
+}
+
+func samePoint(a, b ecc.ECPoint) bool {
+	return a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+func encodeHex(b []byte) string {
+	return fmt.Sprintf("0x%x", b)
+}
@@ -5,9 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/labstack/echo"
+	"maskchain/privacy/ecc"
 	"net/http"
 	"strconv"
-	ecc "wallet/ECC"
+	"wallet/coindb"
 	"wallet/model"
 	"wallet/utils"
 )
@@ -64,7 +65,14 @@ func Register(c echo.Context) error {
 }
 
 func register(account ecc.Account) string {
-	data := account.Info
+	// 向监管者登记完整公钥，监管者审计时据此识别交易双方
+	data := model.Identity{
+		Name:    account.Info.Name,
+		ID:      account.Info.ID,
+		Hashky:  account.Info.Hashky,
+		ExtInfo: account.Info.ExtInfo,
+		Pubkey:  utils.EncodePubKey(account.Pub),
+	}
 	body := ethRPCPost(data, RegulatorURL+"register")
 	res := string(body)
 	if res == "Successful!" {
@@ -83,11 +91,19 @@ func Buycoin(c echo.Context) error {
 	if err := c.Bind(w); err != nil {
 		return c.JSON(http.StatusInternalServerError, err)
 	}
-	// 向交易所发出购币请求
-	body := ethRPCPost(w, ExchangeURL + "buy")
-	var receipt utils.Receipt
-	json.Unmarshal(body, &receipt)
-
+	if w.ID == "" {
+		w.ID = newPurchaseID()
+	}
+	// 向交易所发出购币请求，交易所异步处理，等待其签名购币交易
+	body := ethRPCPost(w, ExchangeURL+"buy")
+	var status purchaseStatus
+	if err := json.Unmarshal(body, &status); err != nil || status.ID == "" {
+		return c.JSON(http.StatusBadRequest, string(body))
+	}
+	receipt, err := waitPurchase(status)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 	if receipt.Cmv == "" || receipt.Epkrc1 == "" || receipt.Epkrc2 == "" || receipt.Hash == "" {
 		return c.JSON(http.StatusBadRequest, ErrorValue)
 	} else {
@@ -95,6 +111,8 @@ func Buycoin(c echo.Context) error {
 		privKey := utils.CreatePriKey(w.G1, w.G2, w.P, w.H, w.X)
 		coin := decryptCoinReceipt(receipt, privKey, w.Amount)
 		utils.MineTx(8545, coin.Hash)
+		amount, _ := strconv.ParseUint(w.Amount, 10, 64)
+		recordCoins(privKey.PublicKey, "", withSpendKey(coindb.Coin{Cm: coin.Cmv, Vor: coin.Vor, Amount: amount, Hash: coin.Hash}, privKey, receipt.Epkrc1, receipt.Epkrc2, receipt.Spendkey))
 		return c.JSON(http.StatusOK, coin)
 	}
 }
@@ -111,11 +129,31 @@ func ExchangeCoin(c echo.Context) error {
 		Vor:    w.Vor,
 		Amount: w.Amount,
 	}
-	amount, _ := strconv.Atoi(coin.Amount)
 	spend, _ := strconv.Atoi(w.Spend)
+	if spend <= 0 {
+		return c.JSON(http.StatusBadRequest, ErrorValue)
+	}
+	// 未指定被花费的承诺时，从承诺数据库中自动选择
+	if coin.Cmv == "" {
+		selected, err := selectCoin(senderPriv, uint64(spend))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		coin = utils.Coin{
+			Cmv:    selected.Cm,
+			Vor:    selected.Vor,
+			Hash:   selected.Hash,
+			Amount: strconv.FormatUint(selected.Amount, 10),
+		}
+	}
+	amount, _ := strconv.Atoi(coin.Amount)
+	if amount < spend {
+		return c.JSON(http.StatusBadRequest, coindb.ErrInsufficientFunds.Error())
+	}
 	senderGethAccount := utils.EthAccounts(8545)[0]
 	receiverGethAccount := utils.EthAccounts(8545)[0]
 	txHash := utils.EthSendTransaction(8545, senderGethAccount, receiverGethAccount, senderPriv, reciverPub, coin, amount, spend)
+	lockCoin(senderPriv.PublicKey, coin.Cmv, txHash)
 	utils.MineTx(8545, txHash)
 	rpcTx := utils.EthGetTransactionByHash(8545, txHash)
 	tx := rpcTx.Result
@@ -125,6 +163,11 @@ func ExchangeCoin(c echo.Context) error {
 		Hash:   txHash,
 		Amount: strconv.Itoa(amount - spend),
 	}
+	change := coindb.Coin{Cm: returnCoin.Cmv, Vor: returnCoin.Vor, Amount: uint64(amount - spend), Hash: txHash}
+	if len(tx.SpendKeys) == 2 {
+		change = withSpendKey(change, senderPriv, tx.CmRRC1, tx.CmRRC2, tx.SpendKeys[1])
+	}
+	recordCoins(senderPriv.PublicKey, coin.Cmv, change)
 	return c.JSON(http.StatusOK, returnCoin)
 }
 func Receive(c echo.Context) error {
@@ -133,14 +176,36 @@ func Receive(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, err)
 	}
 	privKey := utils.CreatePriKey(w.G1, w.G2, w.P, w.H, w.X)
+	// 未指定交易哈希时，扫描新区块返回收到的全部承诺
+	if w.Hash == "" {
+		_, found, err := syncCoins(privKey)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, err.Error())
+		}
+		coins := []utils.Coin{}
+		for _, coin := range found {
+			coins = append(coins, utils.Coin{Cmv: coin.Cm, Vor: coin.Vor, Hash: coin.Hash, Amount: fmt.Sprintf("0x%x", coin.Amount)})
+		}
+		return c.JSON(http.StatusOK, coins)
+	}
 	rpcTx := utils.EthGetTransactionByHash(8545, w.Hash)
 	tx := rpcTx.Result
+	vor := decrypt(tx.CmSRC1, tx.CmSRC2, privKey)
+	if vor == "" {
+		return c.JSON(http.StatusBadRequest, "该交易不是发给此用户的转账")
+	}
 	returnCoin := utils.Coin{
-		Cmv:    tx.CmO,
-		Vor:    decrypt(tx.CmSRC1, tx.CmSRC2, privKey),
+		Cmv:    tx.CmS,
+		Vor:    vor,
 		Hash:   w.Hash,
 		Amount: decryptValue(tx.EvsBsC1, tx.EvsBsC2, privKey),
 	}
+	amount, _ := strconv.ParseUint(returnCoin.Amount[2:], 16, 64)
+	received := coindb.Coin{Cm: returnCoin.Cmv, Vor: returnCoin.Vor, Amount: amount, Hash: w.Hash}
+	if len(tx.SpendKeys) == 2 {
+		received = withSpendKey(received, privKey, tx.CmSRC1, tx.CmSRC2, tx.SpendKeys[0])
+	}
+	recordCoins(privKey.PublicKey, "", received)
 	return c.JSON(http.StatusOK, returnCoin)
 }
 func decryptCoinReceipt(recript utils.Receipt, priv ecc.PrivateKey, amount string) utils.Coin {
@@ -152,15 +217,22 @@ func decryptCoinReceipt(recript utils.Receipt, priv ecc.PrivateKey, amount strin
 	}
 }
 
-//	解密随机数密文
+//	解密随机数密文，密文不是加密给该用户的则返回空串
 func decrypt(hex0xStringC1 string, hex0xStringC2 string, priv ecc.PrivateKey) string {
+	if len(hex0xStringC1) < 2 || len(hex0xStringC2) < 2 {
+		return ""
+	}
 	hexData1, _ := hex.DecodeString(hex0xStringC1[2:])
 	hexData2, _ := hex.DecodeString(hex0xStringC2[2:])
 	C := ecc.CypherText{
 		C1: hexData1,
 		C2: hexData2,
 	}
-	M := fmt.Sprintf("0x%x", ecc.Decrypt(priv, C))
+	r, err := ecc.DecryptBlind(priv, C)
+	if err != nil {
+		return ""
+	}
+	M := fmt.Sprintf("0x%x", r)
 	return M
 }
 
@@ -172,6 +244,10 @@ func decryptValue(hex0xStringC1 string, hex0xStringC2 string, priv ecc.PrivateKe
 		C1: hexData1,
 		C2: hexData2,
 	}
-	M := fmt.Sprintf("0x%x", ecc.DecryptValue(priv, C))
+	v, err := ecc.DecryptValue(priv, C)
+	if err != nil {
+		fmt.Println(err)
+	}
+	M := fmt.Sprintf("0x%x", v)
 	return M
 }
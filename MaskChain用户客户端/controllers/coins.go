@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/labstack/echo"
+	"maskchain/privacy/ecc"
+	"wallet/coindb"
+	"wallet/model"
+	"wallet/utils"
+)
+
+var (
+	coinsLock sync.Mutex
+	coinDBs   = make(map[string]*coindb.DB) // 已打开的用户承诺数据库
+	regPub    *ecc.PublicKey                // 监管者公钥
+)
+
+// CoinsResult 用户承诺余额
+type CoinsResult struct {
+	Balance uint64        `json:"balance"` // 未花费承诺总额
+	Head    uint64        `json:"head"`    // 已扫描到的区块号
+	Coins   []coindb.Coin `json:"coins"`   // 未花费承诺
+	Found   []coindb.Coin `json:"found"`   // 本次扫描新发现的承诺
+}
+
+// Coins 扫描新区块并返回用户的未花费承诺
+func Coins(c echo.Context) error {
+	w := new(model.WalletKey)
+	if err := c.Bind(w); err != nil {
+		return c.JSON(http.StatusInternalServerError, err)
+	}
+	if w.X == "" {
+		return c.JSON(http.StatusBadRequest, ErrorValue)
+	}
+	privKey := utils.CreatePriKey(w.G1, w.G2, w.P, w.H, w.X)
+	db, found, err := syncCoins(privKey)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, CoinsResult{
+		Balance: db.Balance(),
+		Head:    db.Head,
+		Coins:   db.Unspent(),
+		Found:   found,
+	})
+}
+
+// openCoinDB 打开用户的承诺数据库，数据库文件以用户公钥H的哈希命名
+func openCoinDB(pub ecc.PublicKey) (*coindb.DB, error) {
+	name := fmt.Sprintf("%x.json", sha256.Sum256(pub.H.Bytes()))
+	if db, ok := coinDBs[name]; ok {
+		return db, nil
+	}
+	db, err := coindb.Open(filepath.Join(CoinDBDir, name))
+	if err != nil {
+		return nil, err
+	}
+	coinDBs[name] = db
+	return db, nil
+}
+
+// regulatorKey 向监管者获取公钥，链上金额承诺均在监管者公钥下生成
+func regulatorKey() (ecc.PublicKey, error) {
+	if regPub != nil {
+		return *regPub, nil
+	}
+	resp, err := http.Get(Getpuburl)
+	if err != nil {
+		return ecc.PublicKey{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return ecc.PublicKey{}, err
+	}
+	pub := new(ecc.PublicKey)
+	if err := json.Unmarshal(body, pub); err != nil || pub.H == nil {
+		return ecc.PublicKey{}, errors.New("获取监管者公钥失败: " + string(body))
+	}
+	regPub = pub
+	return *pub, nil
+}
+
+// syncCoins 扫描新区块，更新用户的承诺数据库并返回新发现的承诺
+func syncCoins(priv ecc.PrivateKey) (*coindb.DB, []coindb.Coin, error) {
+	coinsLock.Lock()
+	defer coinsLock.Unlock()
+
+	db, err := openCoinDB(priv.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	reg, err := regulatorKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	found, err := coindb.NewScanner(&utils.NodeClient{URL: Ethurl}, priv, reg).Scan(db)
+	return db, found, err
+}
+
+// userCoinDB 返回用户的承诺数据库，不扫描区块
+func userCoinDB(pub ecc.PublicKey) (*coindb.DB, error) {
+	coinsLock.Lock()
+	defer coinsLock.Unlock()
+	return openCoinDB(pub)
+}
+
+// selectCoin 扫描新区块后为付款金额自动选择一个未花费承诺。
+// 节点的转账交易只花费一个承诺，余额分散在多个承诺中时需先合并承诺
+func selectCoin(priv ecc.PrivateKey, amount uint64) (coindb.Coin, error) {
+	db, _, err := syncCoins(priv)
+	if err != nil {
+		return coindb.Coin{}, err
+	}
+	coins, _, err := db.Select(amount)
+	if err != nil {
+		return coindb.Coin{}, err
+	}
+	if len(coins) != 1 {
+		return coindb.Coin{}, errors.New("没有足够金额的单个承诺，需先合并承诺")
+	}
+	return coins[0], nil
+}
+
+// lockCoin 标记承诺已被尚未上链的交易花费，避免被再次选中
+func lockCoin(pub ecc.PublicKey, cm string, txHash string) {
+	db, err := userCoinDB(pub)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	db.Lock(cm, txHash)
+	if err := db.Save(); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// recordCoins 将交易花费的承诺spent标记为已花费，并记录交易产生的用户承诺
+func recordCoins(pub ecc.PublicKey, spent string, coins ...coindb.Coin) {
+	db, err := userCoinDB(pub)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, coin := range coins {
+		if spent != "" {
+			db.MarkSpent(spent, coin.Hash)
+		}
+		if coin.Cm != "" && coin.Vor != "" && coin.Amount > 0 {
+			db.Add(coin)
+		}
+	}
+	if err := db.Save(); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// withSpendKey 由承诺随机数密文和一次性花费公钥求出承诺的花费私钥和零化符。
+// 隐匿分叉前创建的承诺没有花费公钥，原样返回
+func withSpendKey(coin coindb.Coin, priv ecc.PrivateKey, c1, c2, key string) coindb.Coin {
+	if key == "" {
+		return coin
+	}
+	sk, nullifier, err := coindb.SpendSecret(priv, c1, c2, key)
+	if err != nil {
+		fmt.Println(err)
+		return coin
+	}
+	coin.SK, coin.Nullifier = sk, nullifier
+	return coin
+}
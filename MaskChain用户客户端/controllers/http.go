@@ -23,6 +23,9 @@ var (
 	Ethurl       = "http://localhost:8545"
 	RegulatorURL = "http://localhost:1423/" // 监管方URL
 	ExchangeURL  = "http://localhost:1323/"
+
+	CoinDBDir = "coins" // 用户承诺数据库目录
+	DLogDir   = "dlog"  // 金额解密小步表目录
 )
 
 func ethRPCPost(data interface{}, url string) []byte {
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"wallet/utils"
+)
+
+// PurchaseTimeout 等待交易所签名购币交易的最长时间
+var PurchaseTimeout = time.Minute
+
+// purchaseStatus 交易所/buy与/buy/:id返回的购币任务状态
+type purchaseStatus struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"` // pending, mined或failed
+	Cmv      string `json:"cmv"`
+	Epkrc1   string `json:"epkrc1"`
+	Epkrc2   string `json:"epkrc2"`
+	Spendkey string `json:"spendkey"` // 购币承诺的一次性花费公钥
+	Hash     string `json:"hash"`
+	Block    uint64 `json:"block"`
+	Error    string `json:"error"`
+}
+
+// newPurchaseID 生成购币请求的幂等键
+func newPurchaseID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// getPurchase 向交易所查询购币任务的状态
+func getPurchase(id string) (purchaseStatus, error) {
+	var status purchaseStatus
+	resp, err := http.Get(ExchangeURL + "buy/" + id)
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return status, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return status, errors.New(string(body))
+	}
+	return status, json.Unmarshal(body, &status)
+}
+
+// waitPurchase 等待交易所签名购币交易，返回购币回执。交易所处理失败时返回其原因
+func waitPurchase(status purchaseStatus) (utils.Receipt, error) {
+	deadline := time.Now().Add(PurchaseTimeout)
+	for status.Hash == "" && status.Status == "pending" && time.Now().Before(deadline) {
+		time.Sleep(time.Second)
+		next, err := getPurchase(status.ID)
+		if err != nil {
+			return utils.Receipt{}, err
+		}
+		status = next
+	}
+	switch {
+	case status.Status == "failed":
+		return utils.Receipt{}, errors.New("purchase failed: " + status.Error)
+	case status.Hash == "":
+		return utils.Receipt{}, errors.New("purchase " + status.ID + " still pending, query it later with the same id")
+	}
+	return utils.Receipt{Cmv: status.Cmv, Epkrc1: status.Epkrc1, Epkrc2: status.Epkrc2, Spendkey: status.Spendkey, Hash: status.Hash}, nil
+}
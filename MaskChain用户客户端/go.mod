@@ -10,4 +10,7 @@ require (
 	github.com/stretchr/testify v1.7.0 // indirect
 	github.com/valyala/fasttemplate v1.2.1 // indirect
 	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad // indirect
+	maskchain/privacy v0.0.0
 )
+
+replace maskchain/privacy => ../MaskChain隐私计算
@@ -7,6 +7,7 @@ type NewWallet struct {
 }
 
 type BctoEx struct {
+	ID     string `json:"id"` // 购币请求的幂等键，重试时须相同，为空时由钱包生成
 	G1     string `json:"g1"`
 	G2     string `json:"g2"`
 	P      string `json:"p"`
@@ -45,3 +46,21 @@ type RPCbody struct {
 	Params  []string `json:"params"`
 	ID      int      `json:"id"`
 }
+
+// WalletKey 查询承诺余额时提交的用户私钥
+type WalletKey struct {
+	G1 string `json:"g1"`
+	G2 string `json:"g2"`
+	P  string `json:"p"`
+	H  string `json:"h"`
+	X  string `json:"x"`
+}
+
+// Identity 向监管者登记的身份信息
+type Identity struct {
+	Name    string `json:"Name"`
+	ID      string `json:"ID"`
+	Hashky  string `json:"Hashky"`
+	ExtInfo string `json:"ExtInfo"`
+	Pubkey  string `json:"Pubkey"` // 完整公钥P || G1 || G2 || H
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/labstack/echo"
+	"maskchain/privacy/ecc"
 	"wallet/controllers"
 
 	"github.com/labstack/echo/middleware"
@@ -13,6 +14,7 @@ import (
 )
 
 func main() {
+	ecc.SetDLogConfig(ecc.DLogConfig{Dir: controllers.DLogDir})
 	e := echo.New()
 	// 跨域请求配置
 
@@ -29,6 +31,7 @@ func main() {
 		g.POST("/buycoin", controllers.Buycoin)       //购币
 		g.POST("/exchange", controllers.ExchangeCoin) //转账
 		g.POST("/receive", controllers.Receive)       //收款
+		g.POST("/coins", controllers.Coins)           //承诺余额
 	}
 	// 网页的静态文件
 	// 启动服务，平滑关闭
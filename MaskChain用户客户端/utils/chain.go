@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// NodeClient 通过RPC读取区块链节点，出错时返回错误而不是退出钱包
+type NodeClient struct {
+	URL string // 节点RPC地址，如http://localhost:8545
+}
+
+type rpcRequest struct {
+	Jsonrpc string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call 调用节点RPC方法并将结果解析到result
+func (c *NodeClient) call(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, err := json.Marshal(rpcRequest{Jsonrpc: "2.0", Method: method, Params: params, ID: 67})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(c.URL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var res rpcResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return fmt.Errorf("%s: %s", method, res.Error.Message)
+	}
+	if len(res.Result) == 0 || string(res.Result) == "null" {
+		return errors.New(method + ": not found")
+	}
+	return json.Unmarshal(res.Result, result)
+}
+
+// BlockNumber 返回节点当前的区块高度
+func (c *NodeClient) BlockNumber() (uint64, error) {
+	var hex string
+	if err := c.call(&hex, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return ParseHexUint(hex)
+}
+
+// BlockByNumber 返回指定高度的区块及其完整交易
+func (c *NodeClient) BlockByNumber(number uint64) (*RPCBlock, error) {
+	block := new(RPCBlock)
+	if err := c.call(block, "eth_getBlockByNumber", fmt.Sprintf("0x%x", number), true); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// ParseHexUint 解析0x开头的十六进制整数
+func ParseHexUint(s string) (uint64, error) {
+	if len(s) < 3 || s[:2] != "0x" {
+		return 0, fmt.Errorf("invalid hex number %q", s)
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
@@ -7,13 +7,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"maskchain/privacy/ecc"
 	"math/big"
 	"net/http"
 	"os"
 	"runtime"
 	"strconv"
 	"time"
-	ecc "wallet/ECC"
 	"wallet/model"
 )
 
@@ -39,6 +39,11 @@ func CreatePubKey(g1 string, g2 string, p string, h string) (usrpub ecc.PublicKe
 	usrpub.H = stringtobig(h, 16)
 	return
 }
+// EncodePubKey 将公钥编码为节点交易中Spk、Rpk使用的格式P || G1 || G2 || H
+func EncodePubKey(pub ecc.PublicKey) string {
+	return fmt.Sprintf("%0*x%0*x%0*x%0*x", 64, pub.P, 129, pub.G1, 129, pub.G2, 129, pub.H)
+}
+
 func EthSendTransaction(senderRPCPort int, senderGethAccount string, receiverGethAccount string, senderAccount ecc.PrivateKey, receiverAccount ecc.PublicKey, coin Coin, total int, amount int) string {
 	if !personalUnlockAccount(senderRPCPort, senderGethAccount, "1") {
 		Fatalf("发送方账户解锁失败")
@@ -64,8 +69,8 @@ func PerpareTX(senderGethAccount string, receiverGethAccount string, senderAccou
 		Value:    "0x1",
 		ID:       "0x0",
 		Data:     "0x00",
-		Spk:      fmt.Sprintf("%0*x%0*x%0*x%0*x", 64, senderAccount.P, 129, senderAccount.G1, 129, senderAccount.G2, 129, senderAccount.H),
-		Rpk:      fmt.Sprintf("%0*x%0*x%0*x%0*x", 64, receiverAccount.P, 129, receiverAccount.G1, 129, receiverAccount.G2, 129, receiverAccount.H),
+		Spk:      EncodePubKey(senderAccount.PublicKey),
+		Rpk:      EncodePubKey(receiverAccount),
 		S:        fmt.Sprintf("0x%x", amount),
 		R:        fmt.Sprintf("0x%x", total-amount),
 		Vor:      coin.Vor,
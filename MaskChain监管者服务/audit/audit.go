@@ -0,0 +1,259 @@
+// Package audit 用监管者私钥解密链上交易的金额和地址密文，生成关联交易双方身份与金额的审计记录。
+package audit
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"maskchain/privacy/ecc"
+	"regulator/regdb"
+)
+
+// MaxBlockRange 一次审计请求最多扫描的区块数
+const MaxBlockRange = 1000
+
+const (
+	transferTx      = 0 // 转账交易
+	purchaseTx      = 1 // 购币交易
+	plainTx         = 2 // 普通交易
+	multiTransferTx = 3 // 多输入多输出转账交易
+)
+
+var errBlockRange = fmt.Errorf("block range must be within %d blocks", MaxBlockRange)
+
+// Lookup 根据地址公钥解密点查找已登记的身份，未登记返回nil
+type Lookup func(address []byte) *regdb.Identity
+
+// Party 交易参与方
+type Party struct {
+	Address string `json:"address"`          // 地址公钥解密点
+	Known   bool   `json:"known"`            // 是否为已登记身份
+	Name    string `json:"name,omitempty"`   // 姓名
+	ID      string `json:"id,omitempty"`     // 身份证号
+	Hashky  string `json:"hashky,omitempty"` // 公钥
+}
+
+// Input 交易花费的承诺
+type Input struct {
+	Cm     string `json:"cm"`
+	Amount uint64 `json:"amount"`
+}
+
+// Output 交易产生的承诺
+type Output struct {
+	Receiver *Party `json:"receiver,omitempty"` // 接收方，购币交易为空
+	Cm       string `json:"cm"`
+	Amount   uint64 `json:"amount"`
+	Change   bool   `json:"change,omitempty"` // 是否为发送方的找零
+}
+
+// Record 一笔交易的审计记录
+type Record struct {
+	Hash    string   `json:"hash"`
+	Block   uint64   `json:"block"`
+	Type    uint64   `json:"type"`             // 0转账 1购币 2普通交易 3多输入多输出转账
+	Sender  *Party   `json:"sender,omitempty"` // 发送方，购币交易为空
+	Inputs  []Input  `json:"inputs,omitempty"`
+	Outputs []Output `json:"outputs,omitempty"`
+}
+
+// Auditor 用监管者私钥审计交易
+type Auditor struct {
+	Key    ecc.PrivateKey // 监管者私钥
+	Lookup Lookup
+}
+
+// AuditHash 审计指定哈希的交易
+func (a *Auditor) AuditHash(node Node, hash string) (*Record, error) {
+	tx, err := node.TransactionByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return a.AuditTx(tx)
+}
+
+// AuditBlocks 审计区块from到to（含）中的全部隐私交易
+func (a *Auditor) AuditBlocks(node Node, from, to uint64) ([]Record, error) {
+	if from > to || to-from >= MaxBlockRange {
+		return nil, errBlockRange
+	}
+	records := []Record{}
+	for number := from; number <= to; number++ {
+		block, err := node.BlockByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		for i := range block.Transactions {
+			record, err := a.AuditTx(&block.Transactions[i])
+			if err != nil {
+				return nil, fmt.Errorf("block %d tx %s: %v", number, block.Transactions[i].Hash, err)
+			}
+			if record.Type != plainTx {
+				records = append(records, *record)
+			}
+		}
+	}
+	return records, nil
+}
+
+// AuditTx 解密交易中的金额和地址密文
+func (a *Auditor) AuditTx(tx *Transaction) (*Record, error) {
+	typ, err := parseHexUint(tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := parseHexUint(tx.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	record := &Record{Hash: tx.Hash, Block: block, Type: typ}
+	switch typ {
+	case transferTx:
+		if record.Sender, err = a.party(tx.EspkC1, tx.EspkC2); err != nil {
+			return nil, err
+		}
+		receiver, err := a.party(tx.ErpkC1, tx.ErpkC2)
+		if err != nil {
+			return nil, err
+		}
+		vo, err1 := a.value(tx.EvOC1, tx.EvOC2)
+		vs, err2 := a.value(tx.EvSC1, tx.EvSC2)
+		vr, err3 := a.value(tx.EvRC1, tx.EvRC2)
+		if err := firstError(err1, err2, err3); err != nil {
+			return nil, err
+		}
+		record.Inputs = []Input{{Cm: tx.CmO, Amount: vo}}
+		record.Outputs = []Output{
+			{Receiver: receiver, Cm: tx.CmS, Amount: vs},
+			{Receiver: record.Sender, Cm: tx.CmR, Amount: vr, Change: true},
+		}
+	case purchaseTx:
+		// 购币交易中发币者用监管者公钥加密了购币金额
+		v, err := a.value(tx.EpkpC1, tx.EpkpC2)
+		if err != nil {
+			return nil, err
+		}
+		record.Outputs = []Output{{Cm: tx.CmV, Amount: v}}
+	case multiTransferTx:
+		if err := a.auditMultiTransfer(record, tx.Privacy); err != nil {
+			return nil, err
+		}
+	case plainTx:
+	default:
+		return nil, fmt.Errorf("unknown transaction type %d", typ)
+	}
+	return record, nil
+}
+
+// auditMultiTransfer 解密多输入多输出转账的隐私数据。隐私数据为RLP编码的
+// [Espk, CMSpk, SpkEP, Inputs, Outputs, BP, RP]，输入为[Cm, Ev, EP]，
+// 输出为[Erpk, CMRpk, RpkEP, Ev, Cm, FP, EvBs, CmR]，密文为[C1, C2]
+func (a *Auditor) auditMultiTransfer(record *Record, privacy string) error {
+	blob, err := decodeHex(privacy)
+	if err != nil {
+		return err
+	}
+	payload, err := decodeRLP(blob)
+	if err != nil {
+		return err
+	}
+	if record.Sender, err = a.partyOf(payload.field(0)); err != nil {
+		return err
+	}
+	for _, in := range payload.field(3).List {
+		v, err := a.valueOf(in.field(1))
+		if err != nil {
+			return err
+		}
+		record.Inputs = append(record.Inputs, Input{Cm: encodeHex(in.field(0).Bytes), Amount: v})
+	}
+	for _, out := range payload.field(4).List {
+		receiver, err := a.partyOf(out.field(0))
+		if err != nil {
+			return err
+		}
+		v, err := a.valueOf(out.field(3))
+		if err != nil {
+			return err
+		}
+		record.Outputs = append(record.Outputs, Output{
+			Receiver: receiver,
+			Cm:       encodeHex(out.field(4).Bytes),
+			Amount:   v,
+			Change:   receiver.Address == record.Sender.Address,
+		})
+	}
+	return nil
+}
+
+// party 解密地址公钥密文并查找对应的登记身份
+func (a *Auditor) party(c1, c2 string) (*Party, error) {
+	C, err := cypherText(c1, c2)
+	if err != nil {
+		return nil, err
+	}
+	return a.decryptParty(C)
+}
+
+func (a *Auditor) partyOf(item rlpItem) (*Party, error) {
+	return a.decryptParty(ecc.CypherText{C1: item.field(0).Bytes, C2: item.field(1).Bytes})
+}
+
+func (a *Auditor) decryptParty(C ecc.CypherText) (*Party, error) {
+	point, err := ecc.DecryptPoint(a.Key, C)
+	if err != nil {
+		return nil, err
+	}
+	address := ecc.MarshalPoint(point)
+	party := &Party{Address: encodeHex(address)}
+	if a.Lookup != nil {
+		if id := a.Lookup(address); id != nil {
+			party.Known, party.Name, party.ID, party.Hashky = true, id.Name, id.ID, id.Hashky
+		}
+	}
+	return party, nil
+}
+
+// value 解密金额密文
+func (a *Auditor) value(c1, c2 string) (uint64, error) {
+	C, err := cypherText(c1, c2)
+	if err != nil {
+		return 0, err
+	}
+	return ecc.DecryptValue(a.Key, C)
+}
+
+func (a *Auditor) valueOf(item rlpItem) (uint64, error) {
+	return ecc.DecryptValue(a.Key, ecc.CypherText{C1: item.field(0).Bytes, C2: item.field(1).Bytes})
+}
+
+func cypherText(c1, c2 string) (ecc.CypherText, error) {
+	C1, err1 := decodeHex(c1)
+	C2, err2 := decodeHex(c2)
+	if err := firstError(err1, err2); err != nil {
+		return ecc.CypherText{}, err
+	}
+	if len(C1) == 0 || len(C2) == 0 {
+		return ecc.CypherText{}, errors.New("missing cyphertext")
+	}
+	return ecc.CypherText{C1: C1, C2: C2}, nil
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+func encodeHex(b []byte) string {
+	return fmt.Sprintf("0x%x", b)
+}
@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"maskchain/privacy/ecc"
+	"regulator/regdb"
+	"regulator/utils"
+)
+
+type fakeNode map[string]*Transaction
+
+func (n fakeNode) TransactionByHash(hash string) (*Transaction, error) {
+	if tx, ok := n[hash]; ok {
+		return tx, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (n fakeNode) BlockNumber() (uint64, error) {
+	var latest uint64
+	for _, tx := range n {
+		if b, _ := parseHexUint(tx.BlockNumber); b > latest {
+			latest = b
+		}
+	}
+	return latest, nil
+}
+
+func (n fakeNode) BlockByNumber(number uint64) (*Block, error) {
+	block := &Block{Timestamp: fmt.Sprintf("0x%x", 1000+number)}
+	for _, tx := range n {
+		if b, _ := parseHexUint(tx.BlockNumber); b == number {
+			block.Transactions = append(block.Transactions, *tx)
+		}
+	}
+	return block, nil
+}
+
+// rlpBytes和rlpList是测试中构造多输入多输出转账隐私数据的最小RLP编码
+func rlpBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	var content []byte
+	for _, item := range items {
+		content = append(content, item...)
+	}
+	return append(rlpHeader(0xc0, len(content)), content...)
+}
+
+func rlpHeader(base byte, size int) []byte {
+	if size < 56 {
+		return []byte{base + byte(size)}
+	}
+	var n []byte
+	for ; size > 0; size >>= 8 {
+		n = append([]byte{byte(size)}, n...)
+	}
+	return append([]byte{base + 55 + byte(len(n))}, n...)
+}
+
+// testChain 用监管者公钥构造链上交易
+type testChain struct {
+	regPub  ecc.PublicKey
+	auditor *Auditor
+}
+
+// newTestChain 创建监管者密钥和登记了alice、bob的审计者
+func newTestChain() *testChain {
+	regPub, regPriv, _ := ecc.GenerateKeys("regulator")
+	identities := map[string]*regdb.Identity{}
+	for _, id := range []*regdb.Identity{
+		{Name: "alice", ID: "1", Hashky: "a1", Pubkey: "alice"},
+		{Name: "bob", ID: "2", Hashky: "b2", Pubkey: "bob"},
+	} {
+		identities[string(utils.AddressPoint(regPub, id.Pubkey))] = id
+	}
+	return &testChain{regPub: regPub, auditor: &Auditor{
+		Key:    regPriv,
+		Lookup: func(address []byte) *regdb.Identity { return identities[string(address)] },
+	}}
+}
+
+// encrypt 与节点相同的方式加密金额和地址公钥
+func (c *testChain) encrypt(v []byte) ecc.CypherText {
+	_, enc, _ := ecc.ConvertPub(c.regPub).EncryptCM(v)
+	return ecc.CypherText{
+		C1: elliptic.Marshal(ecc.EC.C, enc.P1.X, enc.P1.Y),
+		C2: elliptic.Marshal(ecc.EC.C, enc.P2.X, enc.P2.Y),
+	}
+}
+
+func (c *testChain) value(v uint64) ecc.CypherText {
+	return c.encrypt(new(big.Int).SetUint64(v).Bytes())
+}
+
+func (c *testChain) address(pk string) ecc.CypherText {
+	h := sha256.Sum256([]byte(pk))
+	addr := new(big.Int).SetBytes(h[:])
+	return c.encrypt(addr.Mod(addr, c.regPub.P).Bytes()[:8])
+}
+
+// purchase 构造购币交易，signed为发币者签名的金额
+func (c *testChain) purchase(hash string, block uint64, cm string, v uint64, signed string) *Transaction {
+	ev := c.value(v)
+	return &Transaction{
+		Hash: hash, BlockNumber: fmt.Sprintf("0x%x", block), ID: "0x1", CmV: cm,
+		EpkpC1: encodeHex(ev.C1), EpkpC2: encodeHex(ev.C2), SigM: encodeHex([]byte("1" + signed)),
+	}
+}
+
+// transfer 构造从sender到receiver的转账交易
+func (c *testChain) transfer(hash string, block uint64, sender, receiver, cmO string, vs, vr uint64) *Transaction {
+	espk, erpk := c.address(sender), c.address(receiver)
+	evs, evr, evo := c.value(vs), c.value(vr), c.value(vs+vr)
+	return &Transaction{
+		Hash: hash, BlockNumber: fmt.Sprintf("0x%x", block), ID: "0x0", CmO: cmO, CmS: hash + "01", CmR: hash + "02",
+		EspkC1: encodeHex(espk.C1), EspkC2: encodeHex(espk.C2), ErpkC1: encodeHex(erpk.C1), ErpkC2: encodeHex(erpk.C2),
+		EvSC1: encodeHex(evs.C1), EvSC2: encodeHex(evs.C2), EvRC1: encodeHex(evr.C1), EvRC2: encodeHex(evr.C2),
+		EvOC1: encodeHex(evo.C1), EvOC2: encodeHex(evo.C2),
+	}
+}
+
+func TestAudit(t *testing.T) {
+	chain := newTestChain()
+	regPub, auditor, value, address := chain.regPub, chain.auditor, chain.value, chain.address
+
+	espk, erpk := address("alice"), address("bob")
+	ct := func(c ecc.CypherText) []byte { return rlpList(rlpBytes(c.C1), rlpBytes(c.C2)) }
+	empty := rlpBytes(nil)
+	output := func(receiver ecc.CypherText, cm byte, v uint64) []byte {
+		return rlpList(ct(receiver), empty, empty, ct(value(v)), rlpBytes([]byte{0xc0, cm}), empty, empty, empty)
+	}
+	privacy := rlpList(ct(espk), empty, empty,
+		rlpList(rlpList(rlpBytes([]byte{0xc0, 1}), ct(value(12)), empty), rlpList(rlpBytes([]byte{0xc0, 2}), ct(value(3)), empty)),
+		rlpList(output(erpk, 3, 10), output(address("carol"), 4, 4), output(espk, 5, 1)),
+		empty, empty)
+
+	node := fakeNode{
+		"0x01": chain.purchase("0x01", 1, "0xcc", 20, "20"),
+		"0x02": chain.transfer("0x02", 2, "alice", "bob", "0xcc", 12, 8),
+		"0x03": {Hash: "0x03", BlockNumber: "0x3", ID: "0x3", Privacy: encodeHex(privacy)},
+		"0x04": {Hash: "0x04", BlockNumber: "0x3", ID: "0x2"},
+	}
+
+	// 购币交易只能解密金额
+	record, err := auditor.AuditHash(node, "0x01")
+	if err != nil {
+		t.Fatalf("failed to audit purchase: %v", err)
+	}
+	if record.Type != purchaseTx || len(record.Outputs) != 1 || record.Outputs[0].Amount != 20 || record.Outputs[0].Cm != "0xcc" {
+		t.Errorf("purchase record mismatch: %+v", record)
+	}
+	// 转账交易关联双方身份与金额
+	record, err = auditor.AuditHash(node, "0x02")
+	if err != nil {
+		t.Fatalf("failed to audit transfer: %v", err)
+	}
+	if record.Sender.Name != "alice" || record.Inputs[0].Amount != 20 {
+		t.Errorf("transfer sender mismatch: %+v", record)
+	}
+	if out := record.Outputs[0]; out.Receiver.Name != "bob" || out.Amount != 12 || out.Change {
+		t.Errorf("transfer output mismatch: %+v", out)
+	}
+	if out := record.Outputs[1]; out.Receiver.Name != "alice" || out.Amount != 8 || !out.Change {
+		t.Errorf("transfer change mismatch: %+v", out)
+	}
+	// 区块范围审计跳过普通交易，未登记的接收方只给出地址
+	records, err := auditor.AuditBlocks(node, 3, 3)
+	if err != nil {
+		t.Fatalf("failed to audit blocks: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("record count mismatch: have %d, want 1", len(records))
+	}
+	record = &records[0]
+	if record.Sender.Name != "alice" || len(record.Inputs) != 2 || record.Inputs[0].Amount+record.Inputs[1].Amount != 15 {
+		t.Errorf("multi transfer inputs mismatch: %+v", record)
+	}
+	want := []struct {
+		name   string
+		amount uint64
+		change bool
+	}{{"bob", 10, false}, {"", 4, false}, {"alice", 1, true}}
+	for i, w := range want {
+		out := record.Outputs[i]
+		if out.Receiver.Name != w.name || out.Receiver.Known != (w.name != "") || out.Amount != w.amount || out.Change != w.change {
+			t.Errorf("multi transfer output %d mismatch: %+v", i, out)
+		}
+	}
+	if record.Outputs[1].Receiver.Address != encodeHex(utils.AddressPoint(regPub, "carol")) {
+		t.Errorf("unknown receiver address mismatch")
+	}
+	if _, err := auditor.AuditBlocks(node, 1, MaxBlockRange+1); err != errBlockRange {
+		t.Errorf("oversized block range accepted: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"regulator/regdb"
+)
+
+// Confirmations 索引器只索引已有足够确认数的区块，避免分叉回滚后记错账
+const Confirmations = 6
+
+// exchangeParty 购币流入的对方
+const exchangeParty = "exchange"
+
+// LedgerStore 身份账本存储，由regdb.Ledger实现
+type LedgerStore interface {
+	Head() (uint64, error)
+	Purchase(cm string) (*regdb.Purchase, error)
+	Apply(b *regdb.LedgerBlock) error
+}
+
+// Indexer 持续解密新区块中的隐私交易，按登记身份记录资金流入流出。
+//
+// 转账金额计入发送方的流出和接收方的流入，找零不计入。购币交易中链上没有
+// 购币者身份，购得的承诺首次被花费时计入花费者的流入。
+type Indexer struct {
+	Node          Node
+	Auditor       *Auditor
+	Ledger        LedgerStore
+	Confirmations uint64
+}
+
+// NewIndexer 创建使用默认确认数的索引器
+func NewIndexer(node Node, auditor *Auditor, ledger LedgerStore) *Indexer {
+	return &Indexer{Node: node, Auditor: auditor, Ledger: ledger, Confirmations: Confirmations}
+}
+
+// Run 每隔interval索引一次新区块，直到quit被关闭
+func (ix *Indexer) Run(interval time.Duration, quit <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if head, err := ix.Sync(); err != nil {
+			fmt.Println("索引区块失败:", err, "已索引到区块", head)
+		}
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Sync 索引账本中已索引区块之后所有已确认的区块，返回已索引到的区块号
+func (ix *Indexer) Sync() (uint64, error) {
+	head, err := ix.Ledger.Head()
+	if err != nil {
+		return 0, err
+	}
+	latest, err := ix.Node.BlockNumber()
+	if err != nil || latest < ix.Confirmations {
+		return head, err
+	}
+	for number := head + 1; number <= latest-ix.Confirmations; number++ {
+		if err := ix.indexBlock(number); err != nil {
+			return head, fmt.Errorf("block %d: %v", number, err)
+		}
+		head = number
+	}
+	return head, nil
+}
+
+// indexBlock 解密区块中的隐私交易，并在一次写入中更新账本
+func (ix *Indexer) indexBlock(number uint64) error {
+	block, err := ix.Node.BlockByNumber(number)
+	if err != nil {
+		return err
+	}
+	ts, err := parseHexUint(block.Timestamp)
+	if err != nil {
+		return err
+	}
+	var (
+		lb        = &regdb.LedgerBlock{Number: number}
+		purchases = make(map[string]*regdb.Purchase) // 本区块的购币交易
+	)
+	alert := func(hash, kind, format string, args ...interface{}) {
+		lb.Alerts = append(lb.Alerts, regdb.Alert{Hash: hash, Block: number, Time: ts, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+	}
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		record, err := ix.Auditor.AuditTx(tx)
+		if err != nil {
+			alert(tx.Hash, "undecryptable", "%v", err)
+			continue
+		}
+		switch record.Type {
+		case purchaseTx:
+			// 发币者签名的消息为交易类型"1"加金额
+			p := regdb.Purchase{Hash: tx.Hash, Block: number, Time: ts, Cm: record.Outputs[0].Cm, Amount: record.Outputs[0].Amount}
+			if m, err := decodeHex(tx.SigM); err == nil && len(m) > 0 {
+				p.Signed = string(m[1:])
+			}
+			if p.Signed != strconv.FormatUint(p.Amount, 10) {
+				alert(tx.Hash, "purchase_amount", "signed amount %q, encrypted amount %d", p.Signed, p.Amount)
+			}
+			purchases[p.Cm] = &p
+			lb.Purchases = append(lb.Purchases, p)
+
+		case transferTx, multiTransferTx:
+			sender := record.Sender
+			var in, out uint64
+			for _, input := range record.Inputs {
+				in += input.Amount
+				p := purchases[input.Cm]
+				if p == nil {
+					if p, err = ix.Ledger.Purchase(input.Cm); err != nil {
+						return err
+					}
+				}
+				if p == nil {
+					continue
+				}
+				if p.Amount != input.Amount {
+					alert(tx.Hash, "purchase_spend", "purchase %s of %d spent as %d", p.Hash, p.Amount, input.Amount)
+				}
+				if sender.Known {
+					lb.Flows = append(lb.Flows, regdb.Flow{
+						Hashky: sender.Hashky, Hash: p.Hash, Block: p.Block, Time: p.Time,
+						Direction: "in", Kind: "purchase", Counterparty: exchangeParty, Cm: p.Cm, Amount: p.Amount,
+					})
+				}
+			}
+			for _, output := range record.Outputs {
+				out += output.Amount
+				if output.Change {
+					continue
+				}
+				flow := regdb.Flow{Hash: tx.Hash, Block: number, Time: ts, Kind: "transfer", Cm: output.Cm, Amount: output.Amount}
+				if sender.Known {
+					flow.Hashky, flow.Direction, flow.Counterparty = sender.Hashky, "out", partyKey(output.Receiver)
+					lb.Flows = append(lb.Flows, flow)
+				}
+				if output.Receiver.Known {
+					flow.Hashky, flow.Direction, flow.Counterparty = output.Receiver.Hashky, "in", partyKey(sender)
+					lb.Flows = append(lb.Flows, flow)
+				}
+			}
+			if in != out {
+				alert(tx.Hash, "unbalanced", "inputs %d, outputs %d", in, out)
+			}
+		}
+	}
+	return ix.Ledger.Apply(lb)
+}
+
+// partyKey 返回账本中记录的对方，已登记身份为公钥，否则为地址
+func partyKey(p *Party) string {
+	if p.Known {
+		return p.Hashky
+	}
+	return p.Address
+}
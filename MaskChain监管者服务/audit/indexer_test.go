@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"testing"
+
+	"regulator/regdb"
+	"regulator/utils"
+)
+
+// memLedger 内存中的身份账本
+type memLedger struct {
+	head      uint64
+	flows     []regdb.Flow
+	purchases map[string]*regdb.Purchase
+	alerts    []regdb.Alert
+}
+
+func (l *memLedger) Head() (uint64, error) { return l.head, nil }
+
+func (l *memLedger) Purchase(cm string) (*regdb.Purchase, error) { return l.purchases[cm], nil }
+
+func (l *memLedger) Apply(b *regdb.LedgerBlock) error {
+	l.head = b.Number
+	l.flows = append(l.flows, b.Flows...)
+	for i := range b.Purchases {
+		l.purchases[b.Purchases[i].Cm] = &b.Purchases[i]
+	}
+	l.alerts = append(l.alerts, b.Alerts...)
+	return nil
+}
+
+func (l *memLedger) balance(hashky string) (in, out uint64) {
+	for _, f := range l.flows {
+		if f.Hashky == hashky && f.Direction == "in" {
+			in += f.Amount
+		}
+		if f.Hashky == hashky && f.Direction == "out" {
+			out += f.Amount
+		}
+	}
+	return in, out
+}
+
+func TestIndexer(t *testing.T) {
+	chain := newTestChain()
+	node := fakeNode{
+		"0x01": chain.purchase("0x01", 1, "0xc1", 30, "30"),
+		"0x02": chain.purchase("0x02", 1, "0xc2", 5, "50"), // 签名金额与加密金额不一致
+		"0x03": chain.transfer("0x03", 2, "alice", "bob", "0xc1", 12, 18),
+		"0x04": chain.transfer("0x04", 3, "bob", "carol", "0x0301", 2, 10),
+		"0x05": chain.transfer("0x05", 4, "alice", "bob", "0x0302", 1, 17), // 未确认
+	}
+	ledger := &memLedger{purchases: make(map[string]*regdb.Purchase)}
+	indexer := NewIndexer(node, chain.auditor, ledger)
+	indexer.Confirmations = 1
+
+	head, err := indexer.Sync()
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if head != 3 || ledger.head != 3 {
+		t.Fatalf("indexed up to block %d, want 3", head)
+	}
+	// alice购币30（花费时计入），转给bob 12；bob收到12，转给未登记的carol 2
+	if in, out := ledger.balance("a1"); in != 30 || out != 12 {
+		t.Errorf("alice ledger mismatch: in %d out %d, want 30 12", in, out)
+	}
+	if in, out := ledger.balance("b2"); in != 12 || out != 2 {
+		t.Errorf("bob ledger mismatch: in %d out %d, want 12 2", in, out)
+	}
+	for _, f := range ledger.flows {
+		if f.Kind == "purchase" && (f.Hash != "0x01" || f.Block != 1 || f.Counterparty != exchangeParty) {
+			t.Errorf("purchase flow mismatch: %+v", f)
+		}
+		if f.Hashky == "b2" && f.Direction == "out" && f.Counterparty != encodeHex(utils.AddressPoint(chain.regPub, "carol")) {
+			t.Errorf("unknown counterparty not recorded by address: %+v", f)
+		}
+	}
+	if len(ledger.alerts) != 1 || ledger.alerts[0].Hash != "0x02" || ledger.alerts[0].Kind != "purchase_amount" {
+		t.Errorf("alerts mismatch: %+v", ledger.alerts)
+	}
+	// 已索引的区块不会重复记账
+	if head, err := indexer.Sync(); err != nil || head != 3 || len(ledger.flows) != 4 {
+		t.Errorf("resync changed ledger: head %d, %d flows, err %v", head, len(ledger.flows), err)
+	}
+}
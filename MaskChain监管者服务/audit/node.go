@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// Transaction 节点返回的交易中审计所需的字段
+type Transaction struct {
+	Hash        string `json:"hash"`
+	BlockNumber string `json:"blockNumber"`
+	ID          string `json:"ID"`
+	ErpkC1      string `json:"erpkc1"`
+	ErpkC2      string `json:"erpkc2"`
+	EspkC1      string `json:"espkc1"`
+	EspkC2      string `json:"espkc2"`
+	EvSC1       string `json:"evsc1"`
+	EvSC2       string `json:"evsc2"`
+	EvRC1       string `json:"evrc1"`
+	EvRC2       string `json:"evrc2"`
+	EvOC1       string `json:"evoc1"`
+	EvOC2       string `json:"evoc2"`
+	CmS         string `json:"cms"`
+	CmR         string `json:"cmr"`
+	CmO         string `json:"cmo"`
+	EpkpC1      string `json:"epkpc1"`
+	EpkpC2      string `json:"epkpc2"`
+	SigM        string `json:"sigm"`
+	CmV         string `json:"cmv"`
+	Privacy     string `json:"privacy"`
+}
+
+// Block 节点返回的区块及其完整交易
+type Block struct {
+	Number       string        `json:"number"`
+	Hash         string        `json:"hash"`
+	Timestamp    string        `json:"timestamp"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Node 审计所需的节点接口，由Client实现
+type Node interface {
+	BlockNumber() (uint64, error)
+	TransactionByHash(hash string) (*Transaction, error)
+	BlockByNumber(number uint64) (*Block, error)
+}
+
+// Client 通过RPC读取区块链节点
+type Client struct {
+	URL string // 节点RPC地址，如http://localhost:8545
+}
+
+type rpcRequest struct {
+	Jsonrpc string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call 调用节点RPC方法并将结果解析到result
+func (c *Client) call(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, err := json.Marshal(rpcRequest{Jsonrpc: "2.0", Method: method, Params: params, ID: 67})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(c.URL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var res rpcResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return fmt.Errorf("%s: %s", method, res.Error.Message)
+	}
+	if len(res.Result) == 0 || string(res.Result) == "null" {
+		return errors.New(method + ": not found")
+	}
+	return json.Unmarshal(res.Result, result)
+}
+
+// BlockNumber 返回节点当前的区块高度
+func (c *Client) BlockNumber() (uint64, error) {
+	var hex string
+	if err := c.call(&hex, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return parseHexUint(hex)
+}
+
+// TransactionByHash 返回指定哈希的交易
+func (c *Client) TransactionByHash(hash string) (*Transaction, error) {
+	tx := new(Transaction)
+	if err := c.call(tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// BlockByNumber 返回指定高度的区块及其完整交易
+func (c *Client) BlockByNumber(number uint64) (*Block, error) {
+	block := new(Block)
+	if err := c.call(block, "eth_getBlockByNumber", fmt.Sprintf("0x%x", number), true); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// parseHexUint 解析0x开头的十六进制整数，空串为0
+func parseHexUint(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if len(s) < 3 || s[:2] != "0x" {
+		return 0, fmt.Errorf("invalid hex number %q", s)
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
@@ -0,0 +1,85 @@
+package audit
+
+import "errors"
+
+var errRLP = errors.New("malformed rlp")
+
+// rlpItem 解码后的RLP元素，列表元素的List不为nil
+type rlpItem struct {
+	Bytes []byte
+	List  []rlpItem
+}
+
+// decodeRLP 解码多输入多输出转账的隐私数据。监管者服务不依赖节点代码，这里只实现读取所需的最小子集
+func decodeRLP(b []byte) (rlpItem, error) {
+	item, rest, err := splitRLP(b)
+	if err != nil {
+		return rlpItem{}, err
+	}
+	if len(rest) != 0 {
+		return rlpItem{}, errRLP
+	}
+	return item, nil
+}
+
+func splitRLP(b []byte) (item rlpItem, rest []byte, err error) {
+	if len(b) == 0 {
+		return item, nil, errRLP
+	}
+	var (
+		prefix = b[0]
+		offset int
+		size   int
+		list   bool
+	)
+	switch {
+	case prefix < 0x80:
+		return rlpItem{Bytes: b[:1]}, b[1:], nil
+	case prefix < 0xb8:
+		offset, size = 1, int(prefix-0x80)
+	case prefix < 0xc0:
+		offset, size, err = longSize(b, int(prefix-0xb7))
+	case prefix < 0xf8:
+		offset, size, list = 1, int(prefix-0xc0), true
+	default:
+		offset, size, err = longSize(b, int(prefix-0xf7))
+		list = true
+	}
+	if err != nil {
+		return item, nil, err
+	}
+	if size < 0 || len(b)-offset < size {
+		return item, nil, errRLP
+	}
+	content, rest := b[offset:offset+size], b[offset+size:]
+	if !list {
+		return rlpItem{Bytes: content}, rest, nil
+	}
+	item.List = []rlpItem{}
+	for len(content) > 0 {
+		var elem rlpItem
+		if elem, content, err = splitRLP(content); err != nil {
+			return item, nil, err
+		}
+		item.List = append(item.List, elem)
+	}
+	return item, rest, nil
+}
+
+func longSize(b []byte, n int) (offset, size int, err error) {
+	if n > 4 || len(b) < 1+n {
+		return 0, 0, errRLP
+	}
+	for _, c := range b[1 : 1+n] {
+		size = size<<8 | int(c)
+	}
+	return 1 + n, size, nil
+}
+
+// field 返回列表的第i个元素
+func (it rlpItem) field(i int) rlpItem {
+	if i < len(it.List) {
+		return it.List[i]
+	}
+	return rlpItem{}
+}
@@ -10,4 +10,7 @@ require (
 	github.com/onsi/ginkgo v1.14.1 // indirect
 	github.com/onsi/gomega v1.10.2 // indirect
 	github.com/urfave/cli v1.22.4
+	maskchain/privacy v0.0.0
 )
+
+replace maskchain/privacy => ../MaskChain隐私计算
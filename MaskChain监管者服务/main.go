@@ -1,16 +1,20 @@
 package main
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"github.com/go-redis/redis"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/urfave/cli"
+	"maskchain/privacy/ecc"
+	"math/big"
 	"net/http"
 	"os"
+	"regulator/audit"
 	"regulator/regdb"
 	"regulator/utils"
-	ecc "regulator/utils/ECC"
+	"time"
 )
 
 const (
@@ -19,6 +23,8 @@ const (
 	clientUsage      = "Regulatory server for ethereumZKP"
 )
 
+const indexInterval = 5 * time.Second // 索引器轮询新区块的间隔
+
 var (
 	app       = cli.NewApp()
 	baseFlags = []cli.Flag{
@@ -27,8 +33,15 @@ var (
 		utils.DataportFlag,
 		utils.ListenPortFlag,
 		utils.DbPasswdPortFlag,
+		utils.NodeURLFlag,
+		utils.AuditTokenFlag,
+		utils.IndexFlag,
+		utils.DLogDirFlag,
+		utils.DLogBudgetFlag,
 	}
-	regDb *redis.Client
+	regDb      *redis.Client
+	nodeURL    string // 审计时读取交易的节点RPC地址
+	auditToken string // 审计接口令牌
 )
 
 func init() {
@@ -64,6 +77,12 @@ func prepare(ctx *cli.Context) error {
 		utils.Fatalf("Failed to start server,incomplete database initialization,please initialise again")
 	}
 	fmt.Printf("Chain ID:%s\n", regdb.Get(regDb, "chainConfig").(*regdb.Identity).ID)
+	nodeURL, auditToken = ctx.String("node"), ctx.String("audittoken")
+	ecc.SetDLogConfig(ecc.DLogConfig{Dir: ctx.String("dlogdir"), Budget: ctx.Int("dlogbudget") << 20})
+	if ctx.Bool("index") {
+		go audit.NewIndexer(&audit.Client{URL: nodeURL}, newAuditor(), regdb.NewLedger(regDb)).Run(indexInterval, nil)
+		fmt.Println("Indexing chain", nodeURL, "into the identity ledger")
+	}
 	startNetwork(ctx.String("port"))
 	return nil
 }
@@ -80,7 +99,17 @@ func startNetwork(port string) {
 	e.POST("/register", register)
 	e.POST("/verify", verify)
 	e.GET("/regkey", regkey)
-	// TODO:写一个post路由decrypto，接收参数监管者私钥X和需要解密的数据，如果X正确就返回解密后的明文数据
+	// 审计接口解密链上交易，需在请求头中携带令牌：Authorization: Bearer <audittoken>
+	if auditToken != "" {
+		auth := middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+			return subtle.ConstantTimeCompare([]byte(key), []byte(auditToken)) == 1, nil
+		})
+		e.POST("/decrypto", decrypto, auth)
+		e.POST("/ledger", ledger, auth)
+		e.POST("/alerts", alerts, auth)
+	} else {
+		fmt.Println("Audit interface disabled, set --audittoken to enable it")
+	}
 	// Start server
 	e.Logger.Fatal(e.Start(":" + port))
 }
@@ -98,10 +127,29 @@ func register(c echo.Context) error {
 	if regdb.Exists(regDb, hash) {
 		return c.String(http.StatusOK, "Account registered!") //不允许重复注册
 	}
+	// 完整公钥须与Hashky一致，登记后审计时可根据交易中的地址密文识别该用户
+	var address []byte
+	if u.Pubkey != "" {
+		pub, ok := utils.ParsePubkey(u.Pubkey)
+		hashky, _ := new(big.Int).SetString(u.Hashky, 16)
+		if !ok || hashky == nil || hashky.Cmp(pub.H) != 0 {
+			return c.String(http.StatusOK, "Fail!")
+		}
+		key := regdb.Get(regDb, "key").(*ecc.PrivateKey)
+		if address = utils.AddressPoint(key.PublicKey, u.Pubkey); address == nil {
+			return c.String(http.StatusOK, "Fail!")
+		}
+	}
 	if err := regdb.Set(regDb, hash, u); err != nil {
 		utils.Fatalf("Failed to set : %v", err)
 		return c.String(http.StatusOK, "Fail!")
 	}
+	if address != nil {
+		if err := regdb.Set(regDb, regdb.AddressKey(address), u); err != nil {
+			utils.Fatalf("Failed to set : %v", err)
+			return c.String(http.StatusOK, "Fail!")
+		}
+	}
 	fmt.Println("存储了Hashky", u.Hashky, ",Hash:", hash)
 	return c.String(http.StatusOK, "Successful!")
 	//return c.JSON(http.StatusCreated, u)
@@ -133,3 +181,103 @@ func regkey(c echo.Context) error {
 		return c.String(http.StatusOK, "chainID错误")
 	}
 }
+
+// auditRequest 审计请求，指定交易哈希或区块范围
+type auditRequest struct {
+	Hash string `json:"hash"` // 交易哈希
+	From uint64 `json:"from"` // 起始区块
+	To   uint64 `json:"to"`   // 结束区块（含）
+}
+
+// decrypto 用监管者私钥解密交易中的金额和双方地址，返回关联身份与金额的审计记录
+func decrypto(c echo.Context) error {
+	req := new(auditRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	fmt.Println("审计请求", c.RealIP(), "hash:", req.Hash, "blocks:", req.From, "-", req.To)
+	auditor := newAuditor()
+	node := &audit.Client{URL: nodeURL}
+	if req.Hash != "" {
+		record, err := auditor.AuditHash(node, req.Hash)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusOK, []*audit.Record{record})
+	}
+	records, err := auditor.AuditBlocks(node, req.From, req.To)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusOK, records)
+}
+
+// newAuditor 创建使用数据库中监管者私钥的审计者
+func newAuditor() *audit.Auditor {
+	return &audit.Auditor{
+		Key:    *regdb.Get(regDb, "key").(*ecc.PrivateKey),
+		Lookup: lookupIdentity,
+	}
+}
+
+// ledgerRequest 身份账本查询，时间为区块时间戳，To为0表示不限
+type ledgerRequest struct {
+	Hashky string `json:"hashky"` // 身份公钥
+	From   uint64 `json:"from"`   // 起始时间
+	To     uint64 `json:"to"`     // 结束时间（含）
+}
+
+// ledgerResult 身份账本查询结果
+type ledgerResult struct {
+	Identity *regdb.Identity `json:"identity"`
+	Balance  regdb.Balance   `json:"balance"`
+	Flows    []regdb.Flow    `json:"flows"`
+}
+
+// ledger 返回登记身份在时间范围内的资金流水和流入流出总额
+func ledger(c echo.Context) error {
+	req := new(ledgerRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	hash := utils.Hash(req.Hashky)
+	if req.Hashky == "" || !regdb.Exists(regDb, hash) {
+		return c.String(http.StatusBadRequest, "Identity not registered")
+	}
+	l := regdb.NewLedger(regDb)
+	balance, err := l.Balance(req.Hashky)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	flows, err := l.Flows(req.Hashky, req.From, req.To)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ledgerResult{
+		Identity: regdb.Get(regDb, hash).(*regdb.Identity),
+		Balance:  balance,
+		Flows:    flows,
+	})
+}
+
+// alerts 返回索引时在时间范围内发现的异常，如购币签名金额与加密金额不一致
+func alerts(c echo.Context) error {
+	req := new(ledgerRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	res, err := regdb.NewLedger(regDb).Alerts(req.From, req.To)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// lookupIdentity 根据地址公钥解密点查找登记的身份
+func lookupIdentity(address []byte) *regdb.Identity {
+	key := regdb.AddressKey(address)
+	if !regdb.Exists(regDb, key) {
+		return nil
+	}
+	return regdb.Get(regDb, key).(*regdb.Identity)
+}
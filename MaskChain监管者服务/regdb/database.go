@@ -1,12 +1,13 @@
 package regdb
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/go-redis/redis"
 	"log"
+	"maskchain/privacy/ecc"
 	"regulator/utils"
-	ecc "regulator/utils/ECC"
 )
 
 type Identity struct {
@@ -14,6 +15,7 @@ type Identity struct {
 	ID      string
 	Hashky  string
 	ExtInfo string //新增个备注信息
+	Pubkey  string //用户完整公钥P || G1 || G2 || H，用于审计时识别交易双方
 }
 
 func (id *Identity) GetName() string    { return id.Name }
@@ -21,6 +23,9 @@ func (id *Identity) GetID() string      { return id.ID }
 func (id *Identity) GetHashky() string  { return id.Hashky }
 func (id *Identity) GetExtInfo() string { return id.ExtInfo }
 
+// AddressKey 返回以地址公钥解密点为索引的身份键
+func AddressKey(point []byte) string { return "addr:" + hex.EncodeToString(point) }
+
 func ConnectToDB(dataip string, dataport string, passwd string, database int) *redis.Client {
 	Db, err := Setup(dataip, dataport, passwd, database)
 	if err != nil {
@@ -58,7 +63,7 @@ func Get(regDb *redis.Client, key string) interface{} {
 	// fmt.Println(result)
 	// raw 为反序列化后的Identity结构体
 	switch key {
-	case "key":
+	case "key", "legacyKey":
 		{
 			raw := new(ecc.PrivateKey)
 			if err := json.Unmarshal([]byte(result), &raw); err != nil {
@@ -0,0 +1,183 @@
+package regdb
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/go-redis/redis"
+)
+
+// 身份账本在Redis中的键
+const (
+	ledgerHeadKey   = "ledger:head"     // 已索引到的区块号
+	ledgerAlertsKey = "ledger:alerts"   // 异常记录，按区块时间排序
+	flowsPrefix     = "ledger:flows:"   // 身份的资金流水，按区块时间排序
+	balancePrefix   = "ledger:balance:" // 身份的流入流出总额
+	purchasePrefix  = "ledger:purchase:"
+)
+
+// Flow 一个身份的一笔资金流入或流出
+type Flow struct {
+	Hashky       string `json:"hashky"`       // 身份公钥
+	Hash         string `json:"hash"`         // 交易哈希
+	Block        uint64 `json:"block"`        // 区块号
+	Time         uint64 `json:"time"`         // 区块时间
+	Direction    string `json:"direction"`    // in或out
+	Kind         string `json:"kind"`         // transfer或purchase
+	Counterparty string `json:"counterparty"` // 对方公钥，未登记时为对方地址
+	Cm           string `json:"cm"`           // 对应的承诺
+	Amount       uint64 `json:"amount"`       // 金额
+}
+
+// Balance 一个身份的流入流出总额
+type Balance struct {
+	In      uint64 `json:"in"`
+	Out     uint64 `json:"out"`
+	Balance int64  `json:"balance"`
+}
+
+// Purchase 链上购币交易，购得的承诺首次被花费时计入花费者的流入
+type Purchase struct {
+	Hash   string `json:"hash"`
+	Block  uint64 `json:"block"`
+	Time   uint64 `json:"time"`
+	Cm     string `json:"cm"`
+	Amount uint64 `json:"amount"` // 解密得到的金额
+	Signed string `json:"signed"` // 发币者签名的金额
+}
+
+// Alert 索引时发现的异常
+type Alert struct {
+	Hash   string `json:"hash"`
+	Block  uint64 `json:"block"`
+	Time   uint64 `json:"time"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// LedgerBlock 一个区块对身份账本的全部修改
+type LedgerBlock struct {
+	Number    uint64
+	Flows     []Flow
+	Purchases []Purchase
+	Alerts    []Alert
+}
+
+// Ledger 存储在Redis中的身份账本
+type Ledger struct {
+	db *redis.Client
+}
+
+// NewLedger 返回使用regDb存储的身份账本
+func NewLedger(regDb *redis.Client) *Ledger {
+	return &Ledger{db: regDb}
+}
+
+// Head 返回已索引到的区块号
+func (l *Ledger) Head() (uint64, error) {
+	head, err := l.db.Get(ledgerHeadKey).Uint64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return head, err
+}
+
+// Purchase 返回购得承诺cm的购币交易，不存在时返回nil
+func (l *Ledger) Purchase(cm string) (*Purchase, error) {
+	data, err := l.db.Get(purchasePrefix + cm).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := new(Purchase)
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Apply 在一个Redis事务中写入区块的全部修改并推进已索引区块号，
+// 索引中断后重新索引该区块不会重复记账
+func (l *Ledger) Apply(b *LedgerBlock) error {
+	_, err := l.db.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, f := range b.Flows {
+			data, err := json.Marshal(f)
+			if err != nil {
+				return err
+			}
+			pipe.ZAdd(flowsPrefix+f.Hashky, redis.Z{Score: float64(f.Time), Member: data})
+			pipe.HIncrBy(balancePrefix+f.Hashky, f.Direction, int64(f.Amount))
+		}
+		for _, p := range b.Purchases {
+			data, err := json.Marshal(p)
+			if err != nil {
+				return err
+			}
+			pipe.Set(purchasePrefix+p.Cm, data, 0)
+		}
+		for _, a := range b.Alerts {
+			data, err := json.Marshal(a)
+			if err != nil {
+				return err
+			}
+			pipe.ZAdd(ledgerAlertsKey, redis.Z{Score: float64(a.Time), Member: data})
+		}
+		pipe.Set(ledgerHeadKey, b.Number, 0)
+		return nil
+	})
+	return err
+}
+
+// Flows 返回身份在区块时间from到to（含）之间的资金流水
+func (l *Ledger) Flows(hashky string, from, to uint64) ([]Flow, error) {
+	members, err := l.db.ZRangeByScore(flowsPrefix+hashky, timeRange(from, to)).Result()
+	if err != nil {
+		return nil, err
+	}
+	flows := make([]Flow, len(members))
+	for i, m := range members {
+		if err := json.Unmarshal([]byte(m), &flows[i]); err != nil {
+			return nil, err
+		}
+	}
+	return flows, nil
+}
+
+// Balance 返回身份的流入流出总额
+func (l *Ledger) Balance(hashky string) (Balance, error) {
+	res, err := l.db.HGetAll(balancePrefix + hashky).Result()
+	if err != nil {
+		return Balance{}, err
+	}
+	var b Balance
+	b.In, _ = strconv.ParseUint(res["in"], 10, 64)
+	b.Out, _ = strconv.ParseUint(res["out"], 10, 64)
+	b.Balance = int64(b.In) - int64(b.Out)
+	return b, nil
+}
+
+// Alerts 返回区块时间from到to（含）之间的异常
+func (l *Ledger) Alerts(from, to uint64) ([]Alert, error) {
+	members, err := l.db.ZRangeByScore(ledgerAlertsKey, timeRange(from, to)).Result()
+	if err != nil {
+		return nil, err
+	}
+	alerts := make([]Alert, len(members))
+	for i, m := range members {
+		if err := json.Unmarshal([]byte(m), &alerts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return alerts, nil
+}
+
+// timeRange 返回时间区间的查询范围，to为0表示不限
+func timeRange(from, to uint64) redis.ZRangeBy {
+	max := "+inf"
+	if to != 0 {
+		max = strconv.FormatUint(to, 10)
+	}
+	return redis.ZRangeBy{Min: strconv.FormatUint(from, 10), Max: max}
+}
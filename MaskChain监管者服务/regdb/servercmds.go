@@ -3,6 +3,7 @@ package regdb
 import (
 	"fmt"
 	"github.com/urfave/cli"
+	"maskchain/privacy/ecc"
 	"regulator/utils"
 )
 
@@ -68,6 +69,16 @@ func InitDB(ctx *cli.Context) error {
 		}
 		fmt.Println("Regulator key generated successfully")
 		fmt.Printf("PublicKey：P:%x\nG1:%x\nG2:%x\nH:%x\nPrivateKey：\nX:%x\n", priv.P, priv.G1, priv.G2, priv.H, priv.X)
+	} else if key := Get(regDb, "key").(*ecc.PrivateKey); !key.HasStandardGenerators() {
+		// 旧版本的密钥G1为G的随机倍数，生成者知道其离散对数，可打开任意承诺。
+		// 迁移到标准生成元（私钥和H不变），旧密钥保留为legacyKey以解密迁移前的金额
+		if err := Set(regDb, "legacyKey", key); err != nil {
+			utils.Fatalf("Failed to set : %v", err)
+		}
+		if err := Set(regDb, "key", ecc.MigrateKey(*key)); err != nil {
+			utils.Fatalf("Failed to set : %v", err)
+		}
+		fmt.Println("Regulator key migrated to the standard generators")
 	} else {
 		fmt.Println("Regulator key has been initialised sometimes before")
 	}
@@ -1,101 +0,0 @@
-/*
-Implementation of BulletProofs in Go
-
-*/
-package bp
-
-import (
-	"crypto/elliptic"
-	"crypto/sha256"
-	"encoding/binary"
-	"math/big"
-
-	"github.com/btcsuite/btcd/btcec"
-)
-
-var VecLength = 64
-
-type CryptoParams struct {
-	C   elliptic.Curve      // curve
-	KC  *btcec.KoblitzCurve // curve
-	BPG []ECPoint           // slice of gen 1 for BP
-	BPH []ECPoint           // slice of gen 2 for BP
-	N   *big.Int            // scalar prime
-	U   ECPoint             // a point that is a fixed group element with an unknown discrete-log relative to g,h
-	V   int                 // Vector length
-	G   ECPoint             // G value for commitments of a single value
-	H   ECPoint             // H value for commitments of a single value
-}
-
-func (c CryptoParams) Zero() ECPoint {
-	return ECPoint{big.NewInt(0), big.NewInt(0)}
-}
-
-func check(e error) {
-	if e != nil {
-		panic(e)
-	}
-}
-
-// NewECPrimeGroupKey returns the curve (field),
-// Generator 1 x&y, Generator 2 x&y, order of the generators
-func NewECPrimeGroupKey(n int) CryptoParams {
-	curValue := btcec.S256().Gx
-	s256 := sha256.New()
-	gen1Vals := make([]ECPoint, n)
-	gen2Vals := make([]ECPoint, n)
-	u := ECPoint{big.NewInt(0), big.NewInt(0)}
-	cg := ECPoint{}
-	ch := ECPoint{}
-
-	j := 0
-	confirmed := 0
-	for confirmed < (2*n + 3) {
-		s256.Write(new(big.Int).Add(curValue, big.NewInt(int64(j))).Bytes())
-
-		potentialXValue := make([]byte, 33)
-		binary.LittleEndian.PutUint32(potentialXValue, 2)
-		for i, elem := range s256.Sum(nil) {
-			potentialXValue[i+1] = elem
-		}
-
-		gen2, err := btcec.ParsePubKey(potentialXValue, btcec.S256())
-		if err == nil {
-			if confirmed == 2*n { // once we've generated all g and h values then assign this to u
-				u = ECPoint{gen2.X, gen2.Y}
-				//fmt.Println("Got that U value")
-			} else if confirmed == 2*n+1 {
-				cg = ECPoint{gen2.X, gen2.Y}
-
-			} else if confirmed == 2*n+2 {
-				ch = ECPoint{gen2.X, gen2.Y}
-			} else {
-				if confirmed%2 == 0 {
-					gen1Vals[confirmed/2] = ECPoint{gen2.X, gen2.Y}
-					//fmt.Println("new G Value")
-				} else {
-					gen2Vals[confirmed/2] = ECPoint{gen2.X, gen2.Y}
-					//fmt.Println("new H value")
-				}
-			}
-			confirmed += 1
-		}
-		j += 1
-	}
-
-	return CryptoParams{
-		btcec.S256(),
-		btcec.S256(),
-		gen1Vals,
-		gen2Vals,
-		btcec.S256().N,
-		u,
-		n,
-		cg,
-		ch}
-}
-
-func init() {
-	EC = NewECPrimeGroupKey(VecLength)
-	//fmt.Println(EC)
-}
@@ -1,56 +0,0 @@
-package ElGamal
-
-import (
-	"math/rand"
-	"time"
-)
-
-// 每个元素都是64位
-var primeNumberList = [...]string{
-	"39061f1c854fae629b599d29cefe1f12bc4809aa681809bfaaeb1b7087be6fed",
-	"31a913915070a5c47bd39ea10d384070a9bd38d313cdb4da14e543ef7d42651d",
-	"3d6ec8dd6b52f4536f2286f0d607fdef6e8228a6c7641cab8b9040a982aeffe3",
-	"395dc3e5187baf19ada68f2027a3fdf229242f0e7766e32609634cc727f0d1e5",
-	"2d36cccb05b2a9641818fadde3ed5ba5b65837a7170e105ac8fb454cc0bfde69",
-	"352f4bd674c2ff05ec807a74e07ad2f2e988470463fe95ed6b101c5a84a3fca3",
-	"2ca5931dce4eafe4a3de6f407a0ab2c969ee73d254cf9e73a1353864dd9f0ddf",
-	"35631e389da36d4907bd2d0a581596873cda7e0dc4ae8e13114ce524c9190c73",
-	"24d0f560fe274e5eb1347e75617af6c2d07d5fe9bb00d99fb4e762a5be354049",
-	"32dc3a13e86eded11e481da6c95feea5f510f9eb5a6c997c74549fccd15f74a7",
-	"2691cfb23b0f891599e7c1f5059068b89b741d609216387fef82a39e50f8b991",
-	"236c374b9eb44dc2f3ba5c7e80189d7323aae57e26b5b17015a1039bbb2899c1",
-	"2afdff0594b58873487366464edcc58fed1998f270f99a6c05e251a16c7ed11b",
-	"3f718a2820c1b6159af16c985328d09761ef3d8e5f4170ea3b8ef92c64f71309",
-	"39f572b5715cf43f0099f37ef18eee33eae22602c584d69462e76a32ec38bc63",
-	"2af40d948226fee44a367149d315c196bb8f83184643c6f88d0ceb2472f13459",
-	"328c3da1f822959a0d04a411b3494a2262ff0aacbbc30e3754d0a64d42fb1a05",
-	"3cb4fc0a722ff740741e69027a7836c319a0613ea00818129ca31c37e20a9c61",
-	"32a8ebe1fd65778d4e9972dbcd61aedf6bb51b73b8c0d3eae90a6ce39010a505",
-	"22a2c7dd0be56986e0dda1c43b24f745e9c82dd5b1d260526b10eb0d925da55d",
-	"23679e3afb5bf075c11fb83a66a1bab5cd88b56ca0de6699f48db812bf07d519",
-	"3e5f093edd9ca9014b69c8da782bf8a58a6842ad3e74aa0f4249cfad7059fa33",
-	"23d5ef98bb6c5eef987ed5857505f64d15bd33b722fd1702efcfeab8e8b9afb3",
-	"342d5711728783882114b4b0ebd4027385ca4c5137a68a719a5e5e699608a611",
-	"3ea83f8d7bca3cd72432328f9506a6935ca6812809d3a2ca8e74fdf67a5ffda5",
-	"2222550bc74e7116a2ec6c245039c99081df069e9e235a4157fd0f1656ded827",
-	"222293d1bad8e56c2557deab0428323eea2363cb026d66eab87333c3043d7aff",
-	"22228ef3f266753f3a80e0e8ce5a7eaddf3fa5de4b388e631aebfdc85a062fd7",
-	"2222f8b2105fbac9983166516eaf4605cd0595fd24d1bfbb95aa1842652677ad",
-	"222236512fc2f4b0c032ccb27ac97045da706f05e5cb16e1ab3e18607f239297",
-	"3333d0c7ea9f35dd30b476740c2660b8fb6c2a659f4cf85abaa71ea797f5848f",
-	"3333e7b86c24aca13630203bf8078e58eb5f8a8038baf7bd4d46d34f818cbc3b",
-	"222202ba2b9bba45079b5eb2cb8d7c5032b665986f18de2de700933162e8c389",
-	"22229e965a115cc2acb435ac947b9d62f36460b02fba1dc0369244db6e7110cd",
-	"2222fe9ff8eac6b54539f2d42290fd242f4dd855f02607754dff15a16d3ec445",
-	"3333b0a41ccbfe6b242c2507a83f3df5dff875d5500e4f3b9532475c1310cc2d",
-	"333338d52b05df563667c6c87821f1fb54571e83925617a23f2541254e512c43",
-	"33333c92c974ab23cd8f63c5691c1f6e3337299e7e322e1a18920d7c757bc401",
-	"33333f914834ced561c145797d9b5782719dbd1b43a668d4b01151f9c0e67d9f",
-}
-
-func select_prime() string {
-	// 初始化随机数
-	rand.Seed(time.Now().UnixNano())
-	// 随机返回一个值
-	return primeNumberList[rand.Intn(len(primeNumberList))]
-}
@@ -2,9 +2,11 @@ package utils
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
-	ecc "regulator/utils/ECC"
+	"maskchain/privacy/ecc"
+	"math/big"
 )
 
 func Hash(str string) string {
@@ -21,3 +23,31 @@ func Hash(str string) string {
 func GenElgKeys(passphrase string) (pub ecc.PublicKey, priv ecc.PrivateKey, err error) {
 	return ecc.GenerateKeys(passphrase)
 }
+
+// ParsePubkey 解析用户公钥编码P || G1 || G2 || H，与节点交易中Spk、Rpk的格式一致
+func ParsePubkey(pk string) (ecc.PublicKey, bool) {
+	if len(pk) < 323 {
+		return ecc.PublicKey{}, false
+	}
+	P, ok1 := new(big.Int).SetString(pk[:64], 16)
+	G1, ok2 := new(big.Int).SetString(pk[64:193], 16)
+	G2, ok3 := new(big.Int).SetString(pk[193:322], 16)
+	H, ok4 := new(big.Int).SetString(pk[322:], 16)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return ecc.PublicKey{}, false
+	}
+	return ecc.PublicKey{P: P, G1: G1, G2: G2, H: H}, true
+}
+
+// AddressPoint 计算用户地址公钥在交易密文ErpkC、EspkC中解密后的点。
+// 节点以sha256(pk) mod P作为地址公钥，并加密其前8字节
+func AddressPoint(regulator ecc.PublicKey, pk string) []byte {
+	h := sha256.Sum256([]byte(pk))
+	addr := new(big.Int).SetBytes(h[:])
+	b := addr.Mod(addr, regulator.P).Bytes()
+	if len(b) < 8 {
+		return nil
+	}
+	m := new(big.Int).SetUint64(binary.BigEndian.Uint64(b))
+	return ecc.MarshalPoint(ecc.ValuePoint(regulator, m))
+}
@@ -35,6 +35,30 @@ var (
 		Usage: "Redis password",
 		Value: "",
 	}
+	NodeURLFlag = cli.StringFlag{
+		Name:  "node",
+		Usage: "RPC address of the chain node to audit",
+		Value: "http://localhost:8545",
+	}
+	AuditTokenFlag = cli.StringFlag{
+		Name:  "audittoken",
+		Usage: "Bearer token required by the audit interface, which is disabled if empty",
+		Value: "",
+	}
+	IndexFlag = cli.BoolFlag{
+		Name:  "index",
+		Usage: "Continuously decrypt new blocks into the identity ledger",
+	}
+	DLogDirFlag = cli.StringFlag{
+		Name:  "dlogdir",
+		Usage: "Directory of the precomputed amount decryption tables, kept in memory only if empty",
+		Value: "dlog",
+	}
+	DLogBudgetFlag = cli.IntFlag{
+		Name:  "dlogbudget",
+		Usage: "Memory budget of an amount decryption table in MB",
+		Value: 64,
+	}
 	PassPhraseFlag = cli.StringFlag{
 		Name:  "passphrase, ph",
 		Usage: "Used to generate public and private key",
@@ -0,0 +1,115 @@
+package ecc
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// The accumulator is an append-only Merkle tree of fixed depth over all
+// commitments created on chain, in the order of their creation, like the note
+// commitment tree of Zerocash. Its root, the anchor, commits to the exact
+// sequence of leaves at some point of the chain. A shielded spend proves with
+// a MembershipProof that it spends one of the RingSize leaves of a subtree of
+// the tree below a given anchor, instead of naming the spent commitment. Each
+// leaf commits to a commitment together with its one-time spend key.
+//
+// Only the branch of the left siblings on the path of the next leaf is kept,
+// so appending a leaf and computing the root take AccumulatorDepth hashes.
+
+const (
+	// AccumulatorDepth is the depth of the accumulator, which holds up to
+	// 2^AccumulatorDepth commitments.
+	AccumulatorDepth = 32
+
+	// RingDepth is the height of the subtrees whose leaves form the rings of
+	// shielded spends, i.e. the anonymity set of a spend has RingSize members.
+	RingDepth = 5
+	RingSize  = 1 << RingDepth
+)
+
+// ErrAccumulatorFull is returned when appending to a full accumulator.
+var ErrAccumulatorFull = errors.New("commitment accumulator full")
+
+// emptyRoots[h] is the root of an empty subtree of height h.
+var emptyRoots = func() (roots [AccumulatorDepth + 1][32]byte) {
+	for h := 1; h <= AccumulatorDepth; h++ {
+		roots[h] = nodeHash(roots[h-1], roots[h-1])
+	}
+	return roots
+}()
+
+// LeafHash returns the leaf of a commitment and its spend key in their
+// transaction encoding. The leaves of empty positions are zero, which no
+// commitment hashes to.
+func LeafHash(cm, key []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(cm)
+	h.Write(key)
+	var leaf [32]byte
+	copy(leaf[:], h.Sum(nil))
+	return leaf
+}
+
+func nodeHash(left, right [32]byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left[:])
+	h.Write(right[:])
+	var node [32]byte
+	copy(node[:], h.Sum(nil))
+	return node
+}
+
+// Accumulator is the state of the commitment accumulator: the number of
+// leaves and, for every height h whose bit is set in Size, the root of the
+// complete left subtree of height h on the path of the next leaf.
+type Accumulator struct {
+	Size   uint64
+	Branch [AccumulatorDepth][32]byte
+}
+
+// Append appends the commitment cm with the spend key key as the next leaf.
+func (a *Accumulator) Append(cm, key []byte) error {
+	if a.Size >= 1<<AccumulatorDepth {
+		return ErrAccumulatorFull
+	}
+	a.Size++
+	node := LeafHash(cm, key)
+	for h, size := 0, a.Size; h < AccumulatorDepth; h, size = h+1, size>>1 {
+		if size&1 == 1 {
+			a.Branch[h] = node
+			return nil
+		}
+		node = nodeHash(a.Branch[h], node)
+	}
+	return nil
+}
+
+// Root returns the anchor of the accumulator, i.e. the root of the tree whose
+// leaves after the first Size are empty.
+func (a *Accumulator) Root() [32]byte {
+	node := emptyRoots[0]
+	for h, size := 0, a.Size; h < AccumulatorDepth; h, size = h+1, size>>1 {
+		if size&1 == 1 {
+			node = nodeHash(a.Branch[h], node)
+		} else {
+			node = nodeHash(node, emptyRoots[h])
+		}
+	}
+	return node
+}
+
+// RingBounds returns the leaves [from, to) of ring number ring in an
+// accumulator of size leaves. The positions of the ring from to on are
+// empty. ok is false if the ring has no leaf yet.
+func RingBounds(ring, size uint64) (from, to uint64, ok bool) {
+	if ring >= size>>RingDepth+1 || ring<<RingDepth >= size {
+		return 0, 0, false
+	}
+	from, to = ring<<RingDepth, (ring+1)<<RingDepth
+	if to > size {
+		to = size
+	}
+	return from, to, true
+}
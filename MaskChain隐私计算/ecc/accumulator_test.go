@@ -0,0 +1,77 @@
+package ecc
+
+import (
+	"fmt"
+	"testing"
+)
+
+// naiveRoot computes the root of the accumulator over leaves from all leaves.
+func naiveRoot(leaves [][32]byte) [32]byte {
+	level := append([][32]byte{}, leaves...)
+	for h := 0; h < AccumulatorDepth; h++ {
+		if len(level)%2 == 1 {
+			level = append(level, emptyRoots[h])
+		}
+		next := make([][32]byte, 0, len(level)/2+1)
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, nodeHash(level[i], level[i+1]))
+		}
+		if len(next) == 0 {
+			next = append(next, emptyRoots[h+1])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func TestAccumulator(t *testing.T) {
+	var (
+		acc    Accumulator
+		leaves [][32]byte
+	)
+	if acc.Root() != emptyRoots[AccumulatorDepth] {
+		t.Fatalf("root of the empty accumulator mismatch")
+	}
+	roots := make(map[[32]byte]bool)
+	for i := 0; i < 70; i++ {
+		cm, key := []byte(fmt.Sprintf("commitment %d", i)), []byte(fmt.Sprintf("key %d", i))
+		if err := acc.Append(cm, key); err != nil {
+			t.Fatal(err)
+		}
+		leaves = append(leaves, LeafHash(cm, key))
+		root := acc.Root()
+		if root != naiveRoot(leaves) {
+			t.Fatalf("root mismatch after %d leaves", i+1)
+		}
+		if roots[root] {
+			t.Fatalf("root repeated after %d leaves", i+1)
+		}
+		roots[root] = true
+	}
+	full := Accumulator{Size: 1 << AccumulatorDepth}
+	if err := full.Append(nil, nil); err != ErrAccumulatorFull {
+		t.Errorf("append to full accumulator: have %v", err)
+	}
+}
+
+func TestRingBounds(t *testing.T) {
+	tests := []struct {
+		ring, size uint64
+		from, to   uint64
+		ok         bool
+	}{
+		{0, 0, 0, 0, false},
+		{0, 1, 0, 1, true},
+		{0, RingSize, 0, RingSize, true},
+		{1, RingSize, 0, 0, false},
+		{1, RingSize + 3, RingSize, RingSize + 3, true},
+		{2, RingSize + 3, 0, 0, false},
+		{1 << 60, 10, 0, 0, false},
+	}
+	for _, tt := range tests {
+		from, to, ok := RingBounds(tt.ring, tt.size)
+		if from != tt.from || to != tt.to || ok != tt.ok {
+			t.Errorf("RingBounds(%d, %d) = %d, %d, %v, want %d, %d, %v", tt.ring, tt.size, from, to, ok, tt.from, tt.to, tt.ok)
+		}
+	}
+}
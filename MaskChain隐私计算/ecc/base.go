@@ -1,4 +1,4 @@
-package bp
+package ecc
 
 import (
 	"crypto/elliptic"
@@ -45,7 +45,6 @@ type Account struct {
 
 func GenerateAccount(randString string, name string, id string, extInfo string) Account {
 	pub, priv, _ := GenerateKeys(randString)
-	fmt.Println("生成账户"+name, "私钥：", priv.X.String())
 	return Account{
 		Pub:  pub,
 		Priv: priv,
@@ -79,6 +78,7 @@ func Decrypt(priv PrivateKey, C CypherText) (M []byte) {
 	return pr.Decrypt(Enc{ECPoint{x1,y1},ECPoint{x2, y2}})
 }
 
+// GenerateKeys 生成一对新的随机密钥，info不参与私钥的生成
 func GenerateKeys(info string) (pub PublicKey, priv PrivateKey, err error) {
 	prv := GenKeys(info)
 	pubb := RecoverPub(prv.PubKey)
@@ -88,7 +88,7 @@ func GenerateKeys(info string) (pub PublicKey, priv PrivateKey, err error) {
 
 func (pub PublicKey) Commit(v *big.Int, rnd []byte) Commitment{
 	pub1 := ConvertPub(pub)
-	com := pub1.G1.Mult(v).Add(pub1.H.Mult(new(big.Int).SetBytes(rnd)))
+	com := SecretMultiScalarMult([]ECPoint{pub1.G1, pub1.H}, []*big.Int{v, new(big.Int).SetBytes(rnd)})
 	com1 := elliptic.Marshal(EC.C, com.X, com.Y)
 	return Commitment{com1,rnd}
 }
@@ -96,7 +96,7 @@ func (pub PublicKey) Commit(v *big.Int, rnd []byte) Commitment{
 func (pub PublicKey) CommitByBytes(b []byte, rnd []byte) Commitment {
 	pub1 := ConvertPub(pub)
 	v := new(big.Int).SetBytes(b)
-	com := pub1.G1.Mult(v).Add(pub1.H.Mult(new(big.Int).SetBytes(rnd)))
+	com := SecretMultiScalarMult([]ECPoint{pub1.G1, pub1.H}, []*big.Int{v, new(big.Int).SetBytes(rnd)})
 	com1 := elliptic.Marshal(EC.C, com.X, com.Y)
 	return Commitment{com1,rnd}
 }
@@ -152,15 +152,6 @@ func EncryptValue(pub PublicKey, M uint64) (C CypherText, commit Commitment, err
 	return CypherText{c1,c2},Commitment{com1, r.Bytes()},nil
 }
 
-func DecryptValue(priv PrivateKey, C CypherText) (v uint64) {
-	privkey := ConvertPriv(priv)
-	x1, y1 := elliptic.Unmarshal(EC.C, C.C1)
-	x2, y2 := elliptic.Unmarshal(EC.C, C.C2)
-	enc := Enc{ECPoint{x1,y1},ECPoint{x2,y2}}
-	return privkey.DecryptCM(enc)
-}
-
-
 func EncryptAddress(pub PublicKey, addr []byte) (C CypherText, commit Commitment, err error){
 	addr_uint64 := binary.BigEndian.Uint64(addr)
 	return EncryptValue(pub, addr_uint64)
@@ -192,11 +183,12 @@ func RecoverPub(pub PubKey) PublicKey {
 	return re
 }
 
+// KeyToString 返回账户密钥的十六进制字符串，点为65字节的非压缩编码，P和私钥为32字节
 func (account Account) KeyToString() (privStr PrivStr) {
-	privStr.G1 = fmt.Sprintf("%0*x", 64, account.Pub.G1)
-	privStr.G2 = fmt.Sprintf("%0*x", 64, account.Pub.G2)
+	privStr.G1 = fmt.Sprintf("%0*x", 130, account.Pub.G1)
+	privStr.G2 = fmt.Sprintf("%0*x", 130, account.Pub.G2)
 	privStr.P = fmt.Sprintf("%0*x", 64, account.Pub.P)
-	privStr.Publickey = fmt.Sprintf("%0*x", 64, account.Pub.H)
+	privStr.Publickey = fmt.Sprintf("%0*x", 130, account.Pub.H)
 	privStr.Privatekey = fmt.Sprintf("%0*x", 64, account.Priv.X)
 	return
 }
\ No newline at end of file
@@ -0,0 +1,177 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// batchWeightBits is the bit length of the random weights of the batched
+// equations. A batch holding an invalid proof passes with probability 2^-128.
+const batchWeightBits = 128
+
+// BatchVerifier verifies many format, equality and balance proofs at once.
+//
+// Every proof is checked up to its group equations right away: the challenge
+// is recomputed from the transcript and the scalar relations are checked. The
+// group equations sum(s_i*P_i) = 0 are only collected, each multiplied by a
+// random weight, and Verify checks their sum with a single multi-scalar
+// multiplication. If Verify fails, at least one of the proofs is invalid and
+// the caller has to verify them one by one to find it.
+//
+// A nil *BatchVerifier verifies every proof right away, so callers can share
+// one code path between batched and single verification.
+type BatchVerifier struct {
+	points  []ECPoint
+	scalars []*big.Int
+}
+
+// NewBatchVerifier returns an empty batch.
+func NewBatchVerifier() *BatchVerifier {
+	return new(BatchVerifier)
+}
+
+// Len returns the number of points of the batched equations.
+func (b *BatchVerifier) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.points)
+}
+
+// Verify checks all equations collected so far. A nil or empty batch holds.
+func (b *BatchVerifier) Verify() bool {
+	if b == nil || len(b.points) == 0 {
+		return true
+	}
+	sum := MultiScalarMult(b.points, b.scalars)
+	return sum.X.Sign() == 0 && sum.Y.Sign() == 0
+}
+
+// VerifyFormatProof is the batched VerifyFormatProof. Like the latter it
+// returns an error if a point cannot be decoded.
+func (b *BatchVerifier) VerifyFormatProof(t *Transcript, Ct CypherText, fp FormatProof) (bool, error) {
+	if b == nil {
+		return VerifyFormatProof(t, Ct, fp)
+	}
+	if _, err := decodeField("C1", Ct.C1); err != nil {
+		return false, err
+	}
+	if _, err := decodeField("C2", Ct.C2); err != nil {
+		return false, err
+	}
+	ep, err := decodeEP(fp)
+	if err != nil {
+		return false, err
+	}
+	return b.addEP(formatTranscript(t, Ct), ep), nil
+}
+
+// VerifyEqualityProof is the batched VerifyEqualityProof.
+func (b *BatchVerifier) VerifyEqualityProof(t *Transcript, ep EqualityProof) (bool, error) {
+	if b == nil {
+		return VerifyEqualityProof(t, ep)
+	}
+	proof, err := decodeEP(ep.FormatProof)
+	if err != nil {
+		return false, err
+	}
+	return b.addEP(t.Fork("EqualityProof"), proof), nil
+}
+
+// VerifyBalanceProof is the batched VerifyBalanceProof.
+func (b *BatchVerifier) VerifyBalanceProof(t *Transcript, CM_r, CM_s, CM_o []byte, bp BalanceProof) (bool, error) {
+	if b == nil {
+		return VerifyBalanceProof(t, CM_r, CM_s, CM_o, bp)
+	}
+	var (
+		gn  = make([]ECPoint, 3)
+		err error
+	)
+	for i, cm := range []struct {
+		field string
+		enc   []byte
+	}{{"CM_o", CM_o}, {"CM_s", CM_s}, {"CM_r", CM_r}} {
+		if gn[i], err = decodeField(cm.field, cm.enc); err != nil {
+			return false, err
+		}
+	}
+	lep, err := decodeLEP(bp.Y, bp.T, [][]byte{bp.Sn_1, bp.Sn_2, bp.Sn_3}, bp.C)
+	if err != nil {
+		return false, err
+	}
+	an := []*big.Int{big.NewInt(-1), big.NewInt(1), big.NewInt(1)}
+	return b.addLEP(t.Fork("BalanceProof"), lep, gn, an), nil
+}
+
+// VerifyMultiBalanceProof is the batched VerifyMultiBalanceProof.
+func (b *BatchVerifier) VerifyMultiBalanceProof(t *Transcript, cmIn, cmOut [][]byte, bp MultiBalanceProof) (bool, error) {
+	if b == nil {
+		return VerifyMultiBalanceProof(t, cmIn, cmOut, bp)
+	}
+	if len(cmIn) == 0 || len(cmOut) == 0 || len(bp.Sn) != len(cmIn)+len(cmOut) {
+		return false, &FieldError{"Sn", errBalanceProofLength}
+	}
+	gn, an, err := balancePoints(cmIn, cmOut)
+	if err != nil {
+		return false, err
+	}
+	lep, err := decodeLEP(bp.Y, bp.T, bp.Sn, bp.C)
+	if err != nil {
+		return false, err
+	}
+	return b.addLEP(t.Fork("BalanceProof"), lep, gn, an), nil
+}
+
+// addEP checks the challenge of an equality proof and collects its equations
+// G1*s + Y1*c - T1 = 0 and G2*s + Y2*c - T2 = 0, see EPVerify.
+func (b *BatchVerifier) addEP(t *Transcript, ep EP) bool {
+	c := epChallenge(t, ep)
+	if c != ep.C {
+		return false
+	}
+	intc := new(big.Int).SetBytes(c[:])
+	s := new(big.Int).Sub(EC.N, ep.S)
+	b.add([]ECPoint{ep.G1, ep.Y1, ep.T1}, []*big.Int{s, intc, big.NewInt(-1)})
+	b.add([]ECPoint{ep.G2, ep.Y2, ep.T2}, []*big.Int{s, intc, big.NewInt(-1)})
+	return true
+}
+
+// addLEP checks the challenge and the coefficient relation of a linear
+// equation proof and collects its equation Y*c + sum(Gn[i]*sn[i]) - T = 0,
+// see LepVerify_txn.
+func (b *BatchVerifier) addLEP(tr *Transcript, lep LEP_tx, gn []ECPoint, an []*big.Int) bool {
+	n := len(gn)
+	if n == 0 || len(lep.Sn) != n || len(an) != n {
+		return false
+	}
+	c := lepChallenge(tr, gn, lep.Y, lep.T)
+	if c != lep.C {
+		return false
+	}
+	sn := make([]*big.Int, n)
+	aisi := new(big.Int)
+	for i := range sn {
+		sn[i] = new(big.Int).Sub(EC.N, lep.Sn[i])
+		aisi.Add(aisi, new(big.Int).Mul(an[i], sn[i]))
+	}
+	if aisi.Mod(aisi, EC.N).Sign() != 0 {
+		return false
+	}
+	points := append([]ECPoint{lep.Y, lep.T}, gn...)
+	scalars := append([]*big.Int{new(big.Int).SetBytes(c[:]), big.NewInt(-1)}, sn...)
+	b.add(points, scalars)
+	return true
+}
+
+// add collects the equation sum(scalars[i]*points[i]) = 0 with a fresh random
+// weight.
+func (b *BatchVerifier) add(points []ECPoint, scalars []*big.Int) {
+	w, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), batchWeightBits))
+	check(err)
+	w.Add(w, big.NewInt(1))
+	for i, p := range points {
+		s := new(big.Int).Mul(scalars[i], w)
+		b.points = append(b.points, p)
+		b.scalars = append(b.scalars, s.Mod(s, EC.N))
+	}
+}
@@ -0,0 +1,143 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMultiScalarMult(t *testing.T) {
+	// Both Straus' and Pippenger's method, with repeated points, zero and
+	// negative scalars
+	for _, n := range []int{1, 2, 5, strausThreshold - 1, strausThreshold, 150} {
+		points := make([]ECPoint, n)
+		scalars := RandVector(n)
+		want := EC.Zero()
+		for i := range points {
+			points[i] = EC.BPG[i%8]
+			switch i % 5 {
+			case 3:
+				scalars[i] = new(big.Int)
+			case 4:
+				scalars[i].Neg(scalars[i])
+			}
+			want = want.Add(points[i].Mult(scalars[i]))
+		}
+		if have := MultiScalarMult(points, scalars); have.X.Cmp(want.X) != 0 || have.Y.Cmp(want.Y) != 0 {
+			t.Errorf("%d points: have %v, want %v", n, have, want)
+		}
+	}
+	// Points summing up to the point at infinity
+	g := EC.BPG[0]
+	if sum := MultiScalarMult([]ECPoint{g, g}, []*big.Int{big.NewInt(1), big.NewInt(-1)}); sum.X.Sign() != 0 || sum.Y.Sign() != 0 {
+		t.Errorf("p - p not at infinity: %v", sum)
+	}
+}
+
+// batchProofs holds one proof of each kind, generated against one transcript.
+type batchProofs struct {
+	ct  CypherText
+	fp  FormatProof
+	ep  EqualityProof
+	bp  BalanceProof
+	mbp MultiBalanceProof
+
+	cmr, cms, cmo []byte
+}
+
+func newBatchProofs(t testing.TB, tr *Transcript) *batchProofs {
+	pub, _, _ := GenerateKeys("regulator")
+	ct, cms, _ := EncryptValue(pub, 7)
+	_, cmr, _ := EncryptValue(pub, 3)
+	_, cmo, _ := EncryptValue(pub, 10)
+	_, cmo2, _ := EncryptValue(pub, 10)
+
+	mbp, err := GenerateMultiBalanceProof(tr, []uint64{10}, []uint64{7, 3}, [][]byte{cmo.Commitment}, [][]byte{cms.Commitment, cmr.Commitment})
+	if err != nil {
+		t.Fatalf("failed to generate balance proof: %v", err)
+	}
+	return &batchProofs{
+		ct:  ct,
+		fp:  GenerateFormatProof(tr, pub, 7, cms.R, ct),
+		ep:  GenerateEqualityProof(tr, pub, pub, cmo, cmo2, 10),
+		bp:  GenerateBalanceProof(tr, 3, 7, 10, cmr.Commitment, cms.Commitment, cmo.Commitment),
+		mbp: mbp,
+		cmr: cmr.Commitment, cms: cms.Commitment, cmo: cmo.Commitment,
+	}
+}
+
+func (p *batchProofs) verify(b *BatchVerifier, tr *Transcript) bool {
+	return valid(b.VerifyFormatProof(tr, p.ct, p.fp)) &&
+		valid(b.VerifyEqualityProof(tr, p.ep)) &&
+		valid(b.VerifyBalanceProof(tr, p.cmr, p.cms, p.cmo, p.bp)) &&
+		valid(b.VerifyMultiBalanceProof(tr, [][]byte{p.cmo}, [][]byte{p.cms, p.cmr}, p.mbp))
+}
+
+func TestBatchVerifier(t *testing.T) {
+	var (
+		trs    []*Transcript
+		proofs []*batchProofs
+	)
+	for i := 0; i < 3; i++ {
+		tr := TxTranscript(big.NewInt(1), 0, []byte{byte(i)})
+		trs, proofs = append(trs, tr), append(proofs, newBatchProofs(t, tr))
+	}
+	b := NewBatchVerifier()
+	for i, p := range proofs {
+		if !p.verify(b, trs[i]) {
+			t.Fatalf("proofs %d rejected before the batch check", i)
+		}
+	}
+	if !b.Verify() {
+		t.Fatalf("valid batch rejected")
+	}
+	var single *BatchVerifier
+	if !proofs[0].verify(single, trs[0]) || !single.Verify() {
+		t.Fatalf("valid proofs rejected without batch")
+	}
+
+	// A response which still passes the scalar checks is only caught by the
+	// batched group equations
+	proofs[1].fp.S = new(big.Int).Add(new(big.Int).SetBytes(proofs[1].fp.S), big.NewInt(1)).Bytes()
+	b = NewBatchVerifier()
+	for i, p := range proofs {
+		if !p.verify(b, trs[i]) {
+			t.Fatalf("proofs %d rejected before the batch check", i)
+		}
+	}
+	if b.Verify() {
+		t.Fatalf("batch with a forged response accepted")
+	}
+	for i, p := range proofs {
+		if valid := p.verify(nil, trs[i]); valid != (i != 1) {
+			t.Errorf("proofs %d: single verification %v", i, valid)
+		}
+	}
+	// Proofs of another transaction fail the challenge check right away
+	if proofs[0].verify(NewBatchVerifier(), trs[2]) {
+		t.Errorf("proofs accepted in another transaction")
+	}
+}
+
+func BenchmarkBatchVerifier(b *testing.B) {
+	tr := TxTranscript(big.NewInt(1), 0)
+	var proofs []*batchProofs
+	for i := 0; i < 50; i++ {
+		proofs = append(proofs, newBatchProofs(b, tr))
+	}
+	b.Run("single", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, p := range proofs {
+				p.verify(nil, tr)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			batch := NewBatchVerifier()
+			for _, p := range proofs {
+				p.verify(batch, tr)
+			}
+			batch.Verify()
+		}
+	})
+}
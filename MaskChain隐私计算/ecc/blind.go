@@ -0,0 +1,134 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// Blinding factors are 256 bit scalars which the exponential ElGamal of Encrypt
+// cannot recover. They are therefore encrypted to their owner with hashed
+// ElGamal: C1 = k*G2 and C2 = (r XOR KDF(k*H)) || tag, where k*H = x*C1 can
+// only be computed by the owner of the key. The tag lets a wallet tell its own
+// ciphertexts apart when scanning the chain.
+//
+// The same shared point yields the one-time spend key K = H + t*G2 of the
+// commitment, with t = KDF'(k*H). The nullifier of a shielded spend depends on
+// the spend secret x + t of K rather than on r, so the creator of the
+// commitment, who knows r and t but not x, cannot spend it.
+
+const (
+	blindLen    = 32 // 随机数长度
+	blindTagLen = 8  // 归属标签长度
+)
+
+var (
+	errNotBlindOwner = errors.New("blinding factor not encrypted to this key")
+	errSpendKey      = errors.New("spend key not derived for this key")
+)
+
+// blindKeys derives the pad and the ownership tag of a blinding factor
+// ciphertext from the shared point.
+func blindKeys(c1 []byte, shared ECPoint) (pad, tag []byte) {
+	s := elliptic.Marshal(EC.C, shared.X, shared.Y)
+	p := sha256.Sum256(append([]byte("maskchain blind pad"), s...))
+	t := sha256.Sum256(append(append([]byte("maskchain blind tag"), s...), c1...))
+	return p[:], t[:blindTagLen]
+}
+
+// spendTweak derives the tweak t of the spend key of a commitment from the
+// shared point of its blinding factor ciphertext.
+func spendTweak(c1 []byte, shared ECPoint) *big.Int {
+	s := elliptic.Marshal(EC.C, shared.X, shared.Y)
+	t := sha256.Sum256(append(append([]byte("maskchain spend key"), s...), c1...))
+	return new(big.Int).Mod(new(big.Int).SetBytes(t[:]), EC.N)
+}
+
+// EncryptBlind encrypts a blinding factor to the owner of pub, so that it can
+// be recovered with DecryptBlind.
+func EncryptBlind(pub PublicKey, r []byte) (C CypherText, err error) {
+	C, _, err = EncryptBlindKey(pub, r)
+	return C, err
+}
+
+// EncryptBlindKey encrypts a blinding factor like EncryptBlind and returns the
+// one-time spend key of the commitment for the owner of pub, whose secret
+// SpendKey recovers.
+func EncryptBlindKey(pub PublicKey, r []byte) (C CypherText, key []byte, err error) {
+	if len(r) > blindLen {
+		return CypherText{}, nil, errors.New("blinding factor too long")
+	}
+	pubb := ConvertPub(pub)
+	k := RandScalar()
+	c1 := pubb.G2.MultSecret(k)
+	C.C1 = elliptic.Marshal(EC.C, c1.X, c1.Y)
+	shared := pubb.H.MultSecret(k)
+	pad, tag := blindKeys(C.C1, shared)
+	t := spendTweak(C.C1, shared)
+	_, g2 := StandardGenerators()
+	K := pubb.H.Add(g2.MultSecret(t))
+	ZeroizeInt(k, t)
+
+	m := make([]byte, blindLen)
+	copy(m[blindLen-len(r):], r)
+	for i := range m {
+		m[i] ^= pad[i]
+	}
+	C.C2 = append(m, tag...)
+	return C, elliptic.Marshal(EC.C, K.X, K.Y), nil
+}
+
+// DecryptBlind recovers a blinding factor encrypted with EncryptBlind. It
+// fails if the ciphertext was not encrypted to the key.
+func DecryptBlind(priv PrivateKey, C CypherText) ([]byte, error) {
+	if len(C.C2) != blindLen+blindTagLen || priv.X == nil {
+		return nil, errNotBlindOwner
+	}
+	c1, err := DecodePoint(C.C1)
+	if err != nil {
+		return nil, errNotBlindOwner
+	}
+	pad, tag := blindKeys(C.C1, c1.MultSecret(priv.X))
+	if !hmac.Equal(C.C2[blindLen:], tag) {
+		return nil, errNotBlindOwner
+	}
+	m := make([]byte, blindLen)
+	for i := range m {
+		m[i] = C.C2[i] ^ pad[i]
+	}
+	return new(big.Int).SetBytes(m).Bytes(), nil
+}
+
+// SpendKey recovers the secret of the one-time spend key of a commitment whose
+// blinding factor ciphertext C was created with EncryptBlindKey. It fails if
+// the ciphertext was not encrypted to the key or the spend key was not derived
+// from it.
+func SpendKey(priv PrivateKey, C CypherText, key []byte) ([]byte, error) {
+	if len(C.C2) != blindLen+blindTagLen || priv.X == nil {
+		return nil, errNotBlindOwner
+	}
+	c1, err := DecodePoint(C.C1)
+	if err != nil {
+		return nil, errNotBlindOwner
+	}
+	shared := c1.MultSecret(priv.X)
+	if _, tag := blindKeys(C.C1, shared); !hmac.Equal(C.C2[blindLen:], tag) {
+		return nil, errNotBlindOwner
+	}
+	sk := spendTweak(C.C1, shared)
+	sk.Add(sk, priv.X).Mod(sk, EC.N)
+	defer ZeroizeInt(sk)
+
+	_, g2 := StandardGenerators()
+	K, err := DecodePoint(key)
+	if err != nil || !g2.MultSecret(sk).Equal(K) {
+		return nil, errSpendKey
+	}
+	secret := make([]byte, blindLen)
+	b := sk.Bytes()
+	copy(secret[blindLen-len(b):], b)
+	Zeroize(b)
+	return secret, nil
+}
@@ -0,0 +1,74 @@
+package ecc
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestBlindEncryption(t *testing.T) {
+	pub, priv, _ := GenerateKeys("owner")
+	_, other, _ := GenerateKeys("other")
+
+	_, comm, _ := EncryptValue(pub, 42)
+	C, err := EncryptBlind(pub, comm.R)
+	if err != nil {
+		t.Fatalf("failed to encrypt blinding factor: %v", err)
+	}
+	r, err := DecryptBlind(priv, C)
+	if err != nil {
+		t.Fatalf("failed to decrypt blinding factor: %v", err)
+	}
+	if !bytes.Equal(r, comm.R) {
+		t.Fatalf("blinding factor mismatch: have %x, want %x", r, comm.R)
+	}
+	if _, err := DecryptBlind(other, C); err == nil {
+		t.Errorf("blinding factor decrypted with a foreign key")
+	}
+	// Value ciphertexts and tampered ciphertexts are not blinding factors
+	if _, err := DecryptBlind(priv, Encrypt(pub, comm.R)); err == nil {
+		t.Errorf("exponential ElGamal ciphertext accepted")
+	}
+	C.C2[0] ^= 0x01
+	if r, err := DecryptBlind(priv, C); err == nil && bytes.Equal(r, comm.R) {
+		t.Errorf("tampered ciphertext decrypted to the original blinding factor")
+	}
+	C.C2[len(C.C2)-1] ^= 0x01
+	if _, err := DecryptBlind(priv, C); err == nil {
+		t.Errorf("ciphertext with tampered tag accepted")
+	}
+}
+
+func TestSpendKey(t *testing.T) {
+	pub, priv, _ := GenerateKeys("owner")
+	_, other, _ := GenerateKeys("other")
+
+	_, comm, _ := EncryptValue(pub, 42)
+	C, key, err := EncryptBlindKey(pub, comm.R)
+	if err != nil {
+		t.Fatalf("failed to encrypt blinding factor: %v", err)
+	}
+	if r, err := DecryptBlind(priv, C); err != nil || !bytes.Equal(r, comm.R) {
+		t.Fatalf("blinding factor mismatch: have %x, %v", r, err)
+	}
+	sk, err := SpendKey(priv, C, key)
+	if err != nil {
+		t.Fatalf("failed to recover spend secret: %v", err)
+	}
+	_, g2 := StandardGenerators()
+	K, _ := DecodePoint(key)
+	if !g2.Mult(new(big.Int).SetBytes(sk)).Equal(K) {
+		t.Fatalf("spend secret does not open the spend key")
+	}
+	if _, err := SpendKey(other, C, key); err != errNotBlindOwner {
+		t.Errorf("spend secret recovered with a foreign key: %v", err)
+	}
+	// The spend key of another output of the same owner is not accepted
+	C2, key2, _ := EncryptBlindKey(pub, comm.R)
+	if bytes.Equal(key, key2) {
+		t.Errorf("spend keys of two outputs collide")
+	}
+	if _, err := SpendKey(priv, C2, key); err != errSpendKey {
+		t.Errorf("mismatching spend key: have %v, want %v", err, errSpendKey)
+	}
+}
@@ -0,0 +1,166 @@
+package ecc
+
+import (
+	"errors"
+	"math/big"
+)
+
+// A cipher equality proof shows that two ciphertexts under different keys,
+// e.g. the one of the regulator and the one of the exchange, encrypt the same
+// value, without revealing it. For C = (v*G1 + r*H, r*G2) under the first key
+// and C' = (v*G1' + r'*H', r'*G2') under the second one it is the sigma proof
+// of knowledge of v, r and r' with the commitments
+//
+//	A1 = a*G1 + b*H, A2 = b*G2, A3 = a*G1' + b'*H', A4 = b'*G2'
+//
+// and the responses Zv = a + e*v, Zr = b + e*r and Zr' = b' + e*r'. Unlike the
+// equality proof, the statement is made of the ciphertexts themselves, so the
+// proof cannot be moved to other ciphertexts.
+
+var errCipherEqualityKey = errors.New("public key without generators")
+
+// CipherEqualityProof is the proof that two ciphertexts encrypt the same value.
+type CipherEqualityProof struct {
+	A1, A2, A3, A4 ECPoint
+	Zv, Zr1, Zr2   *big.Int
+}
+
+// cipherEqualityStatement decodes the keys and ciphertexts of a cipher
+// equality proof.
+type cipherEqualityStatement struct {
+	k1, k2 PubKey
+	c1, c2 [2]ECPoint
+}
+
+func newCipherEqualityStatement(pub1 PublicKey, ct1 CypherText, pub2 PublicKey, ct2 CypherText) (*cipherEqualityStatement, error) {
+	for _, k := range []PublicKey{pub1, pub2} {
+		if k.G1 == nil || k.G2 == nil || k.H == nil {
+			return nil, errCipherEqualityKey
+		}
+	}
+	st := &cipherEqualityStatement{k1: ConvertPub(pub1), k2: ConvertPub(pub2)}
+	for _, k := range []PubKey{st.k1, st.k2} {
+		if k.G1.X == nil || k.G2.X == nil || k.H.X == nil {
+			return nil, errCipherEqualityKey
+		}
+	}
+	var err error
+	for _, p := range []struct {
+		field string
+		dst   *ECPoint
+		enc   []byte
+	}{{"C1", &st.c1[0], ct1.C1}, {"C2", &st.c1[1], ct1.C2}, {"C1'", &st.c2[0], ct2.C1}, {"C2'", &st.c2[1], ct2.C2}} {
+		if *p.dst, err = decodeField(p.field, p.enc); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// challenge forks the transcript of the proof, binds it to the keys, the
+// ciphertexts and the commitments of the proof and squeezes the challenge e.
+// A nil transcript starts a new one, there is no version 1 of the proof.
+func (st *cipherEqualityStatement) challenge(t *Transcript, cp *CipherEqualityProof) *big.Int {
+	if t = t.Fork("CipherEqualityProof"); t == nil {
+		t = NewTranscript("CipherEqualityProof")
+	}
+	t.AppendPoints("K1", []ECPoint{st.k1.G1, st.k1.G2, st.k1.H})
+	t.AppendPoints("K2", []ECPoint{st.k2.G1, st.k2.G2, st.k2.H})
+	t.AppendPoints("C1", st.c1[:])
+	t.AppendPoints("C2", st.c2[:])
+	t.AppendPoints("A", []ECPoint{cp.A1, cp.A2, cp.A3, cp.A4})
+	return t.Challenge("e")
+}
+
+// GenerateCipherEqualityProof proves that ct1, encrypted under pub1 with the
+// blinding factor r1, and ct2, encrypted under pub2 with r2, both encrypt v.
+func GenerateCipherEqualityProof(t *Transcript, pub1 PublicKey, ct1 CypherText, r1 []byte, pub2 PublicKey, ct2 CypherText, r2 []byte, v uint64) (cp CipherEqualityProof, err error) {
+	st, err := newCipherEqualityStatement(pub1, ct1, pub2, ct2)
+	if err != nil {
+		return CipherEqualityProof{}, err
+	}
+	var (
+		a, b1, b2 = RandScalar(), RandScalar(), RandScalar()
+		vv        = new(big.Int).SetUint64(v)
+		rr1       = new(big.Int).Mod(new(big.Int).SetBytes(r1), EC.N)
+		rr2       = new(big.Int).Mod(new(big.Int).SetBytes(r2), EC.N)
+	)
+	cp.A1 = SecretMultiScalarMult([]ECPoint{st.k1.G1, st.k1.H}, []*big.Int{a, b1})
+	cp.A2 = st.k1.G2.MultSecret(b1)
+	cp.A3 = SecretMultiScalarMult([]ECPoint{st.k2.G1, st.k2.H}, []*big.Int{a, b2})
+	cp.A4 = st.k2.G2.MultSecret(b2)
+	e := st.challenge(t, &cp)
+
+	response := func(mask, secret *big.Int) *big.Int {
+		z := new(big.Int).Mul(e, secret)
+		return z.Add(z, mask).Mod(z, EC.N)
+	}
+	cp.Zv, cp.Zr1, cp.Zr2 = response(a, vv), response(b1, rr1), response(b2, rr2)
+	ZeroizeInt(a, b1, b2, vv, rr1, rr2)
+	return cp, nil
+}
+
+// VerifyCipherEqualityProof verifies that ct1 under pub1 and ct2 under pub2
+// encrypt the same value. t must be the transcript the proof was generated
+// against. An error is returned if a point cannot be decoded, false without
+// error if the proof is invalid.
+func VerifyCipherEqualityProof(t *Transcript, pub1 PublicKey, ct1 CypherText, pub2 PublicKey, ct2 CypherText, cp CipherEqualityProof) (bool, error) {
+	b := NewBatchVerifier()
+	if ok, err := b.VerifyCipherEqualityProof(t, pub1, ct1, pub2, ct2, cp); !ok || err != nil {
+		return ok, err
+	}
+	return b.Verify(), nil
+}
+
+// VerifyCipherEqualityProof is the batched VerifyCipherEqualityProof.
+func (b *BatchVerifier) VerifyCipherEqualityProof(t *Transcript, pub1 PublicKey, ct1 CypherText, pub2 PublicKey, ct2 CypherText, cp CipherEqualityProof) (bool, error) {
+	if b == nil {
+		return VerifyCipherEqualityProof(t, pub1, ct1, pub2, ct2, cp)
+	}
+	st, err := newCipherEqualityStatement(pub1, ct1, pub2, ct2)
+	if err != nil {
+		return false, err
+	}
+	if cp.A1.X == nil || cp.A2.X == nil || cp.A3.X == nil || cp.A4.X == nil {
+		return false, &FieldError{"A", ErrInvalidPoint}
+	}
+	if cp.Zv == nil || cp.Zr1 == nil || cp.Zr2 == nil {
+		return false, nil
+	}
+	var (
+		e     = st.challenge(t, &cp)
+		ne    = new(big.Int).Neg(e)
+		minus = big.NewInt(-1)
+	)
+	// Zv*G1 + Zr*H = A1 + e*C1 and Zr*G2 = A2 + e*C2 for both keys
+	b.add([]ECPoint{st.k1.G1, st.k1.H, st.c1[0], cp.A1}, []*big.Int{cp.Zv, cp.Zr1, ne, minus})
+	b.add([]ECPoint{st.k1.G2, st.c1[1], cp.A2}, []*big.Int{cp.Zr1, ne, minus})
+	b.add([]ECPoint{st.k2.G1, st.k2.H, st.c2[0], cp.A3}, []*big.Int{cp.Zv, cp.Zr2, ne, minus})
+	b.add([]ECPoint{st.k2.G2, st.c2[1], cp.A4}, []*big.Int{cp.Zr2, ne, minus})
+	return true, nil
+}
+
+// MarshalBinary encodes the proof as A1 || A2 || A3 || A4 || Zv || Zr1 || Zr2.
+func (cp CipherEqualityProof) MarshalBinary() ([]byte, error) {
+	return marshal(KindCipherEqualityProof, func(e *encoder) {
+		for _, p := range []ECPoint{cp.A1, cp.A2, cp.A3, cp.A4} {
+			e.point(p)
+		}
+		for _, s := range []*big.Int{cp.Zv, cp.Zr1, cp.Zr2} {
+			e.scalar(s)
+		}
+	})
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (cp *CipherEqualityProof) UnmarshalBinary(data []byte) error {
+	var p CipherEqualityProof
+	if err := unmarshal(data, KindCipherEqualityProof, func(d *decoder) {
+		p.A1, p.A2, p.A3, p.A4 = d.point(), d.point(), d.point(), d.point()
+		p.Zv, p.Zr1, p.Zr2 = d.scalar(), d.scalar(), d.scalar()
+	}); err != nil {
+		return err
+	}
+	*cp = p
+	return nil
+}
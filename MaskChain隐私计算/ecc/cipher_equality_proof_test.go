@@ -0,0 +1,77 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCipherEqualityProof(t *testing.T) {
+	regulator, regulatorPriv, _ := GenerateKeys("regulator")
+	exchange, exchangePriv, _ := GenerateKeys("exchange")
+
+	const v = 1234
+	ct1, cm1, _ := EncryptValue(regulator, v)
+	ct2, cm2, _ := EncryptValue(exchange, v)
+	tr := TxTranscript(big.NewInt(1), 6, ct1.C1, ct2.C1)
+	cp, err := GenerateCipherEqualityProof(tr.Clone(), regulator, ct1, cm1.R, exchange, ct2, cm2.R, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid(VerifyCipherEqualityProof(tr.Clone(), regulator, ct1, exchange, ct2, cp)) {
+		t.Fatalf("cipher equality proof rejected")
+	}
+	// Both parties decrypt the same value
+	if have, err := ConvertPriv(regulatorPriv).DecryptCM(Enc{mustDecode(t, ct1.C1), mustDecode(t, ct1.C2)}); err != nil || have != v {
+		t.Errorf("regulator decrypted %d (%v), want %d", have, err, v)
+	}
+	if have, err := ConvertPriv(exchangePriv).DecryptCM(Enc{mustDecode(t, ct2.C1), mustDecode(t, ct2.C2)}); err != nil || have != v {
+		t.Errorf("exchange decrypted %d (%v), want %d", have, err, v)
+	}
+	// A ciphertext under another key does not decrypt to an amount
+	if _, err := ConvertPriv(exchangePriv).DecryptCM(Enc{mustDecode(t, ct1.C1), mustDecode(t, ct1.C2)}); err == nil {
+		t.Errorf("exchange decrypted the regulator ciphertext")
+	}
+
+	// Another transcript, key or ciphertext
+	if valid(VerifyCipherEqualityProof(TxTranscript(big.NewInt(2), 6, ct1.C1, ct2.C1), regulator, ct1, exchange, ct2, cp)) {
+		t.Errorf("proof of another transaction accepted")
+	}
+	if valid(VerifyCipherEqualityProof(tr.Clone(), regulator, ct1, regulator, ct2, cp)) {
+		t.Errorf("proof under another key accepted")
+	}
+	other, cm3, _ := EncryptValue(exchange, v+1)
+	if valid(VerifyCipherEqualityProof(tr.Clone(), regulator, ct1, exchange, other, cp)) {
+		t.Errorf("proof for another ciphertext accepted")
+	}
+	// A proof for different values does not hold
+	lying, err := GenerateCipherEqualityProof(tr.Clone(), regulator, ct1, cm1.R, exchange, other, cm3.R, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid(VerifyCipherEqualityProof(tr.Clone(), regulator, ct1, exchange, other, lying)) {
+		t.Errorf("proof for different values accepted")
+	}
+	if _, err := VerifyCipherEqualityProof(tr.Clone(), regulator, CypherText{C1: []byte{1}, C2: ct1.C2}, exchange, ct2, cp); err == nil {
+		t.Errorf("undecodable ciphertext accepted without error")
+	}
+
+	enc, err := cp.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec CipherEqualityProof
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if !valid(VerifyCipherEqualityProof(tr.Clone(), regulator, ct1, exchange, ct2, dec)) {
+		t.Errorf("decoded proof rejected")
+	}
+}
+
+func mustDecode(t *testing.T, b []byte) ECPoint {
+	p, err := DecodePoint(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
@@ -1,4 +1,4 @@
-package bp
+package ecc
 
 import (
 	"bytes"
@@ -25,20 +25,15 @@ type Enc struct {
 	P2 ECPoint
 }
 
+// GenKeys 生成一对新密钥。私钥X取自crypto/rand的随机数，生成元为StandardGenerators，
+// s仅为调用者的标识，不再作为私钥使用（此前X直接取s的字节，可被猜出）
 func GenKeys(s string) PrivKey {
 
-	x1 := []byte(s)
-	x := new(big.Int).SetBytes(x1[:])
+	x := RandScalar()
 	Key := PrivKey{}
-	v1, err := rand.Int(rand.Reader, EC.N)
-	check(err)
-	//v2, err := rand.Int(rand.Reader, EC.N)
-	//check(err)
-	Key.G1 = EC.G.Mult(v1)
-	Key.G2.X = EC.C.Params().Gx
-	Key.G2.Y = EC.C.Params().Gy
+	Key.G1, Key.G2 = StandardGenerators()
 	Key.X = x
-	Key.H = Key.G2.Mult(x)
+	Key.H = Key.G2.MultSecret(x)
 
 	return Key
 }
@@ -46,10 +41,9 @@ func GenKeys(s string) PrivKey {
 // genetate pederson commitment: v*g + r*h, return commitment and random value r
 func (pub PubKey) GenComm(v *big.Int) (ECPoint,*big.Int) {
 
-	r, err := rand.Int(rand.Reader, EC.N)
-	check(err)
+	r := RandScalar()
 
-	com := pub.G1.Mult(v).Add(pub.H.Mult(r))
+	com := SecretMultiScalarMult([]ECPoint{pub.G1, pub.H}, []*big.Int{v, r})
 
 	return com,r
 }
@@ -58,10 +52,9 @@ func (pub PubKey) GenComByBytes(b []byte) (ECPoint,*big.Int) {
 
 	v := new(big.Int).SetBytes(b[:])
 
-	r, err := rand.Int(rand.Reader, EC.N)
-	check(err)
+	r := RandScalar()
 
-	com := pub.G1.Mult(v).Add(pub.H.Mult(r))
+	com := SecretMultiScalarMult([]ECPoint{pub.G1, pub.H}, []*big.Int{v, r})
 
 	return com,r
 }
@@ -70,10 +63,11 @@ func (pub PubKey) Encrypt(plainText []byte) (*big.Int, Enc){
 
 	v := new(big.Int).SetBytes(plainText[:])
 
-	r, err := rand.Int(rand.Reader, big.NewInt(50000))
-	check(err)
-	t1 := pub.G1.Mult(v).Add(pub.H.Mult(r))
-	t2 := pub.G2.Mult(r)
+	// r须为完整的随机标量，取值过小时可由t2穷举出r，进而解出明文
+	r := RandScalar()
+	t1 := SecretMultiScalarMult([]ECPoint{pub.G1, pub.H}, []*big.Int{v, r})
+	t2 := pub.G2.MultSecret(r)
+	ZeroizeInt(r)
 
 	return  v, Enc{t1,t2}
 }
@@ -81,36 +75,33 @@ func (pub PubKey) Encrypt(plainText []byte) (*big.Int, Enc){
 
 // The private key and plaintext are passed in for decryption
 func (priv PrivKey) Decrypt(enc Enc)(msg []byte){
-	g1v := enc.P1.Add(enc.P2.Mult(priv.X).Neg())
-	v := big.NewInt(0)
-	for{
-		if priv.G1.Mult(v).Equal(g1v){
-			break
-		}
-		v = new(big.Int).Add(v,big.NewInt(1))
-		if v == big.NewInt(50000){
-			return []byte("解密了个寂寞")
-			break
-		}
+	g1v := enc.P1.Add(enc.P2.MultSecret(priv.X).Neg())
+	v, err := DiscreteLog(priv.G1, g1v)
+	if err != nil {
+		return nil
 	}
-	plainText := v.Bytes()
-	return plainText
+	return new(big.Int).SetUint64(v).Bytes()
 }
 
 // equal r for commitment and cyphertext, return commitment for plaitext, cyphertext, random r
 func (pub PubKey) EncryptCM(plainText []byte) (ECPoint, Enc, *big.Int){
 	v := new(big.Int).SetBytes(plainText[:])
 
-	r, err := rand.Int(rand.Reader, EC.N)
-	check(err)
-	t1 := pub.G1.Mult(v).Add(pub.H.Mult(r))
-	t2 := pub.G2.Mult(r)
+	r := RandScalar()
+	t1 := SecretMultiScalarMult([]ECPoint{pub.G1, pub.H}, []*big.Int{v, r})
+	t2 := pub.G2.MultSecret(r)
 
-	com := pub.G1.Mult(v).Add(pub.H.Mult(r))
+	com := t1
 
 	return  com, Enc{t1,t2}, r
 }
 
+// DecryptCM 解密金额密文，密文并非金额密文或金额超出范围时返回错误
+func (priv PrivKey) DecryptCM(cyperText Enc) (uint64, error) {
+	gv := cyperText.P1.Add(cyperText.P2.MultSecret(priv.X).Neg())
+	return DiscreteLog(priv.G1, gv)
+}
+
 func (priv PrivKey) Sign(msg []byte)([]byte, []byte, error, []byte){
 	Key := ecdsa.PublicKey{}
 	Key.X = priv.PubKey.H.X
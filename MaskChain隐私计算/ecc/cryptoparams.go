@@ -1,8 +1,10 @@
 /*
-Implementation of BulletProofs in Go
+Package ecc is the privacy cryptography shared by the MaskChain node, wallet,
+exchange and regulator: keys, Pedersen commitments, exponential ElGamal
+encryption, sigma proofs and BulletProofs over secp256k1.
 
 */
-package bp
+package ecc
 
 import (
 	"crypto/elliptic"
@@ -0,0 +1,159 @@
+package ecc
+
+import "math/big"
+
+// btcec's ScalarMult, and with it ECPoint.Mult, runs in time depending on the
+// scalar. That is fine for the public scalars of verifiers, but leaks private
+// keys, blinding factors, values and proof nonces through timing. MultSecret
+// and SecretMultiScalarMult are meant for those: they go through all 256 bits
+// of every scalar in fixed 4 bit windows, look up the multiples of the points
+// by scanning their whole tables and add them with the complete addition
+// formulas of Renes, Costello and Batina (https://eprint.iacr.org/2015/1060),
+// which have no special cases for doublings or the point at infinity, on top
+// of the constant time field arithmetic of field.go.
+//
+// Only the conversion of the scalars from big.Int is not constant time; its
+// running time depends on the byte length of a scalar alone.
+
+// secretWindow is the window width of SecretMultiScalarMult in bits.
+const secretWindow = 4
+
+// projPoint is a point of secp256k1 in projective coordinates, i.e. the affine
+// point (x/z, y/z). The point at infinity is (0, 1, 0).
+type projPoint struct {
+	x, y, z fe
+}
+
+// feB3 is 3*b of y^2 = x^3 + b.
+var feB3 = fe{21}
+
+func toProj(p ECPoint) projPoint {
+	if p.X.Sign() == 0 && p.Y.Sign() == 0 {
+		return projPoint{y: fe{1}}
+	}
+	return projPoint{x: feFromBig(p.X), y: feFromBig(p.Y), z: fe{1}}
+}
+
+// add sets p to p+q for any p and q, including p = q and the point at
+// infinity (algorithm 7 of the paper, secp256k1 has a = 0).
+func (p *projPoint) add(q *projPoint) {
+	t0 := feMul(p.x, q.x)
+	t1 := feMul(p.y, q.y)
+	t2 := feMul(p.z, q.z)
+	t3 := feMul(feAdd(p.x, p.y), feAdd(q.x, q.y))
+	t3 = feSub(t3, feAdd(t0, t1))
+	t4 := feMul(feAdd(p.y, p.z), feAdd(q.y, q.z))
+	t4 = feSub(t4, feAdd(t1, t2))
+	y3 := feMul(feAdd(p.x, p.z), feAdd(q.x, q.z))
+	y3 = feSub(y3, feAdd(t0, t2))
+	t0 = feAdd(feAdd(t0, t0), t0)
+	t2 = feMul(feB3, t2)
+	z3 := feAdd(t1, t2)
+	t1 = feSub(t1, t2)
+	y3 = feMul(feB3, y3)
+	x3 := feSub(feMul(t3, t1), feMul(t4, y3))
+	y3 = feAdd(feMul(t1, z3), feMul(y3, t0))
+	z3 = feAdd(feMul(z3, t4), feMul(t0, t3))
+	p.x, p.y, p.z = x3, y3, z3
+}
+
+// affine converts the point back to affine coordinates, the point at infinity
+// to EC.Zero().
+func (p *projPoint) affine() ECPoint {
+	zinv := feInvCT(p.z)
+	x, y := feMul(p.x, zinv), feMul(p.y, zinv)
+	return ECPoint{x.big(), y.big()}
+}
+
+// feInvCT returns a^(p-2), the inverse of a or zero for a = 0, with a fixed
+// chain of multiplications.
+func feInvCT(a fe) fe {
+	e := fe{feP[0] - 2, feP[1], feP[2], feP[3]}
+	r := fe{1}
+	for i := 255; i >= 0; i-- {
+		r = feMul(r, r)
+		if e[i/64]>>(uint(i)%64)&1 == 1 {
+			r = feMul(r, a)
+		}
+	}
+	return r
+}
+
+// scalarLimbs returns s modulo N as four little endian 64 bit limbs.
+func scalarLimbs(s *big.Int) [4]uint64 {
+	b := scalarBytes(s)
+	var k [4]uint64
+	for i := range k {
+		for j := 0; j < 8; j++ {
+			k[i] = k[i]<<8 | uint64(b[31-8*i-7+j])
+		}
+	}
+	Zeroize(b)
+	return k
+}
+
+// lookup returns table[d] without revealing d through memory access.
+func lookup(table *[1 << secretWindow]projPoint, d uint64) projPoint {
+	var r projPoint
+	for j := range table {
+		// eq is 1 if d == j and 0 otherwise
+		eq := ((d ^ uint64(j)) - 1) >> 63
+		r.x = feSelect(r.x, table[j].x, eq)
+		r.y = feSelect(r.y, table[j].y, eq)
+		r.z = feSelect(r.z, table[j].z, eq)
+	}
+	return r
+}
+
+// MultSecret returns s*p like Mult, in time independent of s. It must be used
+// whenever s is a private key, a blinding factor, a value or a nonce.
+func (p ECPoint) MultSecret(s *big.Int) ECPoint {
+	return SecretMultiScalarMult([]ECPoint{p}, []*big.Int{s})
+}
+
+// SecretMultiScalarMult returns sum(scalars[i]*points[i]) in time independent
+// of the scalars, see MultSecret. The doublings are shared by all points, so
+// the sum is much cheaper than the single multiplications.
+func SecretMultiScalarMult(points []ECPoint, scalars []*big.Int) ECPoint {
+	if len(points) != len(scalars) {
+		panic("SecretMultiScalarMult: length mismatch")
+	}
+	const mask = 1<<secretWindow - 1
+	var (
+		tables = make([][1 << secretWindow]projPoint, len(points))
+		ks     = make([][4]uint64, len(points))
+	)
+	for i, p := range points {
+		tables[i][0], tables[i][1] = projPoint{y: fe{1}}, toProj(p)
+		for d := 2; d <= mask; d++ {
+			tables[i][d] = tables[i][d-1]
+			tables[i][d].add(&tables[i][1])
+		}
+		ks[i] = scalarLimbs(scalars[i])
+	}
+	acc := projPoint{y: fe{1}}
+	for pos := 256 - secretWindow; pos >= 0; pos -= secretWindow {
+		for j := 0; j < secretWindow; j++ {
+			acc.add(&acc)
+		}
+		for i := range tables {
+			q := lookup(&tables[i], ks[i][pos/64]>>(uint(pos)%64)&mask)
+			acc.add(&q)
+		}
+	}
+	for i := range ks {
+		ks[i] = [4]uint64{}
+	}
+	return acc.affine()
+}
+
+// secretVectorCommit returns sum(a[i]*G[i] + b[i]*H[i]) + x*u like
+// TwoVectorPCommitWithGens(G, H, a, b).Add(u.Mult(x)), in time independent of
+// the scalars.
+func secretVectorCommit(G, H []ECPoint, a, b []*big.Int, u ECPoint, x *big.Int) ECPoint {
+	points := make([]ECPoint, 0, len(G)+len(H)+1)
+	scalars := make([]*big.Int, 0, len(a)+len(b)+1)
+	points = append(append(append(points, G...), H...), u)
+	scalars = append(append(append(scalars, a...), b...), x)
+	return SecretMultiScalarMult(points, scalars)
+}
@@ -0,0 +1,45 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMultSecret(t *testing.T) {
+	pub, _, _ := GenerateKeys("ct")
+	g := ConvertPub(pub).H
+	scalars := []*big.Int{
+		big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(-5),
+		new(big.Int).Sub(EC.N, big.NewInt(1)), EC.N, new(big.Int).Lsh(EC.N, 3),
+	}
+	for i := 0; i < 20; i++ {
+		scalars = append(scalars, RandScalar())
+	}
+	for _, s := range scalars {
+		if have, want := g.MultSecret(s), g.Mult(s); have.X.Cmp(want.X) != 0 || have.Y.Cmp(want.Y) != 0 {
+			t.Errorf("%x*G: have (%x, %x), want (%x, %x)", s, have.X, have.Y, want.X, want.Y)
+		}
+	}
+	if p := EC.Zero().MultSecret(RandScalar()); p.X.Sign() != 0 || p.Y.Sign() != 0 {
+		t.Errorf("multiple of the point at infinity: (%x, %x)", p.X, p.Y)
+	}
+	points := []ECPoint{g, g, g.Neg(), EC.G, EC.Zero()}
+	ks := []*big.Int{scalars[10], scalars[11], scalars[10], scalars[12], scalars[13]}
+	if have, want := SecretMultiScalarMult(points, ks), MultiScalarMult(points, ks); !have.Equal(want) {
+		t.Errorf("multi scalar multiplication mismatch")
+	}
+}
+
+func BenchmarkMult(b *testing.B) {
+	s := RandScalar()
+	b.Run("variable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			EC.G.Mult(s)
+		}
+	})
+	b.Run("secret", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			EC.G.MultSecret(s)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+// MarshalPoint 返回点的非压缩编码
+func MarshalPoint(p ECPoint) []byte {
+	return elliptic.Marshal(EC.C, p.X, p.Y)
+}
+
+// ValuePoint 返回v*G1，用于比对解密得到的点
+func ValuePoint(pub PublicKey, v *big.Int) ECPoint {
+	return ConvertPub(pub).G1.Mult(v)
+}
@@ -0,0 +1,317 @@
+package ecc
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	// MaxDecryptBits 可解密金额的最大位数，金额须小于2^MaxDecryptBits
+	MaxDecryptBits = 40
+
+	// DefaultDLogBudget 小步表默认占用的内存（字节）
+	DefaultDLogBudget = 64 << 20
+
+	// dlogEntrySize 小步表每项占用的字节数：8字节横坐标指纹和4字节步数
+	dlogEntrySize = 12
+
+	// dlogChunk 批量求仿射横坐标的点数，一批共用一次域求逆
+	dlogChunk = 1024
+)
+
+var (
+	errInvalidCypherText = errors.New("invalid cyphertext")
+	errValueRange        = errors.New("value out of range")
+
+	dlogMagic = []byte("MCDLOG\x00\x01")
+)
+
+// DLogTable 用小步大步法（baby-step giant-step）求解v*G1中的金额v。
+//
+// 小步表保存j*G1（0 < j < m）横坐标的高64位到j的有序映射。求解时从v*G1起
+// 每次减去m*G1，第i步命中小步表中的j即得v = i*m + j，因此至多2^bits/m步。
+// 表越大（m越大）求解越快，m由内存预算决定。指纹相同的候选值都会用v*G1验证，
+// 因此损坏的表只会导致解密失败，不会解出错误的金额。
+type DLogTable struct {
+	g1    ECPoint
+	giant ECPoint // -m*G1
+	bits  uint
+	m     uint64
+	keys  []uint64 // 升序排列的j*G1指纹
+	steps []uint32 // keys[i]对应的j
+}
+
+// NewDLogTable 为生成元g1生成小步表，表占用内存不超过budget字节，可解密小于
+// 2^bits的金额
+func NewDLogTable(g1 ECPoint, bits int, budget int) (*DLogTable, error) {
+	t, err := newDLogTable(g1, bits, budget)
+	if err != nil {
+		return nil, err
+	}
+	// 在雅可比坐标下依次累加G1，每批点共用一次求逆得到横坐标
+	var (
+		g   = toJac(g1)
+		acc = g
+		pts = make([]jacPoint, 0, dlogChunk)
+	)
+	for j := uint64(1); j < t.m; j++ {
+		pts = append(pts, acc)
+		acc.add(&g)
+		if len(pts) == dlogChunk || j == t.m-1 {
+			for k, x := range batchAffineX(pts) {
+				t.keys = append(t.keys, x[3])
+				t.steps = append(t.steps, uint32(j+1-uint64(len(pts)-k)))
+			}
+			pts = pts[:0]
+		}
+	}
+	sort.Sort(dlogEntries{t})
+	return t, nil
+}
+
+// newDLogTable 返回参数已确定、尚未填充小步的表
+func newDLogTable(g1 ECPoint, bits int, budget int) (*DLogTable, error) {
+	if g1.X == nil || g1.Y == nil || (g1.X.Sign() == 0 && g1.Y.Sign() == 0) {
+		return nil, errors.New("invalid generator")
+	}
+	if bits <= 0 || bits > MaxDecryptBits {
+		return nil, fmt.Errorf("value bits %d out of range (1..%d)", bits, MaxDecryptBits)
+	}
+	m := uint64(budget / dlogEntrySize)
+	if limit := uint64(1) << uint(bits); m > limit {
+		m = limit
+	}
+	if m > 1<<32-1 {
+		m = 1<<32 - 1
+	}
+	if m < 2 {
+		return nil, fmt.Errorf("memory budget %d too small", budget)
+	}
+	return &DLogTable{
+		g1:    g1,
+		giant: g1.Mult(new(big.Int).SetUint64(m)).Neg(),
+		bits:  uint(bits),
+		m:     m,
+		keys:  make([]uint64, 0, m-1),
+		steps: make([]uint32, 0, m-1),
+	}, nil
+}
+
+// Solve 求解p = v*G1中的v，v须小于2^bits
+func (t *DLogTable) Solve(p ECPoint) (uint64, error) {
+	if p.X == nil || p.Y == nil {
+		return 0, errInvalidCypherText
+	}
+	var (
+		limit = uint64(1) << t.bits
+		giant = toJac(t.giant)
+		q     = toJac(p)
+		pts   = make([]jacPoint, 0, dlogChunk)
+	)
+	for base := uint64(0); base < limit; base += uint64(len(pts)) * t.m {
+		// 一批大步p - (base/m + k)*m*G1
+		pts = pts[:0]
+		for k := uint64(0); k < dlogChunk && base+k*t.m < limit; k++ {
+			pts = append(pts, q)
+			q.add(&giant)
+		}
+		for k, x := range batchAffineX(pts) {
+			step := base + uint64(k)*t.m
+			if pts[k].z.isZero() {
+				return step, nil
+			}
+			if v, ok := t.lookup(x[3], step, p); ok && v < limit {
+				return v, nil
+			}
+		}
+	}
+	return 0, errValueRange
+}
+
+// lookup 在小步表中查找指纹key，返回满足(step+j)*G1 = p的step+j
+func (t *DLogTable) lookup(key uint64, step uint64, p ECPoint) (uint64, bool) {
+	for k := sort.Search(len(t.keys), func(i int) bool { return t.keys[i] >= key }); k < len(t.keys) && t.keys[k] == key; k++ {
+		v := step + uint64(t.steps[k])
+		if c := t.g1.Mult(new(big.Int).SetUint64(v)); c.X.Cmp(p.X) == 0 && c.Y.Cmp(p.Y) == 0 {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// Save 将小步表写入文件，先写临时文件再改名，中断不会留下半张表
+func (t *DLogTable) Save(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	w.Write(dlogMagic)
+	w.Write(elliptic.Marshal(EC.C, t.g1.X, t.g1.Y))
+	binary.Write(w, binary.LittleEndian, uint32(len(t.keys)))
+	binary.Write(w, binary.LittleEndian, t.keys)
+	binary.Write(w, binary.LittleEndian, t.steps)
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadDLogTable 读取Save写入的小步表，表须属于生成元g1且大小与budget一致
+func LoadDLogTable(path string, g1 ECPoint, bits int, budget int) (*DLogTable, error) {
+	t, err := newDLogTable(g1, bits, budget)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	header := make([]byte, len(dlogMagic)+65)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(dlogMagic)], dlogMagic) {
+		return nil, errors.New("not a discrete log table")
+	}
+	if !bytes.Equal(header[len(dlogMagic):], elliptic.Marshal(EC.C, g1.X, g1.Y)) {
+		return nil, errors.New("discrete log table of another generator")
+	}
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	if uint64(n) != t.m-1 {
+		return nil, fmt.Errorf("discrete log table size mismatch: have %d, want %d", n, t.m-1)
+	}
+	t.keys, t.steps = t.keys[:n], t.steps[:n]
+	if err := binary.Read(r, binary.LittleEndian, t.keys); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, t.steps); err != nil {
+		return nil, err
+	}
+	if !sort.IsSorted(dlogEntries{t}) {
+		return nil, errors.New("corrupted discrete log table")
+	}
+	return t, nil
+}
+
+// dlogEntries 按指纹对小步表排序
+type dlogEntries struct{ t *DLogTable }
+
+func (e dlogEntries) Len() int           { return len(e.t.keys) }
+func (e dlogEntries) Less(i, j int) bool { return e.t.keys[i] < e.t.keys[j] }
+func (e dlogEntries) Swap(i, j int) {
+	e.t.keys[i], e.t.keys[j] = e.t.keys[j], e.t.keys[i]
+	e.t.steps[i], e.t.steps[j] = e.t.steps[j], e.t.steps[i]
+}
+
+// DLogConfig 金额解密所用小步表的配置
+type DLogConfig struct {
+	Dir    string // 表文件目录，为空时只在内存中生成
+	Budget int    // 每张小步表占用内存的上限（字节）
+	Bits   int    // 可解密金额的位数，至多MaxDecryptBits
+}
+
+var (
+	dlogLock   sync.Mutex
+	dlogConfig = DLogConfig{Budget: DefaultDLogBudget, Bits: MaxDecryptBits}
+	dlogTables = make(map[string]*DLogTable)
+)
+
+// SetDLogConfig 设置DecryptValue使用的小步表配置，已加载的表将被丢弃
+func SetDLogConfig(cfg DLogConfig) {
+	dlogLock.Lock()
+	defer dlogLock.Unlock()
+
+	if cfg.Budget == 0 {
+		cfg.Budget = DefaultDLogBudget
+	}
+	if cfg.Bits == 0 {
+		cfg.Bits = MaxDecryptBits
+	}
+	dlogConfig = cfg
+	dlogTables = make(map[string]*DLogTable)
+}
+
+// dlogTable 返回生成元g1的小步表。表在首次使用时从配置的目录读取，不存在或
+// 不可用时重新生成并写回目录
+func dlogTable(g1 ECPoint) (*DLogTable, error) {
+	dlogLock.Lock()
+	defer dlogLock.Unlock()
+
+	enc := elliptic.Marshal(EC.C, g1.X, g1.Y)
+	if t := dlogTables[string(enc)]; t != nil {
+		return t, nil
+	}
+	cfg := dlogConfig
+	var path string
+	if cfg.Dir != "" {
+		h := sha256.Sum256(enc)
+		path = filepath.Join(cfg.Dir, fmt.Sprintf("dlog-%x-%d.bin", h[:8], cfg.Budget/dlogEntrySize))
+		if t, err := LoadDLogTable(path, g1, cfg.Bits, cfg.Budget); err == nil {
+			dlogTables[string(enc)] = t
+			return t, nil
+		}
+	}
+	t, err := NewDLogTable(g1, cfg.Bits, cfg.Budget)
+	if err != nil {
+		return nil, err
+	}
+	// 写回失败不影响本次使用，下次启动时重新生成
+	if path != "" && os.MkdirAll(cfg.Dir, 0700) == nil {
+		t.Save(path)
+	}
+	dlogTables[string(enc)] = t
+	return t, nil
+}
+
+// DecryptPoint 解密指数ElGamal密文C = (v*G1 + r*H, r*G2)，返回v*G1
+func DecryptPoint(priv PrivateKey, C CypherText) (ECPoint, error) {
+	c1, err1 := DecodePoint(C.C1)
+	c2, err2 := DecodePoint(C.C2)
+	if err1 != nil || err2 != nil || priv.X == nil {
+		return ECPoint{}, errInvalidCypherText
+	}
+	return c1.Add(c2.MultSecret(priv.X).Neg()), nil
+}
+
+// DecryptValue 解密金额密文，金额须小于2^MaxDecryptBits（或配置的位数）
+func DecryptValue(priv PrivateKey, C CypherText) (uint64, error) {
+	gv, err := DecryptPoint(priv, C)
+	if err != nil {
+		return 0, err
+	}
+	return DiscreteLog(ConvertPub(priv.PublicKey).G1, gv)
+}
+
+// DiscreteLog 求解p = v*g1中的金额v
+func DiscreteLog(g1, p ECPoint) (uint64, error) {
+	t, err := dlogTable(g1)
+	if err != nil {
+		return 0, err
+	}
+	return t.Solve(p)
+}
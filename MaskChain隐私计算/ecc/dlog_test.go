@@ -0,0 +1,102 @@
+package ecc
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDLogTable(t *testing.T) {
+	g1 := EC.BPG[0]
+	table, err := NewDLogTable(g1, 24, 4096*dlogEntrySize)
+	if err != nil {
+		t.Fatalf("failed to generate table: %v", err)
+	}
+	for _, v := range []uint64{0, 1, 4095, 4096, 4097, 123456, 1<<24 - 1} {
+		if have, err := table.Solve(g1.Mult(new(big.Int).SetUint64(v))); err != nil || have != v {
+			t.Errorf("value %d: have %d, %v", v, have, err)
+		}
+	}
+	// Values out of range, including the negative ones sharing the x
+	// coordinate of a small value, are not decrypted
+	for _, v := range []*big.Int{big.NewInt(1 << 24), big.NewInt(-5), new(big.Int).Lsh(big.NewInt(1), 100)} {
+		if have, err := table.Solve(g1.Mult(v)); err == nil {
+			t.Errorf("value %v decrypted to %d", v, have)
+		}
+	}
+	if _, err := NewDLogTable(g1, MaxDecryptBits+1, 4096*dlogEntrySize); err == nil {
+		t.Errorf("table beyond %d bits accepted", MaxDecryptBits)
+	}
+	if _, err := NewDLogTable(g1, 24, dlogEntrySize); err == nil {
+		t.Errorf("table of a single entry accepted")
+	}
+}
+
+func TestDLogTableFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	g1, budget := EC.BPG[0], 1024*dlogEntrySize
+	table, err := NewDLogTable(g1, 20, budget)
+	if err != nil {
+		t.Fatalf("failed to generate table: %v", err)
+	}
+	path := filepath.Join(dir, "table")
+	if err := table.Save(path); err != nil {
+		t.Fatalf("failed to save table: %v", err)
+	}
+	loaded, err := LoadDLogTable(path, g1, 20, budget)
+	if err != nil {
+		t.Fatalf("failed to load table: %v", err)
+	}
+	if v, err := loaded.Solve(g1.Mult(big.NewInt(654321))); err != nil || v != 654321 {
+		t.Errorf("loaded table solved %d, %v", v, err)
+	}
+	if _, err := LoadDLogTable(path, EC.BPG[1], 20, budget); err == nil {
+		t.Errorf("table of another generator loaded")
+	}
+	if _, err := LoadDLogTable(path, g1, 20, 2*budget); err == nil {
+		t.Errorf("table of another size loaded")
+	}
+	data, _ := ioutil.ReadFile(path)
+	ioutil.WriteFile(path, data[:len(data)-1], 0600)
+	if _, err := LoadDLogTable(path, g1, 20, budget); err == nil {
+		t.Errorf("truncated table loaded")
+	}
+}
+
+func TestDecryptValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	SetDLogConfig(DLogConfig{Dir: dir, Budget: 1 << 16 * dlogEntrySize})
+	defer SetDLogConfig(DLogConfig{})
+
+	pub, priv, _ := GenerateKeys("regulator")
+	for _, v := range []uint64{0, 20, 262145, 1<<30 + 12345} {
+		C, _, _ := EncryptValue(pub, v)
+		if have, err := DecryptValue(priv, C); err != nil || have != v {
+			t.Errorf("value %d: have %d, %v", v, have, err)
+		}
+	}
+	// The table of the key is stored and reused
+	files, _ := filepath.Glob(filepath.Join(dir, "dlog-*"))
+	if len(files) != 1 {
+		t.Fatalf("table files mismatch: %v", files)
+	}
+	SetDLogConfig(DLogConfig{Dir: dir, Budget: 1 << 16 * dlogEntrySize})
+	C, _, _ := EncryptValue(pub, 99)
+	if v, err := DecryptValue(priv, C); err != nil || v != 99 {
+		t.Errorf("value 99 with stored table: have %d, %v", v, err)
+	}
+	if _, err := DecryptValue(priv, CypherText{C1: []byte{1}, C2: C.C2}); err == nil {
+		t.Errorf("invalid cyphertext decrypted")
+	}
+}
@@ -1,8 +1,7 @@
-package bp
+package ecc
 
 import (
 	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	_ "github.com/btcsuite/btcd/btcec"
 	"math/big"
@@ -29,7 +28,7 @@ func Discrete_Logarithm_Proof(x *big.Int) DLP{
 	t := EC.G.Mult(v)
 	dlpResult.T = t
 	//fmt.Println("t: ",t)
-	c := sha256.Sum256([]byte(EC.G.X.String() + EC.G.Y.String()+y.X.String()+y.Y.String()+t.X.String()+t.Y.String()))
+	c := dlpChallenge(y, t)
 	dlpResult.C = c
 
 	intc := new(big.Int).SetBytes(c[:])
@@ -42,7 +41,7 @@ func Discrete_Logarithm_Proof(x *big.Int) DLP{
 
 func DLPVerify(dlp DLP) bool{
 
-	tempC := sha256.Sum256([]byte(EC.G.X.String() + EC.G.Y.String()+dlp.Y.X.String()+dlp.Y.Y.String()+dlp.T.X.String()+dlp.T.Y.String()))
+	tempC := dlpChallenge(dlp.Y, dlp.T)
 	if tempC != dlp.C {
 		fmt.Println("DLP failed: tem[C != dlp.C")
 		return false
@@ -58,4 +57,13 @@ func DLPVerify(dlp DLP) bool{
 	}
 
 	return true
-}
\ No newline at end of file
+}
+
+// dlpChallenge 将生成元G、y和t加入转录后得出离散对数证明的挑战
+func dlpChallenge(y, t ECPoint) Hash {
+	tr := NewTranscript("DLP")
+	tr.AppendPoint("G", EC.G)
+	tr.AppendPoint("Y", y)
+	tr.AppendPoint("T", t)
+	return hashChallenge(tr.Challenge("c"))
+}
@@ -0,0 +1,623 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// EncodingVersion is the leading byte of the canonical encoding.
+//
+// Every object is encoded as version || kind || length || body, the length
+// being the big endian uint32 byte length of the body. Points are encoded as
+// 33 byte compressed SEC1 points, scalars as 32 byte big endian integers below
+// the group order, challenges as 32 raw bytes and lists with a leading big
+// endian uint16 count. The responses of linear equation proofs, which are
+// checked as integers, are encoded with a leading byte length. Decoding is
+// strict: an object decodes only from the single encoding produced by
+// MarshalBinary, and every point is checked to be on the curve.
+//
+// The text encoding is the 0x prefixed lower case hex string of the binary
+// encoding.
+const EncodingVersion = 1
+
+// Kind identifies the type of an encoded object.
+type Kind byte
+
+const (
+	KindPublicKey Kind = iota + 1
+	KindPrivateKey
+	KindCypherText
+	KindCommitment
+	KindFormatProof
+	KindEqualityProof
+	KindBalanceProof
+	KindMultiBalanceProof
+	KindRangeProof
+	KindMultiRangeProof
+	KindMembershipProof
+	KindCipherEqualityProof
+)
+
+const (
+	encodingHeaderLen = 6
+	pointEncLen       = 33
+	scalarEncLen      = 32
+)
+
+// ErrInvalidEncoding is returned, possibly wrapped, for every object which is
+// not in canonical encoding.
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// EncodeText returns the text encoding of v.
+func EncodeText(v encoding.BinaryMarshaler) (string, error) {
+	data, err := v.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(data), nil
+}
+
+// DecodeText decodes the text encoding s into v. Upper case digits and a
+// missing prefix are rejected, the text encoding being canonical as well.
+func DecodeText(s string, v encoding.BinaryUnmarshaler) error {
+	if !strings.HasPrefix(s, "0x") || strings.ToLower(s) != s {
+		return fmt.Errorf("%w: text is not 0x prefixed lower case hex", ErrInvalidEncoding)
+	}
+	data, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	return v.UnmarshalBinary(data)
+}
+
+// encoder appends the fields of an object to its body. The first failure is
+// kept, later fields are ignored.
+type encoder struct {
+	buf []byte
+	err error
+}
+
+func (e *encoder) point(p ECPoint) {
+	if e.err != nil {
+		return
+	}
+	if p.X == nil || p.Y == nil || !EC.C.IsOnCurve(p.X, p.Y) {
+		e.err = fmt.Errorf("%w: point not on curve", ErrInvalidEncoding)
+		return
+	}
+	x := p.X.Bytes()
+	enc := make([]byte, pointEncLen)
+	enc[0] = 2 | byte(p.Y.Bit(0))
+	copy(enc[pointEncLen-len(x):], x)
+	e.buf = append(e.buf, enc...)
+}
+
+// marshaledPoint appends a point given in the uncompressed encoding of
+// elliptic.Marshal.
+func (e *encoder) marshaledPoint(b []byte) {
+	p, err := DecodePoint(b)
+	if err != nil && e.err == nil {
+		e.err = fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	e.point(p)
+}
+
+// keyPoint appends a point of a PublicKey, i.e. the uncompressed encoding as
+// an integer.
+func (e *encoder) keyPoint(v *big.Int) {
+	if v == nil {
+		e.point(ECPoint{})
+		return
+	}
+	e.marshaledPoint(v.Bytes())
+}
+
+func (e *encoder) scalar(s *big.Int) {
+	if e.err != nil {
+		return
+	}
+	if s == nil || s.Sign() < 0 || s.Cmp(EC.N) >= 0 {
+		e.err = fmt.Errorf("%w: scalar out of range", ErrInvalidEncoding)
+		return
+	}
+	e.buf = append(e.buf, scalarBytes(s)...)
+}
+
+// scalarBytes appends a scalar given as big endian integer, reduced modulo
+// the group order. The responses of the sigma proofs are not reduced when
+// generated, but only used as scalars of point multiplications.
+func (e *encoder) scalarBytes(b []byte) {
+	e.scalar(new(big.Int).Mod(new(big.Int).SetBytes(b), EC.N))
+}
+
+// integer appends a non-negative integer of up to 255 bytes as its length
+// followed by its minimal big endian encoding. The responses of the linear
+// equation proofs are checked as integers and may exceed the group order.
+func (e *encoder) integer(b []byte) {
+	if e.err != nil {
+		return
+	}
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) > 0xff {
+		e.err = fmt.Errorf("%w: integer of %d bytes", ErrInvalidEncoding, len(b))
+		return
+	}
+	e.buf = append(append(e.buf, byte(len(b))), b...)
+}
+
+// challenge appends a 32 byte challenge hash.
+func (e *encoder) challenge(c []byte) {
+	if e.err != nil {
+		return
+	}
+	if len(c) != HashLength {
+		e.err = fmt.Errorf("%w: challenge of %d bytes", ErrInvalidEncoding, len(c))
+		return
+	}
+	e.buf = append(e.buf, c...)
+}
+
+func (e *encoder) count(n int) {
+	if e.err != nil {
+		return
+	}
+	if n > 0xffff {
+		e.err = fmt.Errorf("%w: list of %d items", ErrInvalidEncoding, n)
+		return
+	}
+	e.buf = append(e.buf, byte(n>>8), byte(n))
+}
+
+// marshal returns the encoding of an object of the given kind whose body is
+// written by fn.
+func marshal(kind Kind, fn func(e *encoder)) ([]byte, error) {
+	e := &encoder{buf: make([]byte, encodingHeaderLen, 256)}
+	fn(e)
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.buf[0], e.buf[1] = EncodingVersion, byte(kind)
+	binary.BigEndian.PutUint32(e.buf[2:], uint32(len(e.buf)-encodingHeaderLen))
+	return e.buf, nil
+}
+
+// decoder reads the fields of an object from its body. The first failure is
+// kept, later fields decode to zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < n {
+		d.err = fmt.Errorf("%w: truncated", ErrInvalidEncoding)
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) point() ECPoint {
+	b := d.next(pointEncLen)
+	if b == nil {
+		return ECPoint{}
+	}
+	p, err := decompressPoint(b)
+	if err != nil {
+		d.err = err
+	}
+	return p
+}
+
+// marshaledPoint reads a point into the uncompressed encoding of
+// elliptic.Marshal.
+func (d *decoder) marshaledPoint() []byte {
+	if p := d.point(); d.err == nil {
+		return elliptic.Marshal(EC.C, p.X, p.Y)
+	}
+	return nil
+}
+
+func (d *decoder) keyPoint() *big.Int {
+	return new(big.Int).SetBytes(d.marshaledPoint())
+}
+
+func (d *decoder) scalar() *big.Int {
+	b := d.next(scalarEncLen)
+	if b == nil {
+		return new(big.Int)
+	}
+	s := new(big.Int).SetBytes(b)
+	if s.Cmp(EC.N) >= 0 {
+		d.err = fmt.Errorf("%w: scalar out of range", ErrInvalidEncoding)
+	}
+	return s
+}
+
+// scalarBytes reads a scalar into the minimal big endian encoding used by the
+// proof structures.
+func (d *decoder) scalarBytes() []byte {
+	return d.scalar().Bytes()
+}
+
+// integer reads an integer written by encoder.integer, rejecting leading
+// zero bytes.
+func (d *decoder) integer() []byte {
+	n := d.next(1)
+	if n == nil {
+		return nil
+	}
+	b := d.next(int(n[0]))
+	if len(b) > 0 && b[0] == 0 {
+		d.err = fmt.Errorf("%w: integer with leading zero", ErrInvalidEncoding)
+	}
+	return append([]byte{}, b...)
+}
+
+func (d *decoder) challenge() []byte {
+	return append([]byte{}, d.next(HashLength)...)
+}
+
+func (d *decoder) count() int {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int(b[0])<<8 | int(b[1])
+}
+
+// unmarshal checks the header of an encoded object of the given kind and reads
+// its body with fn. The body must be consumed exactly.
+func unmarshal(data []byte, kind Kind, fn func(d *decoder)) error {
+	if len(data) < encodingHeaderLen {
+		return fmt.Errorf("%w: truncated header", ErrInvalidEncoding)
+	}
+	if data[0] != EncodingVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEncoding, data[0])
+	}
+	if Kind(data[1]) != kind {
+		return fmt.Errorf("%w: kind %d, want %d", ErrInvalidEncoding, data[1], kind)
+	}
+	if n := binary.BigEndian.Uint32(data[2:]); uint64(n) != uint64(len(data)-encodingHeaderLen) {
+		return fmt.Errorf("%w: body length %d, have %d bytes", ErrInvalidEncoding, n, len(data)-encodingHeaderLen)
+	}
+	d := &decoder{buf: data[encodingHeaderLen:]}
+	fn(d)
+	if d.err == nil && len(d.buf) != 0 {
+		d.err = fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(d.buf))
+	}
+	return d.err
+}
+
+// decompressPoint decodes a compressed SEC1 point, rejecting coordinates which
+// are not reduced and x coordinates without a point on the curve.
+func decompressPoint(b []byte) (ECPoint, error) {
+	if len(b) != pointEncLen || (b[0] != 2 && b[0] != 3) {
+		return ECPoint{}, fmt.Errorf("%w: not a compressed point", ErrInvalidEncoding)
+	}
+	p := EC.C.Params().P
+	x := new(big.Int).SetBytes(b[1:])
+	if x.Cmp(p) >= 0 {
+		return ECPoint{}, fmt.Errorf("%w: point coordinate out of range", ErrInvalidEncoding)
+	}
+	// y^2 = x^3 + 7, p = 3 mod 4 gives the square root y = (y^2)^((p+1)/4)
+	y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+	y2.Add(y2, EC.C.Params().B).Mod(y2, p)
+	y := new(big.Int).Exp(y2, new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(1)), 2), p)
+	if new(big.Int).Exp(y, big.NewInt(2), p).Cmp(y2) != 0 {
+		return ECPoint{}, fmt.Errorf("%w: point not on curve", ErrInvalidEncoding)
+	}
+	if y.Bit(0) != uint(b[0]&1) {
+		y.Sub(p, y)
+	}
+	return ECPoint{x, y}, nil
+}
+
+// MarshalBinary encodes the key as G1 || G2 || H. P is the group order and
+// not encoded.
+func (pub PublicKey) MarshalBinary() ([]byte, error) {
+	return marshal(KindPublicKey, func(e *encoder) {
+		if pub.P != nil && pub.P.Cmp(EC.N) != 0 {
+			e.err = fmt.Errorf("%w: key of another group", ErrInvalidEncoding)
+		}
+		e.keyPoint(pub.G1)
+		e.keyPoint(pub.G2)
+		e.keyPoint(pub.H)
+	})
+}
+
+// UnmarshalBinary decodes a key encoded by MarshalBinary.
+func (pub *PublicKey) UnmarshalBinary(data []byte) error {
+	var k PublicKey
+	err := unmarshal(data, KindPublicKey, func(d *decoder) {
+		k.G1, k.G2, k.H = d.keyPoint(), d.keyPoint(), d.keyPoint()
+	})
+	if err != nil {
+		return err
+	}
+	k.P = EC.N
+	*pub = k
+	return nil
+}
+
+// MarshalBinary encodes the key as its public key || X, X reduced modulo the
+// group order.
+func (priv PrivateKey) MarshalBinary() ([]byte, error) {
+	pubEnc, err := priv.PublicKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return marshal(KindPrivateKey, func(e *encoder) {
+		e.buf = append(e.buf, pubEnc[encodingHeaderLen:]...)
+		if priv.X == nil {
+			e.scalar(nil)
+			return
+		}
+		e.scalar(new(big.Int).Mod(priv.X, EC.N))
+	})
+}
+
+// UnmarshalBinary decodes a key encoded by MarshalBinary. The public key must
+// belong to X.
+func (priv *PrivateKey) UnmarshalBinary(data []byte) error {
+	var k PrivateKey
+	err := unmarshal(data, KindPrivateKey, func(d *decoder) {
+		k.G1, k.G2, k.H = d.keyPoint(), d.keyPoint(), d.keyPoint()
+		k.X = d.scalar()
+		if d.err == nil {
+			pub := ConvertPub(k.PublicKey)
+			if h := pub.G2.Mult(k.X); h.X.Cmp(pub.H.X) != 0 || h.Y.Cmp(pub.H.Y) != 0 {
+				d.err = fmt.Errorf("%w: public key of another private key", ErrInvalidEncoding)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	k.P = EC.N
+	*priv = k
+	return nil
+}
+
+// MarshalBinary encodes the cyphertext as C1 || C2.
+func (C CypherText) MarshalBinary() ([]byte, error) {
+	return marshal(KindCypherText, func(e *encoder) {
+		e.marshaledPoint(C.C1)
+		e.marshaledPoint(C.C2)
+	})
+}
+
+// UnmarshalBinary decodes a cyphertext encoded by MarshalBinary.
+func (C *CypherText) UnmarshalBinary(data []byte) error {
+	var c CypherText
+	if err := unmarshal(data, KindCypherText, func(d *decoder) {
+		c.C1, c.C2 = d.marshaledPoint(), d.marshaledPoint()
+	}); err != nil {
+		return err
+	}
+	*C = c
+	return nil
+}
+
+// MarshalBinary encodes the commitment, followed by its blinding factor if
+// known.
+func (cm Commitment) MarshalBinary() ([]byte, error) {
+	return marshal(KindCommitment, func(e *encoder) {
+		e.marshaledPoint(cm.Commitment)
+		if cm.R != nil {
+			e.scalarBytes(cm.R)
+		}
+	})
+}
+
+// UnmarshalBinary decodes a commitment encoded by MarshalBinary.
+func (cm *Commitment) UnmarshalBinary(data []byte) error {
+	var c Commitment
+	if err := unmarshal(data, KindCommitment, func(d *decoder) {
+		c.Commitment = d.marshaledPoint()
+		if len(d.buf) > 0 {
+			c.R = d.scalarBytes()
+		}
+	}); err != nil {
+		return err
+	}
+	*cm = c
+	return nil
+}
+
+func (fp FormatProof) encode(e *encoder) {
+	for _, p := range [][]byte{fp.G1, fp.G2, fp.Y1, fp.Y2, fp.T1, fp.T2} {
+		e.marshaledPoint(p)
+	}
+	e.scalarBytes(fp.S)
+	e.challenge(fp.C)
+}
+
+func (fp *FormatProof) decode(d *decoder) {
+	for _, p := range []*[]byte{&fp.G1, &fp.G2, &fp.Y1, &fp.Y2, &fp.T1, &fp.T2} {
+		*p = d.marshaledPoint()
+	}
+	fp.S = d.scalarBytes()
+	fp.C = d.challenge()
+}
+
+// MarshalBinary encodes the proof as G1 || G2 || Y1 || Y2 || T1 || T2 || S || C.
+func (fp FormatProof) MarshalBinary() ([]byte, error) {
+	return marshal(KindFormatProof, fp.encode)
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (fp *FormatProof) UnmarshalBinary(data []byte) error {
+	var p FormatProof
+	if err := unmarshal(data, KindFormatProof, p.decode); err != nil {
+		return err
+	}
+	*fp = p
+	return nil
+}
+
+// MarshalBinary encodes the proof like a FormatProof.
+func (ep EqualityProof) MarshalBinary() ([]byte, error) {
+	return marshal(KindEqualityProof, ep.FormatProof.encode)
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (ep *EqualityProof) UnmarshalBinary(data []byte) error {
+	var p EqualityProof
+	if err := unmarshal(data, KindEqualityProof, p.FormatProof.decode); err != nil {
+		return err
+	}
+	*ep = p
+	return nil
+}
+
+// MarshalBinary encodes the proof as Y || T || Sn_1 || Sn_2 || Sn_3 || C, the
+// responses as length prefixed integers.
+func (bp BalanceProof) MarshalBinary() ([]byte, error) {
+	return marshal(KindBalanceProof, func(e *encoder) {
+		e.marshaledPoint(bp.Y)
+		e.marshaledPoint(bp.T)
+		e.integer(bp.Sn_1)
+		e.integer(bp.Sn_2)
+		e.integer(bp.Sn_3)
+		e.challenge(bp.C)
+	})
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (bp *BalanceProof) UnmarshalBinary(data []byte) error {
+	var p BalanceProof
+	if err := unmarshal(data, KindBalanceProof, func(d *decoder) {
+		p.Y, p.T = d.marshaledPoint(), d.marshaledPoint()
+		p.Sn_1, p.Sn_2, p.Sn_3 = d.integer(), d.integer(), d.integer()
+		p.C = d.challenge()
+	}); err != nil {
+		return err
+	}
+	*bp = p
+	return nil
+}
+
+// MarshalBinary encodes the proof as Y || T || len(Sn) || Sn... || C.
+func (bp MultiBalanceProof) MarshalBinary() ([]byte, error) {
+	return marshal(KindMultiBalanceProof, func(e *encoder) {
+		e.marshaledPoint(bp.Y)
+		e.marshaledPoint(bp.T)
+		e.count(len(bp.Sn))
+		for _, s := range bp.Sn {
+			e.integer(s)
+		}
+		e.challenge(bp.C)
+	})
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (bp *MultiBalanceProof) UnmarshalBinary(data []byte) error {
+	var p MultiBalanceProof
+	if err := unmarshal(data, KindMultiBalanceProof, func(d *decoder) {
+		p.Y, p.T = d.marshaledPoint(), d.marshaledPoint()
+		for n := d.count(); n > 0 && d.err == nil; n-- {
+			p.Sn = append(p.Sn, d.integer())
+		}
+		p.C = d.challenge()
+	}); err != nil {
+		return err
+	}
+	*bp = p
+	return nil
+}
+
+// encodeBulletproof writes the fields shared by range and multi range proofs.
+// The challenges are recomputed by the verifier and not encoded.
+func encodeBulletproof(e *encoder, A, S, T1, T2 ECPoint, tau, th, mu *big.Int, ipp InnerProdArg) {
+	for _, p := range []ECPoint{A, S, T1, T2} {
+		e.point(p)
+	}
+	for _, s := range []*big.Int{tau, th, mu} {
+		e.scalar(s)
+	}
+	if len(ipp.L) != len(ipp.R) {
+		e.err = fmt.Errorf("%w: mismatched inner product rounds", ErrInvalidEncoding)
+		return
+	}
+	e.count(len(ipp.L))
+	for i := range ipp.L {
+		e.point(ipp.L[i])
+		e.point(ipp.R[i])
+	}
+	e.scalar(ipp.A)
+	e.scalar(ipp.B)
+}
+
+func decodeBulletproof(d *decoder, A, S, T1, T2 *ECPoint, tau, th, mu **big.Int, ipp *InnerProdArg) {
+	*A, *S, *T1, *T2 = d.point(), d.point(), d.point(), d.point()
+	*tau, *th, *mu = d.scalar(), d.scalar(), d.scalar()
+	for n := d.count(); n > 0 && d.err == nil; n-- {
+		ipp.L = append(ipp.L, d.point())
+		ipp.R = append(ipp.R, d.point())
+	}
+	ipp.A, ipp.B = d.scalar(), d.scalar()
+}
+
+// MarshalBinary encodes the proof as Comm || A || S || T1 || T2 || Tau || Th ||
+// Mu || rounds || (L_i || R_i)... || a || b.
+func (rp RangeProof) MarshalBinary() ([]byte, error) {
+	return marshal(KindRangeProof, func(e *encoder) {
+		e.point(rp.Comm)
+		encodeBulletproof(e, rp.A, rp.S, rp.T1, rp.T2, rp.Tau, rp.Th, rp.Mu, rp.IPP)
+	})
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary. The challenges are
+// left nil.
+func (rp *RangeProof) UnmarshalBinary(data []byte) error {
+	var p RangeProof
+	if err := unmarshal(data, KindRangeProof, func(d *decoder) {
+		p.Comm = d.point()
+		decodeBulletproof(d, &p.A, &p.S, &p.T1, &p.T2, &p.Tau, &p.Th, &p.Mu, &p.IPP)
+	}); err != nil {
+		return err
+	}
+	*rp = p
+	return nil
+}
+
+// MarshalBinary encodes the proof as len(Comms) || Comms... followed by the
+// fields of a RangeProof.
+func (mrp MultiRangeProof) MarshalBinary() ([]byte, error) {
+	return marshal(KindMultiRangeProof, func(e *encoder) {
+		e.count(len(mrp.Comms))
+		for _, p := range mrp.Comms {
+			e.point(p)
+		}
+		encodeBulletproof(e, mrp.A, mrp.S, mrp.T1, mrp.T2, mrp.Tau, mrp.Th, mrp.Mu, mrp.IPP)
+	})
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary. The challenges are
+// left nil.
+func (mrp *MultiRangeProof) UnmarshalBinary(data []byte) error {
+	var p MultiRangeProof
+	if err := unmarshal(data, KindMultiRangeProof, func(d *decoder) {
+		for n := d.count(); n > 0 && d.err == nil; n-- {
+			p.Comms = append(p.Comms, d.point())
+		}
+		decodeBulletproof(d, &p.A, &p.S, &p.T1, &p.T2, &p.Tau, &p.Th, &p.Mu, &p.IPP)
+	}); err != nil {
+		return err
+	}
+	*mrp = p
+	return nil
+}
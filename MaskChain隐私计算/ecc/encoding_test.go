@@ -0,0 +1,206 @@
+package ecc
+
+import (
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const encodingFile = "encoding.json"
+
+// encodable is implemented by every type with a canonical encoding.
+type encodable interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// encodingObjects returns the objects of testdata/vectors.json by the names
+// of their golden encodings, together with a constructor of an empty value of
+// each type.
+func encodingObjects(t *testing.T) (map[string]encodable, map[string]func() encodable) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", vectorsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs vectors
+	if err := json.Unmarshal(data, &vs); err != nil {
+		t.Fatal(err)
+	}
+	p := vs.Proofs[1]
+	mrp, err := decodeRangeProof(p.RangeProof, rangeProofVersion, p.RangeBits, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := map[string]encodable{
+		"public key":          &vs.Key.PublicKey,
+		"private key":         &vs.Key,
+		"cyphertext":          &vs.Values[0].CypherText,
+		"commitment":          &vs.Values[0].Commitment,
+		"public commitment":   &Commitment{Commitment: vs.Values[0].Commitment.Commitment},
+		"format proof":        &p.Format,
+		"equality proof":      &p.Equality,
+		"balance proof":       &p.Balance,
+		"multi balance proof": &p.MultiBal,
+		"multi range proof":   &mrp,
+	}
+	types := map[string]func() encodable{
+		"public key":          func() encodable { return new(PublicKey) },
+		"private key":         func() encodable { return new(PrivateKey) },
+		"cyphertext":          func() encodable { return new(CypherText) },
+		"commitment":          func() encodable { return new(Commitment) },
+		"public commitment":   func() encodable { return new(Commitment) },
+		"format proof":        func() encodable { return new(FormatProof) },
+		"equality proof":      func() encodable { return new(EqualityProof) },
+		"balance proof":       func() encodable { return new(BalanceProof) },
+		"multi balance proof": func() encodable { return new(MultiBalanceProof) },
+		"multi range proof":   func() encodable { return new(MultiRangeProof) },
+	}
+	return objects, types
+}
+
+// TestEncodingVectors checks the objects of testdata/vectors.json against
+// their golden text encodings in testdata/encoding.json, and that decoding
+// and encoding again reproduces them.
+func TestEncodingVectors(t *testing.T) {
+	objects, types := encodingObjects(t)
+	path := filepath.Join("testdata", encodingFile)
+	if *updateVectors {
+		golden := make(map[string]string)
+		for name, obj := range objects {
+			text, err := EncodeText(obj)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			golden[name] = text
+		}
+		data, _ := json.MarshalIndent(golden, "", "  ")
+		if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var golden map[string]string
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatal(err)
+	}
+	if len(golden) != len(objects) {
+		t.Fatalf("golden encodings mismatch: have %d, want %d", len(golden), len(objects))
+	}
+	for name, obj := range objects {
+		if text, err := EncodeText(obj); err != nil || text != golden[name] {
+			t.Errorf("%s: encoding mismatch: %v\nhave %s\nwant %s", name, err, text, golden[name])
+		}
+		dec := types[name]()
+		if err := DecodeText(golden[name], dec); err != nil {
+			t.Errorf("%s: failed to decode: %v", name, err)
+			continue
+		}
+		if text, _ := EncodeText(dec); text != golden[name] {
+			t.Errorf("%s: encoding of the decoded object mismatch", name)
+		}
+	}
+}
+
+// TestDecodedProofs checks that decoded proofs still verify.
+func TestDecodedProofs(t *testing.T) {
+	_, types := encodingObjects(t)
+	data, _ := ioutil.ReadFile(filepath.Join("testdata", encodingFile))
+	var golden map[string]string
+	json.Unmarshal(data, &golden)
+	decode := func(name string) encodable {
+		obj := types[name]()
+		if err := DecodeText(golden[name], obj); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return obj
+	}
+	var vs vectors
+	data, _ = ioutil.ReadFile(filepath.Join("testdata", vectorsFile))
+	json.Unmarshal(data, &vs)
+	p := vs.Proofs[1]
+	tr := TxTranscript(p.ChainID, p.TxType, p.Commitments...)
+	ct := decode("cyphertext").(*CypherText)
+	if !valid(VerifyFormatProof(tr, vs.Values[1].CypherText, *decode("format proof").(*FormatProof))) {
+		t.Errorf("decoded format proof rejected")
+	}
+	if !valid(VerifyEqualityProof(tr, *decode("equality proof").(*EqualityProof))) {
+		t.Errorf("decoded equality proof rejected")
+	}
+	if !valid(VerifyBalanceProof(tr, p.Commitments[2], p.Commitments[1], p.Commitments[0], *decode("balance proof").(*BalanceProof))) {
+		t.Errorf("decoded balance proof rejected")
+	}
+	if !valid(VerifyMultiBalanceProof(tr, p.Commitments[:1], [][]byte{p.Commitments[1], p.Commitments[2]}, *decode("multi balance proof").(*MultiBalanceProof))) {
+		t.Errorf("decoded multi balance proof rejected")
+	}
+	key := decode("private key").(*PrivateKey)
+	SetDLogConfig(DLogConfig{Budget: 1 << 12 * dlogEntrySize})
+	defer SetDLogConfig(DLogConfig{})
+	if v, err := DecryptValue(*key, *ct); err != nil || v != 10 {
+		t.Errorf("decoded cyphertext decrypted to %d, %v", v, err)
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	_, priv, _ := GenerateKeys("strict")
+	ct, cm, _ := EncryptValue(priv.PublicKey, 5)
+	valid, _ := ct.MarshalBinary()
+
+	mutate := func(fn func(b []byte) []byte) []byte {
+		return fn(append([]byte{}, valid...))
+	}
+	// x = 5 has no point on secp256k1, x = p is not reduced
+	offCurve, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000005")
+	unreduced, _ := hex.DecodeString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")
+	tests := map[string][]byte{
+		"empty":         nil,
+		"version":       mutate(func(b []byte) []byte { b[0] = 2; return b }),
+		"kind":          mutate(func(b []byte) []byte { b[1] = byte(KindCommitment); return b }),
+		"length":        mutate(func(b []byte) []byte { b[5]++; return b }),
+		"truncated":     mutate(func(b []byte) []byte { b[5]--; return b[:len(b)-1] }),
+		"trailing":      mutate(func(b []byte) []byte { b[5]++; return append(b, 0) }),
+		"uncompressed":  mutate(func(b []byte) []byte { b[6] = 4; return b }),
+		"infinity":      mutate(func(b []byte) []byte { b[6] = 0; return b }),
+		"off curve":     mutate(func(b []byte) []byte { copy(b[7:], offCurve); return b }),
+		"not reduced x": mutate(func(b []byte) []byte { copy(b[7:], unreduced); return b }),
+	}
+	for name, enc := range tests {
+		var dec CypherText
+		if err := dec.UnmarshalBinary(enc); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("%s: have %v, want %v", name, err, ErrInvalidEncoding)
+		}
+	}
+
+	// Scalars not below the group order
+	enc, _ := cm.MarshalBinary()
+	copy(enc[len(enc)-scalarEncLen:], EC.N.Bytes())
+	if err := new(Commitment).UnmarshalBinary(enc); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("unreduced scalar: have %v", err)
+	}
+	// Private keys not matching their public key
+	enc, _ = priv.MarshalBinary()
+	enc[len(enc)-1] ^= 1
+	if err := new(PrivateKey).UnmarshalBinary(enc); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("mismatched private key: have %v", err)
+	}
+	// Text encodings other than 0x prefixed lower case hex
+	text, _ := EncodeText(ct)
+	for _, s := range []string{text[2:], "0X" + text[2:], text[:2] + "A" + text[3:], text + "0"} {
+		if err := DecodeText(s, new(CypherText)); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("text %.10s...: have %v", s, err)
+		}
+	}
+	// Points off the curve are not encoded either
+	bad := ct
+	bad.C1 = append([]byte{}, bad.C1...)
+	bad.C1[64] ^= 1
+	if _, err := bad.MarshalBinary(); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("off curve point encoded: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package ecc
+
+import "C"
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+type EP struct {
+	G1 ECPoint
+	G2 ECPoint
+	Y1 ECPoint
+	Y2 ECPoint
+	T1 ECPoint
+	T2 ECPoint
+	S *big.Int
+	C Hash
+}
+
+//TODO : 这个证明需不需要加两个生成元g1 g2 的阶参数
+// EPProof 证明log_g1(y1) = log_g2(y2) = x，挑战由转录t得出，t为nil时为版本1的挑战
+func EPProof (t *Transcript, g1 ECPoint, g2 ECPoint, x *big.Int) EP {
+
+	epResult := EP{}
+	epResult.G1 = g1
+	epResult.G2 = g2
+
+	y1 := g1.MultSecret(x)
+	y2 := g2.MultSecret(x)
+	epResult.Y1 = y1
+	epResult.Y2 = y2
+
+	v := RandScalar()
+	t1 := g1.MultSecret(v)
+	t2 := g2.MultSecret(v)
+	epResult.T1 = t1
+	epResult.T2 = t2
+
+	c := epChallenge(t, epResult)
+	epResult.C = c
+
+	// 响应为N-(v-c*x)，须模N约简：未约简时s/c近似等于x，会泄露私密的x
+	intc := new(big.Int).SetBytes(c[:])
+	cx :=  new(big.Int).Mul(intc, x)
+	s := new(big.Int).Sub(cx, v)
+	s.Mod(s, EC.N)
+	epResult.S = s
+	ZeroizeInt(v, cx)
+
+	return  epResult
+}
+
+// EPVerify 验证EPProof生成的证明，t须与生成证明时的转录相同
+func EPVerify (t *Transcript, ep EP) bool{
+	c := epChallenge(t, ep)
+	intc := new(big.Int).SetBytes(c[:])
+
+	if c!=ep.C{
+		fmt.Println("Equality proof failed: c wrong")
+		return false
+	}
+	ep.S.Sub(EC.N, ep.S)
+	tempT1 := ep.G1.Mult(ep.S).Add(ep.Y1.Mult(intc))
+	if !tempT1.Equal(ep.T1){
+		fmt.Println("Equality proof failed: t1 wrong")
+		return false
+	}
+
+	tempT2 := ep.G2.Mult(ep.S).Add(ep.Y2.Mult(intc))
+	if !tempT2.Equal(ep.T2){
+		fmt.Println("Equality proof failed: t2 wrong")
+		return false
+	}
+
+	return true
+}
+
+// epChallenge 计算相等证明的挑战。t为nil时为版本1中各点十进制字符串的哈希，
+// 否则将各点依次加入转录后得出挑战
+func epChallenge(t *Transcript, ep EP) Hash {
+	if t == nil {
+		return sha256.Sum256([]byte(ep.G1.X.String()+ep.G1.Y.String()+ep.G2.X.String()+ep.G2.Y.String()+ep.Y1.X.String()+ep.Y1.Y.String()+ep.Y2.X.String()+ep.Y2.Y.String()+ep.T1.X.String()+ep.T1.Y.String()+ep.T2.X.String()+ep.T2.Y.String()))
+	}
+	t.AppendMessage("proof", []byte("EP"))
+	t.AppendPoint("G1", ep.G1)
+	t.AppendPoint("G2", ep.G2)
+	t.AppendPoint("Y1", ep.Y1)
+	t.AppendPoint("Y2", ep.Y2)
+	t.AppendPoint("T1", ep.T1)
+	t.AppendPoint("T2", ep.T2)
+	return hashChallenge(t.Challenge("c"))
+}
@@ -0,0 +1,256 @@
+package ecc
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// fe is an element of the base field of secp256k1 as four little endian
+// 64 bit limbs, always reduced modulo p. The field operations below exploit
+// p = 2^256 - 2^32 - 977 and avoid the allocations of big.Int, which would
+// otherwise dominate long chains of point additions, e.g. in multi-scalar
+// multiplications and discrete log tables.
+type fe [4]uint64
+
+// feC is 2^256 mod p.
+const feC = 0x1000003d1
+
+var feP = fe{0xfffffffefffffc2f, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+
+func feFromBig(x *big.Int) fe {
+	var buf [32]byte
+	b := new(big.Int).Mod(x, EC.C.Params().P).Bytes()
+	copy(buf[32-len(b):], b)
+	var r fe
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			r[i] = r[i]<<8 | uint64(buf[31-8*i-7+j])
+		}
+	}
+	return r
+}
+
+func (a *fe) big() *big.Int {
+	var buf [32]byte
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			buf[31-8*i-j] = byte(a[i] >> (8 * uint(j)))
+		}
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
+
+func (a *fe) isZero() bool {
+	return a[0]|a[1]|a[2]|a[3] == 0
+}
+
+// feNorm reduces r + carry*2^256 modulo p. It runs in constant time, as do
+// feAdd, feSub and feMul, so that they can be used with secret operands.
+func feNorm(r fe, carry uint64) fe {
+	// After the first fold the carry is at most 1, and if it is, r is small
+	// enough for the second fold not to overflow again
+	for i := 0; i < 2; i++ {
+		hi, lo := bits.Mul64(carry, feC)
+		var c uint64
+		r[0], c = bits.Add64(r[0], lo, 0)
+		r[1], c = bits.Add64(r[1], hi, c)
+		r[2], c = bits.Add64(r[2], 0, c)
+		r[3], carry = bits.Add64(r[3], 0, c)
+	}
+	var s fe
+	var b uint64
+	s[0], b = bits.Sub64(r[0], feP[0], 0)
+	s[1], b = bits.Sub64(r[1], feP[1], b)
+	s[2], b = bits.Sub64(r[2], feP[2], b)
+	s[3], b = bits.Sub64(r[3], feP[3], b)
+	return feSelect(r, s, 1-b)
+}
+
+// feSelect returns b if flag is 1 and a if it is 0, without branching.
+func feSelect(a, b fe, flag uint64) fe {
+	mask := -flag
+	for i := range a {
+		a[i] ^= (a[i] ^ b[i]) & mask
+	}
+	return a
+}
+
+func feAdd(a, b fe) fe {
+	var r fe
+	var c uint64
+	r[0], c = bits.Add64(a[0], b[0], 0)
+	r[1], c = bits.Add64(a[1], b[1], c)
+	r[2], c = bits.Add64(a[2], b[2], c)
+	r[3], c = bits.Add64(a[3], b[3], c)
+	return feNorm(r, c)
+}
+
+func feSub(a, b fe) fe {
+	var r fe
+	var c uint64
+	r[0], c = bits.Sub64(a[0], b[0], 0)
+	r[1], c = bits.Sub64(a[1], b[1], c)
+	r[2], c = bits.Sub64(a[2], b[2], c)
+	r[3], c = bits.Sub64(a[3], b[3], c)
+	// On borrow a - b + 2^256 + p, the carry out of the addition cancels 2^256
+	mask := -c
+	r[0], c = bits.Add64(r[0], feP[0]&mask, 0)
+	r[1], c = bits.Add64(r[1], feP[1]&mask, c)
+	r[2], c = bits.Add64(r[2], feP[2]&mask, c)
+	r[3], _ = bits.Add64(r[3], feP[3]&mask, c)
+	return r
+}
+
+func feMul(a, b fe) fe {
+	var t [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j], carry = lo, hi
+		}
+		t[i+4] = carry
+	}
+	// The upper half counts 2^256 = feC times
+	var r fe
+	var carry uint64
+	for i := 0; i < 4; i++ {
+		hi, lo := bits.Mul64(t[i+4], feC)
+		var c uint64
+		lo, c = bits.Add64(lo, t[i], 0)
+		hi += c
+		lo, c = bits.Add64(lo, carry, 0)
+		hi += c
+		r[i], carry = lo, hi
+	}
+	return feNorm(r, carry)
+}
+
+// jacPoint is a point of secp256k1 in Jacobian coordinates, i.e. the affine
+// point (x/z^2, y/z^3). Additions and doublings in Jacobian coordinates need
+// no field inversion, which makes long chains of them much cheaper than the
+// affine EC.C.Add. A zero z stands for the point at infinity.
+type jacPoint struct {
+	x, y, z fe
+}
+
+// toJac converts an affine point, EC.Zero() being the point at infinity.
+func toJac(p ECPoint) jacPoint {
+	if p.X.Sign() == 0 && p.Y.Sign() == 0 {
+		return jacPoint{}
+	}
+	return jacPoint{x: feFromBig(p.X), y: feFromBig(p.Y), z: fe{1}}
+}
+
+// affine converts the point back to affine coordinates.
+func (p *jacPoint) affine() ECPoint {
+	if p.z.isZero() {
+		return EC.Zero()
+	}
+	P := EC.C.Params().P
+	zinv := new(big.Int).ModInverse(p.z.big(), P)
+	zinv2 := new(big.Int).Mul(zinv, zinv)
+	x := new(big.Int).Mul(p.x.big(), zinv2)
+	y := new(big.Int).Mul(p.y.big(), zinv2.Mul(zinv2, zinv))
+	return ECPoint{x.Mod(x, P), y.Mod(y, P)}
+}
+
+// double sets p to 2p (dbl-2009-l, secp256k1 has a = 0).
+func (p *jacPoint) double() {
+	if p.z.isZero() || p.y.isZero() {
+		*p = jacPoint{}
+		return
+	}
+	a := feMul(p.x, p.x)
+	b := feMul(p.y, p.y)
+	c := feMul(b, b)
+	d := feAdd(p.x, b)
+	d = feSub(feSub(feMul(d, d), a), c)
+	d = feAdd(d, d)
+	e := feAdd(feAdd(a, a), a)
+	f := feMul(e, e)
+
+	z := feMul(p.y, p.z)
+	p.z = feAdd(z, z)
+	p.x = feSub(feSub(f, d), d)
+	c = feAdd(c, c)
+	c = feAdd(c, c)
+	c = feAdd(c, c)
+	p.y = feSub(feMul(e, feSub(d, p.x)), c)
+}
+
+// add sets p to p+q (add-2007-bl).
+func (p *jacPoint) add(q *jacPoint) {
+	if q.z.isZero() {
+		return
+	}
+	if p.z.isZero() {
+		*p = *q
+		return
+	}
+	z1z1 := feMul(p.z, p.z)
+	z2z2 := feMul(q.z, q.z)
+	u1 := feMul(p.x, z2z2)
+	u2 := feMul(q.x, z1z1)
+	s1 := feMul(feMul(p.y, q.z), z2z2)
+	s2 := feMul(feMul(q.y, p.z), z1z1)
+
+	h := feSub(u2, u1)
+	r := feSub(s2, s1)
+	if h.isZero() {
+		if r.isZero() {
+			p.double()
+		} else {
+			*p = jacPoint{}
+		}
+		return
+	}
+	i := feAdd(h, h)
+	i = feMul(i, i)
+	j := feMul(h, i)
+	r = feAdd(r, r)
+	v := feMul(u1, i)
+
+	z := feAdd(p.z, q.z)
+	p.z = feMul(feSub(feSub(feMul(z, z), z1z1), z2z2), h)
+	p.x = feSub(feSub(feSub(feMul(r, r), j), v), v)
+	s1 = feMul(s1, j)
+	p.y = feSub(feMul(r, feSub(v, p.x)), feAdd(s1, s1))
+}
+
+// batchAffineX returns the affine x coordinates of the points, sharing a single
+// field inversion among all of them (Montgomery's trick). The x coordinate of
+// a point at infinity is returned as zero.
+func batchAffineX(ps []jacPoint) []fe {
+	// prods[i] is the product of the z coordinates of ps[:i]
+	prods := make([]fe, len(ps)+1)
+	prods[0] = fe{1}
+	for i := range ps {
+		z := ps[i].z
+		if z.isZero() {
+			z = fe{1}
+		}
+		prods[i+1] = feMul(prods[i], z)
+	}
+	inv := feInv(prods[len(ps)])
+	xs := make([]fe, len(ps))
+	for i := len(ps) - 1; i >= 0; i-- {
+		z := ps[i].z
+		if z.isZero() {
+			continue
+		}
+		zinv := feMul(inv, prods[i])
+		inv = feMul(inv, z)
+		xs[i] = feMul(ps[i].x, feMul(zinv, zinv))
+	}
+	return xs
+}
+
+func feInv(a fe) fe {
+	return feFromBig(new(big.Int).ModInverse(a.big(), EC.C.Params().P))
+}
@@ -0,0 +1,83 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// Keys used to be generated with G1 = v*G for a random v. Whoever created a
+// key therefore knew log_G(G1), together with the private key log_G1(H), and
+// could open the commitments v*G1 + r*H under the key to any value, which for
+// the regulator key breaks the binding of every commitment on chain. G1 is now
+// derived with HashToCurve, so nobody knows its logarithm. G2 stays the base
+// point of secp256k1 since H = X*G2 doubles as the ECDSA public key of Sign.
+
+// GeneratorDomain is the domain separation tag of the generators of keys.
+const GeneratorDomain = "MaskChain generators v1"
+
+var standardG1 = HashToCurve(GeneratorDomain, []byte("G1"))
+
+// HashToCurve maps msg to a point of secp256k1 with unknown discrete logarithm
+// to any other point. Like the BulletProof generators of NewECPrimeGroupKey,
+// sha256(domain || msg || counter) is tried as the x coordinate of a point with
+// even y, incrementing the counter until it is one.
+func HashToCurve(domain string, msg []byte) ECPoint {
+	enc := make([]byte, 33)
+	enc[0] = 2
+	var ctr [4]byte
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h := sha256.New()
+		h.Write([]byte(domain))
+		h.Write([]byte{0})
+		h.Write(msg)
+		h.Write(ctr[:])
+		copy(enc[1:], h.Sum(nil))
+		if p, err := btcec.ParsePubKey(enc, btcec.S256()); err == nil {
+			return ECPoint{p.X, p.Y}
+		}
+	}
+}
+
+// StandardGenerators returns the value generator G1 and the encryption
+// generator G2 of the keys generated by GenerateKeys.
+func StandardGenerators() (g1, g2 ECPoint) {
+	return ECPoint{new(big.Int).Set(standardG1.X), new(big.Int).Set(standardG1.Y)},
+		ECPoint{new(big.Int).Set(EC.C.Params().Gx), new(big.Int).Set(EC.C.Params().Gy)}
+}
+
+// MarshalGenerators returns the uncompressed encodings of the generators, as
+// pinned by the chain configuration.
+func MarshalGenerators(g1, g2 ECPoint) ([]byte, []byte) {
+	return elliptic.Marshal(EC.C, g1.X, g1.Y), elliptic.Marshal(EC.C, g2.X, g2.Y)
+}
+
+// HasGenerators reports whether pub uses the generators g1 and g2 in the
+// encoding of MarshalGenerators.
+func (pub PublicKey) HasGenerators(g1, g2 []byte) bool {
+	if pub.G1 == nil || pub.G2 == nil {
+		return false
+	}
+	return pub.G1.Cmp(new(big.Int).SetBytes(g1)) == 0 && pub.G2.Cmp(new(big.Int).SetBytes(g2)) == 0
+}
+
+// HasStandardGenerators reports whether pub uses the generators of
+// StandardGenerators, i.e. was not generated with a random G1.
+func (pub PublicKey) HasStandardGenerators() bool {
+	return pub.HasGenerators(MarshalGenerators(StandardGenerators()))
+}
+
+// MigrateKey returns the key with the same private scalar over the standard
+// generators. As G2 does not change, neither does H, so the migrated key keeps
+// the identity and the signatures of the old one. Amounts encrypted to the old
+// key have to be decrypted with the old key and transferred to the new one.
+func MigrateKey(priv PrivateKey) PrivateKey {
+	key := PrivKey{X: priv.X}
+	key.G1, key.G2 = StandardGenerators()
+	key.H = key.G2.MultSecret(priv.X)
+	return PrivateKey{RecoverPub(key.PubKey), priv.X}
+}
@@ -0,0 +1,60 @@
+package ecc
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// standardG1Hex pins the value generator of the keys, which the chain
+// configurations of the networks pin as well.
+const standardG1Hex = "04162f325b9d9537507e02ea57d8daee35f20fe01c93de18674088ee908fe1168c83bd5f56fe68ba3aa7e84c884ec35ae287403ca598efed01f2e3eaa057b22f7c"
+
+func TestStandardGenerators(t *testing.T) {
+	g1, g2 := MarshalGenerators(StandardGenerators())
+	if hex.EncodeToString(g1) != standardG1Hex {
+		t.Errorf("G1 mismatch: have %x, want %s", g1, standardG1Hex)
+	}
+	if p, err := DecodePoint(g2); err != nil || p.X.Cmp(EC.C.Params().Gx) != 0 || p.Y.Cmp(EC.C.Params().Gy) != 0 {
+		t.Errorf("G2 is not the base point: %x", g2)
+	}
+	if p := HashToCurve(GeneratorDomain, []byte("G2")); p.Equal(standardG1) || !EC.C.IsOnCurve(p.X, p.Y) {
+		t.Errorf("invalid hash to curve point")
+	}
+	if !HashToCurve("other", []byte("G1")).Equal(HashToCurve("other", []byte("G1"))) {
+		t.Errorf("hash to curve not deterministic")
+	}
+
+	pub, _, _ := GenerateKeys("generators")
+	if !pub.HasStandardGenerators() {
+		t.Errorf("generated key uses other generators")
+	}
+}
+
+func TestMigrateKey(t *testing.T) {
+	// A key of the old kind with a random G1
+	key := PrivKey{X: RandScalar()}
+	key.G1 = EC.G.Mult(RandScalar())
+	_, key.G2 = StandardGenerators()
+	key.H = key.G2.Mult(key.X)
+	old := PrivateKey{RecoverPub(key.PubKey), key.X}
+	if old.HasStandardGenerators() {
+		t.Fatalf("random G1 reported as standard")
+	}
+
+	priv := MigrateKey(old)
+	if !priv.HasStandardGenerators() {
+		t.Errorf("migrated key uses other generators")
+	}
+	if priv.X.Cmp(old.X) != 0 || priv.H.Cmp(old.H) != 0 {
+		t.Errorf("migration changed the key")
+	}
+	SetDLogConfig(DLogConfig{Budget: 1 << 12 * dlogEntrySize})
+	defer SetDLogConfig(DLogConfig{})
+	ct, _, err := EncryptValue(priv.PublicKey, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := DecryptValue(priv, ct); err != nil || v != 42 {
+		t.Errorf("decrypted %d, %v", v, err)
+	}
+}
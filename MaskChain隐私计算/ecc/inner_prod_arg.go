@@ -1,7 +1,6 @@
-package bp
+package ecc
 
 import (
-	"crypto/sha256"
 	"fmt"
 	"math"
 	"math/big"
@@ -55,7 +54,7 @@ Proves that <a,b>=c
 This is a building block for BulletProofs
 
 */
-func InnerProductProveSub(proof InnerProdArg, G, H []ECPoint, a []*big.Int, b []*big.Int, u ECPoint, P ECPoint) InnerProdArg {
+func InnerProductProveSub(t *Transcript, proof InnerProdArg, G, H []ECPoint, a []*big.Int, b []*big.Int, u ECPoint, P ECPoint) InnerProdArg {
 	//fmt.Printf("Proof so far: %s\n", proof)
 	if len(a) == 1 {
 		// Prover sends a & b
@@ -72,18 +71,14 @@ func InnerProductProveSub(proof InnerProdArg, G, H []ECPoint, a []*big.Int, b []
 	//fmt.Println(len(H))
 	cl := InnerProduct(a[:nprime], b[nprime:]) // either this line
 	cr := InnerProduct(a[nprime:], b[:nprime]) // or this line
-	L := TwoVectorPCommitWithGens(G[nprime:], H[:nprime], a[:nprime], b[nprime:]).Add(u.Mult(cl))
-	R := TwoVectorPCommitWithGens(G[:nprime], H[nprime:], a[nprime:], b[:nprime]).Add(u.Mult(cr))
+	L := secretVectorCommit(G[nprime:], H[:nprime], a[:nprime], b[nprime:], u, cl)
+	R := secretVectorCommit(G[:nprime], H[nprime:], a[nprime:], b[:nprime], u, cr)
 
 	proof.L[curIt] = L
 	proof.R[curIt] = R
 
 	// prover sends L & R and gets a challenge
-	s256 := sha256.Sum256([]byte(
-		L.X.String() + L.Y.String() +
-			R.X.String() + R.Y.String()))
-
-	x := new(big.Int).SetBytes(s256[:])
+	x := pointsChallenge(t, "IPA/LR", L, R)
 
 	proof.Challenges[curIt] = x
 
@@ -99,10 +94,11 @@ func InnerProductProveSub(proof InnerProdArg, G, H []ECPoint, a []*big.Int, b []
 		ScalarVectorMul(b[:nprime], xinv),
 		ScalarVectorMul(b[nprime:], x))
 
-	return InnerProductProveSub(proof, Gprime, Hprime, aprime, bprime, u, Pprime)
+	return InnerProductProveSub(t, proof, Gprime, Hprime, aprime, bprime, u, Pprime)
 }
 
-func InnerProductProve(a []*big.Int, b []*big.Int, c *big.Int, P, U ECPoint, G, H []ECPoint) InnerProdArg {
+// InnerProductProve 的挑战由转录t得出，t为nil时为版本1的挑战
+func InnerProductProve(t *Transcript, a []*big.Int, b []*big.Int, c *big.Int, P, U ECPoint, G, H []ECPoint) InnerProdArg {
 	loglen := int(math.Log2(float64(len(a))))
 
 	challenges := make([]*big.Int, loglen+1)
@@ -117,14 +113,14 @@ func InnerProductProve(a []*big.Int, b []*big.Int, c *big.Int, P, U ECPoint, G,
 		challenges}
 
 	// randomly generate an x value from public data
-	x := sha256.Sum256([]byte(P.X.String() + P.Y.String()))
+	x := pointsChallenge(t, "IPA/P", P)
 
-	runningProof.Challenges[loglen] = new(big.Int).SetBytes(x[:])
+	runningProof.Challenges[loglen] = x
 
-	Pprime := P.Add(U.Mult(new(big.Int).Mul(new(big.Int).SetBytes(x[:]), c)))
-	ux := U.Mult(new(big.Int).SetBytes(x[:]))
+	Pprime := P.Add(U.Mult(new(big.Int).Mul(x, c)))
+	ux := U.Mult(x)
 	//fmt.Printf("Prover Pprime value to run sub off of: %s\n", Pprime)
-	return InnerProductProveSub(runningProof, G, H, a, b, ux, Pprime)
+	return InnerProductProveSub(t, runningProof, G, H, a, b, ux, Pprime)
 }
 
 /* Inner Product Verify
@@ -137,11 +133,10 @@ P : the Pedersen commitment we are verifying is a commitment to the innner produ
 ipp : the proof
 
 */
-func InnerProductVerify(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdArg) bool {
+func InnerProductVerify(t *Transcript, c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdArg) bool {
 	//fmt.Println("Verifying Inner Product Argument")
 	//fmt.Printf("Commitment Value: %s \n", P)
-	s1 := sha256.Sum256([]byte(P.X.String() + P.Y.String()))
-	chal1 := new(big.Int).SetBytes(s1[:])
+	chal1 := pointsChallenge(t, "IPA/P", P)
 	ux := U.Mult(chal1)
 	curIt := len(ipp.Challenges) - 1
 
@@ -162,11 +157,7 @@ func InnerProductVerify(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdA
 		Rval := ipp.R[curIt]
 
 		// prover sends L & R and gets a challenge
-		s256 := sha256.Sum256([]byte(
-			Lval.X.String() + Lval.Y.String() +
-				Rval.X.String() + Rval.Y.String()))
-
-		chal2 := new(big.Int).SetBytes(s256[:])
+		chal2 := pointsChallenge(t, "IPA/LR", Lval, Rval)
 
 		if ipp.Challenges[curIt].Cmp(chal2) != 0 {
 			fmt.Println("IPVerify - Challenge verification failed at index " + strconv.Itoa(curIt))
@@ -198,11 +189,10 @@ Given a inner product proof, verifies the correctness of the proof. Does the sam
 we replace n separate exponentiations with a single multi-exponentiation.
 */
 
-func InnerProductVerifyFast(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdArg) bool {
+func InnerProductVerifyFast(t *Transcript, c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdArg) bool {
 	//fmt.Println("Verifying Inner Product Argument")
 	//fmt.Printf("Commitment Value: %s \n", P)
-	s1 := sha256.Sum256([]byte(P.X.String() + P.Y.String()))
-	chal1 := new(big.Int).SetBytes(s1[:])
+	chal1 := pointsChallenge(t, "IPA/P", P)
 	ux := U.Mult(chal1)
 	curIt := len(ipp.Challenges) - 1
 
@@ -217,11 +207,7 @@ func InnerProductVerifyFast(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerP
 		Rval := ipp.R[j]
 
 		// prover sends L & R and gets a challenge
-		s256 := sha256.Sum256([]byte(
-			Lval.X.String() + Lval.Y.String() +
-				Rval.X.String() + Rval.Y.String()))
-
-		chal2 := new(big.Int).SetBytes(s256[:])
+		chal2 := pointsChallenge(t, "IPA/LR", Lval, Rval)
 
 		if ipp.Challenges[j].Cmp(chal2) != 0 {
 			fmt.Println("IPVerify - Challenge verification failed at index " + strconv.Itoa(j))
@@ -274,3 +260,13 @@ func InnerProductVerifyFast(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerP
 
 	return true
 }
+
+// innerProductChallenges 返回证明ipp在转录t下的全部挑战，顺序与InnerProdArg.Challenges相同
+func innerProductChallenges(t *Transcript, P ECPoint, ipp InnerProdArg) []*big.Int {
+	challenges := make([]*big.Int, len(ipp.L)+1)
+	challenges[len(ipp.L)] = pointsChallenge(t, "IPA/P", P)
+	for j := len(ipp.L) - 1; j >= 0; j-- {
+		challenges[j] = pointsChallenge(t, "IPA/LR", ipp.L[j], ipp.R[j])
+	}
+	return challenges
+}
@@ -1,4 +1,4 @@
-package bp
+package ecc
 
 import (
 	"fmt"
@@ -20,9 +20,9 @@ func TestInnerProductProveLen1(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerify(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerify(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -45,9 +45,9 @@ func TestInnerProductProveLen2(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerify(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerify(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -74,9 +74,9 @@ func TestInnerProductProveLen4(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerify(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerify(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -111,9 +111,9 @@ func TestInnerProductProveLen8(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerify(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerify(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -130,9 +130,9 @@ func TestInnerProductProveLen64Rand(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerify(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerify(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -155,9 +155,9 @@ func TestInnerProductVerifyFastLen1(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerifyFast(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerifyFast(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -180,9 +180,9 @@ func TestInnerProductVerifyFastLen2(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerifyFast(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerifyFast(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -209,9 +209,9 @@ func TestInnerProductVerifyFastLen4(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerifyFast(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerifyFast(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -246,9 +246,9 @@ func TestInnerProductVerifyFastLen8(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerifyFast(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerifyFast(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
@@ -265,9 +265,9 @@ func TestInnerProductVerifyFastLen64Rand(t *testing.T) {
 
 	P := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, a, b)
 
-	ipp := InnerProductProve(a, b, c, P, EC.U, EC.BPG, EC.BPH)
+	ipp := InnerProductProve(NewTranscript("IPA"), a, b, c, P, EC.U, EC.BPG, EC.BPH)
 
-	if InnerProductVerifyFast(c, P, EC.U, EC.BPG, EC.BPH, ipp) {
+	if InnerProductVerifyFast(NewTranscript("IPA"), c, P, EC.U, EC.BPG, EC.BPH, ipp) {
 		fmt.Println("Inner Product Proof correct")
 	} else {
 		t.Error("Inner Product Proof incorrect")
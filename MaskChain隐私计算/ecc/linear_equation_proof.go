@@ -0,0 +1,219 @@
+package ecc
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+type LEP struct {
+	Y ECPoint
+	T ECPoint
+	An []*big.Int
+	B *big.Int
+	Sn []*big.Int
+	C Hash
+}
+
+// Linear_equation_proof 证明sum(an[i]*xn[i]) = b (mod N)
+func Linear_equation_proof (gn []ECPoint, xn []*big.Int, an []*big.Int, b *big.Int) LEP{
+
+	lepResult := LEP{}
+
+	if len(gn)!=len(xn) || len(gn)!= len(an) || len(xn)!= len(an) {
+		panic("Generate LEP Failed: Array length error")
+	}
+
+	lepResult.An = an
+	lepResult.B = b
+	n := len(gn)
+
+
+	tempy := SecretMultiScalarMult(gn, xn)
+	lepResult.Y = tempy
+
+	vn := lepNonces(an)
+	t := SecretMultiScalarMult(gn, vn)
+	lepResult.T = t
+
+	c := lepChallenge(NewTranscript("LEP"), gn, tempy, t)
+	lepResult.C = c
+	intc := new(big.Int).SetBytes(c[:])
+
+	sn := []*big.Int{}
+	for i:=0;i<n;i++{
+		cx :=  new(big.Int).Mul(intc, xn[i])
+		sni := new(big.Int).Sub(vn[i], cx)
+		sn = append(sn, sni.Mod(sni, EC.N))
+		ZeroizeInt(cx)
+	}
+	lepResult.Sn = sn
+	ZeroizeInt(vn...)
+
+	return  lepResult
+}
+
+
+
+func LepVerify(lep LEP, Gn []ECPoint) bool{
+
+	c := lepChallenge(NewTranscript("LEP"), Gn, lep.Y, lep.T)
+	if c!= lep.C{
+		fmt.Println("lep failed: c wrong")
+		return  false
+	}
+
+	intc := new(big.Int).SetBytes(c[:])
+	n := len(Gn)
+	gisi := Gn[0].Mult(lep.Sn[0])
+	for i:=1;i<n;i++{
+		gisi = gisi.Add(Gn[i].Mult(lep.Sn[i]))
+	}
+	tempT := lep.Y.Mult(intc).Add(gisi)
+	if !tempT.Equal(lep.T){
+		fmt.Println("lep failed: t wrong")
+		return false
+	}
+
+	aisi := new(big.Int).Mul(lep.An[0], lep.Sn[0])
+	for i:=1;i<n;i++{
+		aisi = new(big.Int).Add(aisi,new(big.Int).Mul(lep.An[i], lep.Sn[i]))
+	}
+	aisi.Add(aisi, new(big.Int).Mul(intc, lep.B))
+	if aisi.Mod(aisi, EC.N).Sign()!=0{
+		fmt.Println("lep failed: -cb wrong")
+		return false
+	}
+
+	return true
+}
+
+type LEP_tx struct {
+	Y ECPoint
+	T ECPoint
+	Sn []*big.Int
+	C Hash
+}
+
+// Linear_equation_proof_tx 证明sum(an[i]*xn[i]) = 0 (mod N)，挑战由转录tr得出，tr为nil时为版本1的挑战
+func Linear_equation_proof_tx (tr *Transcript, gn []ECPoint, xn []*big.Int, an []*big.Int) LEP_tx{
+
+	lepResult := LEP_tx{}
+
+	if len(gn)!=len(xn) || len(gn)!= len(an) || len(xn)!= len(an) {
+		panic("Generate LEP Failed: Array length error")
+	}
+
+	n := len(gn)
+
+	tempy := SecretMultiScalarMult(gn, xn)
+	lepResult.Y = tempy
+
+	vn := lepNonces(an)
+	t := SecretMultiScalarMult(gn, vn)
+	lepResult.T = t
+
+	c := lepChallenge(tr, gn, tempy, t)
+	lepResult.C = c
+	intc := new(big.Int).SetBytes(c[:])
+
+	sn := []*big.Int{}
+	for i:=0;i<n;i++{
+		cx :=  new(big.Int).Mul(intc, xn[i])
+		sni := new(big.Int).Sub(cx, vn[i])
+		sn = append(sn, sni.Mod(sni, EC.N))
+		ZeroizeInt(cx)
+	}
+	lepResult.Sn = sn
+	ZeroizeInt(vn...)
+
+	return  lepResult
+}
+
+func LepVerify_tx(tr *Transcript, lep LEP_tx, Gn []ECPoint) bool{
+	return LepVerify_txn(tr, lep, Gn, []*big.Int{big.NewInt(-1), big.NewInt(1), big.NewInt(1)})
+}
+
+// LepVerify_txn verifies a proof generated by Linear_equation_proof_tx with
+// the coefficients an, i.e. that sum(an[i]*xn[i]) = 0 modulo N. The proof is not
+// modified, tr must be the transcript the proof was generated against.
+func LepVerify_txn(tr *Transcript, lep LEP_tx, Gn []ECPoint, an []*big.Int) bool{
+	n := len(Gn)
+	if n == 0 || len(lep.Sn) != n || len(an) != n {
+		fmt.Println("lep failed: length wrong")
+		return false
+	}
+	c := lepChallenge(tr, Gn, lep.Y, lep.T)
+	if c!= lep.C{
+		fmt.Println("lep failed: c wrong")
+		return  false
+	}
+
+	intc := new(big.Int).SetBytes(c[:])
+	sn := make([]*big.Int, n)
+	for i:=0;i<n;i++{
+		sn[i] = new(big.Int).Sub(EC.N, lep.Sn[i])
+	}
+	gisi := Gn[0].Mult(sn[0])
+	for i:=1;i<n;i++{
+		gisi = gisi.Add(Gn[i].Mult(sn[i]))
+	}
+	tempT := lep.Y.Mult(intc).Add(gisi)
+	if !tempT.Equal(lep.T){
+		fmt.Println("lep failed: t wrong")
+		return false
+	}
+
+	aisi := new(big.Int).Mul(an[0], sn[0])
+	for i:=1;i<n;i++{
+		aisi = new(big.Int).Add(aisi,new(big.Int).Mul(an[i], sn[i]))
+	}
+	if aisi.Mod(aisi, EC.N).Sign()!=0{
+		fmt.Println("lep failed: -cb wrong")
+		return false
+	}
+
+	return true
+}
+
+// lepNonces 返回满足sum(an[i]*vn[i]) = 0 (mod N)的随机数vn。除第一个可逆系数
+// 对应的vn[k]由方程解出外，其余均为均匀随机的标量，因此响应不泄露xn。
+// 早期版本取[-500, 500)内的math/rand随机数并要求等式在整数上成立，响应未经
+// 约简，可直接由sn/c估出xn；按模N的等式验证对这些旧证明仍然成立。
+func lepNonces(an []*big.Int) []*big.Int {
+	vn := make([]*big.Int, len(an))
+	k := -1
+	sum := new(big.Int)
+	for i, a := range an {
+		if k < 0 && new(big.Int).Mod(a, EC.N).Sign() != 0 {
+			k = i
+			continue
+		}
+		vn[i] = RandScalar()
+		sum.Add(sum, new(big.Int).Mul(a, vn[i]))
+	}
+	if k < 0 {
+		return vn
+	}
+	inv := new(big.Int).ModInverse(new(big.Int).Mod(an[k], EC.N), EC.N)
+	vn[k] = sum.Neg(sum).Mul(sum, inv)
+	vn[k].Mod(vn[k], EC.N)
+	return vn
+}
+
+// lepChallenge 计算线性方程证明的挑战。tr为nil时为版本1中各点十进制字符串的哈希，
+// 否则将生成元、y和t依次加入转录后得出挑战
+func lepChallenge(tr *Transcript, gn []ECPoint, y, t ECPoint) Hash {
+	if tr == nil {
+		var gnString string
+		for i:=0;i<len(gn);i++{
+			gnString = gnString + gn[i].X.String() + gn[i].Y.String()
+		}
+		return sha256.Sum256([]byte(gnString+y.X.String()+y.Y.String()+t.X.String()+t.Y.String()))
+	}
+	tr.AppendMessage("proof", []byte("LEP"))
+	tr.AppendPoints("G", gn)
+	tr.AppendPoint("Y", y)
+	tr.AppendPoint("T", t)
+	return hashChallenge(tr.Challenge("c"))
+}
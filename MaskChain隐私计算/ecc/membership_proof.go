@@ -0,0 +1,419 @@
+package ecc
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// A membership proof shows that a shielded spend consumes one member of a ring
+// of commitments C_0..C_{n-1}, e.g. the leaves of one subtree of the
+// accumulator, without revealing which one. It is the one-out-of-many proof of
+// Groth and Kohlweiss (https://eprint.iacr.org/2014/764) with the linking tag
+// of Triptych (https://eprint.iacr.org/2020/018):
+//
+// Every member C_i = v*G1 + r*H comes with the one-time spend key K_i = sk*G2
+// of its owner (see EncryptBlindKey). The owner publishes a pseudo commitment
+// C' = v*G1 + r'*H to the same value, which takes the place of C_l in the
+// balance proof, and the nullifier J = sk^-1*U for a generator U of unknown
+// logarithm. J depends on the spend secret of C_l alone, so spending C_l twice
+// reveals the same nullifier, while nobody without sk, not even the creator of
+// C_l who knows r, can compute it or tell which commitment it belongs to.
+//
+// For the index l committed to bit by bit, the proof shows K_l = sk*G2 and
+// sk*J = U with the same sk, and C_l - C' = s*H, i.e. that C' hides the value
+// of C_l. It has O(log n) elements; verifying it takes one multi-scalar
+// multiplication over the ring.
+//
+// Empty positions of the ring, i.e. leaves not created yet, have neither a
+// commitment nor a key.
+
+var (
+	errRingSize       = errors.New("ring size is not a power of two between 2 and RingSize")
+	errRingIndex      = errors.New("spent commitment is not in the ring")
+	errRingKeys       = errors.New("spend keys do not match the ring")
+	errMembershipBits = errors.New("membership proof does not match the ring size")
+	errZeroSecret     = errors.New("zero spend secret")
+)
+
+var (
+	// nullifierBase is the generator U of the nullifiers.
+	nullifierBase = HashToCurve(GeneratorDomain, []byte("Nullifier"))
+
+	// ringBitG and ringBitH commit to the bits of the index of the spent
+	// member.
+	ringBitG = HashToCurve(GeneratorDomain, []byte("RingBit/G"))
+	ringBitH = HashToCurve(GeneratorDomain, []byte("RingBit/H"))
+)
+
+// MembershipProof is the proof that a pseudo commitment hides the value of a
+// member of a ring whose nullifier is revealed. All lists have one element
+// per bit of the member index.
+type MembershipProof struct {
+	Cl, Ca, Cb []ECPoint // Commitments to the index bits l_j, the masks a_j and l_j*a_j
+	X, Y, Z    []ECPoint // Commitments to the lower coefficients of the member polynomials over the keys, J and the commitments
+	F, Za, Zb  []*big.Int
+	Zk, Zs     *big.Int // Responses for the spend secret sk and the blinding difference s
+}
+
+// ringBits returns log2 of the size of a ring.
+func ringBits(n int) (int, bool) {
+	if n < 2 || n > RingSize || n&(n-1) != 0 {
+		return 0, false
+	}
+	m := 0
+	for 1<<uint(m) < n {
+		m++
+	}
+	return m, true
+}
+
+// Nullifier returns the nullifier revealed by a shielded spend of a commitment
+// whose spend key has the secret sk (see SpendKey), e.g. for a wallet to find
+// out which of its coins are spent.
+func Nullifier(sk []byte) ([]byte, error) {
+	x := new(big.Int).Mod(new(big.Int).SetBytes(sk), EC.N)
+	defer ZeroizeInt(x)
+	if x.Sign() == 0 {
+		return nil, errZeroSecret
+	}
+	xinv := new(big.Int).ModInverse(x, EC.N)
+	defer ZeroizeInt(xinv)
+	J := nullifierBase.MultSecret(xinv)
+	return elliptic.Marshal(EC.C, J.X, J.Y), nil
+}
+
+// decodeRing decodes the commitments and spend keys of the members of a ring,
+// leaving empty positions without coordinates. A member has either both or
+// neither.
+func decodeRing(ring, keys [][]byte) (cms, pks []ECPoint, err error) {
+	if len(keys) != len(ring) {
+		return nil, nil, errRingKeys
+	}
+	cms, pks = make([]ECPoint, len(ring)), make([]ECPoint, len(ring))
+	for i := range ring {
+		if len(ring[i]) == 0 && len(keys[i]) == 0 {
+			continue
+		}
+		if cms[i], err = decodeField(fmt.Sprintf("Ring[%d]", i), ring[i]); err != nil {
+			return nil, nil, err
+		}
+		if pks[i], err = decodeField(fmt.Sprintf("Keys[%d]", i), keys[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return cms, pks, nil
+}
+
+// membershipTranscript forks the transcript of a membership proof and binds it
+// to the generators, the ring, the pseudo commitment and the nullifier. A nil
+// transcript starts a new one, there is no version 1 of the proof.
+func membershipTranscript(t *Transcript, g2, h ECPoint, ring, keys [][]byte, pseudo, nullifier []byte) *Transcript {
+	if t = t.Fork("MembershipProof"); t == nil {
+		t = NewTranscript("MembershipProof")
+	}
+	t.AppendPoint("G2", g2)
+	t.AppendPoint("H", h)
+	for i := range ring {
+		t.AppendMessage("member", ring[i])
+		t.AppendMessage("key", keys[i])
+	}
+	t.AppendMessage("pseudo", pseudo)
+	t.AppendMessage("nullifier", nullifier)
+	return t
+}
+
+// membershipChallenge appends the commitments of a membership proof to t and
+// squeezes the challenge x.
+func membershipChallenge(t *Transcript, mp MembershipProof) *big.Int {
+	t.AppendPoints("Cl", mp.Cl)
+	t.AppendPoints("Ca", mp.Ca)
+	t.AppendPoints("Cb", mp.Cb)
+	t.AppendPoints("X", mp.X)
+	t.AppendPoints("Y", mp.Y)
+	t.AppendPoints("Z", mp.Z)
+	return t.Challenge("x")
+}
+
+// memberPolys returns the coefficients p[i][k] of x^k of the polynomials
+// p_i(x) = prod_j f_{j,i_j}(x), where f_{j,1}(x) = l_j*x + a_j and f_{j,0}(x)
+// = x - f_{j,1}(x). p_l has degree m, all others degree below m.
+func memberPolys(n int, l, a []*big.Int) [][]*big.Int {
+	m := len(l)
+	p := make([][]*big.Int, n)
+	for i := range p {
+		p[i] = make([]*big.Int, m+1)
+		p[i][0] = big.NewInt(1)
+		for k := 1; k <= m; k++ {
+			p[i][k] = new(big.Int)
+		}
+		for j := 0; j < m; j++ {
+			// f = c0 + c1*x
+			c0, c1 := new(big.Int).Set(a[j]), new(big.Int).Set(l[j])
+			if i>>uint(j)&1 == 0 {
+				c0.Neg(c0)
+				c1.Sub(big.NewInt(1), c1)
+			}
+			for k := m; k >= 0; k-- {
+				p[i][k].Mul(p[i][k], c0)
+				if k > 0 {
+					p[i][k].Add(p[i][k], new(big.Int).Mul(p[i][k-1], c1))
+				}
+				p[i][k].Mod(p[i][k], EC.N)
+			}
+			ZeroizeInt(c0, c1)
+		}
+	}
+	return p
+}
+
+// GenerateMembershipProof proves that the pseudo commitment hides the value of
+// the commitment spent, which is ring[index] with the spend key keys[index]
+// whose secret is sk, and returns the nullifier of spent. Positions of the ring
+// without a commitment are empty in both ring and keys. Both commitments must
+// come with their blinding factors.
+func GenerateMembershipProof(t *Transcript, pub PublicKey, ring, keys [][]byte, index int, spent, pseudo Commitment, sk []byte) (mp MembershipProof, nullifier []byte, err error) {
+	m, ok := ringBits(len(ring))
+	if !ok {
+		return MembershipProof{}, nil, errRingSize
+	}
+	if index < 0 || index >= len(ring) || len(spent.Commitment) == 0 || !bytes.Equal(ring[index], spent.Commitment) {
+		return MembershipProof{}, nil, errRingIndex
+	}
+	members, pks, err := decodeRing(ring, keys)
+	if err != nil {
+		return MembershipProof{}, nil, err
+	}
+	if _, err := decodeField("Pseudo", pseudo.Commitment); err != nil {
+		return MembershipProof{}, nil, err
+	}
+	if nullifier, err = Nullifier(sk); err != nil {
+		return MembershipProof{}, nil, err
+	}
+	J, _ := DecodePoint(nullifier)
+
+	var (
+		_, g2 = StandardGenerators()
+		h     = ConvertPub(pub).H
+		w     = new(big.Int).Mod(new(big.Int).SetBytes(sk), EC.N)
+		r     = new(big.Int).Mod(new(big.Int).SetBytes(spent.R), EC.N)
+		rp    = new(big.Int).Mod(new(big.Int).SetBytes(pseudo.R), EC.N)
+		s     = new(big.Int).Sub(r, rp)
+	)
+	s.Mod(s, EC.N)
+	t = membershipTranscript(t, g2, h, ring, keys, pseudo.Commitment, nullifier)
+
+	// 逐位承诺被花费成员的下标
+	l, a := make([]*big.Int, m), make([]*big.Int, m)
+	rl, ra, rb := make([]*big.Int, m), make([]*big.Int, m), make([]*big.Int, m)
+	for j := 0; j < m; j++ {
+		l[j] = big.NewInt(int64(index >> uint(j) & 1))
+		a[j], rl[j], ra[j], rb[j] = RandScalar(), RandScalar(), RandScalar(), RandScalar()
+		la := new(big.Int).Mul(l[j], a[j])
+		mp.Cl = append(mp.Cl, SecretMultiScalarMult([]ECPoint{ringBitG, ringBitH}, []*big.Int{l[j], rl[j]}))
+		mp.Ca = append(mp.Ca, SecretMultiScalarMult([]ECPoint{ringBitG, ringBitH}, []*big.Int{a[j], ra[j]}))
+		mp.Cb = append(mp.Cb, SecretMultiScalarMult([]ECPoint{ringBitG, ringBitH}, []*big.Int{la, rb[j]}))
+		ZeroizeInt(la)
+	}
+
+	// X_k = sum(p[i][k]*K_i) + rho_k*G2, Y_k = rho_k*J and Z_k =
+	// sum(p[i][k]*C_i) + sigma_k*H, with the same rho_k in X_k and Y_k
+	p := memberPolys(len(ring), l, a)
+	rho, sigma := make([]*big.Int, m), make([]*big.Int, m)
+	for k := 0; k < m; k++ {
+		rho[k], sigma[k] = RandScalar(), RandScalar()
+		kpoints, kscalars := []ECPoint{g2}, []*big.Int{rho[k]}
+		cpoints, cscalars := []ECPoint{h}, []*big.Int{sigma[k]}
+		for i, member := range members {
+			if member.X != nil {
+				kpoints, kscalars = append(kpoints, pks[i]), append(kscalars, p[i][k])
+				cpoints, cscalars = append(cpoints, member), append(cscalars, p[i][k])
+			}
+		}
+		mp.X = append(mp.X, SecretMultiScalarMult(kpoints, kscalars))
+		mp.Y = append(mp.Y, J.MultSecret(rho[k]))
+		mp.Z = append(mp.Z, SecretMultiScalarMult(cpoints, cscalars))
+	}
+	x := membershipChallenge(t, mp)
+
+	for j := 0; j < m; j++ {
+		f := new(big.Int).Mul(l[j], x)
+		f.Add(f, a[j]).Mod(f, EC.N)
+		za := new(big.Int).Mul(rl[j], x)
+		za.Add(za, ra[j]).Mod(za, EC.N)
+		zb := new(big.Int).Mul(rl[j], new(big.Int).Sub(x, f))
+		zb.Add(zb, rb[j]).Mod(zb, EC.N)
+		mp.F, mp.Za, mp.Zb = append(mp.F, f), append(mp.Za, za), append(mp.Zb, zb)
+	}
+	xm := new(big.Int).Exp(x, big.NewInt(int64(m)), EC.N)
+	mp.Zk = new(big.Int).Mul(w, xm)
+	mp.Zs = new(big.Int).Mul(s, xm)
+	xk := big.NewInt(1)
+	for k := 0; k < m; k++ {
+		mp.Zk.Sub(mp.Zk, new(big.Int).Mul(rho[k], xk))
+		mp.Zs.Sub(mp.Zs, new(big.Int).Mul(sigma[k], xk))
+		xk.Mul(xk, x).Mod(xk, EC.N)
+	}
+	mp.Zk.Mod(mp.Zk, EC.N)
+	mp.Zs.Mod(mp.Zs, EC.N)
+
+	ZeroizeInt(w, r, rp, s)
+	for j := 0; j < m; j++ {
+		ZeroizeInt(l[j], a[j], rl[j], ra[j], rb[j], rho[j], sigma[j])
+	}
+	for i := range p {
+		ZeroizeInt(p[i]...)
+	}
+	return mp, nullifier, nil
+}
+
+// VerifyMembershipProof verifies that the pseudo commitment hides the value
+// of a member of the ring under pub and that nullifier is the nullifier of
+// the spend key of that member. t must be the transcript the proof was
+// generated against. An error is returned if a point cannot be decoded or the
+// proof does not fit the ring, false without error if the proof is invalid.
+func VerifyMembershipProof(t *Transcript, pub PublicKey, ring, keys [][]byte, pseudo, nullifier []byte, mp MembershipProof) (bool, error) {
+	b := NewBatchVerifier()
+	if ok, err := b.VerifyMembershipProof(t, pub, ring, keys, pseudo, nullifier, mp); !ok || err != nil {
+		return ok, err
+	}
+	return b.Verify(), nil
+}
+
+// VerifyMembershipProof is the batched VerifyMembershipProof.
+func (b *BatchVerifier) VerifyMembershipProof(t *Transcript, pub PublicKey, ring, keys [][]byte, pseudo, nullifier []byte, mp MembershipProof) (bool, error) {
+	if b == nil {
+		return VerifyMembershipProof(t, pub, ring, keys, pseudo, nullifier, mp)
+	}
+	m, ok := ringBits(len(ring))
+	if !ok {
+		return false, errRingSize
+	}
+	for _, n := range []struct {
+		field string
+		len   int
+	}{{"Cl", len(mp.Cl)}, {"Ca", len(mp.Ca)}, {"Cb", len(mp.Cb)}, {"X", len(mp.X)}, {"Y", len(mp.Y)}, {"Z", len(mp.Z)}, {"F", len(mp.F)}, {"Za", len(mp.Za)}, {"Zb", len(mp.Zb)}} {
+		if n.len != m {
+			return false, &FieldError{n.field, errMembershipBits}
+		}
+	}
+	if mp.Zk == nil || mp.Zs == nil {
+		return false, &FieldError{"Zk", ErrInvalidEncoding}
+	}
+	members, pks, err := decodeRing(ring, keys)
+	if err != nil {
+		return false, err
+	}
+	cp, err := decodeField("Pseudo", pseudo)
+	if err != nil {
+		return false, err
+	}
+	J, err := decodeField("Nullifier", nullifier)
+	if err != nil {
+		return false, err
+	}
+	_, g2 := StandardGenerators()
+	h := ConvertPub(pub).H
+	if h.X == nil {
+		return false, nil
+	}
+	t = membershipTranscript(t, g2, h, ring, keys, pseudo, nullifier)
+	x := membershipChallenge(t, mp)
+
+	// x*Cl_j + Ca_j = F_j*G' + Za_j*H' and (x - F_j)*Cl_j + Cb_j = Zb_j*H',
+	// i.e. the l_j are bits
+	one := big.NewInt(1)
+	for j := 0; j < m; j++ {
+		xf := new(big.Int).Sub(x, mp.F[j])
+		b.add([]ECPoint{mp.Cl[j], mp.Ca[j], ringBitG, ringBitH}, []*big.Int{x, one, new(big.Int).Neg(mp.F[j]), new(big.Int).Neg(mp.Za[j])})
+		b.add([]ECPoint{mp.Cl[j], mp.Cb[j], ringBitH}, []*big.Int{xf, one, new(big.Int).Neg(mp.Zb[j])})
+	}
+
+	// sum(p_i(x)*K_i) - sum(x^k*X_k) = Zk*G2, so K_l = sk*G2, and
+	// sum(p_i(x)*C_i) - x^m*C' - sum(x^k*Z_k) = Zs*H, so C_l - C' = s*H
+	var (
+		xm      = new(big.Int).Exp(x, big.NewInt(int64(m)), EC.N)
+		xk      = make([]*big.Int, m)
+		kpoints = []ECPoint{g2}
+		kscal   = []*big.Int{new(big.Int).Neg(mp.Zk)}
+		cpoints = []ECPoint{cp, h}
+		cscal   = []*big.Int{new(big.Int).Neg(xm), new(big.Int).Neg(mp.Zs)}
+	)
+	for k := range xk {
+		xk[k] = new(big.Int).Exp(x, big.NewInt(int64(k)), EC.N)
+	}
+	for i, member := range members {
+		if member.X == nil {
+			continue
+		}
+		pi := big.NewInt(1)
+		for j := 0; j < m; j++ {
+			if i>>uint(j)&1 == 1 {
+				pi.Mul(pi, mp.F[j])
+			} else {
+				pi.Mul(pi, new(big.Int).Sub(x, mp.F[j]))
+			}
+			pi.Mod(pi, EC.N)
+		}
+		kpoints, kscal = append(kpoints, pks[i]), append(kscal, pi)
+		cpoints, cscal = append(cpoints, member), append(cscal, pi)
+	}
+	for k := 0; k < m; k++ {
+		kpoints, kscal = append(kpoints, mp.X[k]), append(kscal, new(big.Int).Neg(xk[k]))
+		cpoints, cscal = append(cpoints, mp.Z[k]), append(cscal, new(big.Int).Neg(xk[k]))
+	}
+	b.add(kpoints, kscal)
+	b.add(cpoints, cscal)
+
+	// x^m*U - sum(x^k*Y_k) = Zk*J ties the nullifier to the secret of K_l
+	points := []ECPoint{nullifierBase, J}
+	scal := []*big.Int{xm, new(big.Int).Neg(mp.Zk)}
+	for k := 0; k < m; k++ {
+		points, scal = append(points, mp.Y[k]), append(scal, new(big.Int).Neg(xk[k]))
+	}
+	b.add(points, scal)
+	return true, nil
+}
+
+// MarshalBinary encodes the proof as len(Cl) || (Cl || Ca || Cb || X || Y ||
+// Z || F || Za || Zb)... || Zk || Zs.
+func (mp MembershipProof) MarshalBinary() ([]byte, error) {
+	m := len(mp.Cl)
+	for _, n := range []int{len(mp.Ca), len(mp.Cb), len(mp.X), len(mp.Y), len(mp.Z), len(mp.F), len(mp.Za), len(mp.Zb)} {
+		if n != m {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, errMembershipBits)
+		}
+	}
+	return marshal(KindMembershipProof, func(e *encoder) {
+		e.count(m)
+		for j := 0; j < m; j++ {
+			for _, p := range []ECPoint{mp.Cl[j], mp.Ca[j], mp.Cb[j], mp.X[j], mp.Y[j], mp.Z[j]} {
+				e.point(p)
+			}
+			for _, s := range []*big.Int{mp.F[j], mp.Za[j], mp.Zb[j]} {
+				e.scalar(s)
+			}
+		}
+		e.scalar(mp.Zk)
+		e.scalar(mp.Zs)
+	})
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (mp *MembershipProof) UnmarshalBinary(data []byte) error {
+	var p MembershipProof
+	if err := unmarshal(data, KindMembershipProof, func(d *decoder) {
+		for m := d.count(); m > 0 && d.err == nil; m-- {
+			p.Cl, p.Ca, p.Cb = append(p.Cl, d.point()), append(p.Ca, d.point()), append(p.Cb, d.point())
+			p.X, p.Y, p.Z = append(p.X, d.point()), append(p.Y, d.point()), append(p.Z, d.point())
+			p.F, p.Za, p.Zb = append(p.F, d.scalar()), append(p.Za, d.scalar()), append(p.Zb, d.scalar())
+		}
+		p.Zk, p.Zs = d.scalar(), d.scalar()
+	}); err != nil {
+		return err
+	}
+	*mp = p
+	return nil
+}
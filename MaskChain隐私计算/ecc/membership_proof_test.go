@@ -0,0 +1,171 @@
+package ecc
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// membershipRing returns a ring of n positions of which the first filled are
+// commitments to the values v, v+1, ... under pub, together with their spend
+// keys for owner, their blinding factors and the secrets of their spend keys.
+func membershipRing(pub PublicKey, owner PrivateKey, n, filled int, v uint64) (ring, keys [][]byte, cms []Commitment, sks [][]byte) {
+	ring, keys = make([][]byte, n), make([][]byte, n)
+	cms, sks = make([]Commitment, filled), make([][]byte, filled)
+	for i := 0; i < filled; i++ {
+		_, cms[i], _ = EncryptValue(pub, v+uint64(i))
+		C, key, _ := EncryptBlindKey(owner.PublicKey, cms[i].R)
+		sks[i], _ = SpendKey(owner, C, key)
+		ring[i], keys[i] = cms[i].Commitment, key
+	}
+	return ring, keys, cms, sks
+}
+
+func TestMembershipProof(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	_, owner, _ := GenerateKeys("owner")
+	ring, keys, cms, sks := membershipRing(pub, owner, 8, 6, 20)
+	const index = 5
+	v := uint64(20 + index)
+	_, pseudo, _ := EncryptValue(pub, v)
+
+	tr := TxTranscript(big.NewInt(1), 4, pseudo.Commitment)
+	mp, nullifier, err := GenerateMembershipProof(tr.Clone(), pub, ring, keys, index, cms[index], pseudo, sks[index])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := Nullifier(sks[index]); !bytes.Equal(nullifier, want) {
+		t.Fatalf("nullifier mismatch")
+	}
+	if !valid(VerifyMembershipProof(tr.Clone(), pub, ring, keys, pseudo.Commitment, nullifier, mp)) {
+		t.Fatalf("membership proof rejected")
+	}
+	// A second spend of the same commitment reveals the same nullifier
+	_, pseudo2, _ := EncryptValue(pub, v)
+	if _, nullifier2, _ := GenerateMembershipProof(tr.Clone(), pub, ring, keys, index, cms[index], pseudo2, sks[index]); !bytes.Equal(nullifier, nullifier2) {
+		t.Errorf("nullifier changed between spends")
+	}
+	if other, _ := Nullifier(sks[0]); bytes.Equal(nullifier, other) {
+		t.Errorf("nullifiers of different commitments collide")
+	}
+
+	// Another transcript, ring, key, pseudo commitment or nullifier
+	if valid(VerifyMembershipProof(TxTranscript(big.NewInt(2), 4, pseudo.Commitment), pub, ring, keys, pseudo.Commitment, nullifier, mp)) {
+		t.Errorf("proof of another transaction accepted")
+	}
+	moved, movedKeys := append([][]byte{}, ring...), append([][]byte{}, keys...)
+	moved[index], moved[6] = nil, ring[index]
+	movedKeys[index], movedKeys[6] = nil, keys[index]
+	if valid(VerifyMembershipProof(tr.Clone(), pub, moved, movedKeys, pseudo.Commitment, nullifier, mp)) {
+		t.Errorf("proof for another ring accepted")
+	}
+	swapped := append([][]byte{}, keys...)
+	swapped[index], swapped[0] = keys[0], keys[index]
+	if valid(VerifyMembershipProof(tr.Clone(), pub, ring, swapped, pseudo.Commitment, nullifier, mp)) {
+		t.Errorf("proof for other spend keys accepted")
+	}
+	if valid(VerifyMembershipProof(tr.Clone(), pub, ring, keys, pseudo2.Commitment, nullifier, mp)) {
+		t.Errorf("proof for another pseudo commitment accepted")
+	}
+	other, _ := Nullifier(RandScalar().Bytes())
+	if valid(VerifyMembershipProof(tr.Clone(), pub, ring, keys, pseudo.Commitment, other, mp)) {
+		t.Errorf("proof with another nullifier accepted")
+	}
+
+	// A pseudo commitment to another value
+	_, wrong, _ := EncryptValue(pub, v+1)
+	mp, nullifier, err = GenerateMembershipProof(tr.Clone(), pub, ring, keys, index, cms[index], wrong, sks[index])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid(VerifyMembershipProof(tr.Clone(), pub, ring, keys, wrong.Commitment, nullifier, mp)) {
+		t.Errorf("pseudo commitment to another value accepted")
+	}
+}
+
+// Tests that the creator of a commitment, who knows its value and blinding
+// factor but not the key of its owner, cannot spend it.
+func TestMembershipProofCreator(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	_, owner, _ := GenerateKeys("owner")
+	ring, keys, cms, sks := membershipRing(pub, owner, 4, 4, 7)
+	const index = 2
+	v := uint64(7 + index)
+	_, pseudo, _ := EncryptValue(pub, v)
+	tr := TxTranscript(big.NewInt(1), 4, pseudo.Commitment)
+
+	// The creator knows r and the tweak of the spend key, but every secret it
+	// can come up with yields another nullifier and an invalid proof
+	r := cms[index].R
+	for _, guess := range [][]byte{r, RandScalar().Bytes()} {
+		mp, nullifier, err := GenerateMembershipProof(tr.Clone(), pub, ring, keys, index, cms[index], pseudo, guess)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valid(VerifyMembershipProof(tr.Clone(), pub, ring, keys, pseudo.Commitment, nullifier, mp)) {
+			t.Errorf("spend without the spend secret accepted")
+		}
+		if want, _ := Nullifier(sks[index]); bytes.Equal(nullifier, want) {
+			t.Errorf("nullifier derived without the spend secret")
+		}
+	}
+	// Nor can a commitment without spend key be spent
+	bare := append([][]byte{}, keys...)
+	bare[index] = nil
+	if _, _, err := GenerateMembershipProof(tr.Clone(), pub, ring, bare, index, cms[index], pseudo, r); err == nil {
+		t.Errorf("member without spend key accepted")
+	}
+}
+
+func TestMembershipProofErrors(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	_, owner, _ := GenerateKeys("owner")
+	ring, keys, cms, sks := membershipRing(pub, owner, 4, 4, 1)
+	_, pseudo, _ := EncryptValue(pub, 1)
+	if _, _, err := GenerateMembershipProof(nil, pub, ring[:3], keys[:3], 0, cms[0], pseudo, sks[0]); err != errRingSize {
+		t.Errorf("ring of 3: have %v", err)
+	}
+	if _, _, err := GenerateMembershipProof(nil, pub, ring, keys, 1, cms[0], pseudo, sks[0]); err != errRingIndex {
+		t.Errorf("wrong index: have %v", err)
+	}
+	if _, _, err := GenerateMembershipProof(nil, pub, ring, keys[:2], 0, cms[0], pseudo, sks[0]); err != errRingKeys {
+		t.Errorf("missing keys: have %v", err)
+	}
+	mp, nullifier, err := GenerateMembershipProof(nil, pub, ring, keys, 0, cms[0], pseudo, sks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fe *FieldError
+	if _, err := VerifyMembershipProof(nil, pub, append(ring, ring...), append(keys, keys...), pseudo.Commitment, nullifier, mp); !errors.As(err, &fe) || fe.Field != "Cl" {
+		t.Errorf("proof for a larger ring: have %v", err)
+	}
+	bad := append([][]byte{}, ring...)
+	bad[2] = []byte{4, 1, 2}
+	if _, err := VerifyMembershipProof(nil, pub, bad, keys, pseudo.Commitment, nullifier, mp); !errors.As(err, &fe) || fe.Field != "Ring[2]" {
+		t.Errorf("undecodable member: have %v", err)
+	}
+	badKeys := append([][]byte{}, keys...)
+	badKeys[3] = nil
+	if _, err := VerifyMembershipProof(nil, pub, ring, badKeys, pseudo.Commitment, nullifier, mp); !errors.As(err, &fe) || fe.Field != "Keys[3]" {
+		t.Errorf("member without key: have %v", err)
+	}
+	if _, err := VerifyMembershipProof(nil, pub, ring, keys, pseudo.Commitment, nullifier[:33], mp); !errors.As(err, &fe) || fe.Field != "Nullifier" {
+		t.Errorf("undecodable nullifier: have %v", err)
+	}
+
+	enc, err := mp.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec MembershipProof
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if !valid(VerifyMembershipProof(nil, pub, ring, keys, pseudo.Commitment, nullifier, dec)) {
+		t.Errorf("decoded proof rejected")
+	}
+	if err := dec.UnmarshalBinary(enc[:len(enc)-1]); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("truncated proof: have %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package ecc
+
+import "math/big"
+
+// strausThreshold is the number of points from which MultiScalarMult switches
+// from Straus' method to Pippenger's bucket method.
+const strausThreshold = 64
+
+// MultiScalarMult returns sum(scalars[i]*points[i]). Straus' method is used
+// for few points and Pippenger's bucket method for many, both far cheaper than
+// summing up the single scalar multiplications.
+func MultiScalarMult(points []ECPoint, scalars []*big.Int) ECPoint {
+	if len(points) != len(scalars) {
+		panic("MultiScalarMult: length mismatch")
+	}
+	ks := make([]*big.Int, len(scalars))
+	for i, s := range scalars {
+		ks[i] = new(big.Int).Mod(s, EC.N)
+	}
+	var sum jacPoint
+	if len(points) < strausThreshold {
+		sum = straus(points, ks)
+	} else {
+		sum = pippenger(points, ks)
+	}
+	return sum.affine()
+}
+
+// straus computes the sum with one table of the multiples 1..15 of each point
+// and a shared chain of doublings over 4 bit windows of the scalars.
+func straus(points []ECPoint, ks []*big.Int) jacPoint {
+	const w = 4
+	tables := make([][1 << w]jacPoint, len(points))
+	for i, p := range points {
+		for d := 1; d < 1<<w; d++ {
+			tables[i][d] = toJac(p)
+			tables[i][d].add(&tables[i][d-1])
+		}
+	}
+	var acc jacPoint
+	for bit := (EC.N.BitLen() + w - 1) / w * w; bit > 0; bit -= w {
+		for j := 0; j < w; j++ {
+			acc.double()
+		}
+		for i, k := range ks {
+			if d := window(k, bit-w, w); d != 0 {
+				acc.add(&tables[i][d])
+			}
+		}
+	}
+	return acc
+}
+
+// pippenger computes the sum by sorting the points into buckets by the value
+// of the current window of their scalar, summing each bucket once and
+// weighting the bucket sums with a running sum.
+func pippenger(points []ECPoint, ks []*big.Int) jacPoint {
+	c := 1
+	for 1<<uint(c+1) <= len(points)/2 {
+		c++
+	}
+	js := make([]jacPoint, len(points))
+	for i, p := range points {
+		js[i] = toJac(p)
+	}
+	var acc jacPoint
+	buckets := make([]jacPoint, 1<<uint(c))
+	for bit := (EC.N.BitLen() + c - 1) / c * c; bit > 0; bit -= c {
+		for j := 0; j < c; j++ {
+			acc.double()
+		}
+		for d := range buckets {
+			buckets[d] = jacPoint{}
+		}
+		for i, k := range ks {
+			if d := window(k, bit-c, c); d != 0 {
+				buckets[d].add(&js[i])
+			}
+		}
+		var sum, total jacPoint
+		for d := len(buckets) - 1; d > 0; d-- {
+			sum.add(&buckets[d])
+			total.add(&sum)
+		}
+		acc.add(&total)
+	}
+	return acc
+}
+
+// window returns the w bits of k starting at bit from.
+func window(k *big.Int, from, w int) int {
+	d := 0
+	for j := w - 1; j >= 0; j-- {
+		d = d<<1 | int(k.Bit(from+j))
+	}
+	return d
+}
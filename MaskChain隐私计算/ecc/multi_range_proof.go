@@ -1,8 +1,7 @@
-package bp
+package ecc
 
 import (
 	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	"math/big"
 )
@@ -159,12 +158,9 @@ func MRPProve(values []*big.Int) MultiRangeProof {
 	S := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, sL, sR).Add(EC.H.Mult(rho))
 	MRPResult.S = S
 
-	chal1s256 := sha256.Sum256([]byte(A.X.String() + A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	tr := NewTranscript("MultiRangeProof")
+	cy, cz := rangeChallengesYZ(tr, Comms, A, S)
 	MRPResult.Cy = cy
-
-	chal2s256 := sha256.Sum256([]byte(S.X.String() + S.Y.String()))
-	cz := new(big.Int).SetBytes(chal2s256[:])
 	MRPResult.Cz = cz
 
 	zPowersTimesTwoVec := make([]*big.Int, EC.V)
@@ -239,8 +235,7 @@ func MRPProve(values []*big.Int) MultiRangeProof {
 	MRPResult.T1 = T1
 	MRPResult.T2 = T2
 
-	chal3s256 := sha256.Sum256([]byte(T1.X.String() + T1.Y.String() + T2.X.String() + T2.Y.String()))
-	cx := new(big.Int).SetBytes(chal3s256[:])
+	cx := rangeChallengeX(tr, T1, T2, S)
 
 	MRPResult.Cx = cx
 
@@ -286,7 +281,7 @@ func MRPProve(values []*big.Int) MultiRangeProof {
 	P := TwoVectorPCommitWithGens(EC.BPG, HPrime, left, right)
 	//fmt.Println(P)
 
-	MRPResult.IPP = InnerProductProve(left, right, that, P, EC.U, EC.BPG, HPrime)
+	MRPResult.IPP = InnerProductProve(tr, left, right, that, P, EC.U, EC.BPG, HPrime)
 
 	return MRPResult
 }
@@ -305,20 +300,17 @@ func MRPVerify(mrp MultiRangeProof) bool {
 	// check 2 commitment generation is also different
 
 	// verify the challenges
-	chal1s256 := sha256.Sum256([]byte(mrp.A.X.String() + mrp.A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	tr := NewTranscript("MultiRangeProof")
+	cy, cz := rangeChallengesYZ(tr, mrp.Comms, mrp.A, mrp.S)
 	if cy.Cmp(mrp.Cy) != 0 {
 		fmt.Println("MRPVerify - Challenge Cy failing!")
 		return false
 	}
-	chal2s256 := sha256.Sum256([]byte(mrp.S.X.String() + mrp.S.Y.String()))
-	cz := new(big.Int).SetBytes(chal2s256[:])
 	if cz.Cmp(mrp.Cz) != 0 {
 		fmt.Println("MRPVerify - Challenge Cz failing!")
 		return false
 	}
-	chal3s256 := sha256.Sum256([]byte(mrp.T1.X.String() + mrp.T1.Y.String() + mrp.T2.X.String() + mrp.T2.Y.String()))
-	cx := new(big.Int).SetBytes(chal3s256[:])
+	cx := rangeChallengeX(tr, mrp.T1, mrp.T2, mrp.S)
 	if cx.Cmp(mrp.Cx) != 0 {
 		fmt.Println("RPVerify - Challenge Cx failing!")
 		return false
@@ -380,7 +372,7 @@ func MRPVerify(mrp MultiRangeProof) bool {
 	P := mrp.A.Add(mrp.S.Mult(cx)).Add(tmp1).Add(tmp2).Add(EC.H.Mult(mrp.Mu).Neg())
 	//fmt.Println(P)
 
-	if !InnerProductVerifyFast(mrp.Th, P, EC.U, EC.BPG, HPrime, mrp.IPP) {
+	if !InnerProductVerifyFast(tr, mrp.Th, P, EC.U, EC.BPG, HPrime, mrp.IPP) {
 		fmt.Println("MRPVerify - Uh oh! Check line (65) of verification!")
 		return false
 	}
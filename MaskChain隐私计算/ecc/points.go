@@ -0,0 +1,68 @@
+package ecc
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Errors of DecodePoint. Every point taken from a transaction, a proof or a
+// cyphertext has to be decoded with DecodePoint before it is used; the
+// verifiers return them wrapped in a FieldError naming the offending field.
+var (
+	ErrInvalidPoint    = errors.New("invalid point encoding")
+	ErrPointNotOnCurve = errors.New("point not on curve")
+	ErrPointAtInfinity = errors.New("point at infinity")
+)
+
+// FieldError reports the field of a proof or cyphertext which could not be
+// decoded.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodePoint decodes a point in the uncompressed encoding of
+// elliptic.Marshal. Coordinates which are not reduced modulo p, points off
+// the curve and the point at infinity are rejected.
+//
+// secp256k1 has cofactor 1, so every point of the curve other than the point
+// at infinity generates the whole group and no further subgroup check is
+// needed.
+func DecodePoint(b []byte) (ECPoint, error) {
+	if len(b) == 1 && b[0] == 0 {
+		return ECPoint{}, ErrPointAtInfinity
+	}
+	byteLen := (EC.C.Params().BitSize + 7) / 8
+	if len(b) != 1+2*byteLen || b[0] != 4 {
+		return ECPoint{}, ErrInvalidPoint
+	}
+	x := new(big.Int).SetBytes(b[1 : 1+byteLen])
+	y := new(big.Int).SetBytes(b[1+byteLen:])
+	if p := EC.C.Params().P; x.Cmp(p) >= 0 || y.Cmp(p) >= 0 {
+		return ECPoint{}, ErrInvalidPoint
+	}
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return ECPoint{}, ErrPointAtInfinity
+	}
+	if !EC.C.IsOnCurve(x, y) {
+		return ECPoint{}, ErrPointNotOnCurve
+	}
+	return ECPoint{x, y}, nil
+}
+
+// decodeField decodes the point of a named field, see DecodePoint.
+func decodeField(field string, b []byte) (ECPoint, error) {
+	p, err := DecodePoint(b)
+	if err != nil {
+		return ECPoint{}, &FieldError{field, err}
+	}
+	return p, nil
+}
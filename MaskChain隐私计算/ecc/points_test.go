@@ -0,0 +1,111 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// rawPoint returns the uncompressed encoding of any pair of coordinates below
+// 2^256, which elliptic.Marshal refuses for points off the curve.
+func rawPoint(x, y *big.Int) []byte {
+	enc := make([]byte, 65)
+	enc[0] = 4
+	copy(enc[33-len(x.Bytes()):], x.Bytes())
+	copy(enc[65-len(y.Bytes()):], y.Bytes())
+	return enc
+}
+
+func TestDecodePoint(t *testing.T) {
+	pub, _, _ := GenerateKeys("points")
+	g := ConvertPub(pub).G1
+	enc := elliptic.Marshal(EC.C, g.X, g.Y)
+
+	if p, err := DecodePoint(enc); err != nil || !p.Equal(g) {
+		t.Fatalf("valid point rejected: %v", err)
+	}
+	offCurve := append([]byte{}, enc...)
+	offCurve[64] ^= 1
+	unreduced := rawPoint(EC.C.Params().P, g.Y)
+	tests := map[string]struct {
+		enc  []byte
+		want error
+	}{
+		"empty":      {nil, ErrInvalidPoint},
+		"compressed": {append([]byte{2 | byte(g.Y.Bit(0))}, enc[1:33]...), ErrInvalidPoint},
+		"truncated":  {enc[:64], ErrInvalidPoint},
+		"prefix":     {append([]byte{6}, enc[1:]...), ErrInvalidPoint},
+		"unreduced":  {unreduced, ErrInvalidPoint},
+		"off curve":  {offCurve, ErrPointNotOnCurve},
+		"infinity":   {[]byte{0}, ErrPointAtInfinity},
+		"zero":       {rawPoint(new(big.Int), new(big.Int)), ErrPointAtInfinity},
+	}
+	for name, tt := range tests {
+		if _, err := DecodePoint(tt.enc); err != tt.want {
+			t.Errorf("%s: have %v, want %v", name, err, tt.want)
+		}
+	}
+}
+
+func TestPointEqual(t *testing.T) {
+	pub, _, _ := GenerateKeys("points")
+	g := ConvertPub(pub).G1
+	if !g.Equal(g) || g.Equal(g.Neg()) {
+		t.Errorf("points compared by X only")
+	}
+}
+
+// Malformed points make the verifiers fail with the offending field instead
+// of panicking or verifying against garbage.
+func TestMalformedProofPoints(t *testing.T) {
+	tr := NewTranscript("test")
+	pub, _, _ := GenerateKeys("regulator")
+	ct, cmo, _ := EncryptValue(pub, 10)
+	_, cmo2, _ := EncryptValue(pub, 10)
+	_, cms, _ := EncryptValue(pub, 7)
+	_, cmr, _ := EncryptValue(pub, 3)
+	infinity := rawPoint(new(big.Int), new(big.Int))
+	offCurve := append([]byte{}, cms.Commitment...)
+	offCurve[64] ^= 1
+
+	fieldErr := func(name string, err error, field string, want error) {
+		t.Helper()
+		var ferr *FieldError
+		if !errors.As(err, &ferr) || ferr.Field != field || !errors.Is(err, want) {
+			t.Errorf("%s: have %v, want %s: %v", name, err, field, want)
+		}
+	}
+	fp := GenerateFormatProof(tr, pub, 10, cmo.R, ct)
+	bad := fp
+	bad.T1 = nil
+	_, err := VerifyFormatProof(tr, ct, bad)
+	fieldErr("format proof", err, "T1", ErrInvalidPoint)
+	_, err = NewBatchVerifier().VerifyFormatProof(tr, ct, bad)
+	fieldErr("batched format proof", err, "T1", ErrInvalidPoint)
+	_, err = VerifyFormatProof(tr, CypherText{C1: ct.C1, C2: infinity}, fp)
+	fieldErr("format proof cyphertext", err, "C2", ErrPointAtInfinity)
+
+	bp := GenerateBalanceProof(tr, 3, 7, 10, cmr.Commitment, cms.Commitment, cmo.Commitment)
+	_, err = VerifyBalanceProof(tr, cmr.Commitment, offCurve, cmo.Commitment, bp)
+	fieldErr("balance proof", err, "CM_s", ErrPointNotOnCurve)
+	_, err = NewBatchVerifier().VerifyBalanceProof(tr, cmr.Commitment, offCurve, cmo.Commitment, bp)
+	fieldErr("batched balance proof", err, "CM_s", ErrPointNotOnCurve)
+
+	rp, err := GenerateTransferRangeProof(tr, pub, []uint64{7, 3}, [][]byte{cms.R, cmr.R}, 32)
+	if err != nil {
+		t.Fatalf("failed to generate range proof: %v", err)
+	}
+	_, err = VerifyTransferRangeProof(tr, pub, [][]byte{cms.Commitment, infinity}, rp, 32)
+	fieldErr("range proof", err, "Comms[1]", ErrPointAtInfinity)
+
+	// A well formed proof with the commitment T1 negated is rejected without
+	// error; it used to pass as points were compared by X only.
+	ep := GenerateEqualityProof(tr, pub, pub, cmo, cmo2, 10)
+	t1x, t1y := elliptic.Unmarshal(EC.C, ep.T1)
+	neg := ECPoint{t1x, t1y}.Neg()
+	ep.T1 = elliptic.Marshal(EC.C, neg.X, neg.Y)
+	if ok, err := VerifyEqualityProof(tr, ep); ok || err != nil {
+		t.Errorf("equality proof with negated T1: have %v, %v", ok, err)
+	}
+}
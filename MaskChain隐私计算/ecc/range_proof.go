@@ -1,8 +1,7 @@
-package bp
+package ecc
 
 import (
 	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	"math/big"
 )
@@ -126,14 +125,11 @@ func RPProve(v *big.Int) RangeProof {
 	S := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, sL, sR).Add(EC.H.Mult(rho))
 	rpresult.S = S
 
-	chal1s256 := sha256.Sum256([]byte(A.X.String() + A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	// y和z取决于承诺、A和S，x取决于此前的全部消息
+	tr := NewTranscript("RangeProof")
+	cy, cz := rangeChallengesYZ(tr, []ECPoint{comm}, A, S)
 
 	rpresult.Cy = cy
-
-	chal2s256 := sha256.Sum256([]byte(S.X.String() + S.Y.String()))
-	cz := new(big.Int).SetBytes(chal2s256[:])
-
 	rpresult.Cz = cz
 	z2 := new(big.Int).Exp(cz, big.NewInt(2), EC.N)
 	// need to generate l(X), r(X), and t(X)=<l(X),r(X)>
@@ -186,8 +182,7 @@ func RPProve(v *big.Int) RangeProof {
 	rpresult.T1 = T1
 	rpresult.T2 = T2
 
-	chal3s256 := sha256.Sum256([]byte(T1.X.String() + T1.Y.String() + T2.X.String() + T2.Y.String()))
-	cx := new(big.Int).SetBytes(chal3s256[:])
+	cx := rangeChallengeX(tr, T1, T2, S)
 
 	rpresult.Cx = cx
 
@@ -251,27 +246,24 @@ func RPProve(v *big.Int) RangeProof {
 	//fmt.Println(P1)
 	//fmt.Println(P2)
 
-	rpresult.IPP = InnerProductProve(left, right, that, P, EC.U, EC.BPG, HPrime)
+	rpresult.IPP = InnerProductProve(tr, left, right, that, P, EC.U, EC.BPG, HPrime)
 
 	return rpresult
 }
 
 func RPVerify(rp RangeProof) bool {
 	// verify the challenges
-	chal1s256 := sha256.Sum256([]byte(rp.A.X.String() + rp.A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	tr := NewTranscript("RangeProof")
+	cy, cz := rangeChallengesYZ(tr, []ECPoint{rp.Comm}, rp.A, rp.S)
 	if cy.Cmp(rp.Cy) != 0 {
 		fmt.Println("RPVerify - Challenge Cy failing!")
 		return false
 	}
-	chal2s256 := sha256.Sum256([]byte(rp.S.X.String() + rp.S.Y.String()))
-	cz := new(big.Int).SetBytes(chal2s256[:])
 	if cz.Cmp(rp.Cz) != 0 {
 		fmt.Println("RPVerify - Challenge Cz failing!")
 		return false
 	}
-	chal3s256 := sha256.Sum256([]byte(rp.T1.X.String() + rp.T1.Y.String() + rp.T2.X.String() + rp.T2.Y.String()))
-	cx := new(big.Int).SetBytes(chal3s256[:])
+	cx := rangeChallengeX(tr, rp.T1, rp.T2, rp.S)
 	if cx.Cmp(rp.Cx) != 0 {
 		fmt.Println("RPVerify - Challenge Cx failing!")
 		return false
@@ -323,7 +315,7 @@ func RPVerify(rp RangeProof) bool {
 	P := rp.A.Add(rp.S.Mult(cx)).Add(tmp1).Add(tmp2).Add(EC.H.Mult(rp.Mu).Neg())
 	//fmt.Println(P)
 
-	if !InnerProductVerifyFast(rp.Th, P, EC.U, EC.BPG, HPrime, rp.IPP) {
+	if !InnerProductVerifyFast(tr, rp.Th, P, EC.U, EC.BPG, HPrime, rp.IPP) {
 		fmt.Println("RPVerify - Uh oh! Check line (65) of verification!")
 		return false
 	}
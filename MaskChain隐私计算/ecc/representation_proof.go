@@ -1,8 +1,7 @@
-package bp
+package ecc
 
 import (
 	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	"math/big"
 )
@@ -52,11 +51,7 @@ func RepProof(gn []ECPoint,xn []*big.Int) REP{
 	}
 	repResult.T = t
 
-	var gnString string
-	for i:=0;i<n;i++{
-		gnString = gnString + gn[i].X.String() + gn[i].Y.String()
-	}
-	c := sha256.Sum256([]byte(gnString+tempy.X.String()+tempy.Y.String()+t.X.String()+t.Y.String()))
+	c := repChallenge(gn, tempy, t)
 	repResult.C = c
 	intc := new(big.Int).SetBytes(c[:])
 
@@ -71,11 +66,7 @@ func RepProof(gn []ECPoint,xn []*big.Int) REP{
 }
 
 func RepVerify(rep REP) bool {
-	var gnString string
-	for i:=0;i<rep.N;i++{
-		gnString = gnString + rep.Gn[i].X.String() + rep.Gn[i].Y.String()
-	}
-	c := sha256.Sum256([]byte(gnString+rep.Y.X.String()+rep.Y.Y.String()+rep.T.X.String()+rep.T.Y.String()))
+	c := repChallenge(rep.Gn[:rep.N], rep.Y, rep.T)
 	if c!= rep.C{
 		fmt.Println("REP failed: c != rep.C")
 		return false
@@ -91,4 +82,13 @@ func RepVerify(rep REP) bool {
 		return false
 	}
 	return true
-}
\ No newline at end of file
+}
+
+// repChallenge 将生成元、y和t加入转录后得出表示证明的挑战
+func repChallenge(gn []ECPoint, y, t ECPoint) Hash {
+	tr := NewTranscript("REP")
+	tr.AppendPoints("G", gn)
+	tr.AppendPoint("Y", y)
+	tr.AppendPoint("T", t)
+	return hashChallenge(tr.Challenge("c"))
+}
@@ -0,0 +1,49 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// RandScalar returns a uniformly random scalar in [1, N) read from the
+// operating system's CSPRNG. Private keys, blinding factors and proof nonces
+// must come from here or from crypto/rand directly, never from math/rand.
+func RandScalar() *big.Int {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(EC.N, big.NewInt(1)))
+	check(err)
+	return k.Add(k, big.NewInt(1))
+}
+
+// Zeroize overwrites b with zeros, e.g. a blinding factor which is no longer
+// needed.
+func Zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// ZeroizeInt overwrites the words of the given integers with zeros and sets
+// them to 0. Copies of the words left behind by earlier arithmetic on the
+// integers are not reached.
+func ZeroizeInt(xs ...*big.Int) {
+	for _, x := range xs {
+		if x == nil {
+			continue
+		}
+		ws := x.Bits()
+		for i := range ws {
+			ws[i] = 0
+		}
+		x.SetInt64(0)
+	}
+}
+
+// Zeroize overwrites the private scalar of the key.
+func (priv *PrivateKey) Zeroize() {
+	ZeroizeInt(priv.X)
+}
+
+// Zeroize overwrites the blinding factor of the commitment.
+func (c *Commitment) Zeroize() {
+	Zeroize(c.R)
+}
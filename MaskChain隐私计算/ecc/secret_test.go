@@ -0,0 +1,127 @@
+package ecc
+
+import (
+	"go/parser"
+	"go/token"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKeysRandom(t *testing.T) {
+	_, priv1, _ := GenerateKeys("same")
+	_, priv2, _ := GenerateKeys("same")
+	if priv1.X.Cmp(priv2.X) == 0 {
+		t.Fatalf("keys derived from the info string")
+	}
+	if priv1.X.Sign() <= 0 || priv1.X.Cmp(EC.N) >= 0 {
+		t.Errorf("private key out of range: %x", priv1.X)
+	}
+	if priv1.X.Cmp(new(big.Int).SetBytes([]byte("same"))) == 0 {
+		t.Errorf("private key equals the info string")
+	}
+}
+
+func TestZeroize(t *testing.T) {
+	_, priv, _ := GenerateKeys("zeroize")
+	_, cm, _ := EncryptValue(priv.PublicKey, 3)
+	words := priv.X.Bits()
+	priv.Zeroize()
+	cm.Zeroize()
+	if priv.X.Sign() != 0 {
+		t.Errorf("private key not cleared")
+	}
+	for _, w := range words {
+		if w != 0 {
+			t.Fatalf("private key words not cleared")
+		}
+	}
+	for _, b := range cm.R {
+		if b != 0 {
+			t.Fatalf("blinding factor not cleared")
+		}
+	}
+}
+
+// TestReducedResponses checks that the responses of the sigma proofs are
+// reduced modulo N, so that they reveal nothing about the witnesses, and that
+// linear equations only hold modulo N still verify.
+func TestReducedResponses(t *testing.T) {
+	pub, _, _ := GenerateKeys("responses")
+	g := ConvertPub(pub)
+	x := RandScalar()
+	ep := EPProof(nil, g.G1, g.H, x)
+	if ep.S.Sign() < 0 || ep.S.Cmp(EC.N) >= 0 || !EPVerify(nil, ep) {
+		t.Errorf("equality proof response %x not reduced or rejected", ep.S)
+	}
+
+	// x1 + x2 - x3 = 0 modulo N only
+	x1, x2 := RandScalar(), RandScalar()
+	x3 := new(big.Int).Mod(new(big.Int).Add(x1, x2), EC.N)
+	gn := []ECPoint{g.G1, g.H, EC.G}
+	an := []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(-1)}
+	lep := Linear_equation_proof_tx(nil, gn, []*big.Int{x1, x2, x3}, an)
+	for i, s := range lep.Sn {
+		if s.Sign() < 0 || s.Cmp(EC.N) >= 0 {
+			t.Errorf("response %d not reduced: %x", i, s)
+		}
+	}
+	if !LepVerify_txn(nil, lep, gn, an) {
+		t.Errorf("linear equation proof rejected")
+	}
+	// 3*x1 + 4*x2 = b
+	an = []*big.Int{big.NewInt(3), big.NewInt(4)}
+	b := new(big.Int).Add(new(big.Int).Mul(an[0], x1), new(big.Int).Mul(an[1], x2))
+	if !LepVerify(Linear_equation_proof(gn[:2], []*big.Int{x1, x2}, an, b), gn[:2]) {
+		t.Errorf("linear equation proof with constant rejected")
+	}
+}
+
+// secretPathDirs are the directories, relative to the package, whose code
+// generates keys, blinding factors or proof nonces. The services are only
+// checked when they are checked out next to the module.
+var secretPathDirs = []string{
+	"..",
+	"../../MaskChain交易所服务",
+	"../../MaskChain用户客户端",
+	"../../MaskChain监管者服务",
+	"../../MaskChain区块链/privtx",
+}
+
+// TestNoMathRand fails if any non-test code in secretPathDirs imports
+// math/rand, whose output is predictable and must never end up in keys,
+// blinding factors or nonces; use RandScalar or crypto/rand instead.
+func TestNoMathRand(t *testing.T) {
+	for _, dir := range secretPathDirs {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() && path != dir && (strings.HasPrefix(info.Name(), ".") || info.Name() == "vendor" || info.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+			if err != nil {
+				return err
+			}
+			for _, imp := range f.Imports {
+				if p, _ := strconv.Unquote(imp.Path.Value); p == "math/rand" {
+					t.Errorf("%s imports math/rand", path)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+)
+
+// transcriptDomain separates the challenges of MaskChain proofs from hashes
+// computed for any other purpose.
+const transcriptDomain = "MaskChain/ECC/transcript/v1"
+
+// Transcript derives the Fiat–Shamir challenges of a proof from everything
+// the prover has sent so far. Messages are appended with a label and a length
+// prefix, so different sequences of messages never hash alike, and every
+// challenge is appended back to the transcript before the next message.
+//
+// A nil *Transcript stands for the ad hoc challenges of proof version 1, which
+// are kept only to verify transactions created before the transcript.
+type Transcript struct {
+	buf []byte
+}
+
+// NewTranscript returns a transcript for proofs of the given domain, e.g. the
+// name of the proof system.
+func NewTranscript(domain string) *Transcript {
+	t := new(Transcript)
+	t.AppendMessage("dom-sep", []byte(transcriptDomain))
+	t.AppendMessage("domain", []byte(domain))
+	return t
+}
+
+// TxTranscript returns the transcript the proofs of a privacy transaction are
+// bound to: the chain ID, the transaction type and the commitments carried by
+// the transaction. A proof generated against it does not verify in any other
+// transaction.
+func TxTranscript(chainID *big.Int, txType uint8, commitments ...[]byte) *Transcript {
+	t := NewTranscript("MaskChain/privacy-tx")
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	t.AppendMessage("chain-id", chainID.Bytes())
+	t.AppendMessage("tx-type", []byte{txType})
+	for _, cm := range commitments {
+		t.AppendMessage("commitment", cm)
+	}
+	return t
+}
+
+// AppendMessage appends a labelled message.
+func (t *Transcript) AppendMessage(label string, msg []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(label)))
+	t.buf = append(append(t.buf, n[:]...), label...)
+	binary.BigEndian.PutUint32(n[:], uint32(len(msg)))
+	t.buf = append(append(t.buf, n[:]...), msg...)
+}
+
+// AppendPoint appends a labelled curve point in uncompressed encoding. A
+// point without coordinates is appended as an empty message.
+func (t *Transcript) AppendPoint(label string, p ECPoint) {
+	if p.X == nil || p.Y == nil {
+		t.AppendMessage(label, nil)
+		return
+	}
+	t.AppendMessage(label, elliptic.Marshal(EC.C, p.X, p.Y))
+}
+
+// AppendPoints appends the labelled points in order.
+func (t *Transcript) AppendPoints(label string, ps []ECPoint) {
+	for _, p := range ps {
+		t.AppendPoint(label, p)
+	}
+}
+
+// AppendScalar appends a labelled scalar as 32 bytes modulo the group order.
+func (t *Transcript) AppendScalar(label string, s *big.Int) {
+	t.AppendMessage(label, scalarBytes(s))
+}
+
+// Challenge squeezes a labelled challenge scalar in [1, N) out of the
+// transcript and appends it, so later challenges depend on it.
+func (t *Transcript) Challenge(label string) *big.Int {
+	for i := byte(0); ; i++ {
+		t.AppendMessage(label, []byte{i})
+		h := sha256.Sum256(t.buf)
+		c := new(big.Int).SetBytes(h[:])
+		c.Mod(c, EC.N)
+		if c.Sign() != 0 {
+			t.AppendScalar(label, c)
+			return c
+		}
+	}
+}
+
+// Fork returns a copy of the transcript for the sub-proof of the given label,
+// leaving t untouched. Forking a nil transcript returns nil.
+func (t *Transcript) Fork(label string) *Transcript {
+	if t == nil {
+		return nil
+	}
+	f := t.Clone()
+	f.AppendMessage("fork", []byte(label))
+	return f
+}
+
+// Clone returns a copy of the transcript. Cloning a nil transcript returns nil.
+func (t *Transcript) Clone() *Transcript {
+	if t == nil {
+		return nil
+	}
+	return &Transcript{buf: append([]byte{}, t.buf...)}
+}
+
+// pointsChallenge appends the points to t and squeezes the challenge of the
+// given label. With a nil transcript it returns the version 1 challenge, i.e.
+// the hash of the decimal coordinates of the points.
+func pointsChallenge(t *Transcript, label string, points ...ECPoint) *big.Int {
+	if t == nil {
+		var s string
+		for _, p := range points {
+			s += p.X.String() + p.Y.String()
+		}
+		h := sha256.Sum256([]byte(s))
+		return new(big.Int).SetBytes(h[:])
+	}
+	t.AppendPoints(label, points)
+	return t.Challenge(label)
+}
+
+// hashChallenge returns a challenge scalar as the 32 byte hash stored in the
+// sigma proofs.
+func hashChallenge(c *big.Int) Hash {
+	return BytesToHash(scalarBytes(c))
+}
@@ -0,0 +1,119 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestTranscriptChallenge(t *testing.T) {
+	challenge := func(msgs ...string) *big.Int {
+		tr := NewTranscript("test")
+		for i := 0; i+1 < len(msgs); i += 2 {
+			tr.AppendMessage(msgs[i], []byte(msgs[i+1]))
+		}
+		return tr.Challenge("c")
+	}
+	if challenge("a", "b").Cmp(challenge("a", "b")) != 0 {
+		t.Fatalf("challenge not deterministic")
+	}
+	// Labels and messages are length prefixed
+	if challenge("a", "bc").Cmp(challenge("ab", "c")) == 0 {
+		t.Errorf("challenge ambiguous between label and message")
+	}
+	if challenge("a", "b", "c", "d").Cmp(challenge("a", "bcd")) == 0 {
+		t.Errorf("challenge ambiguous between messages")
+	}
+	if NewTranscript("one").Challenge("c").Cmp(NewTranscript("two").Challenge("c")) == 0 {
+		t.Errorf("challenge independent of the domain")
+	}
+	// Challenges are chained, forks and clones leave the parent untouched
+	tr := NewTranscript("test")
+	fork, clone := tr.Fork("sub"), tr.Clone()
+	c1, c2 := tr.Challenge("c"), tr.Challenge("c")
+	if c1.Cmp(c2) == 0 {
+		t.Errorf("repeated challenge not chained")
+	}
+	if clone.Challenge("c").Cmp(c1) != 0 {
+		t.Errorf("clone diverged from its parent")
+	}
+	if fork.Challenge("c").Cmp(c1) == 0 {
+		t.Errorf("fork not separated from its parent")
+	}
+	if c1.Sign() <= 0 || c1.Cmp(EC.N) >= 0 {
+		t.Errorf("challenge out of range: %v", c1)
+	}
+	var legacy *Transcript
+	if legacy.Fork("sub") != nil || legacy.Clone() != nil {
+		t.Errorf("nil transcript forked into a transcript")
+	}
+}
+
+// Proofs generated against the transcript of one transaction must not verify
+// in another one, i.e. under a different chain ID, transaction type,
+// commitment set or sub-proof label.
+func TestTxTranscriptBinding(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	evS, cmS, _ := EncryptValue(pub, 7)
+	_, cmR, _ := EncryptValue(pub, 3)
+	_, cmO, _ := EncryptValue(pub, 10)
+	_, cmO2, _ := EncryptValue(pub, 10)
+
+	tx := TxTranscript(big.NewInt(1), 0, cmO.Commitment, cmS.Commitment, cmR.Commitment)
+	others := map[string]*Transcript{
+		"chain id":    TxTranscript(big.NewInt(2), 0, cmO.Commitment, cmS.Commitment, cmR.Commitment),
+		"tx type":     TxTranscript(big.NewInt(1), 3, cmO.Commitment, cmS.Commitment, cmR.Commitment),
+		"commitments": TxTranscript(big.NewInt(1), 0, cmO.Commitment, cmS.Commitment, cmR.Commitment, cmO2.Commitment),
+		"label":       tx.Fork("other"),
+		"version 1":   nil,
+	}
+	fp := GenerateFormatProof(tx, pub, 7, cmS.R, evS)
+	ep := GenerateEqualityProof(tx, pub, pub, cmO2, cmO, 10)
+	bp := GenerateBalanceProof(tx, 3, 7, 10, cmR.Commitment, cmS.Commitment, cmO.Commitment)
+	rp, err := GenerateTransferRangeProof(tx, pub, []uint64{7, 3}, [][]byte{cmS.R, cmR.R}, 32)
+	if err != nil {
+		t.Fatalf("failed to generate range proof: %v", err)
+	}
+	verify := func(tr *Transcript) map[string]bool {
+		return map[string]bool{
+			"format":   valid(VerifyFormatProof(tr, evS, fp)),
+			"equality": valid(VerifyEqualityProof(tr, ep)),
+			"balance":  valid(VerifyBalanceProof(tr, cmR.Commitment, cmS.Commitment, cmO.Commitment, bp)),
+			"range":    valid(VerifyTransferRangeProof(tr, pub, [][]byte{cmS.Commitment, cmR.Commitment}, rp, 32)),
+		}
+	}
+	for proof, ok := range verify(tx) {
+		if !ok {
+			t.Errorf("%s proof rejected in its own transaction", proof)
+		}
+	}
+	for name, tr := range others {
+		for proof, ok := range verify(tr) {
+			if ok {
+				t.Errorf("%s proof accepted with a different %s", proof, name)
+			}
+		}
+	}
+}
+
+// Proofs of version 1 keep verifying without a transcript.
+func TestLegacyProofs(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	evS, cmS, _ := EncryptValue(pub, 7)
+	_, cmR, _ := EncryptValue(pub, 3)
+
+	fp := GenerateFormatProof(nil, pub, 7, cmS.R, evS)
+	if !valid(VerifyFormatProof(nil, evS, fp)) {
+		t.Errorf("version 1 format proof rejected")
+	}
+	rp, err := GenerateTransferRangeProof(nil, pub, []uint64{7, 3}, [][]byte{cmS.R, cmR.R}, 32)
+	if err != nil {
+		t.Fatalf("failed to generate range proof: %v", err)
+	}
+	comms := [][]byte{cmS.Commitment, cmR.Commitment}
+	if rp[0] != legacyRangeProofVersion || !valid(VerifyTransferRangeProof(nil, pub, comms, rp, 32)) {
+		t.Errorf("version 1 range proof rejected")
+	}
+	if valid(VerifyTransferRangeProof(TxTranscript(big.NewInt(1), 0), pub, comms, rp, 32)) {
+		t.Errorf("version 1 range proof accepted as version 2")
+	}
+}
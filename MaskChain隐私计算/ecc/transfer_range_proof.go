@@ -0,0 +1,368 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Leading bytes of an encoded transfer range proof. Proofs of version 1 derive
+// their challenges from hashes of the decimal point coordinates, proofs of
+// version 2 from the transcript of the transaction.
+const (
+	legacyRangeProofVersion = 1
+	rangeProofVersion       = 2
+)
+
+// MaxRangeProofValues is the maximum number of commitments covered by one
+// aggregated transfer range proof.
+const MaxRangeProofValues = 16
+
+var (
+	errRangeProofBits     = errors.New("unsupported range proof bit width")
+	errRangeProofValues   = errors.New("mismatched range proof values and blinding factors")
+	errRangeProofEncoding = errors.New("invalid range proof encoding")
+	errRangeProofComms    = errors.New("unsupported number of range proof commitments")
+
+	rangeProofParams sync.Map // vector length -> CryptoParams
+)
+
+// ValidRangeProofBits reports whether the bit width can be used for transfer
+// range proofs. The aggregated values must fill a power of two vector.
+func ValidRangeProofBits(bits int) bool {
+	switch bits {
+	case 8, 16, 32, 64:
+		return true
+	}
+	return false
+}
+
+// rangeParams returns the Bulletproof generators for a vector of length n. They
+// are cached per length and never touch the package level EC parameters.
+func rangeParams(n int) CryptoParams {
+	if params, ok := rangeProofParams.Load(n); ok {
+		return params.(CryptoParams)
+	}
+	params, _ := rangeProofParams.LoadOrStore(n, NewECPrimeGroupKey(n))
+	return params.(CryptoParams)
+}
+
+// rangeProofSlots returns the number of aggregated values of a proof over m
+// commitments, i.e. m rounded up to a power of two. The remaining slots are
+// filled with the commitment H to zero with blinding factor one.
+func rangeProofSlots(m int) int {
+	slots := 1
+	for slots < m {
+		slots <<= 1
+	}
+	return slots
+}
+
+// rangeChallengesYZ returns the challenges y and z of a range proof over the
+// commitments comms. With a nil transcript they are the version 1 challenges,
+// hashed from the commitments and A, and from A and S.
+func rangeChallengesYZ(t *Transcript, comms []ECPoint, A, S ECPoint) (*big.Int, *big.Int) {
+	if t == nil {
+		return pointsChallenge(nil, "", append(append([]ECPoint{}, comms...), A)...), pointsChallenge(nil, "", A, S)
+	}
+	t.AppendPoints("V", comms)
+	y := pointsChallenge(t, "y", A, S)
+	return y, t.Challenge("z")
+}
+
+// rangeChallengeX returns the challenge x of a range proof. With a nil
+// transcript it is the version 1 challenge hashed from T1, T2 and S.
+func rangeChallengeX(t *Transcript, T1, T2, S ECPoint) *big.Int {
+	if t == nil {
+		return pointsChallenge(nil, "", T1, T2, S)
+	}
+	return pointsChallenge(t, "x", T1, T2)
+}
+
+// rangeTranscript forks the transcript of a transfer range proof and binds it
+// to the bit width and the generators. A nil transcript stays nil.
+func rangeTranscript(t *Transcript, bits int, g, h ECPoint) *Transcript {
+	if t = t.Fork("TransferRangeProof"); t != nil {
+		t.AppendMessage("bits", []byte{byte(bits)})
+		t.AppendPoint("G", g)
+		t.AppendPoint("H", h)
+	}
+	return t
+}
+
+// rangeVersion returns the encoding version of transfer range proofs
+// generated against t.
+func rangeVersion(t *Transcript) byte {
+	if t == nil {
+		return legacyRangeProofVersion
+	}
+	return rangeProofVersion
+}
+
+// rangeDelta computes (z-z^2)<1^nm, y^nm> - sum_j z^(3+j)<1^n, 2^n>.
+func rangeDelta(y []*big.Int, z *big.Int, n, m int) *big.Int {
+	z2 := new(big.Int).Mod(new(big.Int).Mul(z, z), EC.N)
+	t1 := new(big.Int).Mod(new(big.Int).Sub(z, z2), EC.N)
+	t2 := new(big.Int).Mod(new(big.Int).Mul(t1, VectorSum(y)), EC.N)
+
+	po2sum := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(n)), big.NewInt(1))
+	t3 := big.NewInt(0)
+	for j := 0; j < m; j++ {
+		zp := new(big.Int).Exp(z, big.NewInt(3+int64(j)), EC.N)
+		t3 = new(big.Int).Mod(new(big.Int).Add(t3, new(big.Int).Mul(zp, po2sum)), EC.N)
+	}
+	return new(big.Int).Mod(new(big.Int).Sub(t2, t3), EC.N)
+}
+
+// GenerateTransferRangeProof generates an aggregated range proof showing that
+// the values of the commitments v_j*G1 + r_j*H under pub lie in [0, 2^bits).
+// The blinding factors must be the ones used for the commitments (e.g. CmS.R
+// and CmR.R of a transfer). The challenges are derived from a fork of t, a nil
+// t generates a proof of version 1.
+func GenerateTransferRangeProof(t *Transcript, pub PublicKey, values []uint64, blinds [][]byte, bits int) ([]byte, error) {
+	if !ValidRangeProofBits(bits) {
+		return nil, errRangeProofBits
+	}
+	if len(values) == 0 || len(values) > MaxRangeProofValues || len(blinds) != len(values) {
+		return nil, errRangeProofValues
+	}
+	m := rangeProofSlots(len(values))
+	for len(values) < m {
+		values = append(append([]uint64{}, values...), 0)
+		blinds = append(append([][]byte{}, blinds...), []byte{1})
+	}
+	var (
+		pubb   = ConvertPub(pub)
+		g, h   = pubb.G1, pubb.H
+		n      = bits
+		params = rangeParams(n * m)
+	)
+	t = rangeTranscript(t, bits, g, h)
+	comms := make([]ECPoint, m)
+	gammas := make([]*big.Int, m)
+	aL := make([]*big.Int, n*m)
+	aR := make([]*big.Int, n*m)
+	for j, v := range values {
+		if bits < 64 && v >= uint64(1)<<uint(bits) {
+			return nil, fmt.Errorf("value %d out of %d bit range", v, bits)
+		}
+		gammas[j] = new(big.Int).SetBytes(blinds[j])
+		comms[j] = SecretMultiScalarMult([]ECPoint{g, h}, []*big.Int{new(big.Int).SetUint64(v), gammas[j]})
+		for i := 0; i < n; i++ {
+			aL[j*n+i] = big.NewInt(int64((v >> uint(i)) & 1))
+			aR[j*n+i] = new(big.Int).Sub(aL[j*n+i], big.NewInt(1))
+		}
+	}
+	// 以下各承诺的标量均为私密的比特、盲化因子或随机数，须用常数时间的乘法
+	alpha := RandScalar()
+	A := secretVectorCommit(params.BPG, params.BPH, aL, aR, h, alpha)
+
+	sL := RandVector(n * m)
+	sR := RandVector(n * m)
+	rho := RandScalar()
+	S := secretVectorCommit(params.BPG, params.BPH, sL, sR, h, rho)
+
+	cy, cz := rangeChallengesYZ(t, comms, A, S)
+
+	powersOfTwo := PowerVector(n, big.NewInt(2))
+	zPowersTimesTwo := make([]*big.Int, n*m)
+	for j := 0; j < m; j++ {
+		zp := new(big.Int).Exp(cz, big.NewInt(2+int64(j)), EC.N)
+		for i := 0; i < n; i++ {
+			zPowersTimesTwo[j*n+i] = new(big.Int).Mod(new(big.Int).Mul(powersOfTwo[i], zp), EC.N)
+		}
+	}
+	powersOfY := PowerVector(n*m, cy)
+	l0 := VectorAddScalar(aL, new(big.Int).Neg(cz))
+	l1 := sL
+	r0 := VectorAdd(VectorHadamard(powersOfY, VectorAddScalar(aR, cz)), zPowersTimesTwo)
+	r1 := VectorHadamard(sR, powersOfY)
+
+	t1 := new(big.Int).Mod(new(big.Int).Add(InnerProduct(l1, r0), InnerProduct(l0, r1)), EC.N)
+	t2 := InnerProduct(l1, r1)
+
+	tau1 := RandScalar()
+	tau2 := RandScalar()
+	T1 := SecretMultiScalarMult([]ECPoint{g, h}, []*big.Int{t1, tau1})
+	T2 := SecretMultiScalarMult([]ECPoint{g, h}, []*big.Int{t2, tau2})
+
+	cx := rangeChallengeX(t, T1, T2, S)
+
+	left := CalculateLMRP(aL, sL, cz, cx)
+	right := CalculateRMRP(aR, sR, powersOfY, zPowersTimesTwo, cz, cx)
+	that := InnerProduct(left, right)
+
+	taux := new(big.Int).Mul(tau2, new(big.Int).Mul(cx, cx))
+	taux.Add(taux, new(big.Int).Mul(tau1, cx))
+	for j := 0; j < m; j++ {
+		zp := new(big.Int).Exp(cz, big.NewInt(2+int64(j)), EC.N)
+		taux.Add(taux, new(big.Int).Mul(gammas[j], zp))
+	}
+	taux.Mod(taux, EC.N)
+	mu := new(big.Int).Mod(new(big.Int).Add(alpha, new(big.Int).Mul(rho, cx)), EC.N)
+	ZeroizeInt(alpha, rho, tau1, tau2, t1, t2)
+	ZeroizeInt(sL...)
+	ZeroizeInt(sR...)
+
+	hPrime := make([]ECPoint, n*m)
+	for i := range hPrime {
+		hPrime[i] = params.BPH[i].Mult(new(big.Int).ModInverse(powersOfY[i], EC.N))
+	}
+	P := secretVectorCommit(params.BPG, hPrime, left, right, params.U, new(big.Int))
+	ipp := InnerProductProve(t, left, right, that, P, params.U, params.BPG, hPrime)
+
+	return encodeRangeProof(rangeVersion(t), bits, MultiRangeProof{
+		A: A, S: S, T1: T1, T2: T2,
+		Tau: taux, Th: that, Mu: mu,
+		IPP: ipp,
+	}), nil
+}
+
+// VerifyTransferRangeProof verifies that the values of the given commitments
+// under pub lie in [0, 2^bits). t must be the transcript the proof was
+// generated against, nil for proofs of version 1. An error is returned if the
+// proof or a commitment cannot be decoded, false without error if the proof
+// is well formed but invalid.
+func VerifyTransferRangeProof(t *Transcript, pub PublicKey, comms [][]byte, proof []byte, bits int) (bool, error) {
+	if !ValidRangeProofBits(bits) {
+		return false, errRangeProofBits
+	}
+	if len(comms) == 0 || len(comms) > MaxRangeProofValues {
+		return false, errRangeProofComms
+	}
+	m := rangeProofSlots(len(comms))
+	mrp, err := decodeRangeProof(proof, rangeVersion(t), bits, m)
+	if err != nil {
+		return false, err
+	}
+	var (
+		pubb   = ConvertPub(pub)
+		g, h   = pubb.G1, pubb.H
+		n      = bits
+		params = rangeParams(n * m)
+	)
+	if g.X == nil || h.X == nil {
+		return false, nil
+	}
+	mrp.Comms = make([]ECPoint, m)
+	for j, comm := range comms {
+		if mrp.Comms[j], err = decodeField(fmt.Sprintf("Comms[%d]", j), comm); err != nil {
+			return false, err
+		}
+	}
+	t = rangeTranscript(t, bits, g, h)
+	for j := len(comms); j < m; j++ {
+		mrp.Comms[j] = h
+	}
+	cy, cz := rangeChallengesYZ(t, mrp.Comms, mrp.A, mrp.S)
+	cx := rangeChallengeX(t, mrp.T1, mrp.T2, mrp.S)
+
+	// t_hat * g + tau * h == z^2 * z^m * V + delta(y,z) * g + x * T1 + x^2 * T2
+	powersOfY := PowerVector(n*m, cy)
+	lhs := g.Mult(mrp.Th).Add(h.Mult(mrp.Tau))
+	rhs := g.Mult(rangeDelta(powersOfY, cz, n, m)).Add(mrp.T1.Mult(cx)).Add(mrp.T2.Mult(new(big.Int).Mul(cx, cx)))
+	for j := 0; j < m; j++ {
+		rhs = rhs.Add(mrp.Comms[j].Mult(new(big.Int).Exp(cz, big.NewInt(2+int64(j)), EC.N)))
+	}
+	if !lhs.Equal(rhs) {
+		return false, nil
+	}
+	// P = A + x*S - z*<1,G> + <z*y^i + z^(2+j)*2^i, H'> - mu*h
+	zneg := new(big.Int).Mod(new(big.Int).Neg(cz), EC.N)
+	powersOfTwo := PowerVector(n, big.NewInt(2))
+	P := mrp.A.Add(mrp.S.Mult(cx)).Add(h.Mult(mrp.Mu).Neg())
+	hPrime := make([]ECPoint, n*m)
+	for j := 0; j < m; j++ {
+		zp := new(big.Int).Exp(cz, big.NewInt(2+int64(j)), EC.N)
+		for i := 0; i < n; i++ {
+			k := j*n + i
+			hPrime[k] = params.BPH[k].Mult(new(big.Int).ModInverse(powersOfY[k], EC.N))
+			exp := new(big.Int).Add(new(big.Int).Mul(cz, powersOfY[k]), new(big.Int).Mul(zp, powersOfTwo[i]))
+			P = P.Add(params.BPG[k].Mult(zneg)).Add(hPrime[k].Mult(exp))
+		}
+	}
+	// Rebuild the inner product challenges, InnerProductVerify checks them again
+	ipp := mrp.IPP
+	ipp.Challenges = innerProductChallenges(t.Clone(), P, ipp)
+	return InnerProductVerify(t, mrp.Th, P, params.U, params.BPG, hPrime, ipp), nil
+}
+
+// encodeRangeProof serialises a range proof as version || bits || A || S ||
+// T1 || T2 || tau || t_hat || mu || a || b || L_i... || R_i..., using 65 byte
+// uncompressed points and 32 byte scalars.
+func encodeRangeProof(version byte, bits int, mrp MultiRangeProof) []byte {
+	enc := []byte{version, byte(bits)}
+	for _, p := range []ECPoint{mrp.A, mrp.S, mrp.T1, mrp.T2} {
+		enc = append(enc, elliptic.Marshal(EC.C, p.X, p.Y)...)
+	}
+	for _, s := range []*big.Int{mrp.Tau, mrp.Th, mrp.Mu, mrp.IPP.A, mrp.IPP.B} {
+		enc = append(enc, scalarBytes(s)...)
+	}
+	for _, p := range append(append([]ECPoint{}, mrp.IPP.L...), mrp.IPP.R...) {
+		enc = append(enc, elliptic.Marshal(EC.C, p.X, p.Y)...)
+	}
+	return enc
+}
+
+// decodeRangeProof parses an encoded range proof of the given version for the
+// given bit width and number of aggregated values.
+func decodeRangeProof(enc []byte, version byte, bits, m int) (MultiRangeProof, error) {
+	const pointLen, scalarLen = 65, 32
+
+	rounds := 0
+	for l := m * bits; l > 1; l >>= 1 {
+		rounds++
+	}
+	if len(enc) != 2+4*pointLen+5*scalarLen+2*rounds*pointLen {
+		return MultiRangeProof{}, errRangeProofEncoding
+	}
+	if enc[0] != version || int(enc[1]) != bits {
+		return MultiRangeProof{}, errRangeProofEncoding
+	}
+	enc = enc[2:]
+	var err error
+	points := make([]ECPoint, 4+2*rounds)
+	for i, field := range []string{"A", "S", "T1", "T2"} {
+		if points[i], err = decodeField(field, enc[:pointLen]); err != nil {
+			return MultiRangeProof{}, err
+		}
+		enc = enc[pointLen:]
+	}
+	scalars := make([]*big.Int, 5)
+	for i := range scalars {
+		scalars[i], enc = new(big.Int).SetBytes(enc[:scalarLen]), enc[scalarLen:]
+		if scalars[i].Cmp(EC.N) >= 0 {
+			return MultiRangeProof{}, errRangeProofEncoding
+		}
+	}
+	for i := 4; i < len(points); i++ {
+		field := fmt.Sprintf("L[%d]", i-4)
+		if i >= 4+rounds {
+			field = fmt.Sprintf("R[%d]", i-4-rounds)
+		}
+		if points[i], err = decodeField(field, enc[:pointLen]); err != nil {
+			return MultiRangeProof{}, err
+		}
+		enc = enc[pointLen:]
+	}
+	return MultiRangeProof{
+		A: points[0], S: points[1], T1: points[2], T2: points[3],
+		Tau: scalars[0], Th: scalars[1], Mu: scalars[2],
+		IPP: InnerProdArg{
+			L: points[4 : 4+rounds],
+			R: points[4+rounds:],
+			A: scalars[3],
+			B: scalars[4],
+		},
+	}, nil
+}
+
+// scalarBytes returns the 32 byte big endian encoding of a scalar.
+func scalarBytes(s *big.Int) []byte {
+	b := make([]byte, 32)
+	m := new(big.Int).Mod(s, EC.N).Bytes()
+	copy(b[32-len(m):], m)
+	return b
+}
@@ -0,0 +1,108 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestTransferRangeProof(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	tr := TxTranscript(big.NewInt(1), 0)
+
+	_, comms, _ := EncryptValue(pub, uint64(7))
+	_, commr, _ := EncryptValue(pub, uint64(1<<32-1))
+	comms2 := [][]byte{comms.Commitment, commr.Commitment}
+
+	proof, err := GenerateTransferRangeProof(tr, pub, []uint64{7, 1<<32 - 1}, [][]byte{comms.R, commr.R}, 32)
+	if err != nil {
+		t.Fatalf("failed to generate range proof: %v", err)
+	}
+	if !valid(VerifyTransferRangeProof(tr, pub, comms2, proof, 32)) {
+		t.Fatalf("valid range proof rejected")
+	}
+	// Proofs are bound to the bit width, the commitments and their order
+	if valid(VerifyTransferRangeProof(tr, pub, comms2, proof, 64)) {
+		t.Errorf("range proof accepted with a different bit width")
+	}
+	if valid(VerifyTransferRangeProof(tr, pub, [][]byte{commr.Commitment, comms.Commitment}, proof, 32)) {
+		t.Errorf("range proof accepted for swapped commitments")
+	}
+	other, _, _ := GenerateKeys("other")
+	if valid(VerifyTransferRangeProof(tr, other, comms2, proof, 32)) {
+		t.Errorf("range proof accepted under a different key")
+	}
+	// Any tampering must be detected, truncation must not panic
+	for _, i := range []int{2, 100, 300, len(proof) - 1} {
+		tampered := append([]byte{}, proof...)
+		tampered[i] ^= 0x01
+		if valid(VerifyTransferRangeProof(tr, pub, comms2, tampered, 32)) {
+			t.Errorf("tampered range proof accepted (byte %d)", i)
+		}
+	}
+	if valid(VerifyTransferRangeProof(tr, pub, comms2, proof[:len(proof)-1], 32)) {
+		t.Errorf("truncated range proof accepted")
+	}
+}
+
+func TestTransferRangeProofOverflow(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	tr := TxTranscript(big.NewInt(1), 0)
+
+	if _, err := GenerateTransferRangeProof(tr, pub, []uint64{1 << 32, 0}, [][]byte{{1}, {2}}, 32); err == nil {
+		t.Fatalf("out of range value accepted by the prover")
+	}
+	// A "negative" output, i.e. N-1, must not verify even with a forged witness
+	r := big.NewInt(5)
+	p := ConvertPub(pub)
+	neg := p.G1.Mult(new(big.Int).Sub(EC.N, big.NewInt(1))).Add(p.H.Mult(r))
+	zero := p.H.Mult(r)
+	proof, err := GenerateTransferRangeProof(tr, pub, []uint64{0, 0}, [][]byte{r.Bytes(), r.Bytes()}, 32)
+	if err != nil {
+		t.Fatalf("failed to generate range proof: %v", err)
+	}
+	comms := [][]byte{elliptic.Marshal(EC.C, neg.X, neg.Y), elliptic.Marshal(EC.C, zero.X, zero.Y)}
+	if valid(VerifyTransferRangeProof(tr, pub, comms, proof, 32)) {
+		t.Fatalf("range proof accepted for a negative value")
+	}
+}
+
+func TestTransferRangeProofValues(t *testing.T) {
+	pub, _, _ := GenerateKeys("regulator")
+	tr := TxTranscript(big.NewInt(1), 0)
+
+	for _, m := range []int{1, 3, 5} {
+		values := make([]uint64, m)
+		blinds := make([][]byte, m)
+		comms := make([][]byte, m)
+		for j := range values {
+			values[j] = uint64(j + 1)
+			_, comm, _ := EncryptValue(pub, values[j])
+			blinds[j], comms[j] = comm.R, comm.Commitment
+		}
+		proof, err := GenerateTransferRangeProof(tr, pub, values, blinds, 16)
+		if err != nil {
+			t.Fatalf("%d values: failed to generate range proof: %v", m, err)
+		}
+		if !valid(VerifyTransferRangeProof(tr, pub, comms, proof, 16)) {
+			t.Fatalf("%d values: valid range proof rejected", m)
+		}
+		// Padding slots must not be fillable with a caller supplied commitment
+		if m < rangeProofSlots(m) {
+			if valid(VerifyTransferRangeProof(tr, pub, append(comms, comms[0]), proof, 16)) {
+				t.Errorf("%d values: range proof accepted with an extra commitment", m)
+			}
+		}
+	}
+	if _, err := GenerateTransferRangeProof(tr, pub, make([]uint64, MaxRangeProofValues+1), make([][]byte, MaxRangeProofValues+1), 8); err == nil {
+		t.Errorf("range proof over too many values generated")
+	}
+}
+
+func TestRangeProofBits(t *testing.T) {
+	for bits, want := range map[int]bool{0: false, 8: true, 16: true, 24: false, 32: true, 64: true, 128: false} {
+		if have := ValidRangeProofBits(bits); have != want {
+			t.Errorf("bits %d: have %v, want %v", bits, have, want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package ecc
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+var updateVectors = flag.Bool("update", false, "regenerate testdata/vectors.json")
+
+const vectorsFile = "vectors.json"
+
+// vectorProofs are the proofs of a transfer of 7 out of a commitment of 10,
+// generated either against the transaction transcript or, for version 1,
+// without one.
+type vectorProofs struct {
+	Version     int
+	ChainID     *big.Int
+	TxType      uint8
+	Format      FormatProof
+	Equality    EqualityProof
+	Balance     BalanceProof
+	MultiBal    MultiBalanceProof
+	RangeProof  []byte
+	RangeBits   int
+	Commitments [][]byte // original, spent and returned commitment
+}
+
+type vectorValue struct {
+	Value      uint64
+	CypherText CypherText
+	Commitment Commitment
+}
+
+type vectors struct {
+	Key     PrivateKey
+	Values  []vectorValue
+	Blind   []byte
+	BlindCT CypherText
+	Proofs  []vectorProofs
+}
+
+// TestVectors checks the keys, cyphertexts and proofs of testdata/vectors.json
+// against the current code. The vectors were generated when the node, wallet,
+// exchange and regulator copies of the package were merged, so a change which
+// breaks them breaks the decryption or verification of data already on chain
+// and in the wallets.
+func TestVectors(t *testing.T) {
+	path := filepath.Join("testdata", vectorsFile)
+	if *updateVectors {
+		data, err := json.MarshalIndent(generateVectors(t), "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs vectors
+	if err := json.Unmarshal(data, &vs); err != nil {
+		t.Fatal(err)
+	}
+	pub := vs.Key.PublicKey
+	SetDLogConfig(DLogConfig{Budget: 1 << 12 * dlogEntrySize})
+	defer SetDLogConfig(DLogConfig{})
+
+	for _, v := range vs.Values {
+		if have, err := DecryptValue(vs.Key, v.CypherText); err != nil || have != v.Value {
+			t.Errorf("value %d: decrypted %d, %v", v.Value, have, err)
+		}
+		if cm := pub.CommitByUint64(v.Value, v.Commitment.R); !bytes.Equal(cm.Commitment, v.Commitment.Commitment) {
+			t.Errorf("value %d: commitment mismatch", v.Value)
+		}
+	}
+	if r, err := DecryptBlind(vs.Key, vs.BlindCT); err != nil || !bytes.Equal(r, vs.Blind) {
+		t.Errorf("blind: decrypted %x, %v", r, err)
+	}
+	for _, p := range vs.Proofs {
+		var tr *Transcript
+		if p.Version > 1 {
+			tr = TxTranscript(p.ChainID, p.TxType, p.Commitments...)
+		}
+		cmo, cms, cmr := p.Commitments[0], p.Commitments[1], p.Commitments[2]
+		if !valid(VerifyFormatProof(tr, vs.Values[1].CypherText, p.Format)) {
+			t.Errorf("version %d: format proof rejected", p.Version)
+		}
+		if !valid(VerifyEqualityProof(tr, p.Equality)) {
+			t.Errorf("version %d: equality proof rejected", p.Version)
+		}
+		if !valid(VerifyBalanceProof(tr, cmr, cms, cmo, p.Balance)) {
+			t.Errorf("version %d: balance proof rejected", p.Version)
+		}
+		if !valid(VerifyMultiBalanceProof(tr, [][]byte{cmo}, [][]byte{cms, cmr}, p.MultiBal)) {
+			t.Errorf("version %d: multi balance proof rejected", p.Version)
+		}
+		if !valid(VerifyTransferRangeProof(tr, pub, [][]byte{cms, cmr}, p.RangeProof, p.RangeBits)) {
+			t.Errorf("version %d: range proof rejected", p.Version)
+		}
+	}
+}
+
+// generateVectors encrypts the values 10, 7 and 3 and generates the proofs of
+// spending 7 out of 10 with and without transcript.
+func generateVectors(t *testing.T) vectors {
+	pub, priv, _ := GenerateKeys("vectors")
+	vs := vectors{Key: priv}
+	for _, v := range []uint64{10, 7, 3} {
+		ct, cm, err := EncryptValue(pub, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs.Values = append(vs.Values, vectorValue{v, ct, cm})
+	}
+	cmo, cms, cmr := vs.Values[0].Commitment, vs.Values[1].Commitment, vs.Values[2].Commitment
+	_, cmo2, _ := EncryptValue(pub, 10)
+
+	vs.Blind = cms.R
+	ct, err := EncryptBlind(pub, cms.R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs.BlindCT = ct
+
+	for _, version := range []int{1, 2} {
+		p := vectorProofs{
+			Version:     version,
+			ChainID:     big.NewInt(1),
+			RangeBits:   32,
+			Commitments: [][]byte{cmo.Commitment, cms.Commitment, cmr.Commitment},
+		}
+		var tr *Transcript
+		if version > 1 {
+			tr = TxTranscript(p.ChainID, p.TxType, p.Commitments...)
+		}
+		p.Format = GenerateFormatProof(tr, pub, 7, cms.R, vs.Values[1].CypherText)
+		p.Equality = GenerateEqualityProof(tr, pub, pub, cmo, cmo2, 10)
+		p.Balance = GenerateBalanceProof(tr, 3, 7, 10, cmr.Commitment, cms.Commitment, cmo.Commitment)
+		if p.MultiBal, err = GenerateMultiBalanceProof(tr, []uint64{10}, []uint64{7, 3}, [][]byte{cmo.Commitment}, [][]byte{cms.Commitment, cmr.Commitment}); err != nil {
+			t.Fatal(err)
+		}
+		if p.RangeProof, err = GenerateTransferRangeProof(tr, pub, []uint64{7, 3}, [][]byte{cms.R, cmr.R}, p.RangeBits); err != nil {
+			t.Fatal(err)
+		}
+		vs.Proofs = append(vs.Proofs, p)
+	}
+	return vs
+}
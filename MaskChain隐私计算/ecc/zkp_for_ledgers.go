@@ -0,0 +1,269 @@
+package ecc
+
+import "C"
+import (
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var errBalanceProofLength = errors.New("number of responses does not match the commitments")
+
+type FormatProof struct {
+	G1, G2 []byte
+	Y1, Y2 []byte
+	T1, T2 []byte
+	S  []byte
+	C  []byte
+}
+
+type BalanceProof struct {
+	Y, T []byte
+	Sn_1, Sn_2, Sn_3 []byte
+	C []byte
+}
+
+type EqualityProof struct {
+	FormatProof
+}
+
+// MultiBalanceProof proves that the values of the input commitments of a
+// transfer sum up to the values of its output commitments.
+type MultiBalanceProof struct {
+	Y, T []byte
+	Sn   [][]byte // one response per input, followed by one per output
+	C    []byte
+}
+
+// 以下证明的生成和验证都使用转录t的分支得出挑战，t通常为交易的TxTranscript，
+// 同一交易中的各证明由调用者用不同标签分支。t为nil时为版本1的挑战，仅用于
+// 验证使用转录之前的交易
+
+// formatTranscript 返回格式证明的转录分支，并绑定密文
+func formatTranscript(t *Transcript, enc CypherText) *Transcript {
+	if t = t.Fork("FormatProof"); t != nil {
+		t.AppendMessage("C1", enc.C1)
+		t.AppendMessage("C2", enc.C2)
+	}
+	return t
+}
+
+func GenerateFormatProof(t *Transcript, pub PublicKey, v uint64, r []byte, enc CypherText) (fp FormatProof) {
+	pubb := ConvertPub(pub)
+	rr := new(big.Int).SetBytes(r)
+	x1, y1 := elliptic.Unmarshal(EC.C, enc.C1)
+	enc_1 := ECPoint{x1,y1}
+	x2, y2 := elliptic.Unmarshal(EC.C, enc.C2)
+	enc_2 := ECPoint{x2,y2}
+	hr := enc_1.Add(pubb.G1.MultSecret(new(big.Int).SetUint64(v)).Neg())
+	formatproof := EPProof(formatTranscript(t, enc), hr, enc_2, rr)
+	fp.G1 = elliptic.Marshal(EC.C, formatproof.G1.X, formatproof.G1.Y)
+	fp.G2 = elliptic.Marshal(EC.C, formatproof.G2.X, formatproof.G2.Y)
+	fp.Y1 = elliptic.Marshal(EC.C, formatproof.Y1.X, formatproof.Y1.Y)
+	fp.Y2 = elliptic.Marshal(EC.C, formatproof.Y2.X, formatproof.Y2.Y)
+	fp.T1 = elliptic.Marshal(EC.C, formatproof.T1.X, formatproof.T1.Y)
+	fp.T2 = elliptic.Marshal(EC.C, formatproof.T2.X, formatproof.T2.Y)
+	fp.S = formatproof.S.Bytes()
+	fp.C = formatproof.C[:]
+	return
+}
+
+// 以下验证函数在证明或承诺中的点无法解码时返回FieldError，指明出错的字段；
+// 证明格式正确但不成立时返回false和nil
+
+// VerifyFormatProof 验证密文Ct的格式证明fp
+func VerifyFormatProof(t *Transcript, Ct CypherText, fp FormatProof) (bool, error) {
+	if _, err := decodeField("C1", Ct.C1); err != nil {
+		return false, err
+	}
+	if _, err := decodeField("C2", Ct.C2); err != nil {
+		return false, err
+	}
+	formatproof, err := decodeEP(fp)
+	if err != nil {
+		return false, err
+	}
+	return EPVerify(formatTranscript(t, Ct), formatproof), nil
+}
+
+func GenerateBalanceProof(t *Transcript, vR, vS, vO uint64, cmr, cms, cmo []byte) BalanceProof {
+	R := new(big.Int).SetUint64(vR)
+	S := new(big.Int).SetUint64(vS)
+	O := new(big.Int).SetUint64(vO)
+	rx, ry := elliptic.Unmarshal(EC.C, cmr)
+	commr := ECPoint{rx,ry}
+	sx, sy := elliptic.Unmarshal(EC.C, cms)
+	comms := ECPoint{sx,sy}
+	ox, oy := elliptic.Unmarshal(EC.C, cmo)
+	commo := ECPoint{ox,oy}
+	linearproof := Linear_equation_proof_tx(t.Fork("BalanceProof"), []ECPoint{commo, comms, commr}, []*big.Int{O, S, R}, []*big.Int{big.NewInt(-1), big.NewInt(1), big.NewInt(1)})
+	bp := BalanceProof{}
+	bp.Y = elliptic.Marshal(EC.C, linearproof.Y.X, linearproof.Y.Y)
+	bp.T = elliptic.Marshal(EC.C, linearproof.T.X, linearproof.T.Y)
+	bp.Sn_1 = linearproof.Sn[0].Bytes()
+	bp.Sn_2 = linearproof.Sn[1].Bytes()
+	bp.Sn_3 = linearproof.Sn[2].Bytes()
+	bp.C = linearproof.C[:]
+	return bp
+}
+
+// VerifyBalanceProof 验证CM_o的金额等于CM_s与CM_r的金额之和
+func VerifyBalanceProof(t *Transcript, CM_r, CM_s, CM_o []byte, bp BalanceProof) (bool, error) {
+	commr, err := decodeField("CM_r", CM_r)
+	if err != nil {
+		return false, err
+	}
+	comms, err := decodeField("CM_s", CM_s)
+	if err != nil {
+		return false, err
+	}
+	commo, err := decodeField("CM_o", CM_o)
+	if err != nil {
+		return false, err
+	}
+	linearproof, err := decodeLEP(bp.Y, bp.T, [][]byte{bp.Sn_1, bp.Sn_2, bp.Sn_3}, bp.C)
+	if err != nil {
+		return false, err
+	}
+	return LepVerify_tx(t.Fork("BalanceProof"), linearproof,[]ECPoint{commo,comms,commr}), nil
+}
+
+// balancePoints decodes the input and output commitments of a transfer and
+// returns them with the coefficients of the balance equation, -1 for inputs
+// and 1 for outputs.
+func balancePoints(cmIn, cmOut [][]byte) ([]ECPoint, []*big.Int, error) {
+	gn := make([]ECPoint, 0, len(cmIn)+len(cmOut))
+	an := make([]*big.Int, 0, len(cmIn)+len(cmOut))
+	for i, cm := range cmIn {
+		p, err := decodeField(fmt.Sprintf("In[%d]", i), cm)
+		if err != nil {
+			return nil, nil, err
+		}
+		gn = append(gn, p)
+		an = append(an, big.NewInt(-1))
+	}
+	for i, cm := range cmOut {
+		p, err := decodeField(fmt.Sprintf("Out[%d]", i), cm)
+		if err != nil {
+			return nil, nil, err
+		}
+		gn = append(gn, p)
+		an = append(an, big.NewInt(1))
+	}
+	return gn, an, nil
+}
+
+// GenerateMultiBalanceProof generates the balance proof of a transfer spending
+// the commitments cmIn of the values vIn into the commitments cmOut of the
+// values vOut. With one input and two outputs it is equivalent to
+// GenerateBalanceProof.
+func GenerateMultiBalanceProof(t *Transcript, vIn, vOut []uint64, cmIn, cmOut [][]byte) (MultiBalanceProof, error) {
+	if len(vIn) == 0 || len(vOut) == 0 || len(vIn) != len(cmIn) || len(vOut) != len(cmOut) {
+		return MultiBalanceProof{}, errors.New("mismatched balance proof values and commitments")
+	}
+	gn, an, err := balancePoints(cmIn, cmOut)
+	if err != nil {
+		return MultiBalanceProof{}, err
+	}
+	xn := make([]*big.Int, 0, len(gn))
+	for _, v := range append(append([]uint64{}, vIn...), vOut...) {
+		xn = append(xn, new(big.Int).SetUint64(v))
+	}
+	linearproof := Linear_equation_proof_tx(t.Fork("BalanceProof"), gn, xn, an)
+	bp := MultiBalanceProof{}
+	bp.Y = elliptic.Marshal(EC.C, linearproof.Y.X, linearproof.Y.Y)
+	bp.T = elliptic.Marshal(EC.C, linearproof.T.X, linearproof.T.Y)
+	for _, sn := range linearproof.Sn {
+		bp.Sn = append(bp.Sn, sn.Bytes())
+	}
+	bp.C = linearproof.C[:]
+	return bp, nil
+}
+
+// VerifyMultiBalanceProof verifies that the values of cmIn sum up to the
+// values of cmOut.
+func VerifyMultiBalanceProof(t *Transcript, cmIn, cmOut [][]byte, bp MultiBalanceProof) (bool, error) {
+	if len(cmIn) == 0 || len(cmOut) == 0 || len(bp.Sn) != len(cmIn)+len(cmOut) {
+		return false, &FieldError{"Sn", errBalanceProofLength}
+	}
+	gn, an, err := balancePoints(cmIn, cmOut)
+	if err != nil {
+		return false, err
+	}
+	linearproof, err := decodeLEP(bp.Y, bp.T, bp.Sn, bp.C)
+	if err != nil {
+		return false, err
+	}
+	return LepVerify_txn(t.Fork("BalanceProof"), linearproof, gn, an), nil
+}
+
+func GenerateEqualityProof(t *Transcript, pub1, pub2 PublicKey, C1, C2 Commitment, v uint) (ep EqualityProof) {
+	pubb1 := ConvertPub(pub1)
+	pubb2 := ConvertPub(pub2)
+	c1x, c1y := elliptic.Unmarshal(EC.C, C1.Commitment)
+	c1comm := ECPoint{c1x,c1y}
+	c2x, c2y := elliptic.Unmarshal(EC.C, C2.Commitment)
+	c2comm := ECPoint{c2x,c2y}
+	r1 := new(big.Int).SetBytes(C1.R)
+	r2 := new(big.Int).SetBytes(C2.R)
+	equalityproof := EPProof(t.Fork("EqualityProof"), c1comm.Add(pubb1.H.MultSecret(r1).Neg()), c2comm.Add(pubb2.H.MultSecret(r2).Neg()), new(big.Int).SetUint64(uint64(v)))
+	ep.G1 = elliptic.Marshal(EC.C, equalityproof.G1.X, equalityproof.G1.Y)
+	ep.G2 = elliptic.Marshal(EC.C, equalityproof.G2.X, equalityproof.G2.Y)
+	ep.Y1 = elliptic.Marshal(EC.C, equalityproof.Y1.X, equalityproof.Y1.Y)
+	ep.Y2 = elliptic.Marshal(EC.C, equalityproof.Y2.X, equalityproof.Y2.Y)
+	ep.T1 = elliptic.Marshal(EC.C, equalityproof.T1.X, equalityproof.T1.Y)
+	ep.T2 = elliptic.Marshal(EC.C, equalityproof.T2.X, equalityproof.T2.Y)
+	ep.S = equalityproof.S.Bytes()
+	ep.C = equalityproof.C[:]
+	return
+}
+
+// VerifyEqualityProof 验证相等证明ep
+func VerifyEqualityProof(t *Transcript, ep EqualityProof) (bool, error) {
+	equalityproof, err := decodeEP(ep.FormatProof)
+	if err != nil {
+		return false, err
+	}
+	return EPVerify(t.Fork("EqualityProof"), equalityproof), nil
+}
+
+// decodeEP decodes the points of a format or equality proof.
+func decodeEP(fp FormatProof) (EP, error) {
+	ep := EP{S: new(big.Int).SetBytes(fp.S), C: BytesToHash(fp.C)}
+	for _, p := range []struct {
+		field string
+		dst   *ECPoint
+		enc   []byte
+	}{{"G1", &ep.G1, fp.G1}, {"G2", &ep.G2, fp.G2}, {"Y1", &ep.Y1, fp.Y1}, {"Y2", &ep.Y2, fp.Y2}, {"T1", &ep.T1, fp.T1}, {"T2", &ep.T2, fp.T2}} {
+		var err error
+		if *p.dst, err = decodeField(p.field, p.enc); err != nil {
+			return EP{}, err
+		}
+	}
+	return ep, nil
+}
+
+// decodeLEP decodes the commitment, responses and challenge of a balance
+// proof.
+func decodeLEP(y, t []byte, sn [][]byte, c []byte) (LEP_tx, error) {
+	var (
+		lep = LEP_tx{C: BytesToHash(c)}
+		err error
+	)
+	if lep.Y, err = decodeField("Y", y); err != nil {
+		return LEP_tx{}, err
+	}
+	if lep.T, err = decodeField("T", t); err != nil {
+		return LEP_tx{}, err
+	}
+	for _, s := range sn {
+		lep.Sn = append(lep.Sn, new(big.Int).SetBytes(s))
+	}
+	return lep, nil
+}
+
+func GenerateAddressEqualityProof(t *Transcript, pub1, pub2 PublicKey, C1, C2 Commitment, addr []byte) (ep EqualityProof) {
+	return GenerateEqualityProof(t, pub1, pub2, C1, C2, uint(binary.BigEndian.Uint64(addr)))
+}
@@ -0,0 +1,107 @@
+package ecc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGenFormatProof(t *testing.T) {
+	tr := NewTranscript("test")
+	pub, _, _ := GenerateKeys("Trump, forever God!")
+	cypher, comm, _ := EncryptValue(pub, uint64(12))
+	ep := GenerateFormatProof(tr, pub, uint64((12)), comm.R, cypher)
+	if valid(VerifyFormatProof(tr, cypher, ep)){
+		fmt.Println("Format Proof works")
+	} else {fmt.Println("format proof failed")}
+}
+
+func TestGenBalanceProof(t *testing.T) {
+	tr := NewTranscript("test")
+	// Testing smallest number in range
+	pub1, _, _ := GenerateKeys("Trump, forever God!1")
+	pub2, _, _ := GenerateKeys("Trump, forever God!2")
+	pub3, _, _ := GenerateKeys("Trump, forever God!3")
+
+	_, commr, _ := EncryptValue(pub2, uint64(3))
+	_, comms, _ := EncryptValue(pub3, uint64(2))
+	_, commo, _ := EncryptValue(pub1, uint64(5))
+
+	blp := GenerateBalanceProof(tr, uint64(3),uint64(2),uint64(5),commr.Commitment, comms.Commitment,commo.Commitment)
+
+	if valid(VerifyBalanceProof(tr, commr.Commitment, comms.Commitment,commo.Commitment,blp)){
+		fmt.Println("Balance Proof works")
+	} else {fmt.Println("Balance proof failed")}
+}
+
+func TestGenEqualityProof(t *testing.T) {
+	tr := NewTranscript("test")
+	pub1, _, _ := GenerateKeys("Trump, forever God!1")
+	pub2, _, _ := GenerateKeys("Trump, forever God!2")
+
+	_, comm1, _ := EncryptValue(pub1, uint64(100))
+	_, comm2, _ := EncryptValue(pub2, uint64(100))
+
+
+	epp := GenerateEqualityProof(tr, pub1, pub2, comm1, comm2, uint(100))
+
+	if valid(VerifyEqualityProof(tr, epp)){
+		fmt.Println("Equality Proof works")
+	} else {fmt.Println("Equality proof failed")}
+}
+
+func TestGenAddrEqualityProof(t *testing.T) {
+	tr := NewTranscript("test")
+	pub1, _, _ := GenerateKeys("Trump, forever God!")
+	_, CMrpk, _ := EncryptAddress(pub1, []byte("Make USA Great Again!"))
+	epp := GenerateAddressEqualityProof(tr, pub1, pub1, CMrpk, CMrpk, []byte("Make USA Great Again!"))
+	if valid(VerifyEqualityProof(tr, epp)){
+		fmt.Println("Equality Proof works")
+	} else {fmt.Println("Equality proof failed")}
+}
+
+func TestGenMultiBalanceProof(t *testing.T) {
+	tr := NewTranscript("test")
+	pub, _, _ := GenerateKeys("regulator")
+
+	commit := func(values ...uint64) [][]byte {
+		var comms [][]byte
+		for _, v := range values {
+			_, comm, _ := EncryptValue(pub, v)
+			comms = append(comms, comm.Commitment)
+		}
+		return comms
+	}
+	cmIn, cmOut := commit(3, 4, 5), commit(6, 2, 1, 3)
+	bp, err := GenerateMultiBalanceProof(tr, []uint64{3, 4, 5}, []uint64{6, 2, 1, 3}, cmIn, cmOut)
+	if err != nil {
+		t.Fatalf("failed to generate balance proof: %v", err)
+	}
+	if !valid(VerifyMultiBalanceProof(tr, cmIn, cmOut, bp)) {
+		t.Fatalf("valid balance proof rejected")
+	}
+	if !valid(VerifyMultiBalanceProof(tr, cmIn, cmOut, bp)) {
+		t.Fatalf("balance proof rejected on second verification")
+	}
+	// The proof is bound to the commitments, their roles and their number
+	if valid(VerifyMultiBalanceProof(tr, cmOut[:3], append(cmIn, cmOut[3]), bp)) {
+		t.Errorf("balance proof accepted with swapped inputs and outputs")
+	}
+	if valid(VerifyMultiBalanceProof(tr, cmIn, cmOut[:3], bp)) {
+		t.Errorf("balance proof accepted with a missing output")
+	}
+	if valid(VerifyMultiBalanceProof(tr, cmIn, append(commit(6), cmOut[1:]...), bp)) {
+		t.Errorf("balance proof accepted for a different output")
+	}
+	// Truncated or undecodable proofs must be rejected without panicking
+	if valid(VerifyMultiBalanceProof(tr, cmIn, cmOut, MultiBalanceProof{Y: bp.Y, T: bp.T, Sn: bp.Sn[1:], C: bp.C})) {
+		t.Errorf("balance proof accepted with a missing response")
+	}
+	if valid(VerifyMultiBalanceProof(tr, cmIn, cmOut, MultiBalanceProof{Y: []byte{4}, T: bp.T, Sn: bp.Sn, C: bp.C})) {
+		t.Errorf("balance proof accepted with an invalid point")
+	}
+}
+
+// valid reports whether a verifier accepted a proof without error.
+func valid(ok bool, err error) bool {
+	return ok && err == nil
+}
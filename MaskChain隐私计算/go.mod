@@ -0,0 +1,5 @@
+module maskchain/privacy
+
+go 1.14
+
+require github.com/btcsuite/btcd v0.0.0-20171128150713-2e60448ffcc6
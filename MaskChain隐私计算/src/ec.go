@@ -1,37 +0,0 @@
-package bp
-
-import "math/big"
-
-var EC CryptoParams
-
-type ECPoint struct {
-	X, Y *big.Int
-}
-
-// Equal returns true if points p (self) and p2 (arg) are the same.
-func (p ECPoint) Equal(p2 ECPoint) bool {
-	if p.X.Cmp(p2.X) == 0 && p2.Y.Cmp(p2.Y) == 0 {
-		return true
-	}
-	return false
-}
-
-// Mult multiplies point p by scalar s and returns the resulting point
-func (p ECPoint) Mult(s *big.Int) ECPoint {
-	modS := new(big.Int).Mod(s, EC.N)
-	X, Y := EC.C.ScalarMult(p.X, p.Y, modS.Bytes())
-	return ECPoint{X, Y}
-}
-
-// Add adds points p and p2 and returns the resulting point
-func (p ECPoint) Add(p2 ECPoint) ECPoint {
-	X, Y := EC.C.Add(p.X, p.Y, p2.X, p2.Y)
-	return ECPoint{X, Y}
-}
-
-// Neg returns the additive inverse of point p
-func (p ECPoint) Neg() ECPoint {
-	negY := new(big.Int).Neg(p.Y)
-	modValue := negY.Mod(negY, EC.C.Params().P) // mod P is fine here because we're describing a curve point
-	return ECPoint{p.X, modValue}
-}
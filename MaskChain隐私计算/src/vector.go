@@ -1,154 +0,0 @@
-package bp
-
-import (
-	"crypto/rand"
-	"fmt"
-	"math/big"
-)
-
-// The length here always has to be a power of two
-func InnerProduct(a []*big.Int, b []*big.Int) *big.Int {
-	if len(a) != len(b) {
-		fmt.Println("InnerProduct: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(a): %d\n", len(a))
-		fmt.Printf("len(b): %d\n", len(b))
-	}
-
-	c := big.NewInt(0)
-
-	for i := range a {
-		tmp1 := new(big.Int).Mul(a[i], b[i])
-		c = new(big.Int).Add(c, new(big.Int).Mod(tmp1, EC.N))
-	}
-
-	return new(big.Int).Mod(c, EC.N)
-}
-
-func VectorAdd(v []*big.Int, w []*big.Int) []*big.Int {
-	if len(v) != len(w) {
-		fmt.Println("VectorAdd: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(v): %d\n", len(v))
-		fmt.Printf("len(w): %d\n", len(w))
-	}
-	result := make([]*big.Int, len(v))
-
-	for i := range v {
-		result[i] = new(big.Int).Mod(new(big.Int).Add(v[i], w[i]), EC.N)
-	}
-
-	return result
-}
-
-func VectorHadamard(v, w []*big.Int) []*big.Int {
-	if len(v) != len(w) {
-		fmt.Println("VectorHadamard: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(v): %d\n", len(w))
-		fmt.Printf("len(w): %d\n", len(v))
-	}
-
-	result := make([]*big.Int, len(v))
-
-	for i := range v {
-		result[i] = new(big.Int).Mod(new(big.Int).Mul(v[i], w[i]), EC.N)
-	}
-
-	return result
-}
-
-func VectorAddScalar(v []*big.Int, s *big.Int) []*big.Int {
-	result := make([]*big.Int, len(v))
-
-	for i := range v {
-		result[i] = new(big.Int).Mod(new(big.Int).Add(v[i], s), EC.N)
-	}
-
-	return result
-}
-
-func ScalarVectorMul(v []*big.Int, s *big.Int) []*big.Int {
-	result := make([]*big.Int, len(v))
-
-	for i := range v {
-		result[i] = new(big.Int).Mod(new(big.Int).Mul(v[i], s), EC.N)
-	}
-
-	return result
-}
-
-// from here: https://play.golang.org/p/zciRZvD0Gr with a fix
-func PadLeft(str, pad string, l int) string {
-	strCopy := str
-	for len(strCopy) < l {
-		strCopy = pad + strCopy
-	}
-
-	return strCopy
-}
-
-func STRNot(str string) string {
-	result := ""
-
-	for _, i := range str {
-		if i == '0' {
-			result += "1"
-		} else {
-			result += "0"
-		}
-	}
-	return result
-}
-
-func StrToBigIntArray(str string) []*big.Int {
-	result := make([]*big.Int, len(str))
-
-	for i := range str {
-		t, success := new(big.Int).SetString(string(str[i]), 10)
-		if success {
-			result[i] = t
-		}
-	}
-
-	return result
-}
-
-func reverse(l []*big.Int) []*big.Int {
-	result := make([]*big.Int, len(l))
-
-	for i := range l {
-		result[i] = l[len(l)-i-1]
-	}
-
-	return result
-}
-
-func PowerVector(l int, base *big.Int) []*big.Int {
-	result := make([]*big.Int, l)
-
-	for i := 0; i < l; i++ {
-		result[i] = new(big.Int).Exp(base, big.NewInt(int64(i)), EC.N)
-	}
-
-	return result
-}
-
-func RandVector(l int) []*big.Int {
-	result := make([]*big.Int, l)
-
-	for i := 0; i < l; i++ {
-		x, err := rand.Int(rand.Reader, EC.N)
-		check(err)
-		result[i] = x
-	}
-
-	return result
-}
-
-func VectorSum(y []*big.Int) *big.Int {
-	result := big.NewInt(0)
-
-	for _, j := range y {
-		result = new(big.Int).Mod(new(big.Int).Add(result, j), EC.N)
-	}
-
-	return result
-}